@@ -40,6 +40,10 @@ func SetDefaultsSidecarSet(obj *v1alpha1.SidecarSet) {
 
 	//default setting volumes
 	SetDefaultPodVolumes(obj.Spec.Volumes)
+
+	if obj.Spec.RevisionHistoryLimit == nil {
+		obj.Spec.RevisionHistoryLimit = utilpointer.Int32Ptr(10)
+	}
 }
 
 func setDefaultSidecarContainer(sidecarContainer *v1alpha1.SidecarContainer) {
@@ -179,6 +183,34 @@ func SetDefaultsUnitedDeployment(obj *v1alpha1.UnitedDeployment, injectTemplateD
 		obj.Spec.UpdateStrategy.ManualUpdate = &v1alpha1.ManualUpdate{}
 	}
 
+	if obj.Spec.UpdateStrategy.Type == v1alpha1.AutoUpdateStrategyType {
+		if obj.Spec.UpdateStrategy.AutoUpdate == nil {
+			obj.Spec.UpdateStrategy.AutoUpdate = &v1alpha1.AutoUpdate{}
+		}
+		if len(obj.Spec.UpdateStrategy.AutoUpdate.Order) == 0 {
+			obj.Spec.UpdateStrategy.AutoUpdate.Order = v1alpha1.OrderedAutoUpdateOrderType
+		}
+		if obj.Spec.UpdateStrategy.AutoUpdate.Partition == nil {
+			obj.Spec.UpdateStrategy.AutoUpdate.Partition = utilpointer.Int32Ptr(0)
+		}
+		if obj.Spec.UpdateStrategy.AutoUpdate.MaxUnavailable == nil {
+			maxUnavailable := intstr.FromString(v1alpha1.DefaultUnitedDeploymentMaxUnavailable)
+			obj.Spec.UpdateStrategy.AutoUpdate.MaxUnavailable = &maxUnavailable
+		}
+	}
+
+	if obj.Spec.Topology.ScheduleStrategy.Type == v1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType {
+		if obj.Spec.Topology.ScheduleStrategy.Adaptive == nil {
+			obj.Spec.Topology.ScheduleStrategy.Adaptive = &v1alpha1.AdaptiveUnitedDeploymentStrategy{}
+		}
+		if obj.Spec.Topology.ScheduleStrategy.Adaptive.RescheduleCriticalSeconds == nil {
+			obj.Spec.Topology.ScheduleStrategy.Adaptive.RescheduleCriticalSeconds = utilpointer.Int32Ptr(v1alpha1.DefaultRescheduleCriticalSeconds)
+		}
+		if obj.Spec.Topology.ScheduleStrategy.Adaptive.UnschedulableLastSeconds == nil {
+			obj.Spec.Topology.ScheduleStrategy.Adaptive.UnschedulableLastSeconds = utilpointer.Int32Ptr(v1alpha1.DefaultUnschedulableLastSeconds)
+		}
+	}
+
 	if obj.Spec.Template.StatefulSetTemplate != nil {
 		if injectTemplateDefaults {
 			SetDefaultPodSpec(&obj.Spec.Template.StatefulSetTemplate.Spec.Template.Spec)
@@ -191,6 +223,28 @@ func SetDefaultsUnitedDeployment(obj *v1alpha1.UnitedDeployment, injectTemplateD
 			}
 		}
 	}
+
+	if obj.Spec.Template.GenericTemplate != nil {
+		paths := &obj.Spec.Template.GenericTemplate.Paths
+		if paths.ReplicasPath == "" {
+			paths.ReplicasPath = v1alpha1.DefaultGenericReplicasPath
+		}
+		if paths.SelectorPath == "" {
+			paths.SelectorPath = v1alpha1.DefaultGenericSelectorPath
+		}
+		if paths.TemplatePath == "" {
+			paths.TemplatePath = v1alpha1.DefaultGenericTemplatePath
+		}
+		if paths.StatusReplicasPath == "" {
+			paths.StatusReplicasPath = v1alpha1.DefaultGenericStatusReplicasPath
+		}
+		if paths.StatusReadyReplicasPath == "" {
+			paths.StatusReadyReplicasPath = v1alpha1.DefaultGenericStatusReadyReplicasPath
+		}
+		if paths.ObservedGenerationPath == "" {
+			paths.ObservedGenerationPath = v1alpha1.DefaultGenericObservedGenerationPath
+		}
+	}
 }
 
 // SetDefaults_CloneSet set default values for CloneSet.
@@ -45,6 +45,13 @@ const (
 	// RuntimeContainerMetaKey is a key in pod annotations. Kruise-daemon should report the
 	// states of runtime containers into its value, which is a structure JSON of RuntimeContainerMetaSet type.
 	RuntimeContainerMetaKey = "apps.kruise.io/runtime-containers-meta"
+
+	// InPlaceUpdateResourcesKey records the resources that containers should be resized to by in-place update.
+	// The value of annotation is a JSON map from container name to ResourceRequirements.
+	// Since resources in pod spec are immutable, kruise-daemon applies them to the runtime containers only,
+	// which means the resize is status-only: pod.spec.containers[].resources keeps the old values, so that
+	// kube-scheduler, ResourceQuota and the eviction of kubelet still account the Pod by the old resources.
+	InPlaceUpdateResourcesKey = "apps.kruise.io/inplace-update-resources"
 )
 
 // InPlaceUpdateState records latest inplace-update state, including old statuses of containers.
@@ -58,6 +65,10 @@ type InPlaceUpdateState struct {
 	// LastContainerStatuses records the before-in-place-update container statuses. It is a map from ContainerName
 	// to InPlaceUpdateContainerStatus
 	LastContainerStatuses map[string]InPlaceUpdateContainerStatus `json:"lastContainerStatuses"`
+
+	// UpdateEnvFromMetadata indicates there are containers in LastContainerStatuses whose env refers to
+	// the labels/annotations that have been changed in this in-place update, so kruise-daemon should restart them.
+	UpdateEnvFromMetadata bool `json:"updateEnvFromMetadata,omitempty"`
 }
 
 // InPlaceUpdateContainerStatus records the statuses of the container that are mainly used
@@ -105,6 +116,9 @@ type RuntimeContainerMeta struct {
 	ContainerID  string                 `json:"containerID"`
 	RestartCount int32                  `json:"restartCount"`
 	Hashes       RuntimeContainerHashes `json:"hashes"`
+	// Resources is the resources that have been applied to the runtime container by kruise-daemon
+	// for in-place resizing. It is nil if the container has never been resized.
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
 }
 
 // RuntimeContainerHashes contains the hashes of such container.
@@ -112,7 +126,23 @@ type RuntimeContainerHashes struct {
 	// PlainHash is the hash that directly calculated from pod.spec.container[x].
 	// Usually it is calculated by Kubelet and will be in annotation of each runtime container.
 	PlainHash uint64 `json:"plainHash"`
-	// TODO: add ConvertEnvHash here to support inplace update for env from annotation/label
+	// ExtractedEnvFromMetadataHash is the hash that calculated from pod.spec.container[x],
+	// whose env from metadata labels/annotations has been converted into the values when the container started.
+	// It is reported by kruise-daemon and is zero if the container has no env from metadata.
+	ExtractedEnvFromMetadataHash uint64 `json:"extractedEnvFromMetadataHash,omitempty"`
+}
+
+func GetInPlaceUpdateResources(obj metav1.Object) (map[string]v1.ResourceRequirements, error) {
+	str, ok := obj.GetAnnotations()[InPlaceUpdateResourcesKey]
+	if !ok {
+		return nil, nil
+	}
+
+	resources := make(map[string]v1.ResourceRequirements)
+	if err := json.Unmarshal([]byte(str), &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
 }
 
 func GetRuntimeContainerMetaSet(obj metav1.Object) (*RuntimeContainerMetaSet, error) {
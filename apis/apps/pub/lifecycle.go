@@ -20,6 +20,7 @@ const (
 	LifecycleStateKey     = "lifecycle.apps.kruise.io/state"
 	LifecycleTimestampKey = "lifecycle.apps.kruise.io/timestamp"
 
+	LifecycleStatePreparingNormal LifecycleStateType = "PreparingNormal"
 	LifecycleStateNormal          LifecycleStateType = "Normal"
 	LifecycleStatePreparingUpdate LifecycleStateType = "PreparingUpdate"
 	LifecycleStateUpdating        LifecycleStateType = "Updating"
@@ -31,6 +32,8 @@ type LifecycleStateType string
 
 // Lifecycle contains the hooks for Pod lifecycle.
 type Lifecycle struct {
+	// PreNormal is the hook after Pod to be created and before it to be counted as available.
+	PreNormal *LifecycleHook `json:"preNormal,omitempty"`
 	// PreDelete is the hook before Pod to be deleted.
 	PreDelete *LifecycleHook `json:"preDelete,omitempty"`
 	// InPlaceUpdate is the hook before Pod to update and after Pod has been updated.
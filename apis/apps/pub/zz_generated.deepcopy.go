@@ -20,7 +20,9 @@ limitations under the License.
 
 package pub
 
-import ()
+import (
+	"k8s.io/api/core/v1"
+)
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InPlaceUpdateContainerStatus) DeepCopyInto(out *InPlaceUpdateContainerStatus) {
@@ -78,6 +80,11 @@ func (in *InPlaceUpdateStrategy) DeepCopy() *InPlaceUpdateStrategy {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Lifecycle) DeepCopyInto(out *Lifecycle) {
 	*out = *in
+	if in.PreNormal != nil {
+		in, out := &in.PreNormal, &out.PreNormal
+		*out = new(LifecycleHook)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PreDelete != nil {
 		in, out := &in.PreDelete, &out.PreDelete
 		*out = new(LifecycleHook)
@@ -146,6 +153,11 @@ func (in *RuntimeContainerHashes) DeepCopy() *RuntimeContainerHashes {
 func (in *RuntimeContainerMeta) DeepCopyInto(out *RuntimeContainerMeta) {
 	*out = *in
 	out.Hashes = in.Hashes
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuntimeContainerMeta.
@@ -164,7 +176,9 @@ func (in *RuntimeContainerMetaSet) DeepCopyInto(out *RuntimeContainerMetaSet) {
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
 		*out = make([]RuntimeContainerMeta, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -76,6 +76,11 @@ type CronJobTemplate struct {
 	// Specifies the broadcastjob that will be created when executing a BroadcastCronJob.
 	// +optional
 	BroadcastJobTemplate *BroadcastJobTemplateSpec `json:"broadcastJobTemplate,omitempty" protobuf:"bytes,2,opt,name=broadcastJobTemplate"`
+
+	// Specifies the imagepulljob that will be created when executing a CronJob.
+	// The completionPolicy of the imagepulljob should be Always, otherwise the jobs created will never finish.
+	// +optional
+	ImagePullJobTemplate *ImagePullJobTemplateSpec `json:"imagePullJobTemplate,omitempty" protobuf:"bytes,3,opt,name=imagePullJobTemplate"`
 }
 
 type TemplateKind string
@@ -84,6 +89,8 @@ const (
 	JobTemplate TemplateKind = "Job"
 
 	BroadcastJobTemplate TemplateKind = "BroadcastJob"
+
+	ImagePullJobTemplate TemplateKind = "ImagePullJob"
 )
 
 // JobTemplateSpec describes the data a Job should have when created from a template
@@ -97,6 +104,17 @@ type BroadcastJobTemplateSpec struct {
 	Spec BroadcastJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 }
 
+// ImagePullJobTemplateSpec describes the data an ImagePullJob should have when created from a template
+type ImagePullJobTemplateSpec struct {
+	// Standard object's metadata of the jobs created from this template.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the desired behavior of the imagepulljob.
+	// +optional
+	Spec ImagePullJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
 // ConcurrencyPolicy describes how the job will be handled.
 // Only one of the following concurrent policies may be specified.
 // If none of the following policies is specified, the default one
@@ -30,6 +30,14 @@ const (
 
 	// DefaultCloneSetMaxUnavailable is the default value of maxUnavailable for CloneSet update strategy.
 	DefaultCloneSetMaxUnavailable = "20%"
+
+	// CloneSetApprovedStepAnnotation is the annotation to approve the update step of CloneSet which is paused
+	// without duration. Its value should be the update revision and the index of the step to approve,
+	// such as "sample-5d8b6c7f9/1", so that an approval will not be taken by the steps of a newer revision.
+	CloneSetApprovedStepAnnotation = "apps.kruise.io/cloneset-approved-step"
+
+	// DefaultCloneSetRollbackNotReadyTimeoutSeconds is the default value of notReadyTimeoutSeconds for CloneSet rollback policy.
+	DefaultCloneSetRollbackNotReadyTimeoutSeconds = 600
 )
 
 // CloneSetSpec defines the desired state of CloneSet
@@ -71,7 +79,7 @@ type CloneSetSpec struct {
 	// Defaults to 0 (pod will be considered available as soon as it is ready)
 	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
 
-	// Lifecycle defines the lifecycle hooks for Pods pre-delete, in-place update.
+	// Lifecycle defines the lifecycle hooks for Pods pre-normal, pre-delete, in-place update.
 	Lifecycle *appspub.Lifecycle `json:"lifecycle,omitempty"`
 }
 
@@ -117,6 +125,43 @@ type CloneSetUpdateStrategy struct {
 	ScatterStrategy UpdateScatterStrategy `json:"scatterStrategy,omitempty"`
 	// InPlaceUpdateStrategy contains strategies for in-place update.
 	InPlaceUpdateStrategy *appspub.InPlaceUpdateStrategy `json:"inPlaceUpdateStrategy,omitempty"`
+	// Steps define the batches to update pods to the update revision in order.
+	// The controller calculates the partition from the current step, so Partition should be 0 when Steps are used.
+	Steps []CloneSetUpdateStep `json:"steps,omitempty"`
+	// RollbackPolicy defines the condition to roll back pods to the current revision automatically during steps.
+	RollbackPolicy *CloneSetRollbackPolicy `json:"rollbackPolicy,omitempty"`
+}
+
+// CloneSetUpdateStep defines a batch of CloneSet update.
+type CloneSetUpdateStep struct {
+	// Replicas is the desired number of pods in update revision after this step.
+	// Value can be an absolute number (ex: 5) or a percentage of desired pods (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up.
+	Replicas intstr.IntOrString `json:"replicas"`
+	// Pause indicates the update will be paused after the pods of this step are ready.
+	// If it is nil, the update continues to the next step immediately.
+	Pause *CloneSetUpdateStepPause `json:"pause,omitempty"`
+}
+
+// CloneSetUpdateStepPause defines how long the update is paused after a step.
+type CloneSetUpdateStepPause struct {
+	// DurationSeconds is the seconds to pause before continuing to the next step.
+	// If it is not set, the update is paused until the step is approved by annotation
+	// apps.kruise.io/cloneset-approved-step with the update revision and the index of this step,
+	// such as "sample-5d8b6c7f9/1".
+	DurationSeconds *int32 `json:"durationSeconds,omitempty"`
+}
+
+// CloneSetRollbackPolicy defines the condition to roll back automatically.
+type CloneSetRollbackPolicy struct {
+	// MaxFailedPods is the maximum number of failed pods in update revision, and the pods will be rolled back
+	// to the current revision once it is exceeded.
+	// Value can be an absolute number (ex: 1) or a percentage of the pods in update revision (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up.
+	MaxFailedPods *intstr.IntOrString `json:"maxFailedPods,omitempty"`
+	// NotReadyTimeoutSeconds is the seconds after which a not-ready pod in update revision is regarded as failed.
+	// Defaults to 600.
+	NotReadyTimeoutSeconds *int32 `json:"notReadyTimeoutSeconds,omitempty"`
 }
 
 // CloneSetUpdateStrategyType defines strategies for pods in-place update.
@@ -175,8 +220,32 @@ type CloneSetStatus struct {
 
 	// LabelSelector is label selectors for query over pods that should match the replica count used by HPA.
 	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// CurrentStepIndex is the index of the update step that CloneSet is in, only set when steps are used.
+	CurrentStepIndex *int32 `json:"currentStepIndex,omitempty"`
+
+	// CurrentStepState is the state of the current update step.
+	CurrentStepState CloneSetUpdateStepState `json:"currentStepState,omitempty"`
+
+	// LastStepTransitionTime is the last time the current update step or its state changed.
+	LastStepTransitionTime *metav1.Time `json:"lastStepTransitionTime,omitempty"`
 }
 
+// CloneSetUpdateStepState is the state of CloneSet update step.
+type CloneSetUpdateStepState string
+
+const (
+	// CloneSetStepUpgrading means the pods of current step are being updated.
+	CloneSetStepUpgrading CloneSetUpdateStepState = "Upgrading"
+	// CloneSetStepPaused means the pods of current step are ready and the update is paused.
+	CloneSetStepPaused CloneSetUpdateStepState = "Paused"
+	// CloneSetStepCompleted means all the steps have been completed.
+	CloneSetStepCompleted CloneSetUpdateStepState = "Completed"
+	// CloneSetStepRolledBack means the failed pods exceeded the rollback policy, and
+	// the pods are rolled back to the current revision.
+	CloneSetStepRolledBack CloneSetUpdateStepState = "RolledBack"
+)
+
 // CloneSetConditionType is type for CloneSet conditions.
 type CloneSetConditionType string
 
@@ -29,7 +29,19 @@ const (
 // ImagePullJobSpec defines the desired state of ImagePullJob
 type ImagePullJobSpec struct {
 	// Image is the image to be pulled by the job
-	Image string `json:"image"`
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Images is a list of images to be pulled by the job, in addition to Image.
+	// All the images will be pulled on the same nodes.
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// ImagesFromWorkload refers to a workload in the same namespace, the images of whose pod template
+	// will be collected and pulled by the job, in addition to Image and Images.
+	// Only CloneSet and Advanced StatefulSet are supported.
+	// +optional
+	ImagesFromWorkload *ImagePullJobWorkloadReference `json:"imagesFromWorkload,omitempty"`
 
 	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling the image.
 	// If specified, these secrets will be passed to individual puller implementations for them to use.  For example,
@@ -62,6 +74,16 @@ type ImagePullJobSpec struct {
 	CompletionPolicy CompletionPolicy `json:"completionPolicy"`
 }
 
+// ImagePullJobWorkloadReference refers to a workload whose images should be pulled
+type ImagePullJobWorkloadReference struct {
+	// APIVersion of the workload, such as apps.kruise.io/v1alpha1.
+	APIVersion string `json:"apiVersion"`
+	// Kind of the workload, such as CloneSet or StatefulSet.
+	Kind string `json:"kind"`
+	// Name of the workload.
+	Name string `json:"name"`
+}
+
 // ImagePullJobPodSelector is a selector over pods
 type ImagePullJobPodSelector struct {
 	// LabelSelector is a label query over pods that should match the job.
@@ -91,6 +113,18 @@ type PullPolicy struct {
 	// Defaults to 3
 	// +optional
 	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// Specifies the maximum bytes per second of pulling the images on each node.
+	// The pulling is still limited by the node-level limit of kruise-daemon, so it can only tighten that limit.
+	// It only works for the containerd runtime currently.
+	// +optional
+	MaxBytesPerSecond *int64 `json:"maxBytesPerSecond,omitempty"`
+
+	// Specifies the maximum number of images pulling concurrently on each node, including the ones of other jobs,
+	// that allows this job to start pulling.
+	// The pulling is still limited by the node-level limit of kruise-daemon, so it can only tighten that limit.
+	// +optional
+	MaxConcurrentPulls *int32 `json:"maxConcurrentPulls,omitempty"`
 }
 
 // ImagePullJobStatus defines the observed state of ImagePullJob
@@ -129,6 +163,33 @@ type ImagePullJobStatus struct {
 	// The nodes that failed to pull the image.
 	// +optional
 	FailedNodes []string `json:"failedNodes,omitempty"`
+
+	// ImageStatuses is the pulling progress of each image in the job.
+	// The counters above are aggregated by nodes, a node succeeds only when all the images have been pulled on it.
+	// +optional
+	ImageStatuses []ImagePullJobImageStatus `json:"imageStatuses,omitempty"`
+}
+
+// ImagePullJobImageStatus defines the pulling progress of an image in the job
+type ImagePullJobImageStatus struct {
+	// Image is the image pulled.
+	Image string `json:"image"`
+
+	// The number of nodes actively pulling the image.
+	// +optional
+	Active int32 `json:"active"`
+
+	// The number of nodes which have pulled the image successfully.
+	// +optional
+	Succeeded int32 `json:"succeeded"`
+
+	// The number of nodes which failed to pull the image.
+	// +optional
+	Failed int32 `json:"failed"`
+
+	// The nodes that failed to pull the image.
+	// +optional
+	FailedNodes []string `json:"failedNodes,omitempty"`
 }
 
 // +genclient
@@ -26,6 +26,18 @@ type NodeImageSpec struct {
 	// Specifies images to be pulled on this node
 	// It can not be more than 256 for each NodeImage
 	Images map[string]ImageSpec `json:"images,omitempty"`
+
+	// Specifies images to be removed from this node once they are not used by any container, including the exited ones.
+	// An image tag which is also in Images, or refers to the same image as a tag in Images, will not be removed.
+	// It can not be more than 256 for each NodeImage
+	// +optional
+	ImagesToRemove map[string]ImageRemoveSpec `json:"imagesToRemove,omitempty"`
+}
+
+// ImageRemoveSpec defines the removing spec of an image
+type ImageRemoveSpec struct {
+	// Tags is a list of versions of this image to be removed
+	Tags []string `json:"tags"`
 }
 
 // ImageSpec defines the pulling spec of an image
@@ -102,6 +114,17 @@ type ImageTagPullPolicy struct {
 	// if not specified, the system will never terminate it.
 	// +optional
 	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// MaxBytesPerSecond limits the bandwidth of pulling this image.
+	// The pulling is still limited by the node-level limit of kruise-daemon, so it can only tighten that limit.
+	// It only works for the containerd runtime currently.
+	// +optional
+	MaxBytesPerSecond *int64 `json:"maxBytesPerSecond,omitempty"`
+
+	// MaxConcurrentPulls is the maximum number of images pulling concurrently on the node, that allows this image to start pulling.
+	// The pulling is still limited by the node-level limit of kruise-daemon, so it can only tighten that limit.
+	// +optional
+	MaxConcurrentPulls *int32 `json:"maxConcurrentPulls,omitempty"`
 }
 
 // NodeImageStatus defines the observed state of NodeImage
@@ -124,6 +147,10 @@ type NodeImageStatus struct {
 	// all statuses of active image pulling tasks
 	ImageStatuses map[string]ImageStatus `json:"imageStatuses,omitempty"`
 
+	// all statuses of image removing tasks
+	// +optional
+	RemovedImageStatuses map[string]ImageRemoveStatus `json:"removedImageStatuses,omitempty"`
+
 	// The first of all job has finished on this node. When a node is added to the cluster, we want to know
 	// the time when the node's image pulling is completed, and use it to trigger the operation of the upper system.
 	// +optional
@@ -173,11 +200,46 @@ type ImageTagStatus struct {
 	Message string `json:"message,omitempty"`
 }
 
+// ImageRemoveStatus defines the removing status of an image
+type ImageRemoveStatus struct {
+	// Represents statuses of removing tasks on this node
+	Tags []ImageTagRemoveStatus `json:"tags"`
+}
+
+// ImageTagRemoveStatus defines the removing status of an image tag
+type ImageTagRemoveStatus struct {
+	// Represents the image tag.
+	Tag string `json:"tag"`
+
+	// Represents the image removing task phase.
+	Phase ImageRemovePhase `json:"phase"`
+
+	// Represents time when the image was found removed from this node.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Represents the summary informations of this task
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ImageRemovePhase defines the removing tasks status
+type ImageRemovePhase string
+
+const (
+	// ImageRemovePhaseRemoved means the image does not exist on this node
+	ImageRemovePhaseRemoved ImageRemovePhase = "Removed"
+	// ImageRemovePhaseInUse means the image is used by containers or desired to be pulled, so it is kept
+	ImageRemovePhaseInUse ImageRemovePhase = "InUse"
+	// ImageRemovePhaseFailed means the image failed to be removed, and it will be retried later
+	ImageRemovePhaseFailed ImageRemovePhase = "Failed"
+)
+
 // ImagePullPhase defines the tasks status
 type ImagePullPhase string
 
 const (
-	// ImagePhaseWaiting means the task has not started
+	// ImagePhaseWaiting means the task has not started, e.g., queued behind the concurrency limit of the node
 	ImagePhaseWaiting ImagePullPhase = "Waiting"
 	// ImagePhasePulling means the task has been started, but not completed
 	ImagePhasePulling ImagePullPhase = "Pulling"
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersistentPodStateSpec defines the desired state of PersistentPodState
+type PersistentPodStateSpec struct {
+	// TargetReference contains enough information to let you identify an workload for PersistentPodState.
+	// Currently only Advanced StatefulSet is supported.
+	TargetReference TargetReference `json:"targetRef"`
+
+	// PersistentPodAnnotations are the annotations of Pods that need to be recorded,
+	// such as the Pod IP annotation allocated by network plugins.
+	// +optional
+	PersistentPodAnnotations []PersistentPodAnnotation `json:"persistentPodAnnotations,omitempty"`
+
+	// RequiredPersistentTopology indicates the node topology labels that the recreated Pod is required to be scheduled on,
+	// e.g. kubernetes.io/hostname, topology.kubernetes.io/zone.
+	// +optional
+	RequiredPersistentTopology *NodeTopologyTerm `json:"requiredPersistentTopology,omitempty"`
+
+	// PreferredPersistentTopology indicates the node topology labels that the recreated Pod prefers to be scheduled on, with weights.
+	// +optional
+	PreferredPersistentTopology []PreferredTopologyTerm `json:"preferredPersistentTopology,omitempty"`
+
+	// PersistentPodStateRetentionPolicy describes when to remove the recorded states of Pods.
+	// Default is WhenScaled, which removes the states of Pods that have been scaled down.
+	// +optional
+	PersistentPodStateRetentionPolicy PersistentPodStateRetentionPolicyType `json:"persistentPodStateRetentionPolicy,omitempty"`
+}
+
+// PersistentPodAnnotation indicates an annotation of Pod that need to be recorded.
+type PersistentPodAnnotation struct {
+	Key string `json:"key"`
+}
+
+// NodeTopologyTerm contains the node topology label keys.
+type NodeTopologyTerm struct {
+	// A list of node topology label keys.
+	NodeTopologyKeys []string `json:"nodeTopologyKeys"`
+}
+
+// PreferredTopologyTerm contains the node topology label keys with a weight.
+type PreferredTopologyTerm struct {
+	// Weight associated with matching the corresponding preference, in the range 1-100.
+	Weight int32 `json:"weight"`
+	// Preference is the node topology term associated with the weight.
+	Preference NodeTopologyTerm `json:"preference"`
+}
+
+// PersistentPodStateRetentionPolicyType is a string enumeration type that enumerates
+// all possible retention policies of PersistentPodState.
+// +kubebuilder:validation:Enum=WhenScaled;WhenDeleted
+type PersistentPodStateRetentionPolicyType string
+
+const (
+	// PersistentPodStateRetentionPolicyWhenScaled indicates the state of Pod will be removed when the Pod is scaled down,
+	// and all states will be removed when the workload is deleted.
+	PersistentPodStateRetentionPolicyWhenScaled PersistentPodStateRetentionPolicyType = "WhenScaled"
+	// PersistentPodStateRetentionPolicyWhenDeleted indicates the states of Pods will be kept until the workload is deleted.
+	PersistentPodStateRetentionPolicyWhenDeleted PersistentPodStateRetentionPolicyType = "WhenDeleted"
+)
+
+// PersistentPodStateStatus defines the observed state of PersistentPodState
+type PersistentPodStateStatus struct {
+	// ObservedGeneration is the most recent generation observed for this PersistentPodState.
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// PodStates records the states of the ready Pods, the key is the name of Pod.
+	// +optional
+	PodStates map[string]PodState `json:"podStates,omitempty"`
+}
+
+// PodState records the state of a Pod when it was ready.
+type PodState struct {
+	// NodeName is the name of node where the Pod was running.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// NodeTopologyLabels are the topology labels of the node, whose keys are specified
+	// in requiredPersistentTopology and preferredPersistentTopology.
+	// +optional
+	NodeTopologyLabels map[string]string `json:"nodeTopologyLabels,omitempty"`
+	// Annotations are the annotations of the Pod, whose keys are specified in persistentPodAnnotations.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// +genclient
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=pps
+// +kubebuilder:printcolumn:name=WorkloadName,type=string,JSONPath=".spec.targetRef.name"
+// +kubebuilder:printcolumn:name=WorkloadKind,type=string,JSONPath=".spec.targetRef.kind"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// PersistentPodState is the Schema for the persistentpodstates API
+type PersistentPodState struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PersistentPodStateSpec   `json:"spec,omitempty"`
+	Status PersistentPodStateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PersistentPodStateList contains a list of PersistentPodState
+type PersistentPodStateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PersistentPodState `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PersistentPodState{}, &PersistentPodStateList{})
+}
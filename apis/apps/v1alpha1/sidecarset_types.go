@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -31,6 +32,10 @@ type SidecarSetSpec struct {
 	// otherwise, match pods in all namespaces(in cluster)
 	Namespace string `json:"namespace,omitempty"`
 
+	// NamespaceSelector is a label query over namespaces, sidecarSet will only match the pods
+	// in the selected namespaces. It can not be used together with Namespace.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// Containers is the list of init containers to be injected into the selected pod
 	// We will inject those containers by their name in ascending order
 	// We only inject init containers when a new pod is created, it does not apply to any existing pod
@@ -50,6 +55,10 @@ type SidecarSetSpec struct {
 
 	// List of the names of secrets required by pulling sidecar container images
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// RevisionHistoryLimit indicates the maximum quantity of stored revisions about the SidecarSet.
+	// default value is 10
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 }
 
 // SidecarContainer defines the container of Sidecar
@@ -73,6 +82,18 @@ type SidecarContainer struct {
 	// TransferEnv will transfer env info from other container
 	// SourceContainerName is pod.spec.container[x].name; EnvName is pod.spec.container[x].Env.name
 	TransferEnv []TransferEnvVar `json:"transferEnv,omitempty"`
+
+	// StartBeforeAppContainers indicates that the app containers in the pod will not start
+	// until this sidecar container is ready. It only takes effect on pod creation,
+	// and requires the SidecarSetLifecycle feature-gate to be enabled.
+	StartBeforeAppContainers bool `json:"startBeforeAppContainers,omitempty"`
+
+	// TerminateWhenJobExit indicates that this sidecar container will be stopped by kruise-daemon
+	// once all the app containers have terminated, so that the pod of Job can complete.
+	// The container should exit with code 0 on SIGTERM, for its exit code counts towards the pod phase.
+	// It only takes effect on the pod whose restartPolicy is not Always,
+	// and requires the SidecarSetLifecycle feature-gate to be enabled.
+	TerminateWhenJobExit bool `json:"terminateWhenJobExit,omitempty"`
 }
 
 type ShareVolumePolicy struct {
@@ -125,6 +146,11 @@ type SidecarSetInjectionStrategy struct {
 	// but the injected sidecar container remains updating and running.
 	// default is false
 	Paused bool `json:"paused,omitempty"`
+
+	// RevisionName is the name of a historical ControllerRevision of the SidecarSet.
+	// If it is set, the sidecar containers of the revision will be injected into the newly created Pods
+	// instead of the latest ones, so that a rollout can be controlled by users.
+	RevisionName *string `json:"revisionName,omitempty"`
 }
 
 // SidecarSetUpdateStrategy indicates the strategy that the SidecarSet
@@ -158,11 +184,20 @@ type SidecarSetUpdateStrategy struct {
 	// Default value is 1.
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 
+	// Priorities are the rules for calculating the priority of updating pods.
+	// Each pod to be updated, will pass through these terms and get a sum of weights.
+	PriorityStrategy *appspub.UpdatePriorityStrategy `json:"priorityStrategy,omitempty"`
+
 	// ScatterStrategy defines the scatter rules to make pods been scattered when update.
 	// This will avoid pods with the same key-value to be updated in one batch.
 	// - Note that pods will be scattered after priority sort. So, although priority strategy and scatter strategy can be applied together, we suggest to use either one of them.
 	// - If scatterStrategy is used, we suggest to just use one term. Otherwise, the update order can be hard to understand.
 	ScatterStrategy UpdateScatterStrategy `json:"scatterStrategy,omitempty"`
+
+	// RevisionName is the name of a historical ControllerRevision of the SidecarSet.
+	// If it is set, the injected pods will be updated to the revision instead of the latest one,
+	// which can be used to roll back the sidecar containers or to target a canary version.
+	RevisionName *string `json:"revisionName,omitempty"`
 }
 
 type SidecarSetUpdateStrategyType string
@@ -189,6 +224,14 @@ type SidecarSetStatus struct {
 
 	// updatedReadyPods is the number of matched pods that updated and ready
 	UpdatedReadyPods int32 `json:"updatedReadyPods,omitempty"`
+
+	// LatestRevision, if not empty, indicates the name of the latest ControllerRevision of the SidecarSet.
+	LatestRevision string `json:"latestRevision,omitempty"`
+
+	// CollisionCount is the count of hash collisions for the SidecarSet. The SidecarSet controller
+	// uses this field as a collision avoidance mechanism when it needs to create the name for the
+	// newest ControllerRevision.
+	CollisionCount *int32 `json:"collisionCount,omitempty"`
 }
 
 // +genclient
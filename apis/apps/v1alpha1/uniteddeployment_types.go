@@ -20,6 +20,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -32,6 +33,39 @@ const (
 	// The update progress is able to be controlled by updating the partitions
 	// of each subset.
 	ManualUpdateStrategyType UpdateStrategyType = "Manual"
+	// AutoUpdateStrategyType indicates the subsets are updated automatically by the controller,
+	// one after another or in parallel, with a global partition and maxUnavailable.
+	AutoUpdateStrategyType UpdateStrategyType = "Auto"
+)
+
+const (
+	// DefaultUnitedDeploymentMaxUnavailable is the default value of maxUnavailable for UnitedDeployment Auto update strategy.
+	DefaultUnitedDeploymentMaxUnavailable = "20%"
+
+	// DefaultRescheduleCriticalSeconds is the default value of rescheduleCriticalSeconds for UnitedDeployment Adaptive schedule strategy.
+	DefaultRescheduleCriticalSeconds = 30
+	// DefaultUnschedulableLastSeconds is the default value of unschedulableLastSeconds for UnitedDeployment Adaptive schedule strategy.
+	DefaultUnschedulableLastSeconds = 300
+
+	// Default paths of the fields in generic workload.
+	DefaultGenericReplicasPath            = ".spec.replicas"
+	DefaultGenericSelectorPath            = ".spec.selector"
+	DefaultGenericTemplatePath            = ".spec.template"
+	DefaultGenericStatusReplicasPath      = ".status.replicas"
+	DefaultGenericStatusReadyReplicasPath = ".status.readyReplicas"
+	DefaultGenericObservedGenerationPath  = ".status.observedGeneration"
+)
+
+// AutoUpdateOrderType is a string enumeration type that enumerates
+// the orders in which the subsets are updated by the Auto update strategy.
+type AutoUpdateOrderType string
+
+const (
+	// OrderedAutoUpdateOrderType indicates the subsets are updated one by one in the order of topology.
+	// A subset will not start to update until the previous ones have been updated and ready.
+	OrderedAutoUpdateOrderType AutoUpdateOrderType = "Ordered"
+	// ParallelAutoUpdateOrderType indicates the subsets are updated at the same time.
+	ParallelAutoUpdateOrderType AutoUpdateOrderType = "Parallel"
 )
 
 // UnitedDeploymentConditionType indicates valid conditions type of a UnitedDeployment.
@@ -94,6 +128,11 @@ type SubsetTemplate struct {
 	// Deployment template
 	// +optional
 	DeploymentTemplate *DeploymentTemplateSpec `json:"deploymentTemplate,omitempty"`
+
+	// Generic template of any workload kind which has a pod template and could be scaled,
+	// such as the workloads with a scale subresource.
+	// +optional
+	GenericTemplate *GenericTemplateSpec `json:"genericTemplate,omitempty"`
 }
 
 // StatefulSetTemplateSpec defines the subset template of StatefulSet.
@@ -123,6 +162,63 @@ type DeploymentTemplateSpec struct {
 	Spec              appsv1.DeploymentSpec `json:"spec"`
 }
 
+// GenericTemplateSpec defines the subset template of an arbitrary workload kind.
+// The workload is managed as an unstructured object, and the fields UnitedDeployment cares about
+// are located by the paths. Note that kruise-manager should be granted the permissions of
+// get/list/watch/create/update/patch/delete on the workload kind, which are not included in its default ClusterRole.
+type GenericTemplateSpec struct {
+	// APIVersion of the workload, such as argoproj.io/v1alpha1.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the workload, such as Rollout.
+	Kind string `json:"kind"`
+
+	// +kubebuilder:validation:XPreserveUnknownFields
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec of the workload.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec runtime.RawExtension `json:"spec"`
+
+	// Paths indicates where the fields of the workload are.
+	// +optional
+	Paths GenericWorkloadPaths `json:"paths,omitempty"`
+}
+
+// GenericWorkloadPaths indicates the paths of fields in the generic workload.
+// Each path is a dot-separated field path like '.spec.replicas', the same as the scale subresource of CRD.
+type GenericWorkloadPaths struct {
+	// Path of the desired replicas. Defaults to '.spec.replicas'.
+	// +optional
+	ReplicasPath string `json:"replicasPath,omitempty"`
+
+	// Path of the partition, which indicates how many pods should be kept in the old revision.
+	// If empty, the workload is regarded as not supporting partition.
+	// +optional
+	PartitionPath string `json:"partitionPath,omitempty"`
+
+	// Path of the label selector. Defaults to '.spec.selector'.
+	// +optional
+	SelectorPath string `json:"selectorPath,omitempty"`
+
+	// Path of the pod template. Defaults to '.spec.template'.
+	// +optional
+	TemplatePath string `json:"templatePath,omitempty"`
+
+	// Path of the observed replicas in status. Defaults to '.status.replicas'.
+	// +optional
+	StatusReplicasPath string `json:"statusReplicasPath,omitempty"`
+
+	// Path of the ready replicas in status. Defaults to '.status.readyReplicas'.
+	// +optional
+	StatusReadyReplicasPath string `json:"statusReadyReplicasPath,omitempty"`
+
+	// Path of the observed generation in status. Defaults to '.status.observedGeneration'.
+	// +optional
+	ObservedGenerationPath string `json:"observedGenerationPath,omitempty"`
+}
+
 // UnitedDeploymentUpdateStrategy defines the update performance
 // when template of UnitedDeployment is changed.
 type UnitedDeploymentUpdateStrategy struct {
@@ -133,6 +229,9 @@ type UnitedDeploymentUpdateStrategy struct {
 	// Includes all of the parameters a Manual update strategy needs.
 	// +optional
 	ManualUpdate *ManualUpdate `json:"manualUpdate,omitempty"`
+	// Includes all of the parameters an Auto update strategy needs.
+	// +optional
+	AutoUpdate *AutoUpdate `json:"autoUpdate,omitempty"`
 }
 
 // ManualUpdate is a update strategy which allows users to control the update progress
@@ -143,6 +242,26 @@ type ManualUpdate struct {
 	Partitions map[string]int32 `json:"partitions,omitempty"`
 }
 
+// AutoUpdate is an update strategy which updates the subsets automatically,
+// so that a new revision can be rolled out through all the subsets with one change.
+type AutoUpdate struct {
+	// Order indicates the order in which the subsets are updated, Ordered or Parallel.
+	// Default is Ordered.
+	// +optional
+	Order AutoUpdateOrderType `json:"order,omitempty"`
+	// Partition is the desired number of pods in old revision across all the subsets.
+	// The pods in old revision are kept in the last subsets of topology.
+	// Default value is 0.
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
+	// The maximum number of pods that can be unavailable across all the subsets during the update.
+	// Value can be an absolute number (ex: 5) or a percentage of desired pods (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up.
+	// Default value is 20%.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // Topology defines the spread detail of each subset under UnitedDeployment.
 // A UnitedDeployment manages multiple homogeneous workloads which are called subset.
 // Each of subsets under the UnitedDeployment is described in Topology.
@@ -151,6 +270,53 @@ type Topology struct {
 	// which will be provisioned and managed by UnitedDeployment.
 	// +optional
 	Subsets []Subset `json:"subsets,omitempty"`
+
+	// ScheduleStrategy indicates the strategy the UnitedDeployment used to deal with the pods
+	// which could not be scheduled in a subset.
+	// +optional
+	ScheduleStrategy UnitedDeploymentScheduleStrategy `json:"scheduleStrategy,omitempty"`
+}
+
+// UnitedDeploymentScheduleStrategyType is a string enumeration type that enumerates
+// all possible schedule strategies for the UnitedDeployment controller.
+// +kubebuilder:validation:Enum=Fixed;Adaptive
+type UnitedDeploymentScheduleStrategyType string
+
+const (
+	// FixedUnitedDeploymentScheduleStrategyType keeps the replicas of each subset as they are allocated,
+	// no matter whether the pods in the subset could be scheduled.
+	FixedUnitedDeploymentScheduleStrategyType UnitedDeploymentScheduleStrategyType = "Fixed"
+	// AdaptiveUnitedDeploymentScheduleStrategyType temporarily shifts the replicas of a subset,
+	// whose pods keep pending for a while, to the other subsets.
+	// It only takes effect when the subsets are allocated by minReplicas and maxReplicas.
+	AdaptiveUnitedDeploymentScheduleStrategyType UnitedDeploymentScheduleStrategyType = "Adaptive"
+)
+
+// UnitedDeploymentScheduleStrategy defines the schedule performance of UnitedDeployment.
+type UnitedDeploymentScheduleStrategy struct {
+	// Type indicates the type of the UnitedDeploymentScheduleStrategy.
+	// Default is Fixed.
+	// +optional
+	Type UnitedDeploymentScheduleStrategyType `json:"type,omitempty"`
+
+	// Adaptive includes the parameters of the Adaptive schedule strategy.
+	// +optional
+	Adaptive *AdaptiveUnitedDeploymentStrategy `json:"adaptive,omitempty"`
+}
+
+// AdaptiveUnitedDeploymentStrategy defines the parameters of the Adaptive schedule strategy.
+type AdaptiveUnitedDeploymentStrategy struct {
+	// RescheduleCriticalSeconds indicates how long a pod could keep pending before its subset is
+	// marked as unschedulable and the replicas of the subset are shifted to the other subsets.
+	// Defaults to 30.
+	// +optional
+	RescheduleCriticalSeconds *int32 `json:"rescheduleCriticalSeconds,omitempty"`
+
+	// UnschedulableLastSeconds indicates how long a subset keeps unschedulable once it is marked.
+	// After that, the replicas shifted from it will be allocated back.
+	// Defaults to 300.
+	// +optional
+	UnschedulableLastSeconds *int32 `json:"unschedulableLastSeconds,omitempty"`
 }
 
 // Subset defines the detail of a subset.
@@ -177,6 +343,27 @@ type Subset struct {
 	// Controller will try to keep all the subsets with nil replicas have average pods.
 	// +optional
 	Replicas *intstr.IntOrString `json:"replicas,omitempty"`
+
+	// Indicates the lower bound of the replicas of this subset. MinReplicas could also be percentage
+	// like '10%' of UnitedDeployment replicas. If minReplicas or maxReplicas is set in any subset,
+	// the replicas of UnitedDeployment will be allocated elastically, each subset gets its minReplicas
+	// in order first, then the rest replicas fill the subsets in order up to their maxReplicas.
+	// It can not be used together with replicas.
+	// +optional
+	MinReplicas *intstr.IntOrString `json:"minReplicas,omitempty"`
+
+	// Indicates the upper bound of the replicas of this subset. MaxReplicas could also be percentage
+	// like '10%' of UnitedDeployment replicas. If nil, the subset has no upper bound, and the replicas
+	// beyond the maxReplicas of previous subsets will overflow into it.
+	// It can not be used together with replicas.
+	// +optional
+	MaxReplicas *intstr.IntOrString `json:"maxReplicas,omitempty"`
+
+	// Patch indicates a strategic merge patch applied to the pod template of this subset,
+	// so that pods in different subsets could have different labels, resources, env and so on.
+	// Changes of patch will be rolled out like the changes of template, but only to the pods of this subset.
+	// +optional
+	Patch runtime.RawExtension `json:"patch,omitempty"`
 }
 
 // UnitedDeploymentStatus defines the observed state of UnitedDeployment.
@@ -220,6 +407,11 @@ type UnitedDeploymentStatus struct {
 	// Records the information of update progress.
 	// +optional
 	UpdateStatus *UpdateStatus `json:"updateStatus,omitempty"`
+
+	// Records the subsets marked as unschedulable by the Adaptive schedule strategy,
+	// and the time when they were marked.
+	// +optional
+	UnschedulableSubsets map[string]metav1.Time `json:"unschedulableSubsets,omitempty"`
 }
 
 // UnitedDeploymentCondition describes current state of a UnitedDeployment.
@@ -105,9 +105,16 @@ type WorkloadSpreadSubset struct {
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
 	// MaxReplicas indicates the desired max replicas of this subset.
+	// Percent is not supported when the target is StatefulSet, whose Pods are placed into subsets by ordinals.
 	// +optional
 	MaxReplicas *intstr.IntOrString `json:"maxReplicas,omitempty"`
 
+	// Weight indicates the proportion of Pods in this subset among all the subsets.
+	// If weight is set, it should be set for all the subsets and maxReplicas should be empty,
+	// then Pods will be distributed to the subsets by the ratio of their weights whatever replicas the workload has.
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+
 	// Patch indicates patching podTemplate to the Pod.
 	// +optional
 	Patch runtime.RawExtension `json:"patch,omitempty"`
@@ -30,6 +30,31 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveUnitedDeploymentStrategy) DeepCopyInto(out *AdaptiveUnitedDeploymentStrategy) {
+	*out = *in
+	if in.RescheduleCriticalSeconds != nil {
+		in, out := &in.RescheduleCriticalSeconds, &out.RescheduleCriticalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnschedulableLastSeconds != nil {
+		in, out := &in.UnschedulableLastSeconds, &out.UnschedulableLastSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveUnitedDeploymentStrategy.
+func (in *AdaptiveUnitedDeploymentStrategy) DeepCopy() *AdaptiveUnitedDeploymentStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveUnitedDeploymentStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdaptiveWorkloadSpreadStrategy) DeepCopyInto(out *AdaptiveWorkloadSpreadStrategy) {
 	*out = *in
@@ -186,6 +211,31 @@ func (in *AdvancedStatefulSetTemplateSpec) DeepCopy() *AdvancedStatefulSetTempla
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoUpdate) DeepCopyInto(out *AutoUpdate) {
+	*out = *in
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoUpdate.
+func (in *AutoUpdate) DeepCopy() *AutoUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BroadcastJob) DeepCopyInto(out *BroadcastJob) {
 	*out = *in
@@ -390,6 +440,31 @@ func (in *CloneSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetRollbackPolicy) DeepCopyInto(out *CloneSetRollbackPolicy) {
+	*out = *in
+	if in.MaxFailedPods != nil {
+		in, out := &in.MaxFailedPods, &out.MaxFailedPods
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.NotReadyTimeoutSeconds != nil {
+		in, out := &in.NotReadyTimeoutSeconds, &out.NotReadyTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetRollbackPolicy.
+func (in *CloneSetRollbackPolicy) DeepCopy() *CloneSetRollbackPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetRollbackPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloneSetScaleStrategy) DeepCopyInto(out *CloneSetScaleStrategy) {
 	*out = *in
@@ -470,6 +545,15 @@ func (in *CloneSetStatus) DeepCopyInto(out *CloneSetStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CurrentStepIndex != nil {
+		in, out := &in.CurrentStepIndex, &out.CurrentStepIndex
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastStepTransitionTime != nil {
+		in, out := &in.LastStepTransitionTime, &out.LastStepTransitionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetStatus.
@@ -499,6 +583,47 @@ func (in *CloneSetTemplateSpec) DeepCopy() *CloneSetTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetUpdateStep) DeepCopyInto(out *CloneSetUpdateStep) {
+	*out = *in
+	out.Replicas = in.Replicas
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(CloneSetUpdateStepPause)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetUpdateStep.
+func (in *CloneSetUpdateStep) DeepCopy() *CloneSetUpdateStep {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetUpdateStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetUpdateStepPause) DeepCopyInto(out *CloneSetUpdateStepPause) {
+	*out = *in
+	if in.DurationSeconds != nil {
+		in, out := &in.DurationSeconds, &out.DurationSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetUpdateStepPause.
+func (in *CloneSetUpdateStepPause) DeepCopy() *CloneSetUpdateStepPause {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetUpdateStepPause)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloneSetUpdateStrategy) DeepCopyInto(out *CloneSetUpdateStrategy) {
 	*out = *in
@@ -532,6 +657,18 @@ func (in *CloneSetUpdateStrategy) DeepCopyInto(out *CloneSetUpdateStrategy) {
 		*out = new(pub.InPlaceUpdateStrategy)
 		**out = **in
 	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]CloneSetUpdateStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollbackPolicy != nil {
+		in, out := &in.RollbackPolicy, &out.RollbackPolicy
+		*out = new(CloneSetRollbackPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetUpdateStrategy.
@@ -802,6 +939,11 @@ func (in *CronJobTemplate) DeepCopyInto(out *CronJobTemplate) {
 		*out = new(BroadcastJobTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ImagePullJobTemplate != nil {
+		in, out := &in.ImagePullJobTemplate, &out.ImagePullJobTemplate
+		*out = new(ImagePullJobTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobTemplate.
@@ -1000,6 +1142,39 @@ func (in *FailurePolicy) DeepCopy() *FailurePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericTemplateSpec) DeepCopyInto(out *GenericTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Paths = in.Paths
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericTemplateSpec.
+func (in *GenericTemplateSpec) DeepCopy() *GenericTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericWorkloadPaths) DeepCopyInto(out *GenericWorkloadPaths) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericWorkloadPaths.
+func (in *GenericWorkloadPaths) DeepCopy() *GenericWorkloadPaths {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericWorkloadPaths)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImagePullJob) DeepCopyInto(out *ImagePullJob) {
 	*out = *in
@@ -1027,6 +1202,26 @@ func (in *ImagePullJob) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullJobImageStatus) DeepCopyInto(out *ImagePullJobImageStatus) {
+	*out = *in
+	if in.FailedNodes != nil {
+		in, out := &in.FailedNodes, &out.FailedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobImageStatus.
+func (in *ImagePullJobImageStatus) DeepCopy() *ImagePullJobImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullJobImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImagePullJobList) DeepCopyInto(out *ImagePullJobList) {
 	*out = *in
@@ -1099,6 +1294,16 @@ func (in *ImagePullJobPodSelector) DeepCopy() *ImagePullJobPodSelector {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImagePullJobSpec) DeepCopyInto(out *ImagePullJobSpec) {
 	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagesFromWorkload != nil {
+		in, out := &in.ImagesFromWorkload, &out.ImagesFromWorkload
+		*out = new(ImagePullJobWorkloadReference)
+		**out = **in
+	}
 	if in.PullSecrets != nil {
 		in, out := &in.PullSecrets, &out.PullSecrets
 		*out = make([]string, len(*in))
@@ -1153,6 +1358,13 @@ func (in *ImagePullJobStatus) DeepCopyInto(out *ImagePullJobStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ImageStatuses != nil {
+		in, out := &in.ImageStatuses, &out.ImageStatuses
+		*out = make([]ImagePullJobImageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobStatus.
@@ -1165,6 +1377,80 @@ func (in *ImagePullJobStatus) DeepCopy() *ImagePullJobStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullJobTemplateSpec) DeepCopyInto(out *ImagePullJobTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobTemplateSpec.
+func (in *ImagePullJobTemplateSpec) DeepCopy() *ImagePullJobTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullJobTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullJobWorkloadReference) DeepCopyInto(out *ImagePullJobWorkloadReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobWorkloadReference.
+func (in *ImagePullJobWorkloadReference) DeepCopy() *ImagePullJobWorkloadReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullJobWorkloadReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRemoveSpec) DeepCopyInto(out *ImageRemoveSpec) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRemoveSpec.
+func (in *ImageRemoveSpec) DeepCopy() *ImageRemoveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRemoveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRemoveStatus) DeepCopyInto(out *ImageRemoveStatus) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]ImageTagRemoveStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRemoveStatus.
+func (in *ImageRemoveStatus) DeepCopy() *ImageRemoveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRemoveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 	*out = *in
@@ -1237,6 +1523,16 @@ func (in *ImageTagPullPolicy) DeepCopyInto(out *ImageTagPullPolicy) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.MaxBytesPerSecond != nil {
+		in, out := &in.MaxBytesPerSecond, &out.MaxBytesPerSecond
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxConcurrentPulls != nil {
+		in, out := &in.MaxConcurrentPulls, &out.MaxConcurrentPulls
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTagPullPolicy.
@@ -1249,6 +1545,25 @@ func (in *ImageTagPullPolicy) DeepCopy() *ImageTagPullPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageTagRemoveStatus) DeepCopyInto(out *ImageTagRemoveStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTagRemoveStatus.
+func (in *ImageTagRemoveStatus) DeepCopy() *ImageTagRemoveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageTagRemoveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageTagSpec) DeepCopyInto(out *ImageTagSpec) {
 	*out = *in
@@ -1409,6 +1724,13 @@ func (in *NodeImageSpec) DeepCopyInto(out *NodeImageSpec) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.ImagesToRemove != nil {
+		in, out := &in.ImagesToRemove, &out.ImagesToRemove
+		*out = make(map[string]ImageRemoveSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeImageSpec.
@@ -1431,6 +1753,13 @@ func (in *NodeImageStatus) DeepCopyInto(out *NodeImageStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.RemovedImageStatuses != nil {
+		in, out := &in.RemovedImageStatuses, &out.RemovedImageStatuses
+		*out = make(map[string]ImageRemoveStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.FirstSyncStatus != nil {
 		in, out := &in.FirstSyncStatus, &out.FirstSyncStatus
 		*out = new(SyncStatus)
@@ -1448,6 +1777,200 @@ func (in *NodeImageStatus) DeepCopy() *NodeImageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeTopologyTerm) DeepCopyInto(out *NodeTopologyTerm) {
+	*out = *in
+	if in.NodeTopologyKeys != nil {
+		in, out := &in.NodeTopologyKeys, &out.NodeTopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeTopologyTerm.
+func (in *NodeTopologyTerm) DeepCopy() *NodeTopologyTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeTopologyTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentPodAnnotation) DeepCopyInto(out *PersistentPodAnnotation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentPodAnnotation.
+func (in *PersistentPodAnnotation) DeepCopy() *PersistentPodAnnotation {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentPodAnnotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentPodState) DeepCopyInto(out *PersistentPodState) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentPodState.
+func (in *PersistentPodState) DeepCopy() *PersistentPodState {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentPodState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PersistentPodState) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentPodStateList) DeepCopyInto(out *PersistentPodStateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PersistentPodState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentPodStateList.
+func (in *PersistentPodStateList) DeepCopy() *PersistentPodStateList {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentPodStateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PersistentPodStateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentPodStateSpec) DeepCopyInto(out *PersistentPodStateSpec) {
+	*out = *in
+	out.TargetReference = in.TargetReference
+	if in.PersistentPodAnnotations != nil {
+		in, out := &in.PersistentPodAnnotations, &out.PersistentPodAnnotations
+		*out = make([]PersistentPodAnnotation, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredPersistentTopology != nil {
+		in, out := &in.RequiredPersistentTopology, &out.RequiredPersistentTopology
+		*out = new(NodeTopologyTerm)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreferredPersistentTopology != nil {
+		in, out := &in.PreferredPersistentTopology, &out.PreferredPersistentTopology
+		*out = make([]PreferredTopologyTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentPodStateSpec.
+func (in *PersistentPodStateSpec) DeepCopy() *PersistentPodStateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentPodStateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentPodStateStatus) DeepCopyInto(out *PersistentPodStateStatus) {
+	*out = *in
+	if in.PodStates != nil {
+		in, out := &in.PodStates, &out.PodStates
+		*out = make(map[string]PodState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentPodStateStatus.
+func (in *PersistentPodStateStatus) DeepCopy() *PersistentPodStateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentPodStateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodState) DeepCopyInto(out *PodState) {
+	*out = *in
+	if in.NodeTopologyLabels != nil {
+		in, out := &in.NodeTopologyLabels, &out.NodeTopologyLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodState.
+func (in *PodState) DeepCopy() *PodState {
+	if in == nil {
+		return nil
+	}
+	out := new(PodState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreferredTopologyTerm) DeepCopyInto(out *PreferredTopologyTerm) {
+	*out = *in
+	in.Preference.DeepCopyInto(&out.Preference)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreferredTopologyTerm.
+func (in *PreferredTopologyTerm) DeepCopy() *PreferredTopologyTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(PreferredTopologyTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullPolicy) DeepCopyInto(out *PullPolicy) {
 	*out = *in
@@ -1461,6 +1984,16 @@ func (in *PullPolicy) DeepCopyInto(out *PullPolicy) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxBytesPerSecond != nil {
+		in, out := &in.MaxBytesPerSecond, &out.MaxBytesPerSecond
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxConcurrentPulls != nil {
+		in, out := &in.MaxConcurrentPulls, &out.MaxConcurrentPulls
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullPolicy.
@@ -1799,7 +2332,7 @@ func (in *SidecarSet) DeepCopyInto(out *SidecarSet) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSet.
@@ -1823,6 +2356,11 @@ func (in *SidecarSet) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SidecarSetInjectionStrategy) DeepCopyInto(out *SidecarSetInjectionStrategy) {
 	*out = *in
+	if in.RevisionName != nil {
+		in, out := &in.RevisionName, &out.RevisionName
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetInjectionStrategy.
@@ -1875,6 +2413,11 @@ func (in *SidecarSetSpec) DeepCopyInto(out *SidecarSetSpec) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.InitContainers != nil {
 		in, out := &in.InitContainers, &out.InitContainers
 		*out = make([]SidecarContainer, len(*in))
@@ -1897,12 +2440,17 @@ func (in *SidecarSetSpec) DeepCopyInto(out *SidecarSetSpec) {
 		}
 	}
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
-	out.InjectionStrategy = in.InjectionStrategy
+	in.InjectionStrategy.DeepCopyInto(&out.InjectionStrategy)
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetSpec.
@@ -1918,6 +2466,11 @@ func (in *SidecarSetSpec) DeepCopy() *SidecarSetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SidecarSetStatus) DeepCopyInto(out *SidecarSetStatus) {
 	*out = *in
+	if in.CollisionCount != nil {
+		in, out := &in.CollisionCount, &out.CollisionCount
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetStatus.
@@ -1948,11 +2501,21 @@ func (in *SidecarSetUpdateStrategy) DeepCopyInto(out *SidecarSetUpdateStrategy)
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.PriorityStrategy != nil {
+		in, out := &in.PriorityStrategy, &out.PriorityStrategy
+		*out = new(pub.UpdatePriorityStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ScatterStrategy != nil {
 		in, out := &in.ScatterStrategy, &out.ScatterStrategy
 		*out = make(UpdateScatterStrategy, len(*in))
 		copy(*out, *in)
 	}
+	if in.RevisionName != nil {
+		in, out := &in.RevisionName, &out.RevisionName
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetUpdateStrategy.
@@ -2143,6 +2706,17 @@ func (in *Subset) DeepCopyInto(out *Subset) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	in.Patch.DeepCopyInto(&out.Patch)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subset.
@@ -2178,6 +2752,11 @@ func (in *SubsetTemplate) DeepCopyInto(out *SubsetTemplate) {
 		*out = new(DeploymentTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GenericTemplate != nil {
+		in, out := &in.GenericTemplate, &out.GenericTemplate
+		*out = new(GenericTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubsetTemplate.
@@ -2231,6 +2810,7 @@ func (in *Topology) DeepCopyInto(out *Topology) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.ScheduleStrategy.DeepCopyInto(&out.ScheduleStrategy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
@@ -2333,6 +2913,26 @@ func (in *UnitedDeploymentList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentScheduleStrategy) DeepCopyInto(out *UnitedDeploymentScheduleStrategy) {
+	*out = *in
+	if in.Adaptive != nil {
+		in, out := &in.Adaptive, &out.Adaptive
+		*out = new(AdaptiveUnitedDeploymentStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentScheduleStrategy.
+func (in *UnitedDeploymentScheduleStrategy) DeepCopy() *UnitedDeploymentScheduleStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentScheduleStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnitedDeploymentSpec) DeepCopyInto(out *UnitedDeploymentSpec) {
 	*out = *in
@@ -2393,6 +2993,13 @@ func (in *UnitedDeploymentStatus) DeepCopyInto(out *UnitedDeploymentStatus) {
 		*out = new(UpdateStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.UnschedulableSubsets != nil {
+		in, out := &in.UnschedulableSubsets, &out.UnschedulableSubsets
+		*out = make(map[string]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentStatus.
@@ -2413,6 +3020,11 @@ func (in *UnitedDeploymentUpdateStrategy) DeepCopyInto(out *UnitedDeploymentUpda
 		*out = new(ManualUpdate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AutoUpdate != nil {
+		in, out := &in.AutoUpdate, &out.AutoUpdate
+		*out = new(AutoUpdate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentUpdateStrategy.
@@ -2657,6 +3269,11 @@ func (in *WorkloadSpreadSubset) DeepCopyInto(out *WorkloadSpreadSubset) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
 	in.Patch.DeepCopyInto(&out.Patch)
 }
 
@@ -16,4 +16,21 @@ limitations under the License.
 
 package v1beta1
 
+import (
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
 func (*StatefulSet) Hub() {}
+
+// ReserveOrdinalsFromInts converts the ordinals to the type of StatefulSetSpec.ReserveOrdinals,
+// which was []int before ranges of ordinals supported.
+func ReserveOrdinalsFromInts(ordinals []int) []intstr.IntOrString {
+	if ordinals == nil {
+		return nil
+	}
+	reserveOrdinals := make([]intstr.IntOrString, 0, len(ordinals))
+	for _, ordinal := range ordinals {
+		reserveOrdinals = append(reserveOrdinals, intstr.FromInt(ordinal))
+	}
+	return reserveOrdinals
+}
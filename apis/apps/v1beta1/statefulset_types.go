@@ -176,14 +176,115 @@ type StatefulSetSpec struct {
 	//   Then controller will delete Pod-1 and create Pod-3 (existing Pods will be [0, 2, 3])
 	// - If you just want to delete Pod-1, you should set spec.reserveOrdinal to [1] and spec.replicas to 2.
 	//   Then controller will delete Pod-1 (existing Pods will be [0, 2])
-	ReserveOrdinals []int `json:"reserveOrdinals,omitempty"`
+	// An item can also be a range of ordinals in the format of "start-end" (both inclusive), e.g. "3-5".
+	// Note that the Go type has been changed from []int to []intstr.IntOrString, while the integers
+	// in JSON are still compatible. Go clients can use ReserveOrdinalsFromInts to convert the integers.
+	ReserveOrdinals []intstr.IntOrString `json:"reserveOrdinals,omitempty"`
+
+	// ordinals controls the numbering of replica indices in a StatefulSet. The
+	// default ordinals behavior assigns a "0" index to the first replica and
+	// increments the index by one for each additional replica requested. Using
+	// the ordinals field requires the StatefulSetStartOrdinal feature gate to be
+	// enabled.
+	// +optional
+	Ordinals *StatefulSetOrdinals `json:"ordinals,omitempty"`
 
-	// Lifecycle defines the lifecycle hooks for Pods pre-delete, in-place update.
+	// Lifecycle defines the lifecycle hooks for Pods pre-normal, pre-delete, in-place update.
 	Lifecycle *appspub.Lifecycle `json:"lifecycle,omitempty"`
 
 	// scaleStrategy indicates the StatefulSetScaleStrategy that will be
 	// employed to scale Pods in the StatefulSet.
 	ScaleStrategy *StatefulSetScaleStrategy `json:"scaleStrategy,omitempty"`
+
+	// persistentVolumeClaimRetentionPolicy describes the lifecycle of persistent
+	// volume claims created from volumeClaimTemplates. By default, all persistent
+	// volume claims are created as needed and retained until manually deleted. This
+	// policy allows the lifecycle to be altered, for example by deleting persistent
+	// volume claims when their stateful set is deleted, or when their pod is scaled
+	// down. This requires the StatefulSetAutoDeletePVC feature gate to be enabled.
+	// +optional
+	PersistentVolumeClaimRetentionPolicy *StatefulSetPersistentVolumeClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
+	// volumeClaimUpdateStrategy indicates how the existing persistent volume claims
+	// are updated when volumeClaimTemplates changed. The spec of volumeClaimTemplates
+	// can be changed only with Recreate type and RollingUpdate updateStrategy.
+	// +optional
+	VolumeClaimUpdateStrategy *VolumeClaimUpdateStrategy `json:"volumeClaimUpdateStrategy,omitempty"`
+}
+
+// StatefulSetOrdinals describes the policy used for replica ordinal assignment
+// in this StatefulSet.
+type StatefulSetOrdinals struct {
+	// start is the number representing the first replica's index. It may be used
+	// to number replicas from an alternate index (eg: 1-indexed) over the default
+	// 0-indexed names, or to orchestrate progressive movement of replicas from
+	// one StatefulSet to another.
+	// If set, replica indices will be in the range:
+	//   [.spec.ordinals.start, .spec.ordinals.start + .spec.replicas + count of reserved ordinals in the range).
+	// If unset, defaults to 0. Replica indices will be in the range:
+	//   [0, .spec.replicas + count of reserved ordinals in the range).
+	// +optional
+	Start int32 `json:"start"`
+}
+
+// PersistentVolumeClaimRetentionPolicyType is a string enumeration of the policies that will determine
+// when volumes from the VolumeClaimTemplates will be deleted when the controlling StatefulSet is
+// deleted or scaled down.
+// +kubebuilder:validation:Enum=Retain;Delete
+type PersistentVolumeClaimRetentionPolicyType string
+
+const (
+	// RetainPersistentVolumeClaimRetentionPolicyType is the default
+	// PersistentVolumeClaimRetentionPolicy and specifies that
+	// PersistentVolumeClaims associated with StatefulSet VolumeClaimTemplates
+	// will not be deleted.
+	RetainPersistentVolumeClaimRetentionPolicyType PersistentVolumeClaimRetentionPolicyType = "Retain"
+	// DeletePersistentVolumeClaimRetentionPolicyType specifies that
+	// PersistentVolumeClaims associated with StatefulSet VolumeClaimTemplates
+	// will be deleted in the scenario specified in
+	// StatefulSetPersistentVolumeClaimRetentionPolicy.
+	DeletePersistentVolumeClaimRetentionPolicyType PersistentVolumeClaimRetentionPolicyType = "Delete"
+)
+
+// StatefulSetPersistentVolumeClaimRetentionPolicy describes the policy used for PVCs
+// created from the StatefulSet VolumeClaimTemplates.
+type StatefulSetPersistentVolumeClaimRetentionPolicy struct {
+	// WhenDeleted specifies what happens to PVCs created from StatefulSet
+	// VolumeClaimTemplates when the StatefulSet is deleted. The default policy
+	// of `Retain` causes PVCs to not be affected by StatefulSet deletion. The
+	// `Delete` policy causes those PVCs to be deleted.
+	WhenDeleted PersistentVolumeClaimRetentionPolicyType `json:"whenDeleted,omitempty"`
+	// WhenScaled specifies what happens to PVCs created from StatefulSet
+	// VolumeClaimTemplates when the StatefulSet is scaled down. The default
+	// policy of `Retain` causes PVCs to not be affected by a scaledown. The
+	// `Delete` policy causes the associated PVCs for any excess pods above
+	// the replica count to be deleted.
+	WhenScaled PersistentVolumeClaimRetentionPolicyType `json:"whenScaled,omitempty"`
+}
+
+// VolumeClaimUpdateStrategyType is a string enumeration type that enumerates
+// all possible update strategies for the persistent volume claims of StatefulSet.
+// +kubebuilder:validation:Enum=OnDelete;Recreate
+type VolumeClaimUpdateStrategyType string
+
+const (
+	// OnDeleteVolumeClaimUpdateStrategyType indicates the existing persistent volume claims will not be changed,
+	// and the spec of volumeClaimTemplates can not be changed.
+	OnDeleteVolumeClaimUpdateStrategyType VolumeClaimUpdateStrategyType = "OnDelete"
+	// RecreateVolumeClaimUpdateStrategyType indicates the persistent volume claims that do not match the
+	// volumeClaimTemplates will be deleted after their Pod is deleted in rolling update, and then created
+	// from the new templates before the Pod created again. Note that the data in the deleted volumes will be lost.
+	// A change of volumeClaimTemplates also triggers the rolling update of Pods with mismatched claims,
+	// which will always be recreated instead of in-place updated, though the update revision is not changed.
+	RecreateVolumeClaimUpdateStrategyType VolumeClaimUpdateStrategyType = "Recreate"
+)
+
+// VolumeClaimUpdateStrategy defines the strategy to update the persistent volume claims of StatefulSet.
+type VolumeClaimUpdateStrategy struct {
+	// Type indicates the type of the VolumeClaimUpdateStrategy.
+	// Default is OnDelete.
+	// +optional
+	Type VolumeClaimUpdateStrategyType `json:"type,omitempty"`
 }
 
 // StatefulSetScaleStrategy defines strategies for pods scale.
@@ -23,6 +23,7 @@ import (
 
 	"github.com/openkruise/kruise/pkg/client"
 	"github.com/openkruise/kruise/pkg/daemon"
+	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
 	"github.com/openkruise/kruise/pkg/features"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/spf13/pflag"
@@ -35,6 +36,13 @@ import (
 
 var (
 	bindAddr = flag.String("addr", ":10221", "The address the metric endpoint and healthz binds to.")
+
+	maxConcurrentImagePulls    = flag.Int("max-concurrent-image-pulls", 0, "The maximum number of images pulling concurrently on the node, 0 means no limit.")
+	maxImagePullBytesPerSecond = flag.Int64("max-image-pull-bytes-per-second", 0, "The maximum bytes per second of pulling images on the node, 0 means no limit. It only works for the containerd runtime currently.")
+
+	registryMirrorConfig          = flag.String("registry-mirror-config", "", "The path of registry mirror config file, images will be pulled from the mirrors first and fall back to the original registry.")
+	imageCredentialProviderConfig = flag.String("image-credential-provider-config", "", "The path of credential provider config file, which has the same format as kubelet's CredentialProviderConfig.")
+	imageCredentialProviderBinDir = flag.String("image-credential-provider-bin-dir", "", "The path of the directory where credential provider plugin binaries are located.")
 )
 
 func main() {
@@ -53,7 +61,14 @@ func main() {
 	}
 
 	ctx := signals.SetupSignalHandler()
-	d, err := daemon.NewDaemon(cfg, *bindAddr)
+	d, err := daemon.NewDaemon(cfg, *bindAddr, daemonoptions.Options{
+		MaxConcurrentImagePulls:    *maxConcurrentImagePulls,
+		MaxImagePullBytesPerSecond: *maxImagePullBytesPerSecond,
+
+		RegistryMirrorConfig:          *registryMirrorConfig,
+		ImageCredentialProviderConfig: *imageCredentialProviderConfig,
+		ImageCredentialProviderBinDir: *imageCredentialProviderBinDir,
+	})
 	if err != nil {
 		klog.Fatalf("Failed to new daemon: %v", err)
 	}
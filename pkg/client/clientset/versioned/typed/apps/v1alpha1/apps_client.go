@@ -32,6 +32,7 @@ type AppsV1alpha1Interface interface {
 	DaemonSetsGetter
 	ImagePullJobsGetter
 	NodeImagesGetter
+	PersistentPodStatesGetter
 	ResourceDistributionsGetter
 	SidecarSetsGetter
 	StatefulSetsGetter
@@ -72,6 +73,10 @@ func (c *AppsV1alpha1Client) NodeImages() NodeImageInterface {
 	return newNodeImages(c)
 }
 
+func (c *AppsV1alpha1Client) PersistentPodStates(namespace string) PersistentPodStateInterface {
+	return newPersistentPodStates(c, namespace)
+}
+
 func (c *AppsV1alpha1Client) ResourceDistributions() ResourceDistributionInterface {
 	return newResourceDistributions(c)
 }
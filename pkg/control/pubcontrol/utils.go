@@ -24,6 +24,7 @@ import (
 	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -51,8 +52,9 @@ var ConflictRetry = wait.Backoff{
 type Operation string
 
 const (
-	UpdateOperation = "UPDATE"
-	//DeleteOperation = "DELETE"
+	UpdateOperation Operation = "UPDATE"
+	DeleteOperation Operation = "DELETE"
+	EvictOperation  Operation = "EVICT"
 )
 
 // parameters:
@@ -108,10 +110,12 @@ func PodUnavailableBudgetValidatePod(client client.Client, pod *corev1.Pod, cont
 	})
 	if err != nil && err != wait.ErrWaitTimeout {
 		klog.V(3).Infof("pod(%s.%s) operation(%s) for pub(%s.%s) failed: %s", pod.Namespace, pod.Name, operation, pub.Namespace, pub.Name, err.Error())
+		metrics.PodUnavailableBudgetRejectedTotal.WithLabelValues(pub.Namespace, pub.Name, string(operation)).Inc()
 		return false, err.Error(), nil
 	} else if err == wait.ErrWaitTimeout {
 		err = errors.NewTimeoutError(fmt.Sprintf("couldn't update PodUnavailableBudget %s due to conflicts", pub.Name), 10)
 		klog.Errorf("pod(%s.%s) operation(%s) failed: %s", pod.Namespace, pod.Name, operation, err.Error())
+		metrics.PodUnavailableBudgetRejectedTotal.WithLabelValues(pub.Namespace, pub.Name, string(operation)).Inc()
 		return false, err.Error(), nil
 	}
 
@@ -136,6 +140,7 @@ func checkAndDecrement(podName string, pub *policyv1alpha1.PodUnavailableBudget,
 		pub.Status.UnavailablePods = make(map[string]metav1.Time)
 	}
 
+	// pods to be in-place updated will be recovered, and pods to be deleted or evicted will be gone
 	if operation == UpdateOperation {
 		pub.Status.UnavailablePods[podName] = metav1.Time{Time: time.Now()}
 		klog.V(3).Infof("pod(%s) is recorded in pub(%s.%s) UnavailablePods", podName, pub.Namespace, pub.Name)
@@ -177,12 +182,17 @@ func GetPodUnavailableBudgetForPod(kClient client.Client, finders *controllerfin
 			if ref == nil {
 				continue
 			}
+			// the workloads with scale subresource are only looked up when the pub targets them
+			getScaleAndSelector := finders.GetScaleAndSelectorForRef
+			if !controllerfinder.IsKnownWorkload(targetRef.APIVersion, targetRef.Kind) {
+				getScaleAndSelector = finders.GetScaleAndSelectorForTargetRef
+			}
 			// recursive fetch pod reference, e.g. ref.Kind=Replicas, return podRef.Kind=Deployment
-			podRef, err := finders.GetScaleAndSelectorForRef(ref.APIVersion, ref.Kind, pod.Namespace, ref.Name, ref.UID)
+			podRef, err := getScaleAndSelector(ref.APIVersion, ref.Kind, pod.Namespace, ref.Name, ref.UID)
 			if err != nil {
 				return nil, err
 			}
-			pubRef, err := finders.GetScaleAndSelectorForRef(targetRef.APIVersion, targetRef.Kind, pub.Namespace, targetRef.Name, "")
+			pubRef, err := getScaleAndSelector(targetRef.APIVersion, targetRef.Kind, pub.Namespace, targetRef.Name, "")
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"context"
+	"testing"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodUnavailableBudgetValidatePodOperations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = policyv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-0"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	cases := []struct {
+		operation         Operation
+		expectUnavailable bool
+		expectDisrupted   bool
+	}{
+		{operation: UpdateOperation, expectUnavailable: true},
+		{operation: DeleteOperation, expectDisrupted: true},
+		{operation: EvictOperation, expectDisrupted: true},
+	}
+
+	for _, cs := range cases {
+		t.Run(string(cs.operation), func(t *testing.T) {
+			pub := &policyv1alpha1.PodUnavailableBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pub-test"},
+				Status:     policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 1},
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+
+			allowed, reason, err := PodUnavailableBudgetValidatePod(cli, pod, NewPubControl(pub), cs.operation, false)
+			if err != nil || !allowed {
+				t.Fatalf("expected allowed, got %v, %s, %v", allowed, reason, err)
+			}
+
+			newPub := &policyv1alpha1.PodUnavailableBudget{}
+			if err := cli.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}, newPub); err != nil {
+				t.Fatalf("failed to get pub: %v", err)
+			}
+			_, unavailable := newPub.Status.UnavailablePods[pod.Name]
+			_, disrupted := newPub.Status.DisruptedPods[pod.Name]
+			if unavailable != cs.expectUnavailable || disrupted != cs.expectDisrupted {
+				t.Fatalf("expected unavailable=%v disrupted=%v, got status %+v", cs.expectUnavailable, cs.expectDisrupted, newPub.Status)
+			}
+			if newPub.Status.UnavailableAllowed != 0 {
+				t.Fatalf("expected unavailableAllowed decremented, got %d", newPub.Status.UnavailableAllowed)
+			}
+
+			// the budget has been used up
+			allowed, _, _ = PodUnavailableBudgetValidatePod(cli, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-1"}, Status: pod.Status}, NewPubControl(newPub), cs.operation, false)
+			if allowed {
+				t.Fatalf("expected rejected after budget used up")
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarcontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruisehistory "github.com/openkruise/kruise/pkg/util/history"
+	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
+
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/kubernetes/pkg/controller/history"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SidecarSetKindName is the label of ControllerRevision which records the name of its SidecarSet
+	SidecarSetKindName = "kruise.io/sidecarset-name"
+)
+
+var (
+	sidecarSetKind = appsv1alpha1.SchemeGroupVersion.WithKind("SidecarSet")
+)
+
+// HistoryControl manages the ControllerRevisions of SidecarSet.
+// SidecarSet is cluster-scoped, so its revisions are stored in the namespace of kruise.
+type HistoryControl interface {
+	history.Interface
+	// NewRevision returns a ControllerRevision which records the sidecar containers of the SidecarSet.
+	NewRevision(s *appsv1alpha1.SidecarSet, revision int64, collisionCount *int32) (*apps.ControllerRevision, error)
+	// ListRevisions returns the ControllerRevisions owned by the SidecarSet, sorted by Revision.
+	ListRevisions(s *appsv1alpha1.SidecarSet) ([]*apps.ControllerRevision, error)
+	// GetHistorySidecarSet returns a SidecarSet whose sidecar containers are restored from the named ControllerRevision.
+	GetHistorySidecarSet(s *appsv1alpha1.SidecarSet, revisionName string) (*appsv1alpha1.SidecarSet, error)
+}
+
+// NewHistoryControl returns a HistoryControl for SidecarSet.
+func NewHistoryControl(c client.Client) HistoryControl {
+	return &realHistoryControl{Interface: kruisehistory.NewHistory(c), client: c}
+}
+
+type realHistoryControl struct {
+	history.Interface
+	client client.Client
+}
+
+func (r *realHistoryControl) NewRevision(s *appsv1alpha1.SidecarSet, revision int64, collisionCount *int32) (*apps.ControllerRevision, error) {
+	patch, err := getSidecarSetPatch(s)
+	if err != nil {
+		return nil, err
+	}
+	cr, err := history.NewControllerRevision(s,
+		sidecarSetKind,
+		map[string]string{SidecarSetKindName: s.Name},
+		runtime.RawExtension{Raw: patch},
+		revision,
+		collisionCount)
+	if err != nil {
+		return nil, err
+	}
+	cr.Namespace = webhookutil.GetNamespace()
+	// record the hash of sidecarSet, which is used to compare with the pods
+	cr.Annotations = map[string]string{
+		SidecarSetHashAnnotation:             GetSidecarSetRevision(s),
+		SidecarSetHashWithoutImageAnnotation: GetSidecarSetWithoutImageRevision(s),
+	}
+	return cr, nil
+}
+
+func (r *realHistoryControl) ListRevisions(s *appsv1alpha1.SidecarSet) ([]*apps.ControllerRevision, error) {
+	selector := labels.SelectorFromSet(labels.Set{SidecarSetKindName: s.Name})
+	revisions, err := r.ListControllerRevisions(s, selector)
+	if err != nil {
+		return nil, err
+	}
+	history.SortControllerRevisions(revisions)
+	return revisions, nil
+}
+
+func (r *realHistoryControl) GetHistorySidecarSet(s *appsv1alpha1.SidecarSet, revisionName string) (*appsv1alpha1.SidecarSet, error) {
+	revision := &apps.ControllerRevision{}
+	key := client.ObjectKey{Namespace: webhookutil.GetNamespace(), Name: revisionName}
+	if err := r.client.Get(context.TODO(), key, revision); err != nil {
+		return nil, err
+	}
+	if revision.Labels[SidecarSetKindName] != s.Name {
+		return nil, fmt.Errorf("revision %s does not belong to sidecarSet %s", revisionName, s.Name)
+	}
+	if ref := metav1.GetControllerOf(revision); ref != nil && ref.UID != s.UID {
+		return nil, fmt.Errorf("revision %s is not owned by sidecarSet %s", revisionName, s.Name)
+	}
+	return ApplyRevision(s, revision)
+}
+
+// ApplyRevision returns a new SidecarSet constructed by restoring the state in revision to s.
+func ApplyRevision(s *appsv1alpha1.SidecarSet, revision *apps.ControllerRevision) (*appsv1alpha1.SidecarSet, error) {
+	clone := s.DeepCopy()
+	cloneBytes, err := json.Marshal(clone)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(cloneBytes, revision.Data.Raw, clone)
+	if err != nil {
+		return nil, err
+	}
+	restored := &appsv1alpha1.SidecarSet{}
+	if err = json.Unmarshal(patched, restored); err != nil {
+		return nil, err
+	}
+	if restored.Annotations == nil {
+		restored.Annotations = make(map[string]string)
+	}
+	for _, key := range []string{SidecarSetHashAnnotation, SidecarSetHashWithoutImageAnnotation} {
+		if value, ok := revision.Annotations[key]; ok {
+			restored.Annotations[key] = value
+		}
+	}
+	return restored, nil
+}
+
+// getSidecarSetPatch returns a strategic merge patch that can be applied to restore a SidecarSet to a
+// previous version. The state that we save is the sidecar containers, volumes and image pull secrets.
+func getSidecarSetPatch(s *appsv1alpha1.SidecarSet) ([]byte, error) {
+	str, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	_ = json.Unmarshal(str, &raw)
+	objCopy := make(map[string]interface{})
+	specCopy := make(map[string]interface{})
+	spec := raw["spec"].(map[string]interface{})
+	// the fields which are not set must be recorded as null, so that they can be removed when applying the patch
+	for _, key := range []string{"initContainers", "containers", "volumes", "imagePullSecrets"} {
+		specCopy[key] = spec[key]
+	}
+	objCopy["spec"] = specCopy
+	return json.Marshal(objCopy)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package sidecarcontrol
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -25,11 +26,13 @@ import (
 	"github.com/openkruise/kruise/pkg/util"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -64,11 +67,17 @@ type SidecarSetUpgradeSpec struct {
 }
 
 // PodMatchSidecarSet determines if pod match Selector of sidecar.
-func PodMatchedSidecarSet(pod *corev1.Pod, sidecarSet appsv1alpha1.SidecarSet) (bool, error) {
+func PodMatchedSidecarSet(c client.Reader, pod *corev1.Pod, sidecarSet appsv1alpha1.SidecarSet) (bool, error) {
 	//If matchedNamespace is not empty, sidecarSet will only match the pods in the namespace
 	if sidecarSet.Spec.Namespace != "" && sidecarSet.Spec.Namespace != pod.Namespace {
 		return false, nil
 	}
+	//If namespaceSelector is not nil, sidecarSet will only match the pods in the selected namespaces
+	if sidecarSet.Spec.NamespaceSelector != nil {
+		if matched, err := IsSelectorNamespace(c, pod.Namespace, sidecarSet.Spec.NamespaceSelector); err != nil || !matched {
+			return false, err
+		}
+	}
 	// if selector not matched, then continue
 	selector, err := metav1.LabelSelectorAsSelector(sidecarSet.Spec.Selector)
 	if err != nil {
@@ -81,6 +90,22 @@ func PodMatchedSidecarSet(pod *corev1.Pod, sidecarSet appsv1alpha1.SidecarSet) (
 	return false, nil
 }
 
+// IsSelectorNamespace determines if the labels of namespace match the namespaceSelector.
+func IsSelectorNamespace(c client.Reader, ns string, nsSelector *metav1.LabelSelector) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(nsSelector)
+	if err != nil {
+		return false, err
+	}
+	nsObj := &corev1.Namespace{}
+	if err = c.Get(context.TODO(), client.ObjectKey{Name: ns}, nsObj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return selector.Matches(labels.Set(nsObj.Labels)), nil
+}
+
 // IsActivePod determines the pod whether need be injected and updated
 func IsActivePod(pod *corev1.Pod) bool {
 	for _, namespace := range SidecarIgnoredNamespaces {
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarcontrol
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/openkruise/kruise/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// SidecarStartBeforeAppEnvKey marks the sidecar container that the app containers should wait for
+	SidecarStartBeforeAppEnvKey = "KRUISE_SIDECAR_START_BEFORE_APP"
+	// SidecarTerminateWhenJobExitEnvKey marks the sidecar container that should be stopped once the app containers exit
+	SidecarTerminateWhenJobExitEnvKey = "KRUISE_TERMINATE_SIDECAR_WHEN_JOB_EXIT"
+
+	// SidecarBarrierEnvKey is the env injected into app containers, which refers to the barrier ConfigMap of the pod.
+	// The app containers can not be started by kubelet until kruise-manager creates the ConfigMap.
+	SidecarBarrierEnvKey = "KRUISE_SIDECAR_BARRIER"
+	// SidecarBarrierConfigMapKey is the key of the barrier ConfigMap referred by SidecarBarrierEnvKey
+	SidecarBarrierConfigMapKey = "sidecar-ready"
+	// SidecarBarrierAnnotation is the name of barrier ConfigMap generated for the pod on creation.
+	// It is derived from the pod name, or from the uid of admission request if the pod name is generated by apiserver.
+	SidecarBarrierAnnotation = "kruise.io/sidecar-barrier"
+
+	sidecarBarrierConfigMapNamePrefix = "kruise-sidecar-barrier-"
+)
+
+// GetSidecarBarrierConfigMapName returns the name of barrier ConfigMap for the pod
+func GetSidecarBarrierConfigMapName(pod *corev1.Pod) string {
+	return pod.Annotations[SidecarBarrierAnnotation]
+}
+
+// IsSidecarStartBeforeApp returns true if the app containers should wait for the sidecar container to be ready
+func IsSidecarStartBeforeApp(container *corev1.Container) bool {
+	return util.GetContainerEnvValue(container, SidecarStartBeforeAppEnvKey) == "true"
+}
+
+// IsSidecarTerminateWhenJobExit returns true if the sidecar container should be stopped once the app containers exit
+func IsSidecarTerminateWhenJobExit(container *corev1.Container) bool {
+	return util.GetContainerEnvValue(container, SidecarTerminateWhenJobExitEnvKey) == "true"
+}
+
+// IsSidecarBarrierInjected returns true if the app containers of pod are waiting for the barrier ConfigMap
+func IsSidecarBarrierInjected(pod *corev1.Pod) bool {
+	return GetSidecarBarrierConfigMapName(pod) != ""
+}
+
+// InjectSidecarBarrier makes the app containers of pod wait for the sidecar containers which should start before them.
+// It does nothing if there is no such sidecar container in pod. The barrier ConfigMap name is always derived again
+// in case the annotation is copied from another pod, and the requestUID is used only if the pod has no name yet.
+func InjectSidecarBarrier(pod *corev1.Pod, requestUID types.UID) {
+	var hasStartBeforeApp bool
+	for i := range pod.Spec.Containers {
+		if IsSidecarStartBeforeApp(&pod.Spec.Containers[i]) {
+			hasStartBeforeApp = true
+			break
+		}
+	}
+	if !hasStartBeforeApp {
+		return
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[SidecarBarrierAnnotation] = generateSidecarBarrierConfigMapName(pod, requestUID)
+	barrierEnv := corev1.EnvVar{
+		Name: SidecarBarrierEnvKey,
+		ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: GetSidecarBarrierConfigMapName(pod)},
+				Key:                  SidecarBarrierConfigMapKey,
+			},
+		},
+	}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if IsInjectedSidecarContainerInPod(container) {
+			continue
+		}
+		if env := util.GetContainerEnvVar(container, SidecarBarrierEnvKey); env != nil {
+			*env = barrierEnv
+		} else {
+			container.Env = append(container.Env, barrierEnv)
+		}
+	}
+}
+
+// generateSidecarBarrierConfigMapName returns the same name for the pod however many times it is mutated in a request.
+func generateSidecarBarrierConfigMapName(pod *corev1.Pod, requestUID types.UID) string {
+	seed := pod.Name
+	if seed == "" {
+		seed = string(requestUID)
+	}
+	name := sidecarBarrierConfigMapNamePrefix + seed
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = fmt.Sprintf("%s%x", sidecarBarrierConfigMapNamePrefix, sha256.Sum256([]byte(seed)))
+	}
+	return name
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The Kruise Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package advancedcronjob
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/robfig/cron"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ref "k8s.io/client-go/tools/reference"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// childJob is a job created by AdvancedCronJob, with the states that the schedule depends on.
+type childJob struct {
+	object client.Object
+	// finishedType is JobComplete or JobFailed if the job has finished, otherwise empty
+	finishedType appsv1alpha1.JobConditionType
+	startTime    *metav1.Time
+}
+
+// reconcileChildJobs updates the status of AdvancedCronJob by its child jobs, cleans up the finished jobs
+// beyond the history limits, and creates a new job by newJob if it is on schedule and allowed by the
+// concurrency policy. newJob returns the job with the given metadata and the spec of template.
+func (r *ReconcileAdvancedCronJob) reconcileChildJobs(ctx context.Context, req ctrl.Request, advancedCronJob *appsv1alpha1.AdvancedCronJob,
+	kind string, children []childJob, templateMeta metav1.ObjectMeta, newJob func(metav1.ObjectMeta) client.Object) (ctrl.Result, error) {
+
+	var activeJobs, successfulJobs, failedJobs []childJob
+	var mostRecentTime *time.Time
+	for _, job := range children {
+		switch job.finishedType {
+		case "":
+			activeJobs = append(activeJobs, job)
+		case appsv1alpha1.JobFailed:
+			failedJobs = append(failedJobs, job)
+		case appsv1alpha1.JobComplete:
+			successfulJobs = append(successfulJobs, job)
+		}
+
+		scheduledTime, err := getScheduledTimeForJob(job.object)
+		if err != nil {
+			klog.Errorf("Unable to parse schedule time for child %s %s of %v: %v", kind, job.object.GetName(), req.NamespacedName, err)
+			continue
+		}
+		if scheduledTime != nil && (mostRecentTime == nil || mostRecentTime.Before(*scheduledTime)) {
+			mostRecentTime = scheduledTime
+		}
+	}
+
+	if mostRecentTime != nil {
+		advancedCronJob.Status.LastScheduleTime = &metav1.Time{Time: *mostRecentTime}
+	} else {
+		advancedCronJob.Status.LastScheduleTime = nil
+	}
+	advancedCronJob.Status.Active = nil
+	for _, job := range activeJobs {
+		jobRef, err := ref.GetReference(r.scheme, job.object)
+		if err != nil {
+			klog.Errorf("Unable to make reference to active %s %s of %v: %v", kind, job.object.GetName(), req.NamespacedName, err)
+			continue
+		}
+		advancedCronJob.Status.Active = append(advancedCronJob.Status.Active, *jobRef)
+	}
+
+	klog.V(1).Infof("AdvancedCronJob %v has %d active, %d successful, %d failed %s", req.NamespacedName, len(activeJobs), len(successfulJobs), len(failedJobs), kind)
+	if err := r.updateAdvancedJobStatus(req, advancedCronJob); err != nil {
+		klog.Errorf("Unable to update AdvancedCronJob %v status: %v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	// deleting the old jobs is best effort, we won't requeue just to finish it
+	if advancedCronJob.Spec.FailedJobsHistoryLimit != nil {
+		r.deleteOldJobs(ctx, req, kind, failedJobs, *advancedCronJob.Spec.FailedJobsHistoryLimit)
+	}
+	if advancedCronJob.Spec.SuccessfulJobsHistoryLimit != nil {
+		r.deleteOldJobs(ctx, req, kind, successfulJobs, *advancedCronJob.Spec.SuccessfulJobsHistoryLimit)
+	}
+
+	if advancedCronJob.Spec.Paused != nil && *advancedCronJob.Spec.Paused {
+		klog.V(1).Infof("AdvancedCronJob %v paused, skipping", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	now := realClock{}.Now()
+	missedRun, nextRun, err := getNextSchedule(advancedCronJob, now)
+	if err != nil {
+		klog.Errorf("Unable to figure out schedule of AdvancedCronJob %v: %v", req.NamespacedName, err)
+		// don't requeue until the schedule is fixed
+		return ctrl.Result{}, nil
+	}
+
+	scheduledResult := ctrl.Result{RequeueAfter: nextRun.Sub(now)}
+	if missedRun.IsZero() {
+		klog.V(1).Infof("AdvancedCronJob %v has no upcoming scheduled times, sleeping until next run %v", req.NamespacedName, nextRun)
+		return scheduledResult, nil
+	}
+	if advancedCronJob.Spec.StartingDeadlineSeconds != nil &&
+		missedRun.Add(time.Duration(*advancedCronJob.Spec.StartingDeadlineSeconds)*time.Second).Before(now) {
+		klog.V(1).Infof("AdvancedCronJob %v missed starting deadline for last run %v, sleeping until next run", req.NamespacedName, missedRun)
+		return scheduledResult, nil
+	}
+
+	switch advancedCronJob.Spec.ConcurrencyPolicy {
+	case appsv1alpha1.ForbidConcurrent:
+		if len(activeJobs) > 0 {
+			klog.V(1).Infof("AdvancedCronJob %v has %d active %s, concurrency policy blocks concurrent runs", req.NamespacedName, len(activeJobs), kind)
+			return scheduledResult, nil
+		}
+	case appsv1alpha1.ReplaceConcurrent:
+		for _, job := range activeJobs {
+			if err := r.Delete(ctx, job.object, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				klog.Errorf("Unable to delete active %s %s of %v: %v", kind, job.object.GetName(), req.NamespacedName, err)
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// the job name is deterministic for a given nominal start time, to avoid the same job being created twice
+	objectMeta := metav1.ObjectMeta{
+		Labels:      make(map[string]string),
+		Annotations: make(map[string]string),
+		Name:        fmt.Sprintf("%s-%d", advancedCronJob.Name, missedRun.Unix()),
+		Namespace:   advancedCronJob.Namespace,
+	}
+	for k, v := range templateMeta.Annotations {
+		objectMeta.Annotations[k] = v
+	}
+	objectMeta.Annotations[scheduledTimeAnnotation] = missedRun.Format(time.RFC3339)
+	for k, v := range templateMeta.Labels {
+		objectMeta.Labels[k] = v
+	}
+	job := newJob(objectMeta)
+	if err := ctrl.SetControllerReference(advancedCronJob, job, r.scheme); err != nil {
+		klog.Errorf("Unable to construct %s from template of AdvancedCronJob %v: %v", kind, req.NamespacedName, err)
+		// don't requeue until the spec is changed
+		return scheduledResult, nil
+	}
+	if err := r.Create(ctx, job); err != nil {
+		klog.Errorf("Unable to create %s %s for AdvancedCronJob %v: %v", kind, job.GetName(), req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+	klog.V(1).Infof("Created %s %s for AdvancedCronJob %v run", kind, job.GetName(), req.NamespacedName)
+
+	// requeue once we see the running job, or it's time for the next scheduled run
+	return scheduledResult, nil
+}
+
+// deleteOldJobs deletes the jobs which started earliest, keeping the latest limit ones.
+func (r *ReconcileAdvancedCronJob) deleteOldJobs(ctx context.Context, req ctrl.Request, kind string, jobs []childJob, limit int32) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].startTime == nil {
+			return jobs[j].startTime != nil
+		}
+		return jobs[i].startTime.Before(jobs[j].startTime)
+	})
+	for i, job := range jobs {
+		if int32(i) >= int32(len(jobs))-limit {
+			break
+		}
+		if err := r.Delete(ctx, job.object, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Unable to delete old %s %s of %v: %v", kind, job.object.GetName(), req.NamespacedName, err)
+		} else {
+			klog.V(0).Infof("Deleted old %s %s of %v", kind, job.object.GetName(), req.NamespacedName)
+		}
+	}
+}
+
+func getScheduledTimeForJob(job metav1.Object) (*time.Time, error) {
+	timeRaw := job.GetAnnotations()[scheduledTimeAnnotation]
+	if len(timeRaw) == 0 {
+		return nil, nil
+	}
+	timeParsed, err := time.Parse(time.RFC3339, timeRaw)
+	if err != nil {
+		return nil, err
+	}
+	return &timeParsed, nil
+}
+
+// getNextSchedule returns the latest missed run and the next run of AdvancedCronJob, starting from
+// the last run or its creation. It refuses to schedule if there are too many missed runs.
+func getNextSchedule(cronJob *appsv1alpha1.AdvancedCronJob, now time.Time) (lastMissed time.Time, next time.Time, err error) {
+	sched, err := cron.ParseStandard(cronJob.Spec.Schedule)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("Unparseable schedule %q: %v", cronJob.Spec.Schedule, err)
+	}
+
+	var earliestTime time.Time
+	if cronJob.Status.LastScheduleTime != nil {
+		earliestTime = cronJob.Status.LastScheduleTime.Time
+	} else {
+		earliestTime = cronJob.ObjectMeta.CreationTimestamp.Time
+	}
+	if cronJob.Spec.StartingDeadlineSeconds != nil {
+		// controller is not going to schedule anything below this point
+		schedulingDeadline := now.Add(-time.Second * time.Duration(*cronJob.Spec.StartingDeadlineSeconds))
+		if schedulingDeadline.After(earliestTime) {
+			earliestTime = schedulingDeadline
+		}
+	}
+	if earliestTime.After(now) {
+		return time.Time{}, sched.Next(now), nil
+	}
+
+	starts := 0
+	for t := sched.Next(earliestTime); !t.After(now); t = sched.Next(t) {
+		lastMissed = t
+		// there could be so many missed start times because of a bug or clock skew,
+		// which would eat up all the CPU and memory of this controller
+		starts++
+		if starts > 100 {
+			return time.Time{}, time.Time{}, fmt.Errorf("too many missed start times (> 100). Set or decrease .spec.startingDeadlineSeconds or check clock skew")
+		}
+	}
+	return lastMissed, sched.Next(now), nil
+}
@@ -25,6 +25,7 @@ import (
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -96,6 +97,11 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		klog.Error(err)
 		return err
 	}
+
+	if err = watchImagePullJob(c); err != nil {
+		klog.Error(err)
+		return err
+	}
 	return nil
 }
 
@@ -126,7 +132,12 @@ type ReconcileAdvancedCronJob struct {
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=advancedcronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=advancedcronjobs/status,verbs=get;update;patch
 
-func (r *ReconcileAdvancedCronJob) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *ReconcileAdvancedCronJob) Reconcile(_ context.Context, req ctrl.Request) (res ctrl.Result, retErr error) {
+	startTime := time.Now()
+	defer func() {
+		metrics.ObserveReconcile("advancedcronjob", startTime, retErr)
+	}()
+
 	ctx := context.Background()
 	klog.Info("Running AdvancedCronJob job ", req.NamespacedName)
 
@@ -150,6 +161,8 @@ func (r *ReconcileAdvancedCronJob) Reconcile(_ context.Context, req ctrl.Request
 		return r.reconcileJob(ctx, req, advancedCronJob)
 	case appsv1alpha1.BroadcastJobTemplate:
 		return r.reconcileBroadcastJob(ctx, req, advancedCronJob)
+	case appsv1alpha1.ImagePullJobTemplate:
+		return r.reconcileImagePullJob(ctx, req, advancedCronJob)
 	default:
 		klog.Info("No template found", req.NamespacedName)
 	}
@@ -122,6 +122,36 @@ func TestReconcileAdvancedJobCreateJob(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestReconcileAdvancedJobCreateImagePullJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+
+	// A job
+	job1 := createJob("job3", imagePullJobTemplate())
+
+	reconcileJob := createReconcileJob(scheme, job1)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "job3",
+			Namespace: "default",
+		},
+	}
+
+	_, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	retrievedJob := &appsv1alpha1.AdvancedCronJob{}
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+	assert.Equal(t, retrievedJob.Status.Type, appsv1alpha1.ImagePullJobTemplate)
+
+	imagePullJobList := &appsv1alpha1.ImagePullJobList{}
+	listOptions := client.InNamespace(request.Namespace)
+	err = reconcileJob.List(context.TODO(), imagePullJobList, listOptions)
+	assert.NoError(t, err)
+}
+
 func createReconcileJob(scheme *runtime.Scheme, initObjs ...client.Object) ReconcileAdvancedCronJob {
 	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build()
 	eventBroadcaster := record.NewBroadcaster()
@@ -194,3 +224,14 @@ func jobTemplate() appsv1alpha1.CronJobTemplate {
 		},
 	}
 }
+
+func imagePullJobTemplate() appsv1alpha1.CronJobTemplate {
+	return appsv1alpha1.CronJobTemplate{
+		ImagePullJobTemplate: &appsv1alpha1.ImagePullJobTemplateSpec{
+			Spec: appsv1alpha1.ImagePullJobSpec{
+				Image:            "nginx:latest",
+				CompletionPolicy: appsv1alpha1.CompletionPolicy{Type: appsv1alpha1.Always},
+			},
+		},
+	}
+}
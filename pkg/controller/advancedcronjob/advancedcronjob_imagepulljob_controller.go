@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kruise Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package advancedcronjob
+
+import (
+	"context"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func watchImagePullJob(c controller.Controller) error {
+	if err := c.Watch(&source.Kind{Type: &appsv1alpha1.ImagePullJob{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &appsv1alpha1.AdvancedCronJob{},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs/status,verbs=get;update;patch
+
+func (r *ReconcileAdvancedCronJob) reconcileImagePullJob(ctx context.Context, req ctrl.Request, advancedCronJob appsv1alpha1.AdvancedCronJob) (ctrl.Result, error) {
+	advancedCronJob.Status.Type = appsv1alpha1.ImagePullJobTemplate
+
+	var childJobs appsv1alpha1.ImagePullJobList
+	if err := r.List(ctx, &childJobs, client.InNamespace(advancedCronJob.Namespace), client.MatchingFields{jobOwnerKey: advancedCronJob.Name}); err != nil {
+		klog.Errorf("Unable to list child ImagePullJobs of %v: %v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	children := make([]childJob, 0, len(childJobs.Items))
+	for i := range childJobs.Items {
+		job := &childJobs.Items[i]
+		children = append(children, childJob{object: job, finishedType: getImagePullJobFinishedType(job), startTime: job.Status.StartTime})
+	}
+
+	template := advancedCronJob.Spec.Template.ImagePullJobTemplate
+	return r.reconcileChildJobs(ctx, req, &advancedCronJob, "ImagePullJob", children, template.ObjectMeta, func(objectMeta metav1.ObjectMeta) client.Object {
+		return &appsv1alpha1.ImagePullJob{ObjectMeta: objectMeta, Spec: *template.Spec.DeepCopy()}
+	})
+}
+
+// getImagePullJobFinishedType regards the ImagePullJob as failed if it failed to pull images on any node.
+func getImagePullJobFinishedType(job *appsv1alpha1.ImagePullJob) appsv1alpha1.JobConditionType {
+	if job.Status.CompletionTime == nil {
+		return ""
+	}
+	if job.Status.Failed > 0 {
+		return appsv1alpha1.JobFailed
+	}
+	return appsv1alpha1.JobComplete
+}
@@ -7,5 +7,9 @@ func FindTemplateKind(spec appsv1alpha1.AdvancedCronJobSpec) appsv1alpha1.Templa
 		return appsv1alpha1.JobTemplate
 	}
 
+	if spec.Template.ImagePullJobTemplate != nil {
+		return appsv1alpha1.ImagePullJobTemplate
+	}
+
 	return appsv1alpha1.BroadcastJobTemplate
 }
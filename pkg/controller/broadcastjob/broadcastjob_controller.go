@@ -28,6 +28,7 @@ import (
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -148,7 +149,12 @@ type ReconcileBroadcastJob struct {
 
 // Reconcile reads that state of the cluster for a BroadcastJob object and makes changes based on the state read
 // and what is in the BroadcastJob.Spec
-func (r *ReconcileBroadcastJob) Reconcile(_ context.Context, request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileBroadcastJob) Reconcile(_ context.Context, request reconcile.Request) (res reconcile.Result, retErr error) {
+	startTime := time.Now()
+	defer func() {
+		metrics.ObserveReconcile("broadcastjob", startTime, retErr)
+	}()
+
 	// Fetch the BroadcastJob instance
 	job := &appsv1alpha1.BroadcastJob{}
 	err := r.Get(context.TODO(), request.NamespacedName, job)
@@ -36,6 +36,7 @@ import (
 	"github.com/openkruise/kruise/pkg/util/fieldindex"
 	historyutil "github.com/openkruise/kruise/pkg/util/history"
 	imagejobutilfunc "github.com/openkruise/kruise/pkg/util/imagejob/utilfunction"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 	"github.com/openkruise/kruise/pkg/util/refmanager"
 	apps "k8s.io/api/apps/v1"
@@ -181,6 +182,7 @@ func (r *ReconcileCloneSet) Reconcile(_ context.Context, request reconcile.Reque
 func (r *ReconcileCloneSet) doReconcile(request reconcile.Request) (res reconcile.Result, retErr error) {
 	startTime := time.Now()
 	defer func() {
+		metrics.ObserveReconcile("cloneset", startTime, retErr)
 		if retErr == nil {
 			if res.Requeue || res.RequeueAfter > 0 {
 				klog.Infof("Finished syncing CloneSet %s, cost %v, result: %v", request, time.Since(startTime), res)
@@ -202,6 +204,7 @@ func (r *ReconcileCloneSet) doReconcile(request reconcile.Request) (res reconcil
 			klog.V(3).Infof("CloneSet %s has been deleted.", request)
 			clonesetutils.ScaleExpectations.DeleteExpectations(request.String())
 			clonesetutils.UpdateExpectations.DeleteExpectations(request.String())
+			metrics.DeleteWorkloadMetrics("CloneSet", request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
@@ -359,6 +362,16 @@ func (r *ReconcileCloneSet) syncCloneSet(
 		return delayDuration, err
 	}
 
+	// the partition of current step overrides the one in strategy, if update steps are used
+	partition, stepDuration := r.syncControl.SyncUpdateSteps(instance, newStatus, filteredPods)
+	if partition != nil {
+		currentSet.Spec.UpdateStrategy.Partition = partition
+		updateSet.Spec.UpdateStrategy.Partition = partition
+		// whether to roll back depends on the step state just calculated, instead of the one in old status
+		currentSet.Status.CurrentStepState = newStatus.CurrentStepState
+		updateSet.Status.CurrentStepState = newStatus.CurrentStepState
+	}
+
 	var scaling bool
 	var podsScaleErr error
 	var podsUpdateErr error
@@ -374,7 +387,7 @@ func (r *ReconcileCloneSet) syncCloneSet(
 		err = podsScaleErr
 	}
 	if scaling {
-		return delayDuration, podsScaleErr
+		return stepDuration, podsScaleErr
 	}
 
 	delayDuration, podsUpdateErr = r.syncControl.Update(updateSet, currentRevision, updateRevision, revisions, filteredPods, filteredPVCs)
@@ -391,6 +404,9 @@ func (r *ReconcileCloneSet) syncCloneSet(
 		}
 	}
 
+	if stepDuration > 0 && (delayDuration <= 0 || stepDuration < delayDuration) {
+		delayDuration = stepDuration
+	}
 	return delayDuration, err
 }
 
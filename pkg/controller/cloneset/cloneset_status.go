@@ -18,10 +18,14 @@ package cloneset
 
 import (
 	"context"
+	"reflect"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	clonesetcore "github.com/openkruise/kruise/pkg/controller/cloneset/core"
 	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
+	"github.com/openkruise/kruise/pkg/util/lifecycle"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -44,6 +48,13 @@ type realStatusUpdater struct {
 
 func (r *realStatusUpdater) UpdateCloneSetStatus(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod) error {
 	r.calculateStatus(cs, newStatus, pods)
+	metrics.RecordWorkloadStatus("CloneSet", cs.Namespace, cs.Name, map[string]int32{
+		"replicas":      newStatus.Replicas,
+		"ready":         newStatus.ReadyReplicas,
+		"available":     newStatus.AvailableReplicas,
+		"updated":       newStatus.UpdatedReplicas,
+		"updated_ready": newStatus.UpdatedReadyReplicas,
+	}, newStatus.CurrentRevision, newStatus.UpdateRevision)
 	if r.inconsistentStatus(cs, newStatus) {
 		klog.Infof("To update CloneSet status for  %s/%s, replicas=%d ready=%d available=%d updated=%d updatedReady=%d, revisions current=%s update=%s",
 			cs.Namespace, cs.Name, newStatus.Replicas, newStatus.ReadyReplicas, newStatus.AvailableReplicas, newStatus.UpdatedReplicas, newStatus.UpdatedReadyReplicas, newStatus.CurrentRevision, newStatus.UpdateRevision)
@@ -77,7 +88,9 @@ func (r *realStatusUpdater) inconsistentStatus(cs *appsv1alpha1.CloneSet, newSta
 		newStatus.UpdatedReplicas != oldStatus.UpdatedReplicas ||
 		newStatus.UpdateRevision != oldStatus.UpdateRevision ||
 		newStatus.CurrentRevision != oldStatus.CurrentRevision ||
-		newStatus.LabelSelector != oldStatus.LabelSelector
+		newStatus.LabelSelector != oldStatus.LabelSelector ||
+		!reflect.DeepEqual(newStatus.CurrentStepIndex, oldStatus.CurrentStepIndex) ||
+		newStatus.CurrentStepState != oldStatus.CurrentStepState
 }
 
 func (r *realStatusUpdater) calculateStatus(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod) {
@@ -87,7 +100,9 @@ func (r *realStatusUpdater) calculateStatus(cs *appsv1alpha1.CloneSet, newStatus
 		if coreControl.IsPodUpdateReady(pod, 0) {
 			newStatus.ReadyReplicas++
 		}
-		if coreControl.IsPodUpdateReady(pod, cs.Spec.MinReadySeconds) {
+		// Pods waiting for PreNormal hook should not be counted as available
+		if coreControl.IsPodUpdateReady(pod, cs.Spec.MinReadySeconds) &&
+			lifecycle.GetPodLifecycleState(pod) != appspub.LifecycleStatePreparingNormal {
 			newStatus.AvailableReplicas++
 		}
 		if clonesetutils.EqualToRevisionHash("", pod, newStatus.UpdateRevision) {
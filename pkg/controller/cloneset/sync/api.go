@@ -25,6 +25,7 @@ import (
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -41,6 +42,11 @@ type Interface interface {
 		currentRevision, updateRevision *apps.ControllerRevision, revisions []*apps.ControllerRevision,
 		pods []*v1.Pod, pvcs []*v1.PersistentVolumeClaim,
 	) (time.Duration, error)
+
+	// SyncUpdateSteps calculates the progress of update steps into newStatus,
+	// and returns the partition of current step, which is nil if steps are not used.
+	SyncUpdateSteps(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod,
+	) (*intstr.IntOrString, time.Duration)
 }
 
 type realControl struct {
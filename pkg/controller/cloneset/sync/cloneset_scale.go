@@ -60,7 +60,10 @@ func (r *realControl) Scale(
 		return false, nil
 	}
 
-	// 1. manage pods to delete and in preDelete
+	// 1. manage pods waiting for preNormal, to delete and in preDelete
+	if modified, err := r.managePreparingNormal(updateCS, pods); err != nil || modified {
+		return modified, err
+	}
 	podsSpecifiedToDelete, podsInPreDelete, numToDelete := getPlannedDeletedPods(updateCS, pods)
 	if modified, err := r.managePreparingDelete(updateCS, pods, podsInPreDelete, numToDelete); err != nil || modified {
 		return modified, err
@@ -160,6 +163,30 @@ func (r *realControl) Scale(
 	return false, nil
 }
 
+// managePreparingNormal turns the Pods from PreparingNormal to Normal once their PreNormal hook has been satisfied.
+func (r *realControl) managePreparingNormal(cs *appsv1alpha1.CloneSet, pods []*v1.Pod) (bool, error) {
+	var modified bool
+	for _, pod := range pods {
+		if lifecycle.GetPodLifecycleState(pod) != appspub.LifecycleStatePreparingNormal {
+			continue
+		}
+		if cs.Spec.Lifecycle != nil && cs.Spec.Lifecycle.PreNormal != nil &&
+			!lifecycle.IsPodAllHooked(cs.Spec.Lifecycle.PreNormal, pod) {
+			continue
+		}
+
+		klog.V(3).Infof("CloneSet %s patch pod %s lifecycle from PreparingNormal to Normal",
+			clonesetutils.GetControllerKey(cs), pod.Name)
+		if updated, err := r.lifecycleControl.UpdatePodLifecycle(pod, appspub.LifecycleStateNormal); err != nil {
+			return modified, err
+		} else if updated {
+			modified = true
+			clonesetutils.ResourceVersionExpectations.Expect(pod)
+		}
+	}
+	return modified, nil
+}
+
 func (r *realControl) managePreparingDelete(cs *appsv1alpha1.CloneSet, pods, podsInPreDelete []*v1.Pod, numToDelete int) (bool, error) {
 	diff := int(*cs.Spec.Replicas) - len(pods) + numToDelete
 	var modified bool
@@ -213,7 +240,11 @@ func (r *realControl) createPods(
 		if clonesetutils.EqualToRevisionHash("", pod, currentRevision) {
 			cs = currentCS
 		}
-		lifecycle.SetPodLifecycle(appspub.LifecycleStateNormal)(pod)
+		if cs.Spec.Lifecycle != nil && cs.Spec.Lifecycle.PreNormal != nil {
+			lifecycle.SetPodLifecycle(appspub.LifecycleStatePreparingNormal)(pod)
+		} else {
+			lifecycle.SetPodLifecycle(appspub.LifecycleStateNormal)(pod)
+		}
 
 		var createErr error
 		if createErr = r.createOnePod(cs, pod, existingPVCNames); createErr != nil {
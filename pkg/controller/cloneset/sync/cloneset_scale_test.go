@@ -28,12 +28,14 @@ import (
 	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	"github.com/openkruise/kruise/pkg/util/lifecycle"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -483,6 +485,46 @@ func TestDeletePods(t *testing.T) {
 	}
 }
 
+func TestManagePreparingNormal(t *testing.T) {
+	cs := &appsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "clone-test"},
+		Spec: appsv1alpha1.CloneSetSpec{
+			Replicas:  utilpointer.Int32Ptr(2),
+			Lifecycle: &appspub.Lifecycle{PreNormal: &appspub.LifecycleHook{LabelsHandler: map[string]string{"registered": "true"}}},
+		},
+	}
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-0", Labels: map[string]string{
+			appspub.LifecycleStateKey: string(appspub.LifecycleStatePreparingNormal),
+			"registered":              "true",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1", Labels: map[string]string{
+			appspub.LifecycleStateKey: string(appspub.LifecycleStatePreparingNormal),
+		}}},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithObjects(pods[0], pods[1]).Build()
+	ctrl := &realControl{Client: fakeClient, lifecycleControl: lifecycle.NewForTest(fakeClient)}
+	modified, err := ctrl.managePreparingNormal(cs, pods)
+	if err != nil || !modified {
+		t.Fatalf("expected modified, got %v, %v", modified, err)
+	}
+
+	expectedStates := map[string]appspub.LifecycleStateType{
+		"pod-0": appspub.LifecycleStateNormal,
+		"pod-1": appspub.LifecycleStatePreparingNormal,
+	}
+	for name, expected := range expectedStates {
+		pod := &v1.Pod{}
+		if err := fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: name}, pod); err != nil {
+			t.Fatal(err)
+		}
+		if state := lifecycle.GetPodLifecycleState(pod); state != expected {
+			t.Fatalf("expected pod %s in state %s, got %s", name, expected, state)
+		}
+	}
+}
+
 func TestGetOrGenAvailableIDs(t *testing.T) {
 	pods := []*v1.Pod{
 		{
@@ -138,8 +138,9 @@ func calculateDiffsWithExpectation(cs *appsv1alpha1.CloneSet, pods []*v1.Pod, cu
 	updateOldDiff := oldRevisionActiveCount - partition
 	updateNewDiff := newRevisionActiveCount - (replicas - partition)
 	// If the currentRevision and updateRevision are consistent, Pods can only update to this revision
-	// If the CloneSetPartitionRollback is not enabled, Pods can only update to the new revision
-	if updateRevision == currentRevision || !utilfeature.DefaultFeatureGate.Enabled(features.CloneSetPartitionRollback) {
+	// If the CloneSetPartitionRollback is not enabled and the update steps are not rolled back, Pods can only update to the new revision
+	if updateRevision == currentRevision ||
+		(!utilfeature.DefaultFeatureGate.Enabled(features.CloneSetPartitionRollback) && cs.Status.CurrentStepState != appsv1alpha1.CloneSetStepRolledBack) {
 		updateOldDiff = integer.IntMax(updateOldDiff, 0)
 		updateNewDiff = integer.IntMin(updateNewDiff, 0)
 	}
@@ -222,6 +223,8 @@ func isPodReady(coreControl clonesetcore.Control, pod *v1.Pod) bool {
 	return isPodAvailable(coreControl, pod, 0)
 }
 
+// isPodAvailable returns true only if the Pod is in Normal lifecycle state and ready for minReadySeconds,
+// so Pods waiting for PreNormal hook or in other hooks will not be counted.
 func isPodAvailable(coreControl clonesetcore.Control, pod *v1.Pod, minReadySeconds int32) bool {
 	state := lifecycle.GetPodLifecycleState(pod)
 	if state != "" && state != appspub.LifecycleStateNormal {
@@ -38,7 +38,12 @@ import (
 	"github.com/openkruise/kruise/pkg/util/updatesort"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"k8s.io/utils/integer"
+	utilpointer "k8s.io/utils/pointer"
 )
 
 func (c *realControl) Update(cs *appsv1alpha1.CloneSet,
@@ -336,3 +341,153 @@ func limitUpdateIndexes(coreControl clonesetcore.Control, minReadySeconds int32,
 	}
 	return waitUpdateIndexes
 }
+
+// SyncUpdateSteps calculates the progress of update steps into newStatus, and returns the partition of current step.
+// The step index starts over once the update revision changes. When the failed Pods in update revision exceed
+// the rollback policy, the steps will be marked as RolledBack and all Pods should be rolled back to current revision.
+func (c *realControl) SyncUpdateSteps(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod,
+) (*intstrutil.IntOrString, time.Duration) {
+	steps := cs.Spec.UpdateStrategy.Steps
+	if len(steps) == 0 {
+		return nil, 0
+	}
+
+	now := metav1.Now()
+	if cs.Status.CurrentStepIndex == nil || cs.Status.UpdateRevision != newStatus.UpdateRevision {
+		setUpdateStep(newStatus, 0, appsv1alpha1.CloneSetStepUpgrading, now)
+	} else {
+		newStatus.CurrentStepIndex = utilpointer.Int32Ptr(*cs.Status.CurrentStepIndex)
+		newStatus.CurrentStepState = cs.Status.CurrentStepState
+		newStatus.LastStepTransitionTime = cs.Status.LastStepTransitionTime.DeepCopy()
+		if int(*newStatus.CurrentStepIndex) >= len(steps) {
+			setUpdateStep(newStatus, int32(len(steps)-1), newStatus.CurrentStepState, now)
+		}
+	}
+
+	// all Pods have been updated, or the update revision is the same as current revision
+	if newStatus.CurrentRevision == newStatus.UpdateRevision {
+		if newStatus.CurrentStepState != appsv1alpha1.CloneSetStepCompleted {
+			setUpdateStep(newStatus, int32(len(steps)-1), appsv1alpha1.CloneSetStepCompleted, now)
+		}
+		return nil, 0
+	}
+	if newStatus.CurrentStepState == appsv1alpha1.CloneSetStepRolledBack {
+		return util.GetIntOrStrPointer(intstrutil.FromString("100%")), 0
+	}
+
+	coreControl := clonesetcore.New(cs)
+	var updatedPods []*v1.Pod
+	var updatedReadyCount int
+	for _, pod := range pods {
+		if clonesetutils.EqualToRevisionHash("", pod, newStatus.UpdateRevision) {
+			updatedPods = append(updatedPods, pod)
+			if isPodAvailable(coreControl, pod, cs.Spec.MinReadySeconds) {
+				updatedReadyCount++
+			}
+		}
+	}
+
+	requeueDuration := requeueduration.Duration{}
+	if policy := cs.Spec.UpdateStrategy.RollbackPolicy; policy != nil {
+		failedCount, duration := countFailedPods(cs, coreControl, updatedPods, now.Time)
+		requeueDuration.Update(duration)
+		maxFailed, _ := intstrutil.GetValueFromIntOrPercent(intstrutil.ValueOrDefault(policy.MaxFailedPods, intstrutil.FromInt(0)), len(updatedPods), true)
+		if failedCount > maxFailed {
+			setUpdateStep(newStatus, *newStatus.CurrentStepIndex, appsv1alpha1.CloneSetStepRolledBack, now)
+			c.recorder.Eventf(cs, v1.EventTypeWarning, "RolledBack",
+				"rollback to revision %s for %d failed pods in revision %s exceed %d", newStatus.CurrentRevision, failedCount, newStatus.UpdateRevision, maxFailed)
+			return util.GetIntOrStrPointer(intstrutil.FromString("100%")), 0
+		}
+	}
+
+	replicas := int(*cs.Spec.Replicas)
+	index := int(*newStatus.CurrentStepIndex)
+	step := steps[index]
+	target := getStepUpdatedReplicas(step, replicas)
+	switch newStatus.CurrentStepState {
+	case appsv1alpha1.CloneSetStepUpgrading:
+		if len(updatedPods) < target || updatedReadyCount < target {
+			break
+		}
+		if step.Pause == nil {
+			index = advanceUpdateStep(newStatus, len(steps), now)
+		} else {
+			setUpdateStep(newStatus, int32(index), appsv1alpha1.CloneSetStepPaused, now)
+			if step.Pause.DurationSeconds != nil {
+				requeueDuration.Update(time.Duration(*step.Pause.DurationSeconds) * time.Second)
+			}
+		}
+	case appsv1alpha1.CloneSetStepPaused:
+		if step.Pause == nil {
+			index = advanceUpdateStep(newStatus, len(steps), now)
+		} else if step.Pause.DurationSeconds != nil {
+			pauseDuration := time.Duration(*step.Pause.DurationSeconds) * time.Second
+			if elapsed := now.Sub(newStatus.LastStepTransitionTime.Time); elapsed >= pauseDuration {
+				index = advanceUpdateStep(newStatus, len(steps), now)
+			} else {
+				requeueDuration.Update(pauseDuration - elapsed)
+			}
+		} else if cs.Annotations[appsv1alpha1.CloneSetApprovedStepAnnotation] == fmt.Sprintf("%s/%d", newStatus.UpdateRevision, index) {
+			index = advanceUpdateStep(newStatus, len(steps), now)
+		}
+	}
+
+	target = getStepUpdatedReplicas(steps[index], replicas)
+	klog.V(3).Infof("CloneSet %s is in update step %d (%s), expected %d pods in update revision",
+		clonesetutils.GetControllerKey(cs), index, newStatus.CurrentStepState, target)
+	return util.GetIntOrStrPointer(intstrutil.FromInt(replicas - target)), requeueDuration.Get()
+}
+
+func setUpdateStep(status *appsv1alpha1.CloneSetStatus, index int32, state appsv1alpha1.CloneSetUpdateStepState, now metav1.Time) {
+	status.CurrentStepIndex = utilpointer.Int32Ptr(index)
+	status.CurrentStepState = state
+	status.LastStepTransitionTime = &now
+}
+
+// advanceUpdateStep moves the status to the next step, or marks the steps completed if it is the last one.
+func advanceUpdateStep(status *appsv1alpha1.CloneSetStatus, stepCount int, now metav1.Time) int {
+	index := int(*status.CurrentStepIndex)
+	if index+1 >= stepCount {
+		setUpdateStep(status, int32(index), appsv1alpha1.CloneSetStepCompleted, now)
+		return index
+	}
+	setUpdateStep(status, int32(index+1), appsv1alpha1.CloneSetStepUpgrading, now)
+	return index + 1
+}
+
+// getStepUpdatedReplicas returns the desired number of Pods in update revision after the step.
+func getStepUpdatedReplicas(step appsv1alpha1.CloneSetUpdateStep, replicas int) int {
+	target, _ := intstrutil.GetValueFromIntOrPercent(&step.Replicas, replicas, true)
+	return integer.IntMax(integer.IntMin(target, replicas), 0)
+}
+
+// countFailedPods returns the number of failed Pods, which are failed or have not been ready for notReadyTimeoutSeconds,
+// and the duration after which the next not-ready Pod will time out.
+func countFailedPods(cs *appsv1alpha1.CloneSet, coreControl clonesetcore.Control, pods []*v1.Pod, now time.Time) (int, time.Duration) {
+	timeout := time.Duration(appsv1alpha1.DefaultCloneSetRollbackNotReadyTimeoutSeconds) * time.Second
+	if seconds := cs.Spec.UpdateStrategy.RollbackPolicy.NotReadyTimeoutSeconds; seconds != nil {
+		timeout = time.Duration(*seconds) * time.Second
+	}
+
+	var failedCount int
+	requeueDuration := requeueduration.Duration{}
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodFailed {
+			failedCount++
+			continue
+		}
+		if !pod.DeletionTimestamp.IsZero() || coreControl.IsPodUpdateReady(pod, 0) {
+			continue
+		}
+		notReadySince := pod.CreationTimestamp.Time
+		if cond := podutil.GetPodReadyCondition(pod.Status); cond != nil && cond.LastTransitionTime.After(notReadySince) {
+			notReadySince = cond.LastTransitionTime.Time
+		}
+		if elapsed := now.Sub(notReadySince); elapsed >= timeout {
+			failedCount++
+		} else {
+			requeueDuration.Update(timeout - elapsed)
+		}
+	}
+	return failedCount, requeueDuration.Get()
+}
@@ -19,6 +19,7 @@ package sync
 import (
 	"context"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -740,3 +741,184 @@ func TestCalculateUpdateCount(t *testing.T) {
 		}
 	}
 }
+
+func TestSyncUpdateSteps(t *testing.T) {
+	now := metav1.Now()
+	twoMinutesAgo := metav1.NewTime(now.Add(-2 * time.Minute))
+	oneHourAgo := metav1.NewTime(now.Add(-time.Hour))
+	newPods := func(updated, updatedNotReady int) []*v1.Pod {
+		var pods []*v1.Pod
+		for i := 0; i < 10; i++ {
+			revision, ready := "cs-rev1", v1.ConditionTrue
+			if i < updated+updatedNotReady {
+				revision = "cs-rev2"
+			}
+			if i >= updated && i < updated+updatedNotReady {
+				ready = v1.ConditionFalse
+			}
+			pods = append(pods, &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pod-" + strconv.Itoa(i),
+					Labels:            map[string]string{apps.ControllerRevisionHashLabelKey: revision},
+					CreationTimestamp: oneHourAgo,
+				},
+				Status: v1.PodStatus{
+					Phase:      v1.PodRunning,
+					Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: ready, LastTransitionTime: oneHourAgo}},
+				},
+			})
+		}
+		return pods
+	}
+	newCloneSet := func(index int32, state appsv1alpha1.CloneSetUpdateStepState, transitionTime metav1.Time) *appsv1alpha1.CloneSet {
+		return &appsv1alpha1.CloneSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cs"},
+			Spec: appsv1alpha1.CloneSetSpec{
+				Replicas: getInt32Pointer(10),
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Steps: []appsv1alpha1.CloneSetUpdateStep{
+						{Replicas: intstrutil.FromString("20%"), Pause: &appsv1alpha1.CloneSetUpdateStepPause{DurationSeconds: getInt32Pointer(60)}},
+						{Replicas: intstrutil.FromInt(5), Pause: &appsv1alpha1.CloneSetUpdateStepPause{}},
+						{Replicas: intstrutil.FromString("100%")},
+					},
+				},
+			},
+			Status: appsv1alpha1.CloneSetStatus{
+				CurrentRevision:        "cs-rev1",
+				UpdateRevision:         "cs-rev2",
+				CurrentStepIndex:       getInt32Pointer(index),
+				CurrentStepState:       state,
+				LastStepTransitionTime: &transitionTime,
+			},
+		}
+	}
+
+	cases := []struct {
+		name              string
+		getCloneSet       func() *appsv1alpha1.CloneSet
+		pods              []*v1.Pod
+		currentRevision   string
+		expectedPartition *intstrutil.IntOrString
+		expectedIndex     int32
+		expectedState     appsv1alpha1.CloneSetUpdateStepState
+	}{
+		{
+			name: "start over for new update revision",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				cs := newCloneSet(2, appsv1alpha1.CloneSetStepCompleted, oneHourAgo)
+				cs.Status.UpdateRevision = "cs-rev1"
+				return cs
+			},
+			pods:              newPods(0, 0),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromInt(8)),
+			expectedIndex:     0,
+			expectedState:     appsv1alpha1.CloneSetStepUpgrading,
+		},
+		{
+			name: "pause after pods of step ready",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				return newCloneSet(0, appsv1alpha1.CloneSetStepUpgrading, oneHourAgo)
+			},
+			pods:              newPods(2, 0),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromInt(8)),
+			expectedIndex:     0,
+			expectedState:     appsv1alpha1.CloneSetStepPaused,
+		},
+		{
+			name: "keep upgrading when pods of step not ready",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				return newCloneSet(0, appsv1alpha1.CloneSetStepUpgrading, oneHourAgo)
+			},
+			pods:              newPods(1, 1),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromInt(8)),
+			expectedIndex:     0,
+			expectedState:     appsv1alpha1.CloneSetStepUpgrading,
+		},
+		{
+			name: "continue to next step after pause duration",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				return newCloneSet(0, appsv1alpha1.CloneSetStepPaused, twoMinutesAgo)
+			},
+			pods:              newPods(2, 0),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromInt(5)),
+			expectedIndex:     1,
+			expectedState:     appsv1alpha1.CloneSetStepUpgrading,
+		},
+		{
+			name: "wait for approval",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				return newCloneSet(1, appsv1alpha1.CloneSetStepPaused, oneHourAgo)
+			},
+			pods:              newPods(5, 0),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromInt(5)),
+			expectedIndex:     1,
+			expectedState:     appsv1alpha1.CloneSetStepPaused,
+		},
+		{
+			name: "continue to next step after approved",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				cs := newCloneSet(1, appsv1alpha1.CloneSetStepPaused, oneHourAgo)
+				cs.Annotations = map[string]string{appsv1alpha1.CloneSetApprovedStepAnnotation: "cs-rev2/1"}
+				return cs
+			},
+			pods:              newPods(5, 0),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromInt(0)),
+			expectedIndex:     2,
+			expectedState:     appsv1alpha1.CloneSetStepUpgrading,
+		},
+		{
+			name: "ignore approval of other revision",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				cs := newCloneSet(1, appsv1alpha1.CloneSetStepPaused, oneHourAgo)
+				cs.Annotations = map[string]string{appsv1alpha1.CloneSetApprovedStepAnnotation: "cs-rev1/1"}
+				return cs
+			},
+			pods:              newPods(5, 0),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromInt(5)),
+			expectedIndex:     1,
+			expectedState:     appsv1alpha1.CloneSetStepPaused,
+		},
+		{
+			name: "roll back for failed pods",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				cs := newCloneSet(1, appsv1alpha1.CloneSetStepUpgrading, oneHourAgo)
+				cs.Spec.UpdateStrategy.RollbackPolicy = &appsv1alpha1.CloneSetRollbackPolicy{MaxFailedPods: util.GetIntOrStrPointer(intstrutil.FromString("20%"))}
+				return cs
+			},
+			pods:              newPods(3, 2),
+			expectedPartition: util.GetIntOrStrPointer(intstrutil.FromString("100%")),
+			expectedIndex:     1,
+			expectedState:     appsv1alpha1.CloneSetStepRolledBack,
+		},
+		{
+			name: "complete when all pods updated",
+			getCloneSet: func() *appsv1alpha1.CloneSet {
+				return newCloneSet(2, appsv1alpha1.CloneSetStepUpgrading, oneHourAgo)
+			},
+			pods:            newPods(10, 0),
+			currentRevision: "cs-rev2",
+			expectedIndex:   2,
+			expectedState:   appsv1alpha1.CloneSetStepCompleted,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := &realControl{recorder: record.NewFakeRecorder(10)}
+			cs := testCase.getCloneSet()
+			newStatus := &appsv1alpha1.CloneSetStatus{CurrentRevision: "cs-rev1", UpdateRevision: "cs-rev2"}
+			if testCase.currentRevision != "" {
+				newStatus.CurrentRevision = testCase.currentRevision
+			}
+
+			partition, _ := ctrl.SyncUpdateSteps(cs, newStatus, testCase.pods)
+			if !reflect.DeepEqual(partition, testCase.expectedPartition) {
+				t.Fatalf("expected partition %v, got %v", testCase.expectedPartition, partition)
+			}
+			if *newStatus.CurrentStepIndex != testCase.expectedIndex || newStatus.CurrentStepState != testCase.expectedState {
+				t.Fatalf("expected step %d %s, got %d %s", testCase.expectedIndex, testCase.expectedState,
+					*newStatus.CurrentStepIndex, newStatus.CurrentStepState)
+			}
+		})
+	}
+}
@@ -24,9 +24,11 @@ import (
 	"github.com/openkruise/kruise/pkg/controller/daemonset"
 	"github.com/openkruise/kruise/pkg/controller/imagepulljob"
 	"github.com/openkruise/kruise/pkg/controller/nodeimage"
+	"github.com/openkruise/kruise/pkg/controller/persistentpodstate"
 	"github.com/openkruise/kruise/pkg/controller/podreadiness"
 	"github.com/openkruise/kruise/pkg/controller/podunavailablebudget"
 	"github.com/openkruise/kruise/pkg/controller/resourcedistribution"
+	"github.com/openkruise/kruise/pkg/controller/sidecarbarrier"
 	"github.com/openkruise/kruise/pkg/controller/sidecarset"
 	"github.com/openkruise/kruise/pkg/controller/statefulset"
 	"github.com/openkruise/kruise/pkg/controller/uniteddeployment"
@@ -48,11 +50,13 @@ func init() {
 	controllerAddFuncs = append(controllerAddFuncs, imagepulljob.Add)
 	controllerAddFuncs = append(controllerAddFuncs, podreadiness.Add)
 	controllerAddFuncs = append(controllerAddFuncs, sidecarset.Add)
+	controllerAddFuncs = append(controllerAddFuncs, sidecarbarrier.Add)
 	controllerAddFuncs = append(controllerAddFuncs, statefulset.Add)
 	controllerAddFuncs = append(controllerAddFuncs, uniteddeployment.Add)
 	controllerAddFuncs = append(controllerAddFuncs, podunavailablebudget.Add)
 	controllerAddFuncs = append(controllerAddFuncs, workloadspread.Add)
 	controllerAddFuncs = append(controllerAddFuncs, resourcedistribution.Add)
+	controllerAddFuncs = append(controllerAddFuncs, persistentpodstate.Add)
 }
 
 func SetupWithManager(m manager.Manager) error {
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -166,6 +167,22 @@ func storeDaemonSetStatus(dsClient kubeClient.Client, ds *appsv1alpha1.DaemonSet
 		Name:      ds.Name,
 	}
 
+	// DaemonSet does not record its current revision, so it is regarded as the update one
+	// only after all scheduled Pods have been updated.
+	var currentRevision string
+	if updatedNumberScheduled >= desiredNumberScheduled {
+		currentRevision = hash
+	}
+	metrics.RecordWorkloadStatus("DaemonSet", ds.Namespace, ds.Name, map[string]int32{
+		"desired":      int32(desiredNumberScheduled),
+		"current":      int32(currentNumberScheduled),
+		"misscheduled": int32(numberMisscheduled),
+		"ready":        int32(numberReady),
+		"updated":      int32(updatedNumberScheduled),
+		"available":    int32(numberAvailable),
+		"unavailable":  int32(numberUnavailable),
+	}, currentRevision, hash)
+
 	if int(ds.Status.DesiredNumberScheduled) == desiredNumberScheduled &&
 		int(ds.Status.CurrentNumberScheduled) == currentNumberScheduled &&
 		int(ds.Status.NumberMisscheduled) == numberMisscheduled &&
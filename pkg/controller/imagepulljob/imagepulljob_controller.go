@@ -24,7 +24,7 @@ import (
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
-	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
 	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
@@ -106,6 +106,16 @@ func add(mgr manager.Manager, r *ReconcileImagePullJob) error {
 		return err
 	}
 
+	// Watch for workloads for jobs that pull the images of workload
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.CloneSet{}}, &workloadEventHandler{Reader: mgr.GetCache(), kind: "CloneSet"})
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &appsv1beta1.StatefulSet{}}, &workloadEventHandler{Reader: mgr.GetCache(), kind: "StatefulSet"})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -120,6 +130,7 @@ type ReconcileImagePullJob struct {
 
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=clonesets;statefulsets,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a ImagePullJob object and makes changes based on the state read
 // and what is in the ImagePullJob.Spec
@@ -200,14 +211,24 @@ func (r *ReconcileImagePullJob) Reconcile(_ context.Context, request reconcile.R
 		}
 	}
 
+	// Get all images to be pulled by this job
+	images, err := getImagesForJob(r.Client, job)
+	if err != nil {
+		if errors.IsNotFound(err) && job.Spec.ImagesFromWorkload != nil {
+			// wait for the workload to be created, which will trigger the job again
+			return reconcile.Result{}, r.updateWorkloadNotFoundStatus(job)
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get images: %v", err)
+	}
+
 	// Calculate the new status for this job
-	newStatus, notSyncedNodeImages, err := r.calculateStatus(job, nodeImages)
+	newStatus, notSyncedNodeImages, err := r.calculateStatus(job, images, nodeImages)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("failed to calculate status: %v", err)
 	}
 
-	// Sync image to more NodeImages
-	if err = r.syncNodeImages(job, newStatus, notSyncedNodeImages); err != nil {
+	// Sync images to more NodeImages
+	if err = r.syncNodeImages(job, images, newStatus, notSyncedNodeImages); err != nil {
 		return reconcile.Result{}, fmt.Errorf("failed to sync NodeImages: %v", err)
 	}
 
@@ -230,7 +251,22 @@ func (r *ReconcileImagePullJob) Reconcile(_ context.Context, request reconcile.R
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, newStatus *appsv1alpha1.ImagePullJobStatus, notSyncedNodeImages []string) error {
+func (r *ReconcileImagePullJob) updateWorkloadNotFoundStatus(job *appsv1alpha1.ImagePullJob) error {
+	ref := job.Spec.ImagesFromWorkload
+	message := fmt.Sprintf("waiting for %s %s to be found", ref.Kind, ref.Name)
+	if job.Status.Message == message {
+		return nil
+	}
+	klog.Warningf("ImagePullJob %s/%s can not find %s %s to pull images", job.Namespace, job.Name, ref.Kind, ref.Name)
+	job.Status.Message = message
+	if err := r.Status().Update(context.TODO(), job); err != nil {
+		return fmt.Errorf("update ImagePullJob status error: %v", err)
+	}
+	resourceVersionExpectations.Expect(job)
+	return nil
+}
+
+func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, images []jobImage, newStatus *appsv1alpha1.ImagePullJobStatus, notSyncedNodeImages []string) error {
 	if len(notSyncedNodeImages) == 0 {
 		return nil
 	}
@@ -269,6 +305,8 @@ func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, n
 	if job.Spec.PullPolicy != nil {
 		pullPolicy.BackoffLimit = job.Spec.PullPolicy.BackoffLimit
 		pullPolicy.TimeoutSeconds = job.Spec.PullPolicy.TimeoutSeconds
+		pullPolicy.MaxBytesPerSecond = job.Spec.PullPolicy.MaxBytesPerSecond
+		pullPolicy.MaxConcurrentPulls = job.Spec.PullPolicy.MaxConcurrentPulls
 	}
 	if job.Spec.CompletionPolicy.Type == appsv1alpha1.Never {
 		pullPolicy.TTLSecondsAfterFinished = getTTLSecondsForNever()
@@ -279,7 +317,6 @@ func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, n
 	}
 
 	now := metav1.NewTime(r.clock.Now())
-	imageName, imageTag, _ := daemonutil.NormalizeImageRefToNameTag(job.Spec.Image)
 	for i := 0; i < parallelism; i++ {
 		var skip bool
 		updateErr := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
@@ -288,55 +325,20 @@ func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, n
 				return err
 			}
 			if nodeImage.Spec.Images == nil {
-				nodeImage.Spec.Images = make(map[string]appsv1alpha1.ImageSpec, 1)
-			}
-			imageSpec := nodeImage.Spec.Images[imageName]
-
-			for _, secret := range secrets {
-				if !containsObject(imageSpec.PullSecrets, secret) {
-					imageSpec.PullSecrets = append(imageSpec.PullSecrets, secret)
-				}
+				nodeImage.Spec.Images = make(map[string]appsv1alpha1.ImageSpec, len(images))
 			}
 
-			var found bool
-			for i := range imageSpec.Tags {
-				tagSpec := &imageSpec.Tags[i]
-				if tagSpec.Tag != imageTag {
-					continue
+			// all tags of the job are written into the NodeImage in one update
+			var modified bool
+			for _, image := range images {
+				if syncImageTag(&nodeImage, image, secrets, ownerRef, &pullPolicy, &now) {
+					modified = true
 				}
-				if containsObjectRef(tagSpec.OwnerReferences, *ownerRef) {
-					skip = true
-					return nil
-				}
-				// increase version to start a new round of image downloads
-				tagSpec.Version++
-				// merge owner reference
-				tagSpec.OwnerReferences = append(tagSpec.OwnerReferences, *ownerRef)
-				tagSpec.CreatedAt = &now
-				found = true
-				break
 			}
-			if !found {
-				var foundVersion int64 = -1
-				if imageStatus, ok := nodeImage.Status.ImageStatuses[imageName]; ok {
-					for _, tagStatus := range imageStatus.Tags {
-						if tagStatus.Tag == imageTag {
-							foundVersion = tagStatus.Version
-							break
-						}
-					}
-				}
-
-				imageSpec.Tags = append(imageSpec.Tags, appsv1alpha1.ImageTagSpec{
-					Tag:             imageTag,
-					Version:         foundVersion + 1,
-					PullPolicy:      &pullPolicy,
-					OwnerReferences: []v1.ObjectReference{*ownerRef},
-					CreatedAt:       &now,
-				})
+			if !modified {
+				skip = true
+				return nil
 			}
-			utilimagejob.SortSpecImageTags(&imageSpec)
-			nodeImage.Spec.Images[imageName] = imageSpec
 
 			oldResourceVersion := nodeImage.ResourceVersion
 			err := r.Update(context.TODO(), &nodeImage)
@@ -351,15 +353,71 @@ func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, n
 		if updateErr != nil {
 			return fmt.Errorf("update NodeImage %s error: %v", notSyncedNodeImages[i], updateErr)
 		} else if skip {
-			klog.V(4).Infof("ImagePullJob %s/%s find %s already synced in NodeImage %s", job.Namespace, job.Name, job.Spec.Image, notSyncedNodeImages[i])
+			klog.V(4).Infof("ImagePullJob %s/%s find %d images already synced in NodeImage %s", job.Namespace, job.Name, len(images), notSyncedNodeImages[i])
 			continue
 		}
-		klog.V(3).Infof("ImagePullJob %s/%s has synced %s into NodeImage %s", job.Namespace, job.Name, job.Spec.Image, notSyncedNodeImages[i])
+		klog.V(3).Infof("ImagePullJob %s/%s has synced %d images into NodeImage %s", job.Namespace, job.Name, len(images), notSyncedNodeImages[i])
 	}
 	return nil
 }
 
-func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob, nodeImages []*appsv1alpha1.NodeImage) (*appsv1alpha1.ImagePullJobStatus, []string, error) {
+// syncImageTag adds the image tag owned by the job into the NodeImage, and returns whether the NodeImage is modified.
+func syncImageTag(nodeImage *appsv1alpha1.NodeImage, image jobImage, secrets []appsv1alpha1.ReferenceObject,
+	ownerRef *v1.ObjectReference, pullPolicy *appsv1alpha1.ImageTagPullPolicy, now *metav1.Time) bool {
+
+	imageSpec := nodeImage.Spec.Images[image.name]
+	for i := range imageSpec.Tags {
+		tagSpec := &imageSpec.Tags[i]
+		if tagSpec.Tag == image.tag && containsObjectRef(tagSpec.OwnerReferences, *ownerRef) {
+			return false
+		}
+	}
+
+	for _, secret := range secrets {
+		if !containsObject(imageSpec.PullSecrets, secret) {
+			imageSpec.PullSecrets = append(imageSpec.PullSecrets, secret)
+		}
+	}
+
+	var found bool
+	for i := range imageSpec.Tags {
+		tagSpec := &imageSpec.Tags[i]
+		if tagSpec.Tag != image.tag {
+			continue
+		}
+		// increase version to start a new round of image downloads
+		tagSpec.Version++
+		// merge owner reference
+		tagSpec.OwnerReferences = append(tagSpec.OwnerReferences, *ownerRef)
+		tagSpec.CreatedAt = now
+		found = true
+		break
+	}
+	if !found {
+		var foundVersion int64 = -1
+		if imageStatus, ok := nodeImage.Status.ImageStatuses[image.name]; ok {
+			for _, tagStatus := range imageStatus.Tags {
+				if tagStatus.Tag == image.tag {
+					foundVersion = tagStatus.Version
+					break
+				}
+			}
+		}
+
+		imageSpec.Tags = append(imageSpec.Tags, appsv1alpha1.ImageTagSpec{
+			Tag:             image.tag,
+			Version:         foundVersion + 1,
+			PullPolicy:      pullPolicy,
+			OwnerReferences: []v1.ObjectReference{*ownerRef},
+			CreatedAt:       now,
+		})
+	}
+	utilimagejob.SortSpecImageTags(&imageSpec)
+	nodeImage.Spec.Images[image.name] = imageSpec
+	return true
+}
+
+func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob, images []jobImage, nodeImages []*appsv1alpha1.NodeImage) (*appsv1alpha1.ImagePullJobStatus, []string, error) {
 	newStatus := appsv1alpha1.ImagePullJobStatus{
 		StartTime: job.Status.StartTime,
 		Desired:   int32(len(nodeImages)),
@@ -368,62 +426,50 @@ func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob,
 	if newStatus.StartTime == nil {
 		newStatus.StartTime = &now
 	}
-
-	imageName, imageTag, err := daemonutil.NormalizeImageRefToNameTag(job.Spec.Image)
-	if err != nil {
-		return nil, nil, fmt.Errorf("invalid image %s: %v", job.Spec.Image, err)
+	if len(images) == 0 {
+		return nil, nil, fmt.Errorf("no image found")
 	}
 
+	newStatus.ImageStatuses = make([]appsv1alpha1.ImagePullJobImageStatus, len(images))
+	// unfinished nodes of each image, which will be failed if the job exceeds activeDeadlineSeconds
+	unfinishedNodes := make([][]string, len(images))
 	var notSynced, pulling, succeeded, failed []string
 	for _, nodeImage := range nodeImages {
-		var tagVersion int64 = -1
-		if imageSpec, ok := nodeImage.Spec.Images[imageName]; ok {
-			for _, tagSpec := range imageSpec.Tags {
-				if tagSpec.Tag != imageTag {
-					continue
-				}
-				var foundOwner bool
-				for _, ref := range tagSpec.OwnerReferences {
-					if ref.UID == job.UID {
-						foundOwner = true
-						break
-					}
-				}
-				if !foundOwner {
-					break
-				}
-				tagVersion = tagSpec.Version
+		nodeState := pullStateSucceeded
+		for i, image := range images {
+			imageStatus := &newStatus.ImageStatuses[i]
+			state := getPullState(nodeImage, image, job.UID)
+			switch state {
+			case pullStateSucceeded:
+				imageStatus.Succeeded++
+			case pullStateFailed:
+				imageStatus.Failed++
+				imageStatus.FailedNodes = append(imageStatus.FailedNodes, nodeImage.Name)
+			case pullStatePulling:
+				imageStatus.Active++
+				unfinishedNodes[i] = append(unfinishedNodes[i], nodeImage.Name)
+			default:
+				unfinishedNodes[i] = append(unfinishedNodes[i], nodeImage.Name)
+			}
+			if state > nodeState {
+				nodeState = state
 			}
-		}
-		if tagVersion < 0 {
-			notSynced = append(notSynced, nodeImage.Name)
-			continue
 		}
 
-		imageStatus, ok := nodeImage.Status.ImageStatuses[imageName]
-		if !ok {
+		switch nodeState {
+		case pullStateSucceeded:
+			succeeded = append(succeeded, nodeImage.Name)
+		case pullStateFailed:
+			failed = append(failed, nodeImage.Name)
+		case pullStatePulling:
 			pulling = append(pulling, nodeImage.Name)
-		}
-
-		for _, tagStatus := range imageStatus.Tags {
-			if tagStatus.Tag != imageTag {
-				continue
-			}
-			if tagStatus.Version != tagVersion {
-				pulling = append(pulling, nodeImage.Name)
-				break
-			}
-			switch tagStatus.Phase {
-			case appsv1alpha1.ImagePhaseSucceeded:
-				succeeded = append(succeeded, nodeImage.Name)
-			case appsv1alpha1.ImagePhaseFailed:
-				failed = append(failed, nodeImage.Name)
-			default:
-				pulling = append(pulling, nodeImage.Name)
-			}
-			break
+		default:
+			notSynced = append(notSynced, nodeImage.Name)
 		}
 	}
+	for i, image := range images {
+		newStatus.ImageStatuses[i].Image = image.image
+	}
 
 	if job.Spec.CompletionPolicy.Type != appsv1alpha1.Never && job.Spec.CompletionPolicy.ActiveDeadlineSeconds != nil && int(newStatus.Desired) != len(succeeded)+len(failed) {
 		if time.Duration(*job.Spec.CompletionPolicy.ActiveDeadlineSeconds)*time.Second <= time.Since(newStatus.StartTime.Time) {
@@ -434,6 +480,12 @@ func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob,
 			newStatus.Failed = int32(len(failed))
 			newStatus.FailedNodes = failed
 			newStatus.Message = "job exceeds activeDeadlineSeconds"
+			for i := range newStatus.ImageStatuses {
+				imageStatus := &newStatus.ImageStatuses[i]
+				imageStatus.Active = 0
+				imageStatus.FailedNodes = append(imageStatus.FailedNodes, unfinishedNodes[i]...)
+				imageStatus.Failed = int32(len(imageStatus.FailedNodes))
+			}
 			return &newStatus, nil, nil
 		}
 	}
@@ -448,5 +500,8 @@ func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob,
 
 	newStatus.Message = formatStatusMessage(&newStatus)
 	sort.Strings(newStatus.FailedNodes)
+	for i := range newStatus.ImageStatuses {
+		sort.Strings(newStatus.ImageStatuses[i].FailedNodes)
+	}
 	return &newStatus, notSynced, nil
 }
@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepulljob
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestCalculateStatusWithMultipleImages(t *testing.T) {
+	job := &appsv1alpha1.ImagePullJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job", UID: "job-uid"},
+		Spec: appsv1alpha1.ImagePullJobSpec{
+			Image:            "nginx:1.9.1",
+			Images:           []string{"busybox:1.32", "nginx:1.9.1"},
+			CompletionPolicy: appsv1alpha1.CompletionPolicy{Type: appsv1alpha1.Always},
+		},
+	}
+	images, err := getImagesForJob(nil, job)
+	if err != nil {
+		t.Fatalf("failed to get images: %v", err)
+	}
+	if len(images) != 2 || images[0].image != "nginx:1.9.1" || images[1].image != "busybox:1.32" {
+		t.Fatalf("unexpected images %v", images)
+	}
+
+	ownerRef := v1.ObjectReference{UID: job.UID}
+	newNodeImage := func(name string, phases map[string]appsv1alpha1.ImagePullPhase) *appsv1alpha1.NodeImage {
+		nodeImage := &appsv1alpha1.NodeImage{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       appsv1alpha1.NodeImageSpec{Images: map[string]appsv1alpha1.ImageSpec{}},
+			Status:     appsv1alpha1.NodeImageStatus{ImageStatuses: map[string]appsv1alpha1.ImageStatus{}},
+		}
+		for _, image := range images {
+			phase, ok := phases[image.image]
+			if !ok {
+				continue
+			}
+			nodeImage.Spec.Images[image.name] = appsv1alpha1.ImageSpec{Tags: []appsv1alpha1.ImageTagSpec{
+				{Tag: image.tag, OwnerReferences: []v1.ObjectReference{ownerRef}},
+			}}
+			if phase != "" {
+				nodeImage.Status.ImageStatuses[image.name] = appsv1alpha1.ImageStatus{Tags: []appsv1alpha1.ImageTagStatus{
+					{Tag: image.tag, Phase: phase},
+				}}
+			}
+		}
+		return nodeImage
+	}
+	nodeImages := []*appsv1alpha1.NodeImage{
+		newNodeImage("node1", map[string]appsv1alpha1.ImagePullPhase{
+			"nginx:1.9.1": appsv1alpha1.ImagePhaseSucceeded, "busybox:1.32": appsv1alpha1.ImagePhaseSucceeded}),
+		newNodeImage("node2", map[string]appsv1alpha1.ImagePullPhase{
+			"nginx:1.9.1": appsv1alpha1.ImagePhaseSucceeded, "busybox:1.32": appsv1alpha1.ImagePhasePulling}),
+		newNodeImage("node3", map[string]appsv1alpha1.ImagePullPhase{
+			"nginx:1.9.1": appsv1alpha1.ImagePhaseFailed, "busybox:1.32": appsv1alpha1.ImagePhaseSucceeded}),
+		newNodeImage("node4", map[string]appsv1alpha1.ImagePullPhase{
+			"nginx:1.9.1": appsv1alpha1.ImagePhaseSucceeded}),
+	}
+
+	r := &ReconcileImagePullJob{clock: clock.RealClock{}}
+	status, notSynced, err := r.calculateStatus(job, images, nodeImages)
+	if err != nil {
+		t.Fatalf("failed to calculate status: %v", err)
+	}
+	if !reflect.DeepEqual(notSynced, []string{"node4"}) {
+		t.Fatalf("expected node4 not synced, got %v", notSynced)
+	}
+	if status.Desired != 4 || status.Active != 1 || status.Succeeded != 1 || status.Failed != 1 ||
+		!reflect.DeepEqual(status.FailedNodes, []string{"node3"}) {
+		t.Fatalf("unexpected aggregated status %+v", status)
+	}
+	expectedImageStatuses := []appsv1alpha1.ImagePullJobImageStatus{
+		{Image: "nginx:1.9.1", Succeeded: 3, Failed: 1, FailedNodes: []string{"node3"}},
+		{Image: "busybox:1.32", Active: 1, Succeeded: 2},
+	}
+	if !reflect.DeepEqual(status.ImageStatuses, expectedImageStatuses) {
+		t.Fatalf("expected image statuses %+v, got %+v", expectedImageStatuses, status.ImageStatuses)
+	}
+
+	// all tags are synced into the NodeImage at once
+	now := metav1.Now()
+	nodeImage := &appsv1alpha1.NodeImage{ObjectMeta: metav1.ObjectMeta{Name: "node5"}, Spec: appsv1alpha1.NodeImageSpec{Images: map[string]appsv1alpha1.ImageSpec{}}}
+	for _, image := range images {
+		if !syncImageTag(nodeImage, image, nil, &ownerRef, &appsv1alpha1.ImageTagPullPolicy{}, &now) {
+			t.Fatalf("expected %s synced", image.image)
+		}
+	}
+	for _, image := range images {
+		if syncImageTag(nodeImage, image, nil, &ownerRef, &appsv1alpha1.ImageTagPullPolicy{}, &now) {
+			t.Fatalf("expected %s not synced again", image.image)
+		}
+		if state := getPullState(nodeImage, image, job.UID); state != pullStatePulling {
+			t.Fatalf("expected %s pulling, got %v", image.image, state)
+		}
+	}
+}
+
+func TestImagesFromWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	job := &appsv1alpha1.ImagePullJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job"},
+		Spec: appsv1alpha1.ImagePullJobSpec{
+			ImagesFromWorkload: &appsv1alpha1.ImagePullJobWorkloadReference{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "cs"},
+			CompletionPolicy:   appsv1alpha1.CompletionPolicy{Type: appsv1alpha1.Always},
+		},
+	}
+	otherJob := job.DeepCopy()
+	otherJob.Name = "other-job"
+	otherJob.Spec.ImagesFromWorkload.Name = "other-cs"
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(job, otherJob).Build()
+	r := &ReconcileImagePullJob{Client: fakeClient, clock: clock.RealClock{}}
+
+	// report the missing workload in status instead of returning error
+	if _, err := getImagesForJob(fakeClient, job); err == nil {
+		t.Fatalf("expected error for workload not found")
+	}
+	if err := r.updateWorkloadNotFoundStatus(job); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	newJob := &appsv1alpha1.ImagePullJob{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, newJob); err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if newJob.Status.Message == "" {
+		t.Fatalf("expected message of workload not found in status")
+	}
+
+	// the job is triggered by the workload
+	cs := &appsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cs"},
+		Spec: appsv1alpha1.CloneSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "main", Image: "nginx:1.9.1"}},
+		}}},
+	}
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	h := &workloadEventHandler{Reader: fakeClient, kind: "CloneSet"}
+	h.Create(event.CreateEvent{Object: cs}, q)
+	if q.Len() != 1 {
+		t.Fatalf("expected only job enqueued, got %d", q.Len())
+	}
+	if item, _ := q.Get(); item != (reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "job"}}) {
+		t.Fatalf("unexpected item %v", item)
+	}
+
+	// the job is not triggered if the images are not changed
+	newCS := cs.DeepCopy()
+	newCS.Labels = map[string]string{"foo": "bar"}
+	q = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	h.Update(event.UpdateEvent{ObjectOld: cs, ObjectNew: newCS}, q)
+	if q.Len() != 0 {
+		t.Fatalf("expected no job enqueued, got %d", q.Len())
+	}
+
+	if err := fakeClient.Create(context.TODO(), cs); err != nil {
+		t.Fatalf("failed to create CloneSet: %v", err)
+	}
+	images, err := getImagesForJob(fakeClient, job)
+	if err != nil || len(images) != 1 || images[0].image != "nginx:1.9.1" {
+		t.Fatalf("unexpected images %v, %v", images, err)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package imagepulljob
 
 import (
+	"context"
 	"reflect"
 	"strings"
 
@@ -107,7 +108,7 @@ func (e *nodeImageEventHandler) handleUpdate(nodeImage, oldNodeImage *appsv1alph
 	diffSet := diffJobs(newJobs, oldJobs)
 	for _, j := range newJobs {
 		for _, cImage := range changedImages.List() {
-			if j.Spec.Image == cImage || strings.HasPrefix(j.Spec.Image, cImage+":") {
+			if jobContainsImage(j, cImage) {
 				diffSet[types.NamespacedName{Namespace: j.Namespace, Name: j.Name}] = struct{}{}
 				break
 			}
@@ -118,6 +119,21 @@ func (e *nodeImageEventHandler) handleUpdate(nodeImage, oldNodeImage *appsv1alph
 	}
 }
 
+// jobContainsImage returns whether the image name may be pulled by the job.
+func jobContainsImage(job *appsv1alpha1.ImagePullJob, imageName string) bool {
+	// images of the workload are unknown here, so any changed image should be considered
+	if job.Spec.ImagesFromWorkload != nil {
+		return true
+	}
+	images := append([]string{job.Spec.Image}, job.Spec.Images...)
+	for _, image := range images {
+		if image == imageName || strings.HasPrefix(image, imageName+":") {
+			return true
+		}
+	}
+	return false
+}
+
 type podEventHandler struct {
 	client.Reader
 }
@@ -209,3 +225,60 @@ func diffJobs(newJobs, oldJobs []*appsv1alpha1.ImagePullJob) set {
 }
 
 type set map[types.NamespacedName]struct{}
+
+// workloadEventHandler enqueues the unfinished jobs which pull the images of the workload,
+// when the workload is created, deleted, or its images are changed.
+type workloadEventHandler struct {
+	client.Reader
+	kind string
+}
+
+var _ handler.EventHandler = &workloadEventHandler{}
+
+func (e *workloadEventHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.handle(evt.Object, q)
+}
+
+func (e *workloadEventHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldPodSpec := podSpecOfWorkload(evt.ObjectOld)
+	newPodSpec := podSpecOfWorkload(evt.ObjectNew)
+	if oldPodSpec == nil || newPodSpec == nil {
+		return
+	}
+	if !reflect.DeepEqual(getPodSpecImages(oldPodSpec), getPodSpecImages(newPodSpec)) {
+		e.handle(evt.ObjectNew, q)
+	}
+}
+
+func (e *workloadEventHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.handle(evt.Object, q)
+}
+
+func (e *workloadEventHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *workloadEventHandler) handle(obj client.Object, q workqueue.RateLimitingInterface) {
+	jobList := &appsv1alpha1.ImagePullJobList{}
+	if err := e.List(context.TODO(), jobList, client.InNamespace(obj.GetNamespace())); err != nil {
+		klog.Errorf("Failed to get jobs for %s %s/%s: %v", e.kind, obj.GetNamespace(), obj.GetName(), err)
+		return
+	}
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if job.DeletionTimestamp != nil || job.Status.CompletionTime != nil || !isJobForWorkload(job, e.kind, obj.GetName()) {
+			continue
+		}
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: job.Namespace, Name: job.Name}})
+	}
+}
+
+func getPodSpecImages(podSpec *v1.PodSpec) []string {
+	var images []string
+	for _, c := range podSpec.InitContainers {
+		images = append(images, c.Image)
+	}
+	for _, c := range podSpec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
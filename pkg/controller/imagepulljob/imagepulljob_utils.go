@@ -17,11 +17,18 @@ limitations under the License.
 package imagepulljob
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -30,6 +37,133 @@ const (
 	defaultActiveDeadlineSecondsForNever = int64(1800)
 )
 
+type pullState int
+
+// The pull states are ordered by priority, the state of a node is the highest one of all images on it.
+const (
+	pullStateSucceeded pullState = iota
+	pullStateFailed
+	pullStatePulling
+	pullStateNotSynced
+)
+
+// jobImage is an image to be pulled by the job, with its normalized name and tag.
+type jobImage struct {
+	image string
+	name  string
+	tag   string
+}
+
+// getImagesForJob returns all images that should be pulled by the job, deduplicated and in order.
+func getImagesForJob(reader client.Reader, job *appsv1alpha1.ImagePullJob) ([]jobImage, error) {
+	var images []string
+	images = append(images, job.Spec.Image)
+	images = append(images, job.Spec.Images...)
+	if job.Spec.ImagesFromWorkload != nil {
+		podSpec, err := getWorkloadPodSpec(reader, job.Namespace, job.Spec.ImagesFromWorkload)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, getPodSpecImages(podSpec)...)
+	}
+
+	var ret []jobImage
+	existing := sets.NewString()
+	for _, image := range images {
+		if image == "" || existing.Has(image) {
+			continue
+		}
+		existing.Insert(image)
+		imageName, imageTag, err := daemonutil.NormalizeImageRefToNameTag(image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image %s: %v", image, err)
+		}
+		ret = append(ret, jobImage{image: image, name: imageName, tag: imageTag})
+	}
+	return ret, nil
+}
+
+func getWorkloadPodSpec(reader client.Reader, namespace string, ref *appsv1alpha1.ImagePullJobWorkloadReference) (*v1.PodSpec, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion %s of workload: %v", ref.APIVersion, err)
+	}
+	var obj client.Object
+	switch {
+	case gv.Group == appsv1alpha1.GroupVersion.Group && ref.Kind == "CloneSet":
+		obj = &appsv1alpha1.CloneSet{}
+	case gv == appsv1beta1.GroupVersion && ref.Kind == "StatefulSet":
+		obj = &appsv1beta1.StatefulSet{}
+	case gv.Group == appsv1alpha1.GroupVersion.Group && ref.Kind == "StatefulSet":
+		obj = &appsv1alpha1.StatefulSet{}
+	default:
+		return nil, fmt.Errorf("unsupported workload %s %s", ref.APIVersion, ref.Kind)
+	}
+	if err := reader.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, obj); err != nil {
+		return nil, err
+	}
+	return podSpecOfWorkload(obj), nil
+}
+
+// podSpecOfWorkload returns the pod spec in template of the workload, or nil if the workload is not supported.
+func podSpecOfWorkload(obj client.Object) *v1.PodSpec {
+	switch workload := obj.(type) {
+	case *appsv1alpha1.CloneSet:
+		return &workload.Spec.Template.Spec
+	case *appsv1beta1.StatefulSet:
+		return &workload.Spec.Template.Spec
+	case *appsv1alpha1.StatefulSet:
+		return &workload.Spec.Template.Spec
+	}
+	return nil
+}
+
+// isJobForWorkload returns whether the images of job come from the workload of the kind and name.
+func isJobForWorkload(job *appsv1alpha1.ImagePullJob, kind, name string) bool {
+	ref := job.Spec.ImagesFromWorkload
+	if ref == nil || ref.Kind != kind || ref.Name != name {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	return err == nil && gv.Group == appsv1alpha1.GroupVersion.Group
+}
+
+// getPullState returns the pulling state of the image tag owned by the job on the NodeImage.
+func getPullState(nodeImage *appsv1alpha1.NodeImage, image jobImage, jobUID types.UID) pullState {
+	var tagVersion int64 = -1
+	if imageSpec, ok := nodeImage.Spec.Images[image.name]; ok {
+		for _, tagSpec := range imageSpec.Tags {
+			if tagSpec.Tag != image.tag {
+				continue
+			}
+			if containsObjectRef(tagSpec.OwnerReferences, v1.ObjectReference{UID: jobUID}) {
+				tagVersion = tagSpec.Version
+			}
+			break
+		}
+	}
+	if tagVersion < 0 {
+		return pullStateNotSynced
+	}
+
+	for _, tagStatus := range nodeImage.Status.ImageStatuses[image.name].Tags {
+		if tagStatus.Tag != image.tag {
+			continue
+		}
+		if tagStatus.Version != tagVersion {
+			return pullStatePulling
+		}
+		switch tagStatus.Phase {
+		case appsv1alpha1.ImagePhaseSucceeded:
+			return pullStateSucceeded
+		case appsv1alpha1.ImagePhaseFailed:
+			return pullStateFailed
+		}
+		break
+	}
+	return pullStatePulling
+}
+
 func getTTLSecondsForAlways(job *appsv1alpha1.ImagePullJob) *int32 {
 	var ret int32
 	if job.Spec.CompletionPolicy.TTLSecondsAfterFinished != nil {
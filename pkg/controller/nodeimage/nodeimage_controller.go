@@ -459,7 +459,7 @@ func (r *ReconcileNodeImage) updateNodeImageStatus(nodeImage *appsv1alpha1.NodeI
 			switch tagStatus.Phase {
 			case appsv1alpha1.ImagePhaseSucceeded:
 				succeeded++
-			case appsv1alpha1.ImagePhasePulling:
+			case appsv1alpha1.ImagePhasePulling, appsv1alpha1.ImagePhaseWaiting:
 				pulling++
 			case appsv1alpha1.ImagePhaseFailed:
 				failed++
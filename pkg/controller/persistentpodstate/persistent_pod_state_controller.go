@@ -0,0 +1,300 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentpodstate
+
+import (
+	"context"
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
+	utilapi "github.com/openkruise/kruise/pkg/util/api"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	ppsutil "github.com/openkruise/kruise/pkg/util/persistentpodstate"
+	"github.com/openkruise/kruise/pkg/util/ratelimiter"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "persistentpodstate-workers", concurrentReconciles, "Max concurrent workers for PersistentPodState controller.")
+}
+
+var (
+	concurrentReconciles = 3
+	controllerKind       = appsv1alpha1.SchemeGroupVersion.WithKind("PersistentPodState")
+)
+
+const (
+	controllerName = "persistentpodstate-controller"
+)
+
+// Add creates a new PersistentPodState Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if !utildiscovery.DiscoverGVK(controllerKind) || !utildiscovery.DiscoverGVK(ppsutil.KruiseKindSts) {
+		return nil
+	}
+	if !utilfeature.DefaultFeatureGate.Enabled(features.PersistentPodState) {
+		return nil
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	cli := util.NewClientFromManager(mgr, controllerName)
+	return &ReconcilePersistentPodState{
+		Client:           cli,
+		scheme:           mgr.GetScheme(),
+		controllerFinder: controllerfinder.NewControllerFinder(cli),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{
+		Reconciler: r, MaxConcurrentReconciles: concurrentReconciles,
+		RateLimiter: ratelimiter.DefaultControllerRateLimiter()})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to PersistentPodState
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.PersistentPodState{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Pods of Advanced StatefulSet
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &enqueueRequestForPod{reader: mgr.GetClient()})
+	if err != nil {
+		return err
+	}
+
+	// Watch for replica changes to Advanced StatefulSet
+	err = c.Watch(&source.Kind{Type: &appsv1beta1.StatefulSet{}}, &enqueueRequestForStatefulSet{reader: mgr.GetClient()})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcilePersistentPodState{}
+
+// ReconcilePersistentPodState reconciles a PersistentPodState object
+type ReconcilePersistentPodState struct {
+	client.Client
+	scheme           *runtime.Scheme
+	controllerFinder *controllerfinder.ControllerFinder
+}
+
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=persistentpodstates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=persistentpodstates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+func (r *ReconcilePersistentPodState) Reconcile(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pps := &appsv1alpha1.PersistentPodState{}
+	err := r.Get(context.TODO(), req.NamespacedName, pps)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	if !pps.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	startTime := time.Now()
+	klog.V(3).Infof("Begin to process PersistentPodState (%s/%s)", pps.Namespace, pps.Name)
+	err = r.syncPersistentPodState(pps)
+	klog.V(3).Infof("Finished syncing PersistentPodState (%s/%s), cost: %v", pps.Namespace, pps.Name, time.Since(startTime))
+	return reconcile.Result{}, err
+}
+
+func (r *ReconcilePersistentPodState) syncPersistentPodState(pps *appsv1alpha1.PersistentPodState) error {
+	newStatus := pps.Status.DeepCopy()
+	newStatus.ObservedGeneration = pps.Generation
+
+	sts, err := r.getTargetStatefulSet(pps)
+	if err != nil {
+		return err
+	}
+	if sts == nil || !sts.DeletionTimestamp.IsZero() {
+		// all the states will be removed when the workload is deleted
+		newStatus.PodStates = nil
+	} else {
+		podStates, err := r.calculatePodStates(pps, sts)
+		if err != nil {
+			return err
+		}
+		newStatus.PodStates = podStates
+	}
+
+	if apiequality.Semantic.DeepEqual(pps.Status, *newStatus) {
+		return nil
+	}
+	ppsClone := pps.DeepCopy()
+	ppsClone.Status = *newStatus
+	if err = r.Status().Update(context.TODO(), ppsClone); err != nil {
+		klog.Errorf("Failed to update PersistentPodState (%s/%s) status: %s", pps.Namespace, pps.Name, err.Error())
+		return err
+	}
+	klog.V(3).Infof("Updated PersistentPodState (%s/%s) status to %s", pps.Namespace, pps.Name, util.DumpJSON(newStatus))
+	return nil
+}
+
+// getTargetStatefulSet returns the Advanced StatefulSet of targetRef, it returns nil if not found.
+func (r *ReconcilePersistentPodState) getTargetStatefulSet(pps *appsv1alpha1.PersistentPodState) (*appsv1beta1.StatefulSet, error) {
+	ref := pps.Spec.TargetReference
+	if !ppsutil.IsKruiseStatefulSet(ref.APIVersion, ref.Kind) {
+		klog.Warningf("PersistentPodState (%s/%s) targetRef %s %s is not supported", pps.Namespace, pps.Name, ref.APIVersion, ref.Kind)
+		return nil, nil
+	}
+	sts := &appsv1beta1.StatefulSet{}
+	err := r.Get(context.TODO(), client.ObjectKey{Namespace: pps.Namespace, Name: ref.Name}, sts)
+	if err != nil {
+		// when error is NotFound, it is ok here.
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return sts, nil
+}
+
+func (r *ReconcilePersistentPodState) calculatePodStates(pps *appsv1alpha1.PersistentPodState, sts *appsv1beta1.StatefulSet) (map[string]appsv1alpha1.PodState, error) {
+	pods, _, err := r.controllerFinder.GetPodsForRef(ppsutil.KruiseKindSts.GroupVersion().String(), ppsutil.KruiseKindSts.Kind, sts.Name, sts.Namespace, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// the states of Pods that are not ready currently, e.g. being recreated, should be kept,
+	// except the hostname of the deleted nodes which the Pods can never be scheduled to
+	podStates := make(map[string]appsv1alpha1.PodState, len(pps.Status.PodStates))
+	for name, state := range pps.Status.PodStates {
+		if _, ok := state.NodeTopologyLabels[corev1.LabelHostname]; ok && state.NodeName != "" {
+			exists, err := r.isNodeExisting(state.NodeName)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				ppsutil.RelaxPodStateOfDeletedNode(&state)
+			}
+		}
+		podStates[name] = state
+	}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || !pod.DeletionTimestamp.IsZero() || !podutil.IsPodReady(pod) {
+			continue
+		}
+		state, err := r.getPodState(pps, pod)
+		if err != nil {
+			return nil, err
+		}
+		podStates[pod.Name] = state
+	}
+
+	if pps.Spec.PersistentPodStateRetentionPolicy != appsv1alpha1.PersistentPodStateRetentionPolicyWhenDeleted {
+		validOrdinals := utilapi.GetValidOrdinals(sts)
+		for name := range podStates {
+			if ordinal, ok := getPodOrdinal(sts.Name, name); !ok || !validOrdinals.Has(ordinal) {
+				delete(podStates, name)
+			}
+		}
+	}
+
+	if len(podStates) == 0 {
+		return nil, nil
+	}
+	return podStates, nil
+}
+
+func (r *ReconcilePersistentPodState) getPodState(pps *appsv1alpha1.PersistentPodState, pod *corev1.Pod) (appsv1alpha1.PodState, error) {
+	state := appsv1alpha1.PodState{NodeName: pod.Spec.NodeName}
+
+	if keys := ppsutil.GetTopologyKeys(pps); len(keys) > 0 {
+		node := &corev1.Node{}
+		err := r.Get(context.TODO(), client.ObjectKey{Name: pod.Spec.NodeName}, node)
+		if err != nil && !errors.IsNotFound(err) {
+			return state, err
+		}
+		for _, key := range keys {
+			if value, ok := node.Labels[key]; ok {
+				if state.NodeTopologyLabels == nil {
+					state.NodeTopologyLabels = map[string]string{}
+				}
+				state.NodeTopologyLabels[key] = value
+			}
+		}
+	}
+
+	for _, annotation := range pps.Spec.PersistentPodAnnotations {
+		if value, ok := pod.Annotations[annotation.Key]; ok {
+			if state.Annotations == nil {
+				state.Annotations = map[string]string{}
+			}
+			state.Annotations[annotation.Key] = value
+		}
+	}
+	return state, nil
+}
+
+func (r *ReconcilePersistentPodState) isNodeExisting(nodeName string) (bool, error) {
+	err := r.Get(context.TODO(), client.ObjectKey{Name: nodeName}, &corev1.Node{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// getPodOrdinal returns the ordinal of Pod name that belongs to the Advanced StatefulSet.
+func getPodOrdinal(stsName, podName string) (int, bool) {
+	prefix := stsName + "-"
+	if !strings.HasPrefix(podName, prefix) {
+		return -1, false
+	}
+	ordinal, err := strconv.Atoi(strings.TrimPrefix(podName, prefix))
+	if err != nil || ordinal < 0 {
+		return -1, false
+	}
+	return ordinal, true
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentpodstate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+)
+
+var (
+	scheme *runtime.Scheme
+
+	stsDemo = &appsv1beta1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps.kruise.io/v1beta1",
+			Kind:       "StatefulSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sts-demo",
+			Namespace: "default",
+			UID:       types.UID("sts-uid"),
+		},
+		Spec: appsv1beta1.StatefulSetSpec{
+			Replicas: utilpointer.Int32Ptr(2),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+		},
+	}
+
+	ppsDemo = &appsv1alpha1.PersistentPodState{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "pps-demo",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: appsv1alpha1.PersistentPodStateSpec{
+			TargetReference: appsv1alpha1.TargetReference{
+				APIVersion: "apps.kruise.io/v1beta1",
+				Kind:       "StatefulSet",
+				Name:       "sts-demo",
+			},
+			PersistentPodAnnotations:   []appsv1alpha1.PersistentPodAnnotation{{Key: "ip"}},
+			RequiredPersistentTopology: &appsv1alpha1.NodeTopologyTerm{NodeTopologyKeys: []string{"kubernetes.io/hostname"}},
+			PreferredPersistentTopology: []appsv1alpha1.PreferredTopologyTerm{{
+				Weight:     100,
+				Preference: appsv1alpha1.NodeTopologyTerm{NodeTopologyKeys: []string{"topology.kubernetes.io/zone"}},
+			}},
+		},
+	}
+)
+
+func init() {
+	scheme = runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = appsv1beta1.AddToScheme(scheme)
+}
+
+func newNode(name, zone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"kubernetes.io/hostname": name, "topology.kubernetes.io/zone": zone},
+		},
+	}
+}
+
+func newPod(ordinal int, nodeName string, ready bool) *corev1.Pod {
+	isController := true
+	condition := corev1.ConditionFalse
+	if ready {
+		condition = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("sts-demo-%d", ordinal),
+			Namespace:   "default",
+			Labels:      map[string]string{"app": "demo"},
+			Annotations: map[string]string{"ip": fmt.Sprintf("10.0.0.%d", ordinal), "other": "foo"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps.kruise.io/v1beta1",
+				Kind:       "StatefulSet",
+				Name:       "sts-demo",
+				UID:        types.UID("sts-uid"),
+				Controller: &isController,
+			}},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: condition}},
+		},
+	}
+}
+
+func TestReconcilePersistentPodState(t *testing.T) {
+	cases := []struct {
+		name           string
+		getSts         func() *appsv1beta1.StatefulSet
+		getPps         func() *appsv1alpha1.PersistentPodState
+		getPods        func() []*corev1.Pod
+		expectedStates map[string]appsv1alpha1.PodState
+	}{
+		{
+			name:   "record states of ready pods",
+			getSts: func() *appsv1beta1.StatefulSet { return stsDemo.DeepCopy() },
+			getPps: func() *appsv1alpha1.PersistentPodState { return ppsDemo.DeepCopy() },
+			getPods: func() []*corev1.Pod {
+				return []*corev1.Pod{newPod(0, "node-a", true), newPod(1, "node-b", false)}
+			},
+			expectedStates: map[string]appsv1alpha1.PodState{
+				"sts-demo-0": {
+					NodeName:           "node-a",
+					NodeTopologyLabels: map[string]string{"kubernetes.io/hostname": "node-a", "topology.kubernetes.io/zone": "zone-a"},
+					Annotations:        map[string]string{"ip": "10.0.0.0"},
+				},
+			},
+		},
+		{
+			name:   "keep states of recreating pods and remove scaled pods",
+			getSts: func() *appsv1beta1.StatefulSet { return stsDemo.DeepCopy() },
+			getPps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Status.PodStates = map[string]appsv1alpha1.PodState{
+					"sts-demo-1": {NodeName: "node-b"},
+					"sts-demo-2": {NodeName: "node-a"},
+				}
+				return pps
+			},
+			getPods: func() []*corev1.Pod {
+				return []*corev1.Pod{newPod(1, "", false)}
+			},
+			expectedStates: map[string]appsv1alpha1.PodState{
+				"sts-demo-1": {NodeName: "node-b"},
+			},
+		},
+		{
+			name:   "relax hostname of deleted node in states of recreating pods",
+			getSts: func() *appsv1beta1.StatefulSet { return stsDemo.DeepCopy() },
+			getPps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Status.PodStates = map[string]appsv1alpha1.PodState{
+					"sts-demo-0": {
+						NodeName:           "node-c",
+						NodeTopologyLabels: map[string]string{"kubernetes.io/hostname": "node-c", "topology.kubernetes.io/zone": "zone-c"},
+						Annotations:        map[string]string{"ip": "10.0.0.0"},
+					},
+					"sts-demo-1": {
+						NodeName:           "node-b",
+						NodeTopologyLabels: map[string]string{"kubernetes.io/hostname": "node-b"},
+					},
+				}
+				return pps
+			},
+			getPods: func() []*corev1.Pod { return nil },
+			expectedStates: map[string]appsv1alpha1.PodState{
+				"sts-demo-0": {
+					NodeName:           "node-c",
+					NodeTopologyLabels: map[string]string{"topology.kubernetes.io/zone": "zone-c"},
+					Annotations:        map[string]string{"ip": "10.0.0.0"},
+				},
+				"sts-demo-1": {
+					NodeName:           "node-b",
+					NodeTopologyLabels: map[string]string{"kubernetes.io/hostname": "node-b"},
+				},
+			},
+		},
+		{
+			name: "remove states of reserved ordinals",
+			getSts: func() *appsv1beta1.StatefulSet {
+				sts := stsDemo.DeepCopy()
+				sts.Spec.ReserveOrdinals = []intstr.IntOrString{intstr.FromInt(0)}
+				return sts
+			},
+			getPps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Status.PodStates = map[string]appsv1alpha1.PodState{
+					"sts-demo-0": {NodeName: "node-a"},
+					"sts-demo-2": {NodeName: "node-b"},
+				}
+				return pps
+			},
+			getPods: func() []*corev1.Pod { return nil },
+			expectedStates: map[string]appsv1alpha1.PodState{
+				"sts-demo-2": {NodeName: "node-b"},
+			},
+		},
+		{
+			name: "keep states of scaled pods when retention policy is WhenDeleted",
+			getSts: func() *appsv1beta1.StatefulSet {
+				sts := stsDemo.DeepCopy()
+				sts.Spec.Replicas = utilpointer.Int32Ptr(0)
+				return sts
+			},
+			getPps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.PersistentPodStateRetentionPolicy = appsv1alpha1.PersistentPodStateRetentionPolicyWhenDeleted
+				pps.Status.PodStates = map[string]appsv1alpha1.PodState{"sts-demo-0": {NodeName: "node-a"}}
+				return pps
+			},
+			getPods: func() []*corev1.Pod { return nil },
+			expectedStates: map[string]appsv1alpha1.PodState{
+				"sts-demo-0": {NodeName: "node-a"},
+			},
+		},
+		{
+			name:   "remove all states when workload is deleted",
+			getSts: func() *appsv1beta1.StatefulSet { return nil },
+			getPps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.PersistentPodStateRetentionPolicy = appsv1alpha1.PersistentPodStateRetentionPolicyWhenDeleted
+				pps.Status.PodStates = map[string]appsv1alpha1.PodState{"sts-demo-0": {NodeName: "node-a"}}
+				return pps
+			},
+			getPods:        func() []*corev1.Pod { return nil },
+			expectedStates: nil,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			objs := []client.Object{cs.getPps(), newNode("node-a", "zone-a"), newNode("node-b", "zone-b")}
+			if sts := cs.getSts(); sts != nil {
+				objs = append(objs, sts)
+			}
+			for _, pod := range cs.getPods() {
+				objs = append(objs, pod)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			reconciler := ReconcilePersistentPodState{
+				Client:           fakeClient,
+				scheme:           scheme,
+				controllerFinder: &controllerfinder.ControllerFinder{Client: fakeClient},
+			}
+
+			nsn := types.NamespacedName{Namespace: ppsDemo.Namespace, Name: ppsDemo.Name}
+			if _, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsn}); err != nil {
+				t.Fatalf("Reconcile failed: %s", err.Error())
+			}
+
+			pps := &appsv1alpha1.PersistentPodState{}
+			if err := fakeClient.Get(context.TODO(), nsn, pps); err != nil {
+				t.Fatalf("Get PersistentPodState failed: %s", err.Error())
+			}
+			if pps.Status.ObservedGeneration != ppsDemo.Generation {
+				t.Fatalf("expected observedGeneration %d, got %d", ppsDemo.Generation, pps.Status.ObservedGeneration)
+			}
+			if !reflect.DeepEqual(pps.Status.PodStates, cs.expectedStates) {
+				t.Fatalf("expected podStates %s, got %s", util.DumpJSON(cs.expectedStates), util.DumpJSON(pps.Status.PodStates))
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentpodstate
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	ppsutil "github.com/openkruise/kruise/pkg/util/persistentpodstate"
+)
+
+var _ handler.EventHandler = &enqueueRequestForPod{}
+
+type enqueueRequestForPod struct {
+	reader client.Client
+}
+
+func (p *enqueueRequestForPod) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	pod := evt.Object.(*corev1.Pod)
+	if podutil.IsPodReady(pod) {
+		p.enqueue(q, pod)
+	}
+}
+
+func (p *enqueueRequestForPod) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldPod := evt.ObjectOld.(*corev1.Pod)
+	newPod := evt.ObjectNew.(*corev1.Pod)
+	// the recorded states may be changed when Pod becomes ready, or the annotations of ready Pod changed
+	if podutil.IsPodReady(oldPod) != podutil.IsPodReady(newPod) ||
+		(podutil.IsPodReady(newPod) && !reflect.DeepEqual(oldPod.Annotations, newPod.Annotations)) {
+		p.enqueue(q, newPod)
+	}
+}
+
+func (p *enqueueRequestForPod) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {}
+
+func (p *enqueueRequestForPod) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {}
+
+func (p *enqueueRequestForPod) enqueue(q workqueue.RateLimitingInterface, pod *corev1.Pod) {
+	ref := ppsutil.GetPodOwnerStatefulSet(pod)
+	if ref == nil {
+		return
+	}
+	enqueuePersistentPodState(p.reader, q, pod.Namespace, ref.Name)
+}
+
+var _ handler.EventHandler = &enqueueRequestForStatefulSet{}
+
+type enqueueRequestForStatefulSet struct {
+	reader client.Client
+}
+
+func (p *enqueueRequestForStatefulSet) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	enqueuePersistentPodState(p.reader, q, evt.Object.GetNamespace(), evt.Object.GetName())
+}
+
+func (p *enqueueRequestForStatefulSet) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldSts := evt.ObjectOld.(*appsv1beta1.StatefulSet)
+	newSts := evt.ObjectNew.(*appsv1beta1.StatefulSet)
+	// the recorded states of scaled down Pods should be removed
+	if !reflect.DeepEqual(oldSts.Spec.Replicas, newSts.Spec.Replicas) ||
+		!reflect.DeepEqual(oldSts.Spec.ReserveOrdinals, newSts.Spec.ReserveOrdinals) ||
+		!reflect.DeepEqual(oldSts.Spec.Ordinals, newSts.Spec.Ordinals) ||
+		!oldSts.DeletionTimestamp.Equal(newSts.DeletionTimestamp) {
+		enqueuePersistentPodState(p.reader, q, newSts.Namespace, newSts.Name)
+	}
+}
+
+func (p *enqueueRequestForStatefulSet) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	enqueuePersistentPodState(p.reader, q, evt.Object.GetNamespace(), evt.Object.GetName())
+}
+
+func (p *enqueueRequestForStatefulSet) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func enqueuePersistentPodState(reader client.Client, q workqueue.RateLimitingInterface, namespace, stsName string) {
+	pps, err := ppsutil.FetchPersistentPodStateByWorkload(reader, namespace, stsName)
+	if err != nil {
+		klog.Errorf("Failed to fetch PersistentPodState for StatefulSet (%s/%s): %s", namespace, stsName, err.Error())
+		return
+	}
+	if pps != nil {
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pps.Namespace, Name: pps.Name}})
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarbarrier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var (
+	concurrentReconciles = 3
+
+	podKind = v1.SchemeGroupVersion.WithKind("Pod")
+)
+
+func Add(mgr manager.Manager) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.SidecarSetLifecycle) {
+		return nil
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) *ReconcileSidecarBarrier {
+	return &ReconcileSidecarBarrier{
+		Client:    util.NewClientFromManager(mgr, "sidecar-barrier-controller"),
+		apiReader: mgr.GetAPIReader(),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileSidecarBarrier) error {
+	// Create a new controller
+	c, err := controller.New("sidecar-barrier-controller", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: concurrentReconciles})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &v1.Pod{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return shouldReleaseBarrier(e.Object.(*v1.Pod))
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return shouldReleaseBarrier(e.ObjectNew.(*v1.Pod))
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileSidecarBarrier{}
+
+// ReconcileSidecarBarrier releases the app containers of Pod once the sidecar containers
+// which should start before them are ready, by creating the barrier ConfigMap they refer to.
+type ReconcileSidecarBarrier struct {
+	client.Client
+	// apiReader reads the barrier ConfigMap from apiserver, so that all ConfigMaps will not be cached
+	apiReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;delete
+
+func (r *ReconcileSidecarBarrier) Reconcile(_ context.Context, request reconcile.Request) (res reconcile.Result, err error) {
+	start := time.Now()
+	klog.V(3).Infof("Starting to process Pod %v", request.NamespacedName)
+	defer func() {
+		if err != nil {
+			klog.Warningf("Failed to process Pod %v, elapsedTime %v, error: %v", request.NamespacedName, time.Since(start), err)
+		} else {
+			klog.V(3).Infof("Finish to process Pod %v, elapsedTime %v", request.NamespacedName, time.Since(start))
+		}
+	}()
+
+	pod := &v1.Pod{}
+	err = r.Get(context.TODO(), request.NamespacedName, pod)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if !shouldReleaseBarrier(pod) {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{}, r.releaseBarrier(pod)
+}
+
+// releaseBarrier creates the barrier ConfigMap, then kubelet can start the app containers which refer to it.
+// The ConfigMap is owned by the pod, so it will be deleted with the pod. If there is a stale one owned by
+// another pod, it will be recreated.
+func (r *ReconcileSidecarBarrier) releaseBarrier(pod *v1.Pod) error {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       pod.Namespace,
+			Name:            sidecarcontrol.GetSidecarBarrierConfigMapName(pod),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(pod, podKind)},
+		},
+		Data: map[string]string{sidecarcontrol.SidecarBarrierConfigMapKey: "true"},
+	}
+	err := r.Create(context.TODO(), cm)
+	if err == nil {
+		klog.Infof("Released barrier for app containers in Pod %s/%s, sidecar containers are ready", pod.Namespace, pod.Name)
+		return nil
+	} else if !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create barrier ConfigMap %s: %v", cm.Name, err)
+	}
+
+	existing := &v1.ConfigMap{}
+	if err := r.apiReader.Get(context.TODO(), client.ObjectKeyFromObject(cm), existing); err != nil {
+		return fmt.Errorf("failed to get barrier ConfigMap %s: %v", cm.Name, err)
+	}
+	if owner := metav1.GetControllerOf(existing); owner != nil && owner.UID == pod.UID {
+		return nil
+	}
+
+	klog.Warningf("Found stale barrier ConfigMap %s not owned by Pod %s/%s, recreate it", cm.Name, pod.Namespace, pod.Name)
+	if err := r.Delete(context.TODO(), existing, client.Preconditions{UID: &existing.UID}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete stale barrier ConfigMap %s: %v", cm.Name, err)
+	}
+	if err := r.Create(context.TODO(), cm); err != nil {
+		return fmt.Errorf("failed to recreate barrier ConfigMap %s: %v", cm.Name, err)
+	}
+	klog.Infof("Released barrier for app containers in Pod %s/%s, sidecar containers are ready", pod.Namespace, pod.Name)
+	return nil
+}
+
+// shouldReleaseBarrier returns true if there are app containers waiting for the barrier ConfigMap,
+// and all the sidecar containers which should start before them are ready.
+func shouldReleaseBarrier(pod *v1.Pod) bool {
+	if pod.DeletionTimestamp != nil || !sidecarcontrol.IsSidecarBarrierInjected(pod) {
+		return false
+	}
+	var appWaiting bool
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		status := getContainerStatus(pod, container.Name)
+		if status == nil {
+			return false
+		}
+		if sidecarcontrol.IsSidecarStartBeforeApp(container) {
+			if !status.Ready {
+				return false
+			}
+		} else if !sidecarcontrol.IsInjectedSidecarContainerInPod(container) {
+			// the app container has never been created
+			if status.State.Waiting != nil && status.LastTerminationState.Terminated == nil && status.ContainerID == "" {
+				appWaiting = true
+			}
+		}
+	}
+	return appWaiting
+}
+
+func getContainerStatus(pod *v1.Pod, name string) *v1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == name {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
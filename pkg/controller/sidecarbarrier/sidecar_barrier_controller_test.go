@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarbarrier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestPod(sidecarStatus, appStatus v1.ContainerStatus) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job-pod", UID: "pod-uid"},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Containers: []v1.Container{
+				{
+					Name: "sidecar",
+					Env: []v1.EnvVar{
+						{Name: sidecarcontrol.SidecarEnvKey, Value: "true"},
+						{Name: sidecarcontrol.SidecarStartBeforeAppEnvKey, Value: "true"},
+					},
+				},
+				{Name: "main"},
+			},
+		},
+	}
+	sidecarcontrol.InjectSidecarBarrier(pod, "")
+	sidecarStatus.Name = "sidecar"
+	appStatus.Name = "main"
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{sidecarStatus, appStatus}
+	return pod
+}
+
+func TestShouldReleaseBarrier(t *testing.T) {
+	running := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	waiting := v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CreateContainerConfigError"}}
+	cases := []struct {
+		name          string
+		sidecarStatus v1.ContainerStatus
+		appStatus     v1.ContainerStatus
+		expected      bool
+	}{
+		{
+			name:          "sidecar not ready",
+			sidecarStatus: v1.ContainerStatus{State: running, ContainerID: "containerd://sidecar"},
+			appStatus:     v1.ContainerStatus{State: waiting},
+			expected:      false,
+		},
+		{
+			name:          "sidecar ready and app waiting",
+			sidecarStatus: v1.ContainerStatus{State: running, Ready: true, ContainerID: "containerd://sidecar"},
+			appStatus:     v1.ContainerStatus{State: waiting},
+			expected:      true,
+		},
+		{
+			name:          "app has started",
+			sidecarStatus: v1.ContainerStatus{State: running, Ready: true, ContainerID: "containerd://sidecar"},
+			appStatus:     v1.ContainerStatus{State: running, ContainerID: "containerd://main"},
+			expected:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		pod := newTestPod(tc.sidecarStatus, tc.appStatus)
+		if got := shouldReleaseBarrier(pod); got != tc.expected {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	pod := newTestPod(
+		v1.ContainerStatus{State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Ready: true, ContainerID: "containerd://sidecar"},
+		v1.ContainerStatus{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CreateContainerConfigError"}}},
+	)
+	cmName := sidecarcontrol.GetSidecarBarrierConfigMapName(pod)
+
+	cases := []struct {
+		name     string
+		existing *v1.ConfigMap
+	}{
+		{
+			name: "create barrier",
+		},
+		{
+			name: "recreate stale barrier",
+			existing: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       pod.Namespace,
+					Name:            cmName,
+					OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, UID: "stale-uid"}}, podKind)},
+				},
+				Data: map[string]string{sidecarcontrol.SidecarBarrierConfigMapKey: "true"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod.DeepCopy())
+			if tc.existing != nil {
+				builder = builder.WithObjects(tc.existing)
+			}
+			fakeClient := builder.Build()
+			reconciler := &ReconcileSidecarBarrier{Client: fakeClient, apiReader: fakeClient}
+
+			request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}}
+			if _, err := reconciler.Reconcile(context.TODO(), request); err != nil {
+				t.Fatal(err)
+			}
+
+			cm := &v1.ConfigMap{}
+			if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pod.Namespace, Name: cmName}, cm); err != nil {
+				t.Fatal(err)
+			}
+			if owner := metav1.GetControllerOf(cm); owner == nil || owner.UID != pod.UID {
+				t.Fatalf("expected barrier owned by pod %s, got %v", pod.UID, cm.OwnerReferences)
+			}
+		})
+	}
+}
@@ -20,12 +20,14 @@ import (
 	"context"
 	"flag"
 	"reflect"
+	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 
 	corev1 "k8s.io/api/core/v1"
@@ -108,6 +110,11 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to Namespace
+	if err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, &enqueueRequestForNamespace{reader: mgr.GetCache()}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -123,10 +130,17 @@ type ReconcileSidecarSet struct {
 
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=sidecarsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=sidecarsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads that state of the cluster for a SidecarSet object and makes changes based on the state read
 // and what is in the SidecarSet.Spec
-func (r *ReconcileSidecarSet) Reconcile(_ context.Context, request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileSidecarSet) Reconcile(_ context.Context, request reconcile.Request) (res reconcile.Result, retErr error) {
+	startTime := time.Now()
+	defer func() {
+		metrics.ObserveReconcile("sidecarset", startTime, retErr)
+	}()
+
 	// Fetch the SidecarSet instance
 	sidecarSet := &appsv1alpha1.SidecarSet{}
 	err := r.Get(context.TODO(), request.NamespacedName, sidecarSet)
@@ -2,12 +2,17 @@ package sidecarset
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 
+	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -129,6 +134,8 @@ func init() {
 	scheme = runtime.NewScheme()
 	_ = appsv1alpha1.AddToScheme(scheme)
 	_ = corev1.AddToScheme(scheme)
+	_ = apps.AddToScheme(scheme)
+	_ = policyv1alpha1.AddToScheme(scheme)
 }
 
 func getLatestPod(client client.Client, pod *corev1.Pod) (*corev1.Pod, error) {
@@ -264,6 +271,75 @@ func testUpdateWhenMaxUnavailableNotZero(t *testing.T, sidecarSetInput *appsv1al
 	}
 }
 
+func TestUpdateWhenPodUnavailableBudgetNotAllowed(t *testing.T) {
+	sidecarSetInput := sidecarSetDemo.DeepCopy()
+	testUpdateWhenPodUnavailableBudgetNotAllowed(t, sidecarSetInput)
+}
+
+func testUpdateWhenPodUnavailableBudgetNotAllowed(t *testing.T, sidecarSetInput *appsv1alpha1.SidecarSet) {
+	_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.PodUnavailableBudgetUpdateGate))
+	defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.PodUnavailableBudgetUpdateGate))
+
+	podInput := podDemo.DeepCopy()
+	pub := &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: podInput.Namespace, Name: "test-pub"},
+		Spec: policyv1alpha1.PodUnavailableBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+		},
+		Status: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 0},
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: sidecarSetInput.Namespace,
+			Name:      sidecarSetInput.Name,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSetInput, podInput, pub).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	reconciler := ReconcileSidecarSet{
+		Client:             fakeClient,
+		updateExpectations: exps,
+		processor:          NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10)),
+	}
+	result, err := reconciler.Reconcile(context.TODO(), request)
+	if err != nil {
+		t.Fatalf("reconcile failed, err: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Fatalf("expect requeue when pub does not allow to update pod")
+	}
+	podOutput, err := getLatestPod(fakeClient, podInput)
+	if err != nil {
+		t.Fatalf("get latest pod failed, err: %v", err)
+	}
+	if isSidecarImageUpdated(podOutput, "test-sidecar", "test-image:v2") {
+		t.Fatalf("expect sidecar not upgraded when pub does not allow")
+	}
+
+	// pub allows one pod to be unavailable
+	pub.Status.UnavailableAllowed = 1
+	if err = fakeClient.Status().Update(context.TODO(), pub); err != nil {
+		t.Fatalf("update pub failed, err: %v", err)
+	}
+	if _, err = reconciler.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("reconcile failed, err: %v", err)
+	}
+	if podOutput, err = getLatestPod(fakeClient, podInput); err != nil {
+		t.Fatalf("get latest pod failed, err: %v", err)
+	}
+	if !isSidecarImageUpdated(podOutput, "test-sidecar", "test-image:v2") {
+		t.Fatalf("sidecarset upgrade image failed")
+	}
+	newPub := &policyv1alpha1.PodUnavailableBudget{}
+	if err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}, newPub); err != nil {
+		t.Fatalf("get pub failed, err: %v", err)
+	}
+	if _, ok := newPub.Status.UnavailablePods[podInput.Name]; !ok || newPub.Status.UnavailableAllowed != 0 {
+		t.Fatalf("expect pod recorded in pub, but got %v", newPub.Status)
+	}
+}
+
 func TestUpdateWhenPartitionFinished(t *testing.T) {
 	sidecarSetInput := sidecarSetDemo.DeepCopy()
 	testUpdateWhenPartitionFinished(t, sidecarSetInput)
@@ -335,3 +411,84 @@ func testRemoveSidecarSet(t *testing.T, sidecarSetInput *appsv1alpha1.SidecarSet
 		t.Errorf("should remove sidecarset info")
 	}
 }
+
+func TestUpdateToHistoryRevision(t *testing.T) {
+	sidecarSetInput := sidecarSetDemo.DeepCopy()
+	sidecarSetInput.Annotations[sidecarcontrol.SidecarSetHashAnnotation] = "aaa"
+	sidecarSetInput.Spec.Containers[0].Image = "test-image:v1"
+	testUpdateToHistoryRevision(t, sidecarSetInput)
+}
+
+func testUpdateToHistoryRevision(t *testing.T, sidecarSetInput *appsv1alpha1.SidecarSet) {
+	podInput := podDemo.DeepCopy()
+	podInput.Status.Phase = corev1.PodPending
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name: sidecarSetInput.Name,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSetInput, podInput).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	reconciler := ReconcileSidecarSet{
+		Client:             fakeClient,
+		updateExpectations: exps,
+		processor:          NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10)),
+	}
+	if _, err := reconciler.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("reconcile failed, err: %v", err)
+	}
+	sidecarSetOutput, err := getLatestSidecarSet(fakeClient, sidecarSetInput)
+	if err != nil {
+		t.Fatalf("get latest sidecarSet failed, err: %v", err)
+	}
+	firstRevision := sidecarSetOutput.Status.LatestRevision
+	if firstRevision == "" {
+		t.Fatalf("expect the latest revision of sidecarSet is recorded in status")
+	}
+
+	// update sidecar image to v2
+	sidecarSetOutput.Annotations[sidecarcontrol.SidecarSetHashAnnotation] = "bbb"
+	sidecarSetOutput.Spec.Containers[0].Image = "test-image:v2"
+	if err = fakeClient.Update(context.TODO(), sidecarSetOutput); err != nil {
+		t.Fatalf("update sidecarSet failed, err: %v", err)
+	}
+	if _, err = reconciler.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("reconcile failed, err: %v", err)
+	}
+	podOutput, _ := getLatestPod(fakeClient, podInput)
+	if !isSidecarImageUpdated(podOutput, "test-sidecar", "test-image:v2") {
+		t.Fatalf("sidecarset upgrade image failed")
+	}
+	revisions := &apps.ControllerRevisionList{}
+	if err = fakeClient.List(context.TODO(), revisions); err != nil {
+		t.Fatalf("list revisions failed, err: %v", err)
+	}
+	if len(revisions.Items) != 2 {
+		t.Fatalf("expect 2 revisions, but got %d", len(revisions.Items))
+	}
+
+	// sidecar container has been upgraded by kubelet
+	podOutput.Status.ContainerStatuses[1].Image = "test-image:v2"
+	podOutput.Status.ContainerStatuses[1].ImageID = "docker-pullable://test-image@sha256:v2"
+	if err = fakeClient.Status().Update(context.TODO(), podOutput); err != nil {
+		t.Fatalf("update pod status failed, err: %v", err)
+	}
+
+	// roll back to the first revision
+	sidecarSetOutput, _ = getLatestSidecarSet(fakeClient, sidecarSetInput)
+	sidecarSetOutput.Spec.UpdateStrategy.RevisionName = &firstRevision
+	if err = fakeClient.Update(context.TODO(), sidecarSetOutput); err != nil {
+		t.Fatalf("update sidecarSet failed, err: %v", err)
+	}
+	if _, err = reconciler.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("reconcile failed, err: %v", err)
+	}
+	podOutput, _ = getLatestPod(fakeClient, podInput)
+	if !isSidecarImageUpdated(podOutput, "test-sidecar", "test-image:v1") {
+		t.Fatalf("sidecarset roll back image failed")
+	}
+	if revision := sidecarcontrol.GetPodSidecarSetRevision(sidecarSetInput.Name, podOutput); revision != "aaa" {
+		t.Fatalf("expect pod sidecarSet revision aaa, but got %s", revision)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"reflect"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ handler.EventHandler = &enqueueRequestForNamespace{}
+
+type enqueueRequestForNamespace struct {
+	reader client.Reader
+}
+
+func (p *enqueueRequestForNamespace) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (p *enqueueRequestForNamespace) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (p *enqueueRequestForNamespace) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (p *enqueueRequestForNamespace) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldNs, oldOK := evt.ObjectOld.(*corev1.Namespace)
+	newNs, newOK := evt.ObjectNew.(*corev1.Namespace)
+	if !oldOK || !newOK || reflect.DeepEqual(oldNs.Labels, newNs.Labels) {
+		return
+	}
+
+	sidecarSets, err := p.getNamespaceChangedSidecarSets(oldNs, newNs)
+	if err != nil {
+		klog.Errorf("unable to get sidecarSets related with namespace %s, err: %v", newNs.Name, err)
+		return
+	}
+	for _, sidecarSet := range sidecarSets {
+		klog.V(3).Infof("namespace(%s) labels changed, and reconcile sidecarSet(%s)", newNs.Name, sidecarSet.Name)
+		q.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: sidecarSet.Name,
+			},
+		})
+	}
+}
+
+// getNamespaceChangedSidecarSets returns the sidecarSets whose namespaceSelector
+// matching result is changed by the labels update of namespace.
+func (p *enqueueRequestForNamespace) getNamespaceChangedSidecarSets(oldNs, newNs *corev1.Namespace) ([]*appsv1alpha1.SidecarSet, error) {
+	sidecarSetList := &appsv1alpha1.SidecarSetList{}
+	if err := p.reader.List(context.TODO(), sidecarSetList); err != nil {
+		return nil, err
+	}
+
+	var changedSidecarSets []*appsv1alpha1.SidecarSet
+	for i := range sidecarSetList.Items {
+		sidecarSet := &sidecarSetList.Items[i]
+		if sidecarSet.Spec.NamespaceSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(sidecarSet.Spec.NamespaceSelector)
+		if err != nil {
+			klog.Warningf("sidecarSet(%s) parse namespaceSelector failed: %s", sidecarSet.Name, err.Error())
+			continue
+		}
+		if selector.Matches(labels.Set(oldNs.Labels)) != selector.Matches(labels.Set(newNs.Labels)) {
+			changedSidecarSets = append(changedSidecarSets, sidecarSet)
+		}
+	}
+	return changedSidecarSets, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestNamespaceEventHandler(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	handler := enqueueRequestForNamespace{reader: fakeClient}
+
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"sidecar-injection": "enabled"},
+	}
+	if err := fakeClient.Create(context.TODO(), sidecarSet); err != nil {
+		t.Fatalf("unexpected create sidecarSet %s failed: %v", sidecarSet.Name, err)
+	}
+	sidecarSetWithoutSelector := sidecarSetDemo.DeepCopy()
+	sidecarSetWithoutSelector.Name = "test-sidecarset-without-selector"
+	if err := fakeClient.Create(context.TODO(), sidecarSetWithoutSelector); err != nil {
+		t.Fatalf("unexpected create sidecarSet %s failed: %v", sidecarSetWithoutSelector.Name, err)
+	}
+
+	cases := []struct {
+		name         string
+		oldLabels    map[string]string
+		newLabels    map[string]string
+		expectQueued int
+	}{
+		{
+			name:         "namespace becomes selected",
+			oldLabels:    map[string]string{"team": "a"},
+			newLabels:    map[string]string{"team": "a", "sidecar-injection": "enabled"},
+			expectQueued: 1,
+		},
+		{
+			name:         "namespace becomes unselected",
+			oldLabels:    map[string]string{"sidecar-injection": "enabled"},
+			newLabels:    map[string]string{"sidecar-injection": "disabled"},
+			expectQueued: 1,
+		},
+		{
+			name:         "namespace selection unchanged",
+			oldLabels:    map[string]string{"sidecar-injection": "enabled"},
+			newLabels:    map[string]string{"sidecar-injection": "enabled", "team": "a"},
+			expectQueued: 0,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			handler.Update(event.UpdateEvent{
+				ObjectOld: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Labels: cs.oldLabels}},
+				ObjectNew: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Labels: cs.newLabels}},
+			}, q)
+			if q.Len() != cs.expectQueued {
+				t.Fatalf("unexpected update event handle queue size, expected %d actual %d", cs.expectQueued, q.Len())
+			}
+		})
+	}
+}
@@ -128,7 +128,7 @@ func (p *enqueueRequestForPod) getPodMatchedSidecarSets(pod *corev1.Pod) ([]*app
 	}
 
 	for _, sidecarSet := range sidecarSets.Items {
-		matched, err := sidecarcontrol.PodMatchedSidecarSet(pod, sidecarSet)
+		matched, err := sidecarcontrol.PodMatchedSidecarSet(p.reader, pod, sidecarSet)
 		if err != nil {
 			return nil, err
 		}
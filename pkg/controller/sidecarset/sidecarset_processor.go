@@ -20,20 +20,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 
+	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller/history"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -42,31 +52,51 @@ type Processor struct {
 	Client             client.Client
 	recorder           record.EventRecorder
 	updateExpectations expectations.UpdateExpectations
+	historyController  sidecarcontrol.HistoryControl
+	controllerFinder   *controllerfinder.ControllerFinder
 }
 
 func NewSidecarSetProcessor(cli client.Client, expectations expectations.UpdateExpectations, rec record.EventRecorder) *Processor {
 	return &Processor{
 		Client:             cli,
 		updateExpectations: expectations,
+		historyController:  sidecarcontrol.NewHistoryControl(cli),
+		controllerFinder:   controllerfinder.NewControllerFinder(cli),
 		recorder:           rec,
 	}
 }
 
 func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (reconcile.Result, error) {
-	control := sidecarcontrol.New(sidecarSet)
 	// check whether sidecarSet is active
-	if !control.IsActiveSidecarSet() {
+	if !sidecarcontrol.New(sidecarSet).IsActiveSidecarSet() {
 		return reconcile.Result{}, nil
 	}
-	// 1. get matching pods with the sidecarSet
+	// 1. register the latest revision of sidecarSet
+	latestRevision, collisionCount, err := p.registerLatestRevision(sidecarSet)
+	if err != nil {
+		klog.Errorf("sidecarSet register the latest revision error, err: %v, name: %s", err, sidecarSet.Name)
+		return reconcile.Result{}, err
+	}
+
+	// 2. get the sidecarSet which the matched pods will be updated to
+	targetSidecarSet, err := p.getTargetSidecarSet(sidecarSet)
+	if err != nil {
+		klog.Errorf("sidecarSet get the target revision error, err: %v, name: %s", err, sidecarSet.Name)
+		return reconcile.Result{}, err
+	}
+	control := sidecarcontrol.New(targetSidecarSet)
+
+	// 3. get matching pods with the sidecarSet
 	pods, err := p.getMatchingPods(sidecarSet)
 	if err != nil {
 		klog.Errorf("sidecarSet get matching pods error, err: %v, name: %s", err, sidecarSet.Name)
 		return reconcile.Result{}, err
 	}
 
-	// 2. calculate SidecarSet status based on pods
+	// 4. calculate SidecarSet status based on pods
 	status := calculateStatus(control, pods)
+	status.LatestRevision = latestRevision.Name
+	status.CollisionCount = &collisionCount
 	//update sidecarSet status in store
 	if err := p.updateSidecarSetStatus(sidecarSet, status); err != nil {
 		return reconcile.Result{}, err
@@ -74,16 +104,16 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 
 	// in case of informer cache latency
 	for _, pod := range pods {
-		p.updateExpectations.ObserveUpdated(sidecarSet.Name, sidecarcontrol.GetSidecarSetRevision(sidecarSet), pod)
+		p.updateExpectations.ObserveUpdated(sidecarSet.Name, sidecarcontrol.GetSidecarSetRevision(targetSidecarSet), pod)
 	}
-	allUpdated, _, inflightPods := p.updateExpectations.SatisfiedExpectations(sidecarSet.Name, sidecarcontrol.GetSidecarSetRevision(sidecarSet))
+	allUpdated, _, inflightPods := p.updateExpectations.SatisfiedExpectations(sidecarSet.Name, sidecarcontrol.GetSidecarSetRevision(targetSidecarSet))
 	if !allUpdated {
 		klog.V(3).Infof("sidecarset %s matched pods has some update in flight: %v, will sync later", sidecarSet.Name, inflightPods)
 		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
 
-	// 3. If sidecar container hot upgrade complete, then set the other one(empty sidecar container) image to HotUpgradeEmptyImage
-	if isSidecarSetHasHotUpgradeContainer(sidecarSet) {
+	// 5. If sidecar container hot upgrade complete, then set the other one(empty sidecar container) image to HotUpgradeEmptyImage
+	if isSidecarSetHasHotUpgradeContainer(targetSidecarSet) {
 		var podsInHotUpgrading []*corev1.Pod
 		for _, pod := range pods {
 			// flip other hot sidecar container to empty, in the following:
@@ -92,15 +122,15 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 			// 3. all containers with exception of empty sidecar containers is ready
 
 			// don't contain sidecar empty containers
-			sidecarContainers := sidecarcontrol.GetSidecarContainersInPod(sidecarSet)
-			for _, sidecarContainer := range sidecarSet.Spec.Containers {
+			sidecarContainers := sidecarcontrol.GetSidecarContainersInPod(targetSidecarSet)
+			for _, sidecarContainer := range targetSidecarSet.Spec.Containers {
 				if sidecarcontrol.IsHotUpgradeContainer(&sidecarContainer) {
 					_, emptyContainer := sidecarcontrol.GetPodHotUpgradeContainers(sidecarContainer.Name, pod)
 					sidecarContainers.Delete(emptyContainer)
 				}
 			}
-			if isPodSidecarInHotUpgrading(sidecarSet, pod) && control.IsPodStateConsistent(pod, sidecarContainers) &&
-				isHotUpgradingReady(sidecarSet, pod) {
+			if isPodSidecarInHotUpgrading(targetSidecarSet, pod) && control.IsPodStateConsistent(pod, sidecarContainers) &&
+				isHotUpgradingReady(targetSidecarSet, pod) {
 				podsInHotUpgrading = append(podsInHotUpgrading, pod)
 			}
 		}
@@ -109,52 +139,81 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 		}
 	}
 
-	// 4. SidecarSet upgrade strategy type is NotUpdate
+	// 6. SidecarSet upgrade strategy type is NotUpdate
 	if !isSidecarSetNotUpdate(sidecarSet) {
 		return reconcile.Result{}, nil
 	}
 
-	// 5. sidecarset already updates all matched pods, then return
+	// 7. sidecarset already updates all matched pods, then return
 	if isSidecarSetUpdateFinish(status) {
 		klog.V(3).Infof("sidecarSet(%s) matched pods(number=%d) are latest, and don't need update", sidecarSet.Name, len(pods))
 		return reconcile.Result{}, nil
 	}
 
-	// 6. Paused indicates that the SidecarSet is paused to update matched pods
+	// 8. Paused indicates that the SidecarSet is paused to update matched pods
 	if sidecarSet.Spec.UpdateStrategy.Paused {
 		klog.V(3).Infof("sidecarSet is paused, name: %s", sidecarSet.Name)
 		return reconcile.Result{}, nil
 	}
 
-	// 7. upgrade pod sidecar
-	if err := p.updatePods(control, pods); err != nil {
+	// 9. upgrade pod sidecar
+	requeueAfter, err := p.updatePods(control, pods)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (p *Processor) updatePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) error {
+// updatePods upgrades the sidecar containers of the next pods, and returns the duration to requeue
+// if some pods are not allowed to be upgraded by PodUnavailableBudget for the time.
+func (p *Processor) updatePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) (time.Duration, error) {
 	sidecarset := control.GetSidecarset()
 	// compute next updated pods based on the sidecarset upgrade strategy
 	upgradePods := NewStrategy().GetNextUpgradePods(control, pods)
 	if len(upgradePods) == 0 {
 		klog.V(3).Infof("sidecarSet next update is nil, skip this round, name: %s", sidecarset.Name)
-		return nil
+		return 0, nil
 	}
+	var requeueAfter time.Duration
 	// mark upgrade pods list
 	podNames := make([]string, 0, len(upgradePods))
 	// upgrade pod sidecar
 	for _, pod := range upgradePods {
+		// Determine the pub before updating the pod
+		if utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) {
+			allowed, err := p.isPodUpdateAllowedByPub(pod)
+			if err != nil {
+				return 0, err
+			} else if !allowed {
+				// pub check does not pass, try again in seconds
+				klog.V(3).Infof("sidecarSet(%s) pod(%s/%s) is not allowed to upgrade by pub, and try again later", sidecarset.Name, pod.Namespace, pod.Name)
+				requeueAfter = time.Second
+				continue
+			}
+		}
 		podNames = append(podNames, pod.Name)
 		if err := p.updatePodSidecarAndHash(control, pod); err != nil {
 			err := fmt.Errorf("updatePodSidecarAndHash error, s:%s, pod:%s, err:%v", sidecarset.Name, pod.Name, err)
-			return err
+			return 0, err
 		}
 		p.updateExpectations.ExpectUpdated(sidecarset.Name, sidecarcontrol.GetSidecarSetRevision(sidecarset), pod)
 	}
 
 	klog.V(3).Infof("sidecarSet(%s) updated pods(%s)", sidecarset.Name, strings.Join(podNames, ","))
-	return nil
+	return requeueAfter, nil
+}
+
+// isPodUpdateAllowedByPub checks whether the PodUnavailableBudget of pod allows the sidecar containers to be upgraded.
+// The matched pods of SidecarSet may belong to different workloads, so the pub is determined for each pod.
+func (p *Processor) isPodUpdateAllowedByPub(pod *corev1.Pod) (bool, error) {
+	pub, err := pubcontrol.GetPodUnavailableBudgetForPod(p.Client, p.controllerFinder, pod)
+	if err != nil {
+		return false, err
+	} else if pub == nil {
+		return true, nil
+	}
+	allowed, _, err := pubcontrol.PodUnavailableBudgetValidatePod(p.Client, pod, pubcontrol.NewPubControl(pub), pubcontrol.UpdateOperation, false)
+	return allowed, err
 }
 
 func (p *Processor) updatePodSidecarAndHash(control sidecarcontrol.SidecarControl, pod *corev1.Pod) error {
@@ -199,7 +258,7 @@ func (p *Processor) listMatchedSidecarSets(pod *corev1.Pod) string {
 	//matched SidecarSet.Name list
 	sidecarSetNames := make([]string, 0)
 	for _, sidecarSet := range sidecarSetList.Items {
-		if matched, _ := sidecarcontrol.PodMatchedSidecarSet(pod, sidecarSet); matched {
+		if matched, _ := sidecarcontrol.PodMatchedSidecarSet(p.Client, pod, sidecarSet); matched {
 			sidecarSetNames = append(sidecarSetNames, sidecarSet.Name)
 		}
 	}
@@ -248,6 +307,11 @@ func (p *Processor) getMatchingPods(s *appsv1alpha1.SidecarSet) ([]*corev1.Pod,
 
 	// If sidecarSet.Spec.Namespace is empty, then select in cluster
 	scopedNamespaces := []string{s.Spec.Namespace}
+	if s.Spec.NamespaceSelector != nil {
+		if scopedNamespaces, err = p.getSelectedNamespaces(s.Spec.NamespaceSelector); err != nil {
+			return nil, err
+		}
+	}
 	selectedPods, err := p.getSelectedPods(scopedNamespaces, selector)
 	if err != nil {
 		return nil, err
@@ -266,6 +330,127 @@ func (p *Processor) getMatchingPods(s *appsv1alpha1.SidecarSet) ([]*corev1.Pod,
 	return filteredPods, nil
 }
 
+// getSelectedNamespaces returns the names of namespaces that match the namespaceSelector
+func (p *Processor) getSelectedNamespaces(nsSelector *metav1.LabelSelector) ([]string, error) {
+	selector, err := util.GetFastLabelSelector(nsSelector)
+	if err != nil {
+		return nil, err
+	}
+	nsList := &corev1.NamespaceList{}
+	if err = p.Client.List(context.TODO(), nsList, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, fmt.Errorf("sidecarSet list namespaces error, err:%v", err)
+	}
+	namespaces := make([]string, 0, len(nsList.Items))
+	for i := range nsList.Items {
+		namespaces = append(namespaces, nsList.Items[i].Name)
+	}
+	return namespaces, nil
+}
+
+// registerLatestRevision creates the ControllerRevision of the current sidecarSet if it does not exist,
+// and cleans up the expired revisions.
+func (p *Processor) registerLatestRevision(sidecarSet *appsv1alpha1.SidecarSet) (*apps.ControllerRevision, int32, error) {
+	// Use a local copy of sidecarSet.Status.CollisionCount to avoid modifying sidecarSet.Status directly.
+	var collisionCount int32
+	if sidecarSet.Status.CollisionCount != nil {
+		collisionCount = *sidecarSet.Status.CollisionCount
+	}
+
+	revisions, err := p.historyController.ListRevisions(sidecarSet)
+	if err != nil {
+		return nil, collisionCount, err
+	}
+
+	// create a new revision from the current sidecarSet
+	latestRevision, err := p.historyController.NewRevision(sidecarSet, nextRevision(revisions), &collisionCount)
+	if err != nil {
+		return nil, collisionCount, err
+	}
+
+	// find any equivalent revisions
+	equalRevisions := history.FindEqualRevisions(revisions, latestRevision)
+	equalCount := len(equalRevisions)
+	revisionCount := len(revisions)
+
+	if equalCount > 0 && history.EqualRevision(revisions[revisionCount-1], equalRevisions[equalCount-1]) {
+		// if the equivalent revision is immediately prior the latest revision has not changed
+		latestRevision = revisions[revisionCount-1]
+	} else if equalCount > 0 {
+		// if the equivalent revision is not immediately prior we will roll back by incrementing the
+		// Revision of the equivalent revision
+		latestRevision, err = p.historyController.UpdateControllerRevision(equalRevisions[equalCount-1], latestRevision.Revision)
+		if err != nil {
+			return nil, collisionCount, err
+		}
+	} else {
+		// if there is no equivalent revision we create a new one
+		latestRevision, err = p.historyController.CreateControllerRevision(sidecarSet, latestRevision, &collisionCount)
+		if err != nil {
+			return nil, collisionCount, err
+		}
+		revisions = append(revisions, latestRevision)
+	}
+
+	if err = p.truncateHistory(sidecarSet, revisions, latestRevision); err != nil {
+		return nil, collisionCount, err
+	}
+	return latestRevision, collisionCount, nil
+}
+
+// truncateHistory deletes the oldest revisions that exceed the RevisionHistoryLimit of sidecarSet.
+// The latest revision and the revisions referenced by sidecarSet will not be deleted.
+func (p *Processor) truncateHistory(sidecarSet *appsv1alpha1.SidecarSet, revisions []*apps.ControllerRevision, latestRevision *apps.ControllerRevision) error {
+	historyLimit := 10
+	if sidecarSet.Spec.RevisionHistoryLimit != nil {
+		historyLimit = int(*sidecarSet.Spec.RevisionHistoryLimit)
+	}
+	live := sets.NewString(latestRevision.Name)
+	if sidecarSet.Spec.InjectionStrategy.RevisionName != nil {
+		live.Insert(*sidecarSet.Spec.InjectionStrategy.RevisionName)
+	}
+	if sidecarSet.Spec.UpdateStrategy.RevisionName != nil {
+		live.Insert(*sidecarSet.Spec.UpdateStrategy.RevisionName)
+	}
+
+	// revisions are sorted by Revision, so the oldest ones will be deleted first
+	var expired []*apps.ControllerRevision
+	for _, revision := range revisions {
+		if !live.Has(revision.Name) {
+			expired = append(expired, revision)
+		}
+	}
+	if len(expired) <= historyLimit {
+		return nil
+	}
+	for _, revision := range expired[:len(expired)-historyLimit] {
+		if err := p.historyController.DeleteControllerRevision(revision); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getTargetSidecarSet returns the sidecarSet which the matched pods will be updated to.
+// If updateStrategy.revisionName is set, the sidecar containers will be restored from the revision.
+func (p *Processor) getTargetSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (*appsv1alpha1.SidecarSet, error) {
+	revisionName := sidecarSet.Spec.UpdateStrategy.RevisionName
+	if revisionName == nil || *revisionName == "" {
+		return sidecarSet, nil
+	}
+	return p.historyController.GetHistorySidecarSet(sidecarSet, *revisionName)
+}
+
+// nextRevision finds the next valid revision number based on revisions. If the length of revisions
+// is 0 this is 1. Otherwise, it is 1 greater than the largest revision's Revision. This method
+// assumes that revisions has been sorted by Revision.
+func nextRevision(revisions []*apps.ControllerRevision) int64 {
+	count := len(revisions)
+	if count <= 0 {
+		return 1
+	}
+	return revisions[count-1].Revision + 1
+}
+
 // get selected pods(DisableDeepCopy:true, indicates must be deep copy before update pod objection)
 func (p *Processor) getSelectedPods(namespaces []string, selector labels.Selector) (relatedPods []*corev1.Pod, err error) {
 	// DisableDeepCopy:true, indicates must be deep copy before update pod objection
@@ -398,7 +583,9 @@ func inconsistentStatus(sidecarSet *appsv1alpha1.SidecarSet, status *appsv1alpha
 		status.MatchedPods != sidecarSet.Status.MatchedPods ||
 		status.UpdatedPods != sidecarSet.Status.UpdatedPods ||
 		status.ReadyPods != sidecarSet.Status.ReadyPods ||
-		status.UpdatedReadyPods != sidecarSet.Status.UpdatedReadyPods
+		status.UpdatedReadyPods != sidecarSet.Status.UpdatedReadyPods ||
+		status.LatestRevision != sidecarSet.Status.LatestRevision ||
+		!reflect.DeepEqual(status.CollisionCount, sidecarSet.Status.CollisionCount)
 }
 
 func isSidecarSetUpdateFinish(status *appsv1alpha1.SidecarSetStatus) bool {
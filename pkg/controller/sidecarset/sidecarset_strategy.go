@@ -20,7 +20,7 @@ type Strategy interface {
 	//	* pod must be not updated for the latest sidecarSet
 	//	* If selector is not nil, this upgrade will only update the selected pods.
 	//2. Sort Pods with default sequence
-	//3. sort waitUpdateIndexes based on the scatter rules
+	//3. sort waitUpdateIndexes based on the priority and scatter rules
 	//4. calculate max count of pods can update with maxUnavailable
 	GetNextUpgradePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) []*corev1.Pod
 }
@@ -74,7 +74,7 @@ func (p *spreadingStrategy) GetNextUpgradePods(control sidecarcontrol.SidecarCon
 	}
 
 	klog.V(3).Infof("sidecarSet(%s) matchedPods(%d) waitUpdated(%d)", sidecarset.Name, len(pods), len(waitUpgradedIndexes))
-	//2. sort Pods with default sequence and scatter
+	//2. sort Pods with default sequence, priority and scatter
 	waitUpgradedIndexes = SortUpdateIndexes(strategy, pods, waitUpgradedIndexes)
 
 	//3. calculate to be upgraded pods number for the time
@@ -101,6 +101,11 @@ func SortUpdateIndexes(strategy appsv1alpha1.SidecarSetUpdateStrategy, pods []*c
 	//	- Empty creation time pods < newer pods < older pods
 	sort.Slice(waitUpdateIndexes, sidecarcontrol.GetPodsSortFunc(pods, waitUpdateIndexes))
 
+	//sort waitUpdateIndexes based on the priority rules
+	if strategy.PriorityStrategy != nil {
+		waitUpdateIndexes = updatesort.NewPrioritySorter(strategy.PriorityStrategy).Sort(pods, waitUpdateIndexes)
+	}
+
 	//sort waitUpdateIndexes based on the scatter rules
 	if strategy.ScatterStrategy != nil {
 		// convert regular terms to scatter terms
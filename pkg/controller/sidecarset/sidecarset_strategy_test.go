@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"testing"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 
@@ -514,6 +515,32 @@ func testSortNextUpgradePods(t *testing.T, factoryPods FactoryPods, factorySidec
 			},
 			exceptNextUpgradePods: []string{"pod-13", "pod-10", "pod-19", "pod-18", "pod-17", "pod-16", "pod-15"},
 		},
+		{
+			name: "weight priority, maxUnavailable(int=10) and pods(count=20, upgraded=10, upgradedAndReady=5)",
+			getPods: func() []*corev1.Pod {
+				pods := factoryPods(20, 10, 5)
+				pods[11].Labels["priority"] = "high"
+				pods[12].Labels["priority"] = "high"
+				return pods
+			},
+			getSidecarset: func() *appsv1alpha1.SidecarSet {
+				sidecarSet := factorySidecar()
+				sidecarSet.Spec.UpdateStrategy.MaxUnavailable = &intstr.IntOrString{
+					Type:   intstr.Int,
+					IntVal: 10,
+				}
+				sidecarSet.Spec.UpdateStrategy.PriorityStrategy = &appspub.UpdatePriorityStrategy{
+					WeightPriority: []appspub.UpdatePriorityWeightTerm{
+						{
+							Weight:        50,
+							MatchSelector: metav1.LabelSelector{MatchLabels: map[string]string{"priority": "high"}},
+						},
+					},
+				}
+				return sidecarSet
+			},
+			exceptNextUpgradePods: []string{"pod-12", "pod-11", "pod-19", "pod-18", "pod-17"},
+		},
 	}
 
 	strategy := NewStrategy()
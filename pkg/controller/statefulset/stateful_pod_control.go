@@ -24,6 +24,8 @@ import (
 
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
 	kruiseappslisters "github.com/openkruise/kruise/pkg/client/listers/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +35,7 @@ import (
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
 )
 
 // StatefulPodControlInterface defines the interface that StatefulSetController uses to create, update, and delete Pods,
@@ -51,6 +54,22 @@ type StatefulPodControlInterface interface {
 	// DeleteStatefulPod deletes a Pod in a StatefulSet. The pods PVCs are not deleted. If the delete is successful,
 	// the returned error is nil.
 	DeleteStatefulPod(set *appsv1beta1.StatefulSet, pod *v1.Pod) error
+	// ClaimsMatchRetentionPolicy returns false if the PVCs for pod are not consistent with set's PVC deletion policy.
+	// An error is returned if something is not consistent. This is expected if the pod is being otherwise updated,
+	// but a problem otherwise (see usage of this method in UpdateStatefulPod).
+	ClaimsMatchRetentionPolicy(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error)
+	// UpdatePodClaimForRetentionPolicy updates the PVCs used by pod to match the PVC deletion policy of set.
+	UpdatePodClaimForRetentionPolicy(set *appsv1beta1.StatefulSet, pod *v1.Pod) error
+	// PodClaimIsStale returns true for a stale PVC that should block pod creation. If the scaling
+	// policy is deletion, and a PVC has an ownerRef that does not match the pod, the PVC is stale. This
+	// includes pods whose UID has not been created.
+	PodClaimIsStale(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error)
+	// PodClaimIsMismatched returns true if any existing PVC of pod, which is not being deleted, does not match
+	// the volumeClaimTemplates of set.
+	PodClaimIsMismatched(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error)
+	// DeleteMismatchedPersistentVolumeClaims deletes the PVCs of pod that do not match the volumeClaimTemplates
+	// of set, so that they can be recreated from the new templates together with the pod.
+	DeleteMismatchedPersistentVolumeClaims(set *appsv1beta1.StatefulSet, pod *v1.Pod) error
 }
 
 // NewRealStatefulPodControl returns a new realStatefulPodControl
@@ -110,6 +129,20 @@ func (spc *realStatefulPodControl) UpdateStatefulPod(set *appsv1beta1.StatefulSe
 				return err
 			}
 		}
+		if utilfeature.DefaultFeatureGate.Enabled(features.StatefulSetAutoDeletePVC) {
+			// if the Pod's PVCs are not consistent with the StatefulSet's PVC deletion policy, update the PVC
+			// and dirty the pod.
+			if match, err := spc.ClaimsMatchRetentionPolicy(set, pod); err != nil {
+				spc.recordPodEvent("update", set, pod, err)
+				return err
+			} else if !match {
+				if err := spc.UpdatePodClaimForRetentionPolicy(set, pod); err != nil {
+					spc.recordPodEvent("update", set, pod, err)
+					return err
+				}
+				consistent = false
+			}
+		}
 		// if the Pod is not dirty, do nothing
 		if consistent {
 			return nil
@@ -143,6 +176,116 @@ func (spc *realStatefulPodControl) DeleteStatefulPod(set *appsv1beta1.StatefulSe
 	return err
 }
 
+func (spc *realStatefulPodControl) ClaimsMatchRetentionPolicy(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
+	ordinal := getOrdinal(pod)
+	templates := set.Spec.VolumeClaimTemplates
+	for i := range templates {
+		claimName := getPersistentVolumeClaimName(set, &templates[i], ordinal)
+		claim, err := spc.pvcLister.PersistentVolumeClaims(set.Namespace).Get(claimName)
+		switch {
+		case apierrors.IsNotFound(err):
+			klog.V(4).Infof("Expected claim %s missing, continuing to pick up in next iteration", claimName)
+		case err != nil:
+			return false, fmt.Errorf("could not retrieve claim %s for %s when checking PVC deletion policy: %v", claimName, pod.Name, err)
+		default:
+			if !claimOwnerMatchesSetAndPod(claim, set, pod) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func (spc *realStatefulPodControl) UpdatePodClaimForRetentionPolicy(set *appsv1beta1.StatefulSet, pod *v1.Pod) error {
+	ordinal := getOrdinal(pod)
+	templates := set.Spec.VolumeClaimTemplates
+	for i := range templates {
+		claimName := getPersistentVolumeClaimName(set, &templates[i], ordinal)
+		claim, err := spc.pvcLister.PersistentVolumeClaims(set.Namespace).Get(claimName)
+		switch {
+		case apierrors.IsNotFound(err):
+			klog.V(4).Infof("Expected claim %s missing, continuing to pick up in next iteration", claimName)
+		case err != nil:
+			return fmt.Errorf("could not retrieve claim %s for %s when checking PVC deletion policy: %v", claimName, pod.Name, err)
+		default:
+			if !claimOwnerMatchesSetAndPod(claim, set, pod) {
+				// make a copy so we don't mutate the shared cache
+				claim = claim.DeepCopy()
+				if needsUpdate := updateClaimOwnerRefForSetAndPod(claim, set, pod); needsUpdate {
+					_, err := spc.client.CoreV1().PersistentVolumeClaims(claim.Namespace).Update(context.TODO(), claim, metav1.UpdateOptions{})
+					if err != nil {
+						return fmt.Errorf("could not update claim %s for delete policy ownerRefs: %v", claimName, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (spc *realStatefulPodControl) PodClaimIsStale(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
+	policy := getPersistentVolumeClaimRetentionPolicy(set)
+	if policy.WhenScaled == appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType {
+		// PVCs are meant to be reused and so can't be stale.
+		return false, nil
+	}
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.pvcLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			// If the claim doesn't exist yet, it can't be stale.
+			continue
+		case err != nil:
+			return false, err
+		default:
+			// A claim is stale if it doesn't match the pod's UID, including if the pod has no UID.
+			if hasStaleOwnerRef(pvc, pod) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (spc *realStatefulPodControl) PodClaimIsMismatched(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.pvcLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return false, err
+		case pvc.DeletionTimestamp == nil && !claimMatchesTemplate(pvc, &claim):
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (spc *realStatefulPodControl) DeleteMismatchedPersistentVolumeClaims(set *appsv1beta1.StatefulSet, pod *v1.Pod) error {
+	var errs []error
+	for name, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.pvcLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to retrieve PVC %s: %s", claim.Name, err))
+			continue
+		case pvc.DeletionTimestamp != nil || claimMatchesTemplate(pvc, &claim):
+			continue
+		}
+		klog.V(2).Infof("StatefulSet %s/%s deleting PVC %s for template %s changed", set.Namespace, set.Name, pvc.Name, name)
+		err = spc.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(context.TODO(), pvc.Name,
+			metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &pvc.UID}})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete PVC %s: %s", pvc.Name, err))
+		}
+		spc.recordClaimEvent("delete", set, pod, pvc, err)
+	}
+	return errorutils.NewAggregate(errs)
+}
+
 // recordPodEvent records an event for verb applied to a Pod in a StatefulSet. If err is nil the generated event will
 // have a reason of v1.EventTypeNormal. If err is not nil the generated event will have a reason of v1.EventTypeWarning.
 func (spc *realStatefulPodControl) recordPodEvent(verb string, set *appsv1beta1.StatefulSet, pod *v1.Pod, err error) {
@@ -27,7 +27,6 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/controller/history"
@@ -35,9 +34,13 @@ import (
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilapi "github.com/openkruise/kruise/pkg/util/api"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	imagejobutilfunc "github.com/openkruise/kruise/pkg/util/imagejob/utilfunction"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 )
 
 // ControlInterface implements the control logic for updating StatefulSets and their children Pods. It is implemented
@@ -329,17 +332,12 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	status.CollisionCount = utilpointer.Int32Ptr(collisionCount)
 	status.LabelSelector = selector.String()
 
-	reserveOrdinals := sets.NewInt(set.Spec.ReserveOrdinals...)
-	replicaCount := 0
-	for realReplicaCount := 0; realReplicaCount < int(*set.Spec.Replicas); replicaCount++ {
-		if reserveOrdinals.Has(replicaCount) {
-			continue
-		}
-		realReplicaCount++
-	}
-	// slice that will contain all Pods such that 0 <= getOrdinal(pod) < replicaCount and not in reserveOrdinals
-	replicas := make([]*v1.Pod, replicaCount)
-	// slice that will contain all Pods such that replicaCount <= getOrdinal(pod) or in reserveOrdinals
+	// the replicas use ordinals in [startOrdinal, endOrdinal) which are not in reserveOrdinals
+	startOrdinal, endOrdinal, reserveOrdinals := utilapi.GetOrdinalRange(set)
+	// slice that will contain all Pods such that startOrdinal <= getOrdinal(pod) < endOrdinal and not in reserveOrdinals,
+	// the Pod is indexed by getOrdinal(pod) - startOrdinal
+	replicas := make([]*v1.Pod, endOrdinal-startOrdinal)
+	// slice that will contain all Pods such that getOrdinal(pod) is out of the range or in reserveOrdinals
 	condemned := make([]*v1.Pod, 0, len(pods))
 	unhealthy := 0
 	firstUnhealthyOrdinal := math.MaxInt32
@@ -381,26 +379,26 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 			}
 		}
 
-		if ord := getOrdinal(pods[i]); 0 <= ord && ord < replicaCount && !reserveOrdinals.Has(ord) {
+		if ord := getOrdinal(pods[i]); startOrdinal <= ord && ord < endOrdinal && !reserveOrdinals.Has(ord) {
 			// if the ordinal of the pod is within the range of the current number of replicas and not in reserveOrdinals,
 			// insert it at the indirection of its ordinal
-			replicas[ord] = pods[i]
+			replicas[ord-startOrdinal] = pods[i]
 
-		} else if ord >= replicaCount || reserveOrdinals.Has(ord) {
-			// if the ordinal is greater than the number of replicas or in reserveOrdinals,
+		} else if ord >= 0 {
+			// if the ordinal is out of the range of the current number of replicas or in reserveOrdinals,
 			// add it to the condemned list
 			condemned = append(condemned, pods[i])
 		}
 		// If the ordinal could not be parsed (ord < 0), ignore the Pod.
 	}
 
-	// for any empty indices in the sequence [0,set.Spec.Replicas) create a new Pod at the correct revision
-	for ord := 0; ord < replicaCount; ord++ {
+	// for any empty indices in the sequence [startOrdinal,endOrdinal) create a new Pod at the correct revision
+	for ord := startOrdinal; ord < endOrdinal; ord++ {
 		if reserveOrdinals.Has(ord) {
 			continue
 		}
-		if replicas[ord] == nil {
-			replicas[ord] = newVersionedStatefulSetPod(
+		if replicas[ord-startOrdinal] == nil {
+			replicas[ord-startOrdinal] = newVersionedStatefulSetPod(
 				currentSet,
 				updateSet,
 				currentRevision.Name,
@@ -476,11 +474,23 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 				updateSet,
 				currentRevision.Name,
 				updateRevision.Name,
-				i, replicas)
+				i+startOrdinal, replicas)
 		}
 		// If we find a Pod that has not been created we create the Pod
 		if !isCreated(replicas[i]) {
-			lifecycle.SetPodLifecycle(appspub.LifecycleStateNormal)(replicas[i])
+			if utilfeature.DefaultFeatureGate.Enabled(features.StatefulSetAutoDeletePVC) {
+				if isStale, err := ssc.podControl.PodClaimIsStale(set, replicas[i]); err != nil {
+					return &status, err
+				} else if isStale {
+					// If a pod has a stale PVC, no more work can be done this round.
+					return &status, err
+				}
+			}
+			if set.Spec.Lifecycle != nil && set.Spec.Lifecycle.PreNormal != nil {
+				lifecycle.SetPodLifecycle(appspub.LifecycleStatePreparingNormal)(replicas[i])
+			} else {
+				lifecycle.SetPodLifecycle(appspub.LifecycleStateNormal)(replicas[i])
+			}
 			if err := ssc.podControl.CreateStatefulPod(set, replicas[i]); err != nil {
 				msg := fmt.Sprintf("StatefulPodControl failed to create Pod error: %s", err)
 				condition := NewStatefulsetCondition(appsv1beta1.FailedCreatePod, v1.ConditionTrue, "", msg)
@@ -520,6 +530,18 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 		} else if res.DelayDuration > 0 {
 			durationStore.Push(getStatefulSetKey(set), res.DelayDuration)
 		}
+		// Turn the Pod from PreparingNormal to Normal once its PreNormal hook has been satisfied
+		if lifecycle.GetPodLifecycleState(replicas[i]) == appspub.LifecycleStatePreparingNormal &&
+			(set.Spec.Lifecycle == nil ||
+				set.Spec.Lifecycle.PreNormal == nil ||
+				lifecycle.IsPodAllHooked(set.Spec.Lifecycle.PreNormal, replicas[i])) {
+			if updated, err := ssc.lifecycleControl.UpdatePodLifecycle(replicas[i], appspub.LifecycleStateNormal); err != nil {
+				return &status, err
+			} else if updated {
+				klog.V(3).Infof("AdvancedStatefulSet %s update pod %s lifecycle to %s",
+					getStatefulSetKey(set), replicas[i].Name, appspub.LifecycleStateNormal)
+			}
+		}
 		// If we have a Pod that has been created but is not running and available we can not make progress.
 		// We must ensure that all for each Pod, when we create it, all of its predecessors, with respect to its
 		// ordinal, are Running and Available.
@@ -548,7 +570,16 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 			}
 		}
 		// Enforce the StatefulSet invariants
-		if identityMatches(set, replicas[i]) && storageMatches(set, replicas[i]) {
+		retentionMatch := true
+		if utilfeature.DefaultFeatureGate.Enabled(features.StatefulSetAutoDeletePVC) {
+			var err error
+			retentionMatch, err = ssc.podControl.ClaimsMatchRetentionPolicy(updateSet, replicas[i])
+			// An error is expected if the pod is not yet fully updated, and so return is treated as matching.
+			if err != nil {
+				retentionMatch = true
+			}
+		}
+		if identityMatches(set, replicas[i]) && storageMatches(set, replicas[i]) && retentionMatch {
 			continue
 		}
 		// Make a deep copy so we don't mutate the shared cache
@@ -561,6 +592,19 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 		}
 	}
 
+	// Fix pod claims for condemned pods, if necessary.
+	if utilfeature.DefaultFeatureGate.Enabled(features.StatefulSetAutoDeletePVC) {
+		for i := range condemned {
+			if matchPolicy, err := ssc.podControl.ClaimsMatchRetentionPolicy(updateSet, condemned[i]); err != nil {
+				return &status, err
+			} else if !matchPolicy {
+				if err := ssc.podControl.UpdatePodClaimForRetentionPolicy(updateSet, condemned[i]); err != nil {
+					return &status, err
+				}
+			}
+		}
+	}
+
 	// At this point, all of the current Replicas are Running and Ready, we can consider termination.
 	// We will wait for all predecessors to be Running and Ready prior to attempting a deletion.
 	// We will terminate Pods in a monotonically decreasing order over [len(pods),set.Spec.Replicas).
@@ -663,12 +707,24 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	// update pods in sequence
 	for _, target := range updateIndexes {
 
-		// delete the Pod if it is not already terminating and does not match the update revision.
-		if getPodRevision(replicas[target]) != updateRevision.Name && !isTerminating(replicas[target]) {
-			// todo validate in-place for pub
-			inplacing, inplaceUpdateErr := ssc.inPlaceUpdatePod(set, replicas[target], updateRevision, revisions)
-			if inplaceUpdateErr != nil {
-				return &status, inplaceUpdateErr
+		// the claims that mismatch the volumeClaimTemplates should be recreated together with the Pod
+		var claimsMismatched bool
+		if isVolumeClaimRecreateEnabled(updateSet) && !isTerminating(replicas[target]) {
+			if claimsMismatched, err = ssc.podControl.PodClaimIsMismatched(updateSet, replicas[target]); err != nil {
+				return &status, err
+			}
+		}
+
+		// delete the Pod if it is not already terminating and does not match the update revision or the claim templates.
+		if (getPodRevision(replicas[target]) != updateRevision.Name || claimsMismatched) && !isTerminating(replicas[target]) {
+			var inplacing bool
+			if !claimsMismatched {
+				// todo validate in-place for pub
+				var inplaceUpdateErr error
+				inplacing, inplaceUpdateErr = ssc.inPlaceUpdatePod(set, replicas[target], updateRevision, revisions)
+				if inplaceUpdateErr != nil {
+					return &status, inplaceUpdateErr
+				}
 			}
 			if !inplacing {
 				klog.V(2).Infof("StatefulSet %s/%s terminating Pod %s for update",
@@ -678,6 +734,14 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 				if err := ssc.podControl.DeleteStatefulPod(set, replicas[target]); err != nil {
 					return &status, err
 				}
+				// The claims are deleted after the Pod, and they are protected from removal until the Pod is gone.
+				// The new Pod will not be created until the claims are removed, or it will be deleted again
+				// for its mismatched claims if they failed to be deleted here.
+				if claimsMismatched {
+					if err := ssc.podControl.DeleteMismatchedPersistentVolumeClaims(updateSet, replicas[target]); err != nil {
+						return &status, err
+					}
+				}
 			}
 
 			if getPodRevision(replicas[target]) == currentRevision.Name {
@@ -687,7 +751,7 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 
 		opts := &inplaceupdate.UpdateOptions{}
 		opts = inplaceupdate.SetOptionsDefaults(opts)
-		if getPodRevision(replicas[target]) != updateRevision.Name || !isHealthy(replicas[target]) {
+		if getPodRevision(replicas[target]) != updateRevision.Name || claimsMismatched || !isHealthy(replicas[target]) {
 			unavailablePods = append(unavailablePods, replicas[target].Name)
 		} else if completedErr := opts.CheckUpdateCompleted(replicas[target]); completedErr != nil {
 			klog.V(4).Infof("StatefulSet %s/%s check Pod %s in-place update not-ready: %v",
@@ -884,6 +948,14 @@ func (ssc *defaultStatefulSetControl) updateStatefulSetStatus(
 	// complete any in progress rolling update if necessary
 	completeRollingUpdate(set, status)
 
+	metrics.RecordWorkloadStatus("StatefulSet", set.Namespace, set.Name, map[string]int32{
+		"replicas":  status.Replicas,
+		"ready":     status.ReadyReplicas,
+		"available": status.AvailableReplicas,
+		"current":   status.CurrentReplicas,
+		"updated":   status.UpdatedReplicas,
+	}, status.CurrentRevision, status.UpdateRevision)
+
 	// if the status is not inconsistent do not perform an update
 	if !inconsistentStatus(set, status) {
 		return nil
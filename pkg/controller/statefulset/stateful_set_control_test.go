@@ -32,6 +32,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -2099,6 +2100,84 @@ func TestStatefulSetControlInPlaceUpdate(t *testing.T) {
 	}
 }
 
+func TestStatefulSetControlRecreateVolumeClaims(t *testing.T) {
+	set := burst(newStatefulSet(3))
+	set.Spec.UpdateStrategy = appsv1beta1.StatefulSetUpdateStrategy{
+		Type: apps.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1beta1.RollingUpdateStatefulSetStrategy{
+			PodUpdatePolicy: appsv1beta1.InPlaceIfPossiblePodUpdateStrategyType,
+		},
+	}
+	set.Spec.VolumeClaimUpdateStrategy = &appsv1beta1.VolumeClaimUpdateStrategy{Type: appsv1beta1.RecreateVolumeClaimUpdateStrategyType}
+
+	client := fake.NewSimpleClientset()
+	kruiseClient := kruisefake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(client, kruiseClient)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertBurstInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalPods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRevision := getPodRevision(originalPods[0])
+
+	// only the volumeClaimTemplates changed, which rolls the Pods with mismatched claims in the same revision
+	set = set.DeepCopy()
+	newSize := resource.MustParse("2Gi")
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = newSize
+	var pods []*v1.Pod
+	for i := 0; i < 10; i++ {
+		if err := updateStatefulSetControl(set, ssc, spc, assertUpdateInvariants); err != nil {
+			t.Fatal(err)
+		}
+		if pods, err = spc.podsLister.Pods(set.Namespace).List(selector); err != nil {
+			t.Fatal(err)
+		}
+		var mismatched bool
+		for _, pod := range pods {
+			if mismatched, err = spc.PodClaimIsMismatched(set, pod); err != nil {
+				t.Fatal(err)
+			} else if mismatched {
+				break
+			}
+		}
+		if len(pods) == 3 && !mismatched {
+			break
+		}
+	}
+
+	if len(pods) != 3 {
+		t.Fatalf("Expected 3 pods, got %d", len(pods))
+	}
+	for _, pod := range pods {
+		if getPodRevision(pod) != oldRevision {
+			t.Fatalf("Expected pod %s kept in revision %s, got %s", pod.Name, oldRevision, getPodRevision(pod))
+		}
+		if _, ok := pod.Annotations[appspub.InPlaceUpdateStateKey]; ok {
+			t.Fatalf("Expected pod %s recreated instead of in-place update", pod.Name)
+		}
+		for _, claim := range getPersistentVolumeClaims(set, pod) {
+			pvc, err := spc.claimsLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := pvc.Spec.Resources.Requests[v1.ResourceStorage]; size.Cmp(newSize) != 0 {
+				t.Fatalf("Expected PVC %s recreated with size %s, got %s", pvc.Name, newSize.String(), size.String())
+			}
+		}
+	}
+}
+
 func TestStatefulSetControlLifecycleHook(t *testing.T) {
 	set := burst(newStatefulSet(3))
 	var partition int32 = 2
@@ -2769,6 +2848,79 @@ func (spc *fakeStatefulPodControl) DeleteStatefulPod(set *appsv1beta1.StatefulSe
 	return nil
 }
 
+func (spc *fakeStatefulPodControl) ClaimsMatchRetentionPolicy(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.claimsLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		if err != nil {
+			continue
+		}
+		if !claimOwnerMatchesSetAndPod(pvc, set, pod) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (spc *fakeStatefulPodControl) UpdatePodClaimForRetentionPolicy(set *appsv1beta1.StatefulSet, pod *v1.Pod) error {
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.claimsLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		if err != nil {
+			continue
+		}
+		pvc = pvc.DeepCopy()
+		if updateClaimOwnerRefForSetAndPod(pvc, set, pod) {
+			if err := spc.claimsIndexer.Update(pvc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (spc *fakeStatefulPodControl) PodClaimIsStale(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
+	if getPersistentVolumeClaimRetentionPolicy(set).WhenScaled == appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType {
+		return false, nil
+	}
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.claimsLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		if err != nil {
+			continue
+		}
+		if hasStaleOwnerRef(pvc, pod) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (spc *fakeStatefulPodControl) PodClaimIsMismatched(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.claimsLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		if err != nil {
+			continue
+		}
+		if !claimMatchesTemplate(pvc, &claim) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (spc *fakeStatefulPodControl) DeleteMismatchedPersistentVolumeClaims(set *appsv1beta1.StatefulSet, pod *v1.Pod) error {
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvc, err := spc.claimsLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		if err != nil {
+			continue
+		}
+		if !claimMatchesTemplate(pvc, &claim) {
+			if err := spc.claimsIndexer.Delete(pvc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 var _ StatefulPodControlInterface = &fakeStatefulPodControl{}
 
 type fakeStatefulSetStatusUpdater struct {
@@ -28,16 +28,19 @@ import (
 
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/pkg/controller"
 	"k8s.io/kubernetes/pkg/controller/history"
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	utilapi "github.com/openkruise/kruise/pkg/util/api"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
 )
 
@@ -167,6 +170,192 @@ func updateStorage(set *appsv1beta1.StatefulSet, pod *v1.Pod) {
 	pod.Spec.Volumes = newVolumes
 }
 
+// getPersistentVolumeClaimRetentionPolicy returns the PVC retention policy for a StatefulSet, returning a retain policy if the set policy is nil.
+func getPersistentVolumeClaimRetentionPolicy(set *appsv1beta1.StatefulSet) appsv1beta1.StatefulSetPersistentVolumeClaimRetentionPolicy {
+	policy := appsv1beta1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if set.Spec.PersistentVolumeClaimRetentionPolicy != nil {
+		if set.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted != "" {
+			policy.WhenDeleted = set.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted
+		}
+		if set.Spec.PersistentVolumeClaimRetentionPolicy.WhenScaled != "" {
+			policy.WhenScaled = set.Spec.PersistentVolumeClaimRetentionPolicy.WhenScaled
+		}
+	}
+	return policy
+}
+
+// isPodScaledDown returns true if the ordinal of pod is out of the ordinal range or in reserveOrdinals of set,
+// which means the pod is condemned to be scaled down.
+func isPodScaledDown(set *appsv1beta1.StatefulSet, pod *v1.Pod) bool {
+	return !utilapi.GetValidOrdinals(set).Has(getOrdinal(pod))
+}
+
+// claimOwnerMatchesSetAndPod returns false if the ownerRefs of the claim are not set consistently with the
+// PVC deletion policy for the StatefulSet.
+func claimOwnerMatchesSetAndPod(claim *v1.PersistentVolumeClaim, set *appsv1beta1.StatefulSet, pod *v1.Pod) bool {
+	policy := getPersistentVolumeClaimRetentionPolicy(set)
+	const retain = appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType
+	const delete = appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType
+	switch {
+	default:
+		klog.Errorf("Unknown policy %v; treating as Retain", set.Spec.PersistentVolumeClaimRetentionPolicy)
+		fallthrough
+	case policy.WhenScaled == retain && policy.WhenDeleted == retain:
+		if hasOwnerRef(claim, set) || hasOwnerRef(claim, pod) {
+			return false
+		}
+	case policy.WhenScaled == retain && policy.WhenDeleted == delete:
+		if !hasOwnerRef(claim, set) || hasOwnerRef(claim, pod) {
+			return false
+		}
+	case policy.WhenScaled == delete && policy.WhenDeleted == retain:
+		if hasOwnerRef(claim, set) {
+			return false
+		}
+		podScaledDown := isPodScaledDown(set, pod)
+		if podScaledDown != hasOwnerRef(claim, pod) {
+			return false
+		}
+	case policy.WhenScaled == delete && policy.WhenDeleted == delete:
+		podScaledDown := isPodScaledDown(set, pod)
+		// If a pod is scaled down, there should be no set ref and a pod ref;
+		// if the pod is not scaled down it's the other way around.
+		if podScaledDown == hasOwnerRef(claim, set) {
+			return false
+		}
+		if podScaledDown != hasOwnerRef(claim, pod) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateClaimOwnerRefForSetAndPod updates the ownerRefs for the claim according to the deletion policy of
+// the StatefulSet. Returns true if the claim was changed and should be updated and false otherwise.
+func updateClaimOwnerRefForSetAndPod(claim *v1.PersistentVolumeClaim, set *appsv1beta1.StatefulSet, pod *v1.Pod) bool {
+	needsUpdate := false
+	// The version and kind are not always set in TypeMeta of pod and set, which are necessary for the ownerRef.
+	podMeta := metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+	setMeta := metav1.TypeMeta{APIVersion: appsv1beta1.SchemeGroupVersion.String(), Kind: "StatefulSet"}
+	policy := getPersistentVolumeClaimRetentionPolicy(set)
+	const retain = appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType
+	const delete = appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType
+	switch {
+	default:
+		klog.Errorf("Unknown policy %v, treating as Retain", set.Spec.PersistentVolumeClaimRetentionPolicy)
+		fallthrough
+	case policy.WhenScaled == retain && policy.WhenDeleted == retain:
+		needsUpdate = removeOwnerRef(claim, set) || needsUpdate
+		needsUpdate = removeOwnerRef(claim, pod) || needsUpdate
+	case policy.WhenScaled == retain && policy.WhenDeleted == delete:
+		needsUpdate = setOwnerRef(claim, set, &setMeta) || needsUpdate
+		needsUpdate = removeOwnerRef(claim, pod) || needsUpdate
+	case policy.WhenScaled == delete && policy.WhenDeleted == retain:
+		needsUpdate = removeOwnerRef(claim, set) || needsUpdate
+		if isPodScaledDown(set, pod) {
+			needsUpdate = setOwnerRef(claim, pod, &podMeta) || needsUpdate
+		} else {
+			needsUpdate = removeOwnerRef(claim, pod) || needsUpdate
+		}
+	case policy.WhenScaled == delete && policy.WhenDeleted == delete:
+		if isPodScaledDown(set, pod) {
+			needsUpdate = removeOwnerRef(claim, set) || needsUpdate
+			needsUpdate = setOwnerRef(claim, pod, &podMeta) || needsUpdate
+		} else {
+			needsUpdate = setOwnerRef(claim, set, &setMeta) || needsUpdate
+			needsUpdate = removeOwnerRef(claim, pod) || needsUpdate
+		}
+	}
+	return needsUpdate
+}
+
+// hasOwnerRef returns true if target has an ownerRef to owner.
+func hasOwnerRef(target, owner metav1.Object) bool {
+	ownerUID := owner.GetUID()
+	for _, ownerRef := range target.GetOwnerReferences() {
+		if ownerRef.UID == ownerUID {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStaleOwnerRef returns true if target has a ref to owner that appears to be stale.
+func hasStaleOwnerRef(target, owner metav1.Object) bool {
+	for _, ownerRef := range target.GetOwnerReferences() {
+		if ownerRef.Name == owner.GetName() && ownerRef.UID != owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+// setOwnerRef adds owner to the ownerRefs of target, if necessary. Returns true if target needs to be
+// updated and false otherwise.
+func setOwnerRef(target, owner metav1.Object, ownerType *metav1.TypeMeta) bool {
+	if hasOwnerRef(target, owner) {
+		return false
+	}
+	ownerRefs := append(
+		target.GetOwnerReferences(),
+		metav1.OwnerReference{
+			APIVersion: ownerType.APIVersion,
+			Kind:       ownerType.Kind,
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+		})
+	target.SetOwnerReferences(ownerRefs)
+	return true
+}
+
+// removeOwnerRef removes owner from the ownerRefs of target, if necessary. Returns true if target needs
+// to be updated and false otherwise.
+func removeOwnerRef(target, owner metav1.Object) bool {
+	if !hasOwnerRef(target, owner) {
+		return false
+	}
+	ownerUID := owner.GetUID()
+	oldRefs := target.GetOwnerReferences()
+	newRefs := make([]metav1.OwnerReference, len(oldRefs)-1)
+	skip := 0
+	for i := range oldRefs {
+		if oldRefs[i].UID == ownerUID {
+			skip = -1
+		} else {
+			newRefs[i+skip] = oldRefs[i]
+		}
+	}
+	target.SetOwnerReferences(newRefs)
+	return true
+}
+
+// isVolumeClaimRecreateEnabled returns true if the claims that mismatch the templates should be recreated with pod.
+func isVolumeClaimRecreateEnabled(set *appsv1beta1.StatefulSet) bool {
+	return set.Spec.VolumeClaimUpdateStrategy != nil &&
+		set.Spec.VolumeClaimUpdateStrategy.Type == appsv1beta1.RecreateVolumeClaimUpdateStrategyType
+}
+
+// claimMatchesTemplate returns true if the storage class, access modes and resource requests of claim
+// are consistent with the template.
+func claimMatchesTemplate(claim, template *v1.PersistentVolumeClaim) bool {
+	if template.Spec.StorageClassName != nil &&
+		(claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName != *template.Spec.StorageClassName) {
+		return false
+	}
+	if len(template.Spec.AccessModes) > 0 && !apiequality.Semantic.DeepEqual(claim.Spec.AccessModes, template.Spec.AccessModes) {
+		return false
+	}
+	for name, quantity := range template.Spec.Resources.Requests {
+		if current, ok := claim.Spec.Resources.Requests[name]; !ok || current.Cmp(quantity) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func initIdentity(set *appsv1beta1.StatefulSet, pod *v1.Pod) {
 	updateIdentity(set, pod)
 	// Set these immutable fields only on initial Pod creation, not updates.
@@ -194,6 +383,10 @@ func isRunningAndAvailable(pod *v1.Pod, minReadySeconds int32) (bool, time.Durat
 	if pod.Status.Phase != v1.PodRunning || !podutil.IsPodReady(pod) {
 		return false, 0
 	}
+	// Pods waiting for PreNormal hook should not be counted as available
+	if lifecycle.GetPodLifecycleState(pod) == appspub.LifecycleStatePreparingNormal {
+		return false, 0
+	}
 	c := podutil.GetPodReadyCondition(pod.Status)
 	minReadySecondsDuration := time.Duration(minReadySeconds) * time.Second
 	if minReadySeconds == 0 {
@@ -300,16 +493,19 @@ func isCurrentRevisionNeeded(set *appsv1beta1.StatefulSet, updateRevision string
 	if set.Spec.UpdateStrategy.Type != apps.RollingUpdateStatefulSetStrategyType {
 		return false
 	}
+	// the partition and current replicas are compared with the index of Pod in replicas,
+	// which starts from the start ordinal
+	index := ordinal - utilapi.GetStartOrdinal(set)
 	if set.Spec.UpdateStrategy.RollingUpdate == nil {
-		return ordinal < int(set.Status.CurrentReplicas)
+		return index < int(set.Status.CurrentReplicas)
 	}
 	if set.Spec.UpdateStrategy.RollingUpdate.UnorderedUpdate == nil {
-		return ordinal < int(*set.Spec.UpdateStrategy.RollingUpdate.Partition)
+		return index < int(*set.Spec.UpdateStrategy.RollingUpdate.Partition)
 	}
 
 	var noUpdatedReplicas int
 	for i, pod := range replicas {
-		if pod == nil || i == ordinal {
+		if pod == nil || i == index {
 			continue
 		}
 		if getPodRevision(pod) != updateRevision {
@@ -332,6 +528,8 @@ func Match(ss *appsv1beta1.StatefulSet, history *apps.ControllerRevision) (bool,
 // previous version. If the returned error is nil the patch is valid. The current state that we save is just the
 // PodSpecTemplate. We can modify this later to encompass more state (or less) and remain compatible with previously
 // recorded patches.
+// The volumeClaimTemplates are not saved, so that the revision does not depend on the volumeClaimUpdateStrategy.
+// The claims mismatching the templates are checked against the Pods directly when they should be recreated.
 func getPatch(set *appsv1beta1.StatefulSet) ([]byte, error) {
 	str, err := runtime.Encode(patchCodec, set)
 	if err != nil {
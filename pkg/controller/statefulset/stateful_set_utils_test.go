@@ -32,11 +32,16 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/pkg/controller/history"
 	utilpointer "k8s.io/utils/pointer"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	"github.com/openkruise/kruise/pkg/util/lifecycle"
 )
 
 // overlappingStatefulSets sorts a list of StatefulSets by creation timestamp, using their names as a tie breaker.
@@ -270,6 +275,11 @@ func TestIsRunningAndAvailable(t *testing.T) {
 		t.Errorf("isRunningAndAvailable does not respect Pod condition  last transaction, avail = %t, wait = %d",
 			avail, wait)
 	}
+	lifecycle.SetPodLifecycle(appspub.LifecycleStatePreparingNormal)(pod)
+	if avail, wait := isRunningAndAvailable(pod, 3); avail || wait != 0 {
+		t.Errorf("isRunningAndAvailable does not respect PreparingNormal lifecycle state, avail = %t, wait = %d",
+			avail, wait)
+	}
 }
 
 func TestAscendingOrdinal(t *testing.T) {
@@ -364,6 +374,25 @@ func TestCreateApplyRevision(t *testing.T) {
 	}
 }
 
+func TestRevisionIndependentOfVolumeClaimUpdateStrategy(t *testing.T) {
+	set := newStatefulSet(1)
+	set.Status.CollisionCount = new(int32)
+	revision, err := newRevision(set, 1, set.Status.CollisionCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set = set.DeepCopy()
+	set.Spec.VolumeClaimUpdateStrategy = &appsv1beta1.VolumeClaimUpdateStrategy{Type: appsv1beta1.RecreateVolumeClaimUpdateStrategyType}
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse("2Gi")
+	recreateRevision, err := newRevision(set, 2, set.Status.CollisionCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !history.EqualRevision(revision, recreateRevision) {
+		t.Errorf("wanted %v got %v", string(revision.Data.Raw), string(recreateRevision.Data.Raw))
+	}
+}
+
 func TestRollingUpdateApplyRevision(t *testing.T) {
 	set := newStatefulSet(1)
 	set.Status.CollisionCount = new(int32)
@@ -397,6 +426,161 @@ func TestRollingUpdateApplyRevision(t *testing.T) {
 	}
 }
 
+func TestIsPodScaledDown(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.ReserveOrdinals = []intstr.IntOrString{intstr.FromInt(1)}
+	expected := map[int]bool{0: false, 1: true, 2: false, 3: false, 4: true}
+	for ordinal, scaledDown := range expected {
+		if got := isPodScaledDown(set, newStatefulSetPod(set, ordinal)); got != scaledDown {
+			t.Errorf("ordinal %d: expected scaled down %v, got %v", ordinal, scaledDown, got)
+		}
+	}
+
+	defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.StatefulSetStartOrdinal))
+	_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.StatefulSetStartOrdinal))
+	set.Spec.Ordinals = &appsv1beta1.StatefulSetOrdinals{Start: 2}
+	set.Spec.ReserveOrdinals = []intstr.IntOrString{intstr.FromString("3-4")}
+	expected = map[int]bool{0: true, 1: true, 2: false, 3: true, 4: true, 5: false, 6: false, 7: true}
+	for ordinal, scaledDown := range expected {
+		if got := isPodScaledDown(set, newStatefulSetPod(set, ordinal)); got != scaledDown {
+			t.Errorf("ordinal %d with start ordinal: expected scaled down %v, got %v", ordinal, scaledDown, got)
+		}
+	}
+}
+
+func TestIsCurrentRevisionNeededWithStartOrdinal(t *testing.T) {
+	defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.StatefulSetStartOrdinal))
+	_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.StatefulSetStartOrdinal))
+
+	set := newStatefulSet(3)
+	set.Spec.Ordinals = &appsv1beta1.StatefulSetOrdinals{Start: 5}
+	set.Spec.UpdateStrategy = appsv1beta1.StatefulSetUpdateStrategy{
+		Type:          apps.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1beta1.RollingUpdateStatefulSetStrategy{Partition: utilpointer.Int32Ptr(1)},
+	}
+	expected := map[int]bool{5: true, 6: false, 7: false}
+	for ordinal, needed := range expected {
+		if got := isCurrentRevisionNeeded(set, "update", ordinal, make([]*v1.Pod, 3)); got != needed {
+			t.Errorf("ordinal %d: expected current revision needed %v, got %v", ordinal, needed, got)
+		}
+	}
+
+	set.Spec.UpdateStrategy.RollingUpdate = nil
+	set.Status.CurrentReplicas = 2
+	expected = map[int]bool{5: true, 6: true, 7: false}
+	for ordinal, needed := range expected {
+		if got := isCurrentRevisionNeeded(set, "update", ordinal, make([]*v1.Pod, 3)); got != needed {
+			t.Errorf("ordinal %d without rollingUpdate: expected current revision needed %v, got %v", ordinal, needed, got)
+		}
+	}
+}
+
+func TestClaimOwnerMatchesSetAndPod(t *testing.T) {
+	const retain = appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType
+	const delete = appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType
+	cases := []struct {
+		name          string
+		whenDeleted   appsv1beta1.PersistentVolumeClaimRetentionPolicyType
+		whenScaled    appsv1beta1.PersistentVolumeClaimRetentionPolicyType
+		scaledDown    bool
+		expectSetRef  bool
+		expectPodRef  bool
+		nilRetentions bool
+	}{
+		{name: "nil policy", nilRetentions: true},
+		{name: "retain, retain", whenDeleted: retain, whenScaled: retain},
+		{name: "delete, retain", whenDeleted: delete, whenScaled: retain, expectSetRef: true},
+		{name: "retain, delete, not scaled", whenDeleted: retain, whenScaled: delete},
+		{name: "retain, delete, scaled", whenDeleted: retain, whenScaled: delete, scaledDown: true, expectPodRef: true},
+		{name: "delete, delete, not scaled", whenDeleted: delete, whenScaled: delete, expectSetRef: true},
+		{name: "delete, delete, scaled", whenDeleted: delete, whenScaled: delete, scaledDown: true, expectPodRef: true},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			if !cs.nilRetentions {
+				set.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1beta1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+					WhenDeleted: cs.whenDeleted,
+					WhenScaled:  cs.whenScaled,
+				}
+			}
+			ordinal := 1
+			if cs.scaledDown {
+				ordinal = 5
+			}
+			pod := newStatefulSetPod(set, ordinal)
+			pod.UID = types.UID("pod-uid")
+			claim := getPersistentVolumeClaims(set, pod)["datadir"]
+			// the claims of pod and set with all ownerRefs should be updated to match the policy
+			setOwnerRef(&claim, set, &set.TypeMeta)
+			setOwnerRef(&claim, pod, &metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"})
+			expectMatch := cs.expectSetRef && cs.expectPodRef
+			if claimOwnerMatchesSetAndPod(&claim, set, pod) != expectMatch {
+				t.Fatalf("expected match %v before update", expectMatch)
+			}
+			if !updateClaimOwnerRefForSetAndPod(&claim, set, pod) {
+				t.Fatalf("expected claim to be updated")
+			}
+			if !claimOwnerMatchesSetAndPod(&claim, set, pod) {
+				t.Fatalf("expected match after update, got ownerRefs %v", claim.OwnerReferences)
+			}
+			if hasOwnerRef(&claim, set) != cs.expectSetRef || hasOwnerRef(&claim, pod) != cs.expectPodRef {
+				t.Fatalf("unexpected ownerRefs %v", claim.OwnerReferences)
+			}
+			if updateClaimOwnerRefForSetAndPod(&claim, set, pod) {
+				t.Fatalf("expected no more update")
+			}
+		})
+	}
+}
+
+func TestHasStaleOwnerRef(t *testing.T) {
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 1)
+	pod.UID = types.UID("new-uid")
+	claim := getPersistentVolumeClaims(set, pod)["datadir"]
+	if hasStaleOwnerRef(&claim, pod) {
+		t.Fatalf("expected claim without ownerRefs not to be stale")
+	}
+	stalePod := pod.DeepCopy()
+	stalePod.UID = types.UID("old-uid")
+	setOwnerRef(&claim, stalePod, &metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"})
+	if !hasStaleOwnerRef(&claim, pod) {
+		t.Fatalf("expected claim owned by old pod to be stale")
+	}
+	if !removeOwnerRef(&claim, stalePod) || len(claim.OwnerReferences) != 0 {
+		t.Fatalf("expected ownerRef of old pod removed, got %v", claim.OwnerReferences)
+	}
+}
+
+func TestClaimMatchesTemplate(t *testing.T) {
+	template := newPVC("datadir")
+	template.Spec.StorageClassName = utilpointer.StringPtr("ssd")
+	template.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+
+	claim := template.DeepCopy()
+	if !claimMatchesTemplate(claim, &template) {
+		t.Fatalf("expected claim matches template")
+	}
+	claim.Spec.Resources.Requests = v1.ResourceList{v1.ResourceStorage: resource.MustParse("1")}
+	if !claimMatchesTemplate(claim, &template) {
+		t.Fatalf("expected claim with the same quantity matches template")
+	}
+
+	claim = template.DeepCopy()
+	claim.Spec.StorageClassName = utilpointer.StringPtr("hdd")
+	if claimMatchesTemplate(claim, &template) {
+		t.Fatalf("expected claim with different storage class mismatches template")
+	}
+
+	claim = template.DeepCopy()
+	claim.Spec.Resources.Requests = v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")}
+	if claimMatchesTemplate(claim, &template) {
+		t.Fatalf("expected claim with different size mismatches template")
+	}
+}
+
 func newPVC(name string) v1.PersistentVolumeClaim {
 	return v1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
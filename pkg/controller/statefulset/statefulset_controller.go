@@ -35,6 +35,7 @@ import (
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 	"github.com/openkruise/kruise/pkg/util/requeueduration"
 	"github.com/openkruise/kruise/pkg/util/revisionadapter"
@@ -233,6 +234,7 @@ func (ssc *ReconcileStatefulSet) Reconcile(_ context.Context, request reconcile.
 
 	startTime := time.Now()
 	defer func() {
+		metrics.ObserveReconcile("statefulset", startTime, retErr)
 		if retErr == nil {
 			if res.Requeue || res.RequeueAfter > 0 {
 				klog.Infof("Finished syncing statefulset %q (%v), result: %v", key, time.Since(startTime), res)
@@ -248,6 +250,7 @@ func (ssc *ReconcileStatefulSet) Reconcile(_ context.Context, request reconcile.
 	if errors.IsNotFound(err) {
 		klog.Infof("StatefulSet has been deleted %v", key)
 		updateExpectations.DeleteExpectations(key)
+		metrics.DeleteWorkloadMetrics("StatefulSet", namespace, name)
 		return reconcile.Result{}, nil
 	}
 	if err != nil {
@@ -17,11 +17,13 @@ limitations under the License.
 package adapter
 
 import (
+	"encoding/json"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 )
@@ -75,6 +77,28 @@ func attachTolerations(podSpec *corev1.PodSpec, subsetConfig *appsv1alpha1.Subse
 	podSpec.Tolerations = append(podSpec.Tolerations, subsetConfig.Tolerations...)
 }
 
+func applySubsetPatch(template *corev1.PodTemplateSpec, subsetConfig *appsv1alpha1.Subset) error {
+	if subsetConfig.Patch.Raw == nil {
+		return nil
+	}
+
+	templateBytes, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	modified, err := strategicpatch.StrategicMergePatch(templateBytes, subsetConfig.Patch.Raw, &corev1.PodTemplateSpec{})
+	if err != nil {
+		return fmt.Errorf("fail to apply patch of subset %s: %v", subsetConfig.Name, err)
+	}
+
+	patched := corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(modified, &patched); err != nil {
+		return err
+	}
+	*template = patched
+	return nil
+}
+
 func getRevision(objMeta metav1.Object) string {
 	if objMeta.GetLabels() == nil {
 		return ""
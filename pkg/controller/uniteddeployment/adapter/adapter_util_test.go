@@ -21,7 +21,9 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 )
@@ -63,6 +65,58 @@ func TestGetCurrentPartitionForStrategyOnDelete(t *testing.T) {
 	}
 }
 
+func TestApplySubsetPatch(t *testing.T) {
+	template := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"app": "demo"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "main",
+					Image: "nginx:alpine",
+					Env:   []corev1.EnvVar{{Name: "A", Value: "a"}},
+				},
+				{
+					Name:  "sidecar",
+					Image: "busybox:latest",
+				},
+			},
+		},
+	}
+	subset := &appsv1alpha1.Subset{
+		Name: "spot",
+		Patch: runtime.RawExtension{
+			Raw: []byte(`{"metadata":{"labels":{"node-type":"spot"}},"spec":{"priorityClassName":"low","containers":[{"name":"main","resources":{"requests":{"cpu":"100m"}},"env":[{"name":"B","value":"b"}]}]}}`),
+		},
+	}
+
+	if err := applySubsetPatch(template, subset); err != nil {
+		t.Fatalf("failed to apply subset patch: %v", err)
+	}
+	if template.Labels["app"] != "demo" || template.Labels["node-type"] != "spot" {
+		t.Fatalf("unexpected labels %v", template.Labels)
+	}
+	if template.Spec.PriorityClassName != "low" {
+		t.Fatalf("expected priorityClassName low, got %s", template.Spec.PriorityClassName)
+	}
+	if len(template.Spec.Containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(template.Spec.Containers))
+	}
+	mainContainer := template.Spec.Containers[0]
+	if mainContainer.Image != "nginx:alpine" || len(mainContainer.Env) != 2 {
+		t.Fatalf("unexpected main container %v", mainContainer)
+	}
+	if !mainContainer.Resources.Requests.Cpu().Equal(resource.MustParse("100m")) {
+		t.Fatalf("unexpected cpu request %v", mainContainer.Resources.Requests.Cpu())
+	}
+
+	subset.Patch = runtime.RawExtension{Raw: []byte(`{"spec":{"containers":"invalid"}}`)}
+	if err := applySubsetPatch(template, subset); err == nil {
+		t.Fatalf("expected error for invalid patch")
+	}
+}
+
 func buildPodList(ordinals []int, revisions []string, t *testing.T) []*corev1.Pod {
 	if len(ordinals) != len(revisions) {
 		t.Fatalf("ordinals count should equals to revision count")
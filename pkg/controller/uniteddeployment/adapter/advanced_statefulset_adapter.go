@@ -82,6 +82,11 @@ func (a *AdvancedStatefulSetAdapter) GetReplicaDetails(obj metav1.Object, update
 	return
 }
 
+// GetSubsetPods returns all the pods of the subset.
+func (a *AdvancedStatefulSetAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getStatefulSetPods(obj.(*alpha1.StatefulSet))
+}
+
 // GetSubsetFailure returns the failure information of the subset.
 // AdvancedStatefulSet has no condition.
 func (a *AdvancedStatefulSetAdapter) GetSubsetFailure() *string {
@@ -158,6 +163,9 @@ func (a *AdvancedStatefulSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeploy
 	}
 
 	set.Spec.Template = *ud.Spec.Template.AdvancedStatefulSetTemplate.Spec.Template.DeepCopy()
+	if err := applySubsetPatch(&set.Spec.Template, subSetConfig); err != nil {
+		return err
+	}
 	if set.Spec.Template.Labels == nil {
 		set.Spec.Template.Labels = map[string]string{}
 	}
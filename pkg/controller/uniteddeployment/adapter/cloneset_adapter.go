@@ -63,6 +63,11 @@ func (a *CloneSetAdapter) GetReplicaDetails(obj metav1.Object, updatedRevision s
 	return
 }
 
+// GetSubsetPods returns all the pods of the subset.
+func (a *CloneSetAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getCloneSetPods(obj.(*alpha1.CloneSet))
+}
+
 func (a *CloneSetAdapter) GetSubsetFailure() *string {
 	return nil
 }
@@ -122,6 +127,9 @@ func (a *CloneSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, subse
 	set.Spec.UpdateStrategy.Partition = util.GetIntOrStrPointer(intstr.FromInt(int(partition)))
 
 	set.Spec.Template = *ud.Spec.Template.CloneSetTemplate.Spec.Template.DeepCopy()
+	if err := applySubsetPatch(&set.Spec.Template, subSetConfig); err != nil {
+		return err
+	}
 
 	if set.Spec.Template.Labels == nil {
 		set.Spec.Template.Labels = map[string]string{}
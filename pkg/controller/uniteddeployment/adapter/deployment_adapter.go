@@ -74,6 +74,11 @@ func (a *DeploymentAdapter) GetReplicaDetails(obj metav1.Object, updatedRevision
 	return
 }
 
+// GetSubsetPods returns all the pods of the subset.
+func (a *DeploymentAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getDeploymentPods(obj.(*appsv1.Deployment))
+}
+
 // GetSubsetFailure returns the failure information of the subset.
 // Deployment has no condition.
 func (a *DeploymentAdapter) GetSubsetFailure() *string {
@@ -136,6 +141,9 @@ func (a *DeploymentAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, sub
 	set.Spec.Selector = selectors
 	set.Spec.Replicas = &replicas
 	set.Spec.Template = *ud.Spec.Template.DeploymentTemplate.Spec.Template.DeepCopy()
+	if err := applySubsetPatch(&set.Spec.Template, subSetConfig); err != nil {
+		return err
+	}
 	if set.Spec.Template.Labels == nil {
 		set.Spec.Template.Labels = map[string]string{}
 	}
@@ -0,0 +1,318 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+// GenericAdapter implements the Adapter interface for any workload kind described by GenericTemplateSpec.
+// The workload is operated as an unstructured object, and its fields are located by the paths in the template.
+type GenericAdapter struct {
+	client.Client
+
+	Scheme   *runtime.Scheme
+	Template *alpha1.GenericTemplateSpec
+}
+
+// GroupVersionKind returns the GroupVersionKind of the generic workload.
+func (a *GenericAdapter) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(a.Template.APIVersion, a.Template.Kind)
+}
+
+// NewResourceObject creates a empty generic workload object.
+func (a *GenericAdapter) NewResourceObject() client.Object {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(a.GroupVersionKind())
+	return obj
+}
+
+// NewResourceListObject creates a empty generic workload list object.
+func (a *GenericAdapter) NewResourceListObject() client.ObjectList {
+	gvk := a.GroupVersionKind()
+	gvk.Kind = gvk.Kind + "List"
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	return list
+}
+
+// GetStatusObservedGeneration returns the observed generation of the subset.
+func (a *GenericAdapter) GetStatusObservedGeneration(obj metav1.Object) int64 {
+	generation, _, _ := getNestedInt64(obj.(*unstructured.Unstructured).Object,
+		pathOrDefault(a.Template.Paths.ObservedGenerationPath, alpha1.DefaultGenericObservedGenerationPath))
+	return generation
+}
+
+// GetReplicaDetails returns the replicas detail the subset needs.
+func (a *GenericAdapter) GetReplicaDetails(obj metav1.Object, updatedRevision string) (specReplicas, specPartition *int32, statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas int32, err error) {
+	set := obj.(*unstructured.Unstructured)
+	var pods []*corev1.Pod
+	pods, err = a.GetSubsetPods(set)
+	if err != nil {
+		return
+	}
+
+	paths := a.Template.Paths
+	var value int64
+	var found bool
+	if value, found, err = getNestedInt64(set.Object, pathOrDefault(paths.ReplicasPath, alpha1.DefaultGenericReplicasPath)); err != nil {
+		return
+	} else if found {
+		replicas := int32(value)
+		specReplicas = &replicas
+	}
+	if paths.PartitionPath != "" {
+		if value, found, err = getNestedInt64(set.Object, paths.PartitionPath); err != nil {
+			return
+		} else if found {
+			partition := int32(value)
+			specPartition = &partition
+		}
+	}
+	if value, _, err = getNestedInt64(set.Object, pathOrDefault(paths.StatusReplicasPath, alpha1.DefaultGenericStatusReplicasPath)); err != nil {
+		return
+	}
+	statusReplicas = int32(value)
+	if value, _, err = getNestedInt64(set.Object, pathOrDefault(paths.StatusReadyReplicasPath, alpha1.DefaultGenericStatusReadyReplicasPath)); err != nil {
+		return
+	}
+	statusReadyReplicas = int32(value)
+	statusUpdatedReplicas, statusUpdatedReadyReplicas = calculateUpdatedReplicas(pods, updatedRevision)
+
+	return
+}
+
+// GetSubsetPods returns all the pods of the subset, which are selected by the selector of the workload.
+func (a *GenericAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	set := obj.(*unstructured.Unstructured)
+	rawSelector, found, err := unstructured.NestedMap(set.Object, splitPath(pathOrDefault(a.Template.Paths.SelectorPath, alpha1.DefaultGenericSelectorPath))...)
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("selector not found in %s %s/%s", set.GetKind(), set.GetNamespace(), set.GetName())
+	}
+	labelSelector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSelector, labelSelector); err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	podList := &corev1.PodList{}
+	if err := a.Client.List(context.TODO(), podList, &client.ListOptions{Namespace: set.GetNamespace(), LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+	return pods, nil
+}
+
+// GetSubsetFailure returns the failure information of the subset.
+func (a *GenericAdapter) GetSubsetFailure() *string {
+	return nil
+}
+
+// ApplySubsetTemplate updates the subset to the latest revision, depending on the GenericTemplate.
+func (a *GenericAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, subsetName, revision string, replicas, partition int32, obj runtime.Object) error {
+	set := obj.(*unstructured.Unstructured)
+
+	var subSetConfig *alpha1.Subset
+	for _, subset := range ud.Spec.Topology.Subsets {
+		if subset.Name == subsetName {
+			subSetConfig = &subset
+			break
+		}
+	}
+	if subSetConfig == nil {
+		return fmt.Errorf("fail to find subset config %s", subsetName)
+	}
+
+	set.SetGroupVersionKind(a.GroupVersionKind())
+	set.SetNamespace(ud.Namespace)
+
+	labels := set.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range a.Template.Labels {
+		labels[k] = v
+	}
+	for k, v := range ud.Spec.Selector.MatchLabels {
+		labels[k] = v
+	}
+	labels[alpha1.ControllerRevisionHashLabelKey] = revision
+	// record the subset name as a label
+	labels[alpha1.SubSetNameLabelKey] = subsetName
+	set.SetLabels(labels)
+
+	annotations := set.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range a.Template.Annotations {
+		annotations[k] = v
+	}
+	set.SetAnnotations(annotations)
+
+	set.SetGenerateName(getSubsetPrefix(ud.Name, subsetName))
+
+	if err := controllerutil.SetControllerReference(ud, set, a.Scheme); err != nil {
+		return err
+	}
+
+	spec := map[string]interface{}{}
+	if a.Template.Spec.Raw != nil {
+		if err := json.Unmarshal(a.Template.Spec.Raw, &spec); err != nil {
+			return fmt.Errorf("fail to unmarshal spec of generic template: %v", err)
+		}
+	}
+	paths := a.Template.Paths
+	// the pod template is replaced by the one in generic template, so that the fields removed from it are removed from the workload
+	templatePath := splitPath(pathOrDefault(paths.TemplatePath, alpha1.DefaultGenericTemplatePath))
+	rawTemplate, _, err := unstructured.NestedMap(map[string]interface{}{"spec": spec}, templatePath...)
+	if err != nil {
+		return err
+	}
+	existingSpec, _, _ := unstructured.NestedMap(set.Object, "spec")
+	set.Object["spec"] = mergeGenericSpec(existingSpec, spec)
+
+	selectors := ud.Spec.Selector.DeepCopy()
+	selectors.MatchLabels[alpha1.SubSetNameLabelKey] = subsetName
+	rawSelector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(selectors)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedMap(set.Object, rawSelector, splitPath(pathOrDefault(paths.SelectorPath, alpha1.DefaultGenericSelectorPath))...); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(set.Object, int64(replicas), splitPath(pathOrDefault(paths.ReplicasPath, alpha1.DefaultGenericReplicasPath))...); err != nil {
+		return err
+	}
+	if paths.PartitionPath != "" {
+		if err := unstructured.SetNestedField(set.Object, int64(partition), splitPath(paths.PartitionPath)...); err != nil {
+			return err
+		}
+	}
+
+	template := &corev1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawTemplate, template); err != nil {
+		return fmt.Errorf("fail to convert pod template of generic template: %v", err)
+	}
+	if err := applySubsetPatch(template, subSetConfig); err != nil {
+		return err
+	}
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels[alpha1.SubSetNameLabelKey] = subsetName
+	template.Labels[alpha1.ControllerRevisionHashLabelKey] = revision
+
+	attachNodeAffinity(&template.Spec, subSetConfig)
+	attachTolerations(&template.Spec, subSetConfig)
+
+	rawTemplate, err = runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedMap(set.Object, rawTemplate, templatePath...)
+}
+
+// mergeGenericSpec merges the spec of generic template into the existing spec of workload recursively, so that
+// the fields not in the template, which may be defaulted or owned by others, are kept rather than removed on every update.
+// The lists and other values in the template override the existing ones.
+func mergeGenericSpec(existing, template map[string]interface{}) map[string]interface{} {
+	if existing == nil {
+		existing = make(map[string]interface{}, len(template))
+	}
+	for k, v := range template {
+		if templateMap, ok := v.(map[string]interface{}); ok {
+			if existingMap, ok := existing[k].(map[string]interface{}); ok {
+				existing[k] = mergeGenericSpec(existingMap, templateMap)
+				continue
+			}
+		}
+		existing[k] = v
+	}
+	return existing
+}
+
+// PostUpdate does some works after subset updated.
+func (a *GenericAdapter) PostUpdate(ud *alpha1.UnitedDeployment, obj runtime.Object, revision string, partition int32) error {
+	return nil
+}
+
+// IsExpected checks the subset is the expected revision or not.
+// The revision label can tell the current subset revision.
+func (a *GenericAdapter) IsExpected(obj metav1.Object, revision string) bool {
+	return obj.GetLabels()[alpha1.ControllerRevisionHashLabelKey] != revision
+}
+
+func pathOrDefault(path, defaultPath string) string {
+	if path == "" {
+		return defaultPath
+	}
+	return path
+}
+
+// splitPath splits the dot-separated path like '.spec.replicas' into fields.
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "."), ".")
+}
+
+// getNestedInt64 returns the integer value in the path, which may be stored as integer, float or string.
+func getNestedInt64(obj map[string]interface{}, path string) (int64, bool, error) {
+	val, found, err := unstructured.NestedFieldNoCopy(obj, splitPath(path)...)
+	if err != nil || !found || val == nil {
+		return 0, false, err
+	}
+	switch v := val.(type) {
+	case int64:
+		return v, true, nil
+	case int32:
+		return int64(v), true, nil
+	case int:
+		return int64(v), true, nil
+	case float64:
+		return int64(v), true, nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%s is not an integer: %v", path, err)
+		}
+		return i, true, nil
+	default:
+		return 0, false, fmt.Errorf("%s has unexpected type %T", path, val)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestGenericAdapter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	podTemplate := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main", Image: "nginx"}},
+		},
+	}
+	spec, _ := json.Marshal(map[string]interface{}{
+		"template": podTemplate,
+		"strategy": map[string]interface{}{"canary": map[string]interface{}{}},
+	})
+	ud := &appsv1alpha1.UnitedDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "ud", Namespace: "default", UID: "ud-uid"},
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			Template: appsv1alpha1.SubsetTemplate{
+				GenericTemplate: &appsv1alpha1.GenericTemplateSpec{
+					APIVersion: "argoproj.io/v1alpha1",
+					Kind:       "Rollout",
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+					Spec:       runtime.RawExtension{Raw: spec},
+					Paths: appsv1alpha1.GenericWorkloadPaths{
+						PartitionPath: ".spec.strategy.canary.partition",
+					},
+				},
+			},
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{
+					{
+						Name: "subset-a",
+						NodeSelectorTerm: corev1.NodeSelectorTerm{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	pods := []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default", Labels: map[string]string{
+			"app": "demo", appsv1alpha1.SubSetNameLabelKey: "subset-a", appsv1alpha1.ControllerRevisionHashLabelKey: "v2"}},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "default", Labels: map[string]string{
+			"app": "demo", appsv1alpha1.SubSetNameLabelKey: "subset-a", appsv1alpha1.ControllerRevisionHashLabelKey: "v1"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-3", Namespace: "default", Labels: map[string]string{
+			"app": "demo", appsv1alpha1.SubSetNameLabelKey: "subset-b", appsv1alpha1.ControllerRevisionHashLabelKey: "v2"}}},
+	}
+	a := &GenericAdapter{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pods...).Build(),
+		Scheme:   scheme,
+		Template: ud.Spec.Template.GenericTemplate,
+	}
+
+	set := a.NewResourceObject().(*unstructured.Unstructured)
+	// the fields defaulted by the workload controller should be kept, but the removed pod template fields should not
+	_ = unstructured.SetNestedField(set.Object, int64(10), "spec", "revisionHistoryLimit")
+	_ = unstructured.SetNestedField(set.Object, "ssd", "spec", "template", "spec", "nodeSelector", "disk")
+	if err := a.ApplySubsetTemplate(ud, "subset-a", "v2", 3, 1, set); err != nil {
+		t.Fatalf("failed to apply subset template: %v", err)
+	}
+	if set.GetKind() != "Rollout" || set.GetAPIVersion() != "argoproj.io/v1alpha1" {
+		t.Fatalf("unexpected kind %s/%s", set.GetAPIVersion(), set.GetKind())
+	}
+	if set.GetLabels()[appsv1alpha1.SubSetNameLabelKey] != "subset-a" || !metav1.IsControlledBy(set, ud) {
+		t.Fatalf("unexpected metadata %v", set.GetLabels())
+	}
+	if limit, _, _ := unstructured.NestedInt64(set.Object, "spec", "revisionHistoryLimit"); limit != 10 {
+		t.Fatalf("expected revisionHistoryLimit kept, got %v", set.Object["spec"])
+	}
+	if replicas, _, _ := unstructured.NestedInt64(set.Object, "spec", "replicas"); replicas != 3 {
+		t.Fatalf("expected replicas 3, got %d", replicas)
+	}
+	if partition, _, _ := unstructured.NestedInt64(set.Object, "spec", "strategy", "canary", "partition"); partition != 1 {
+		t.Fatalf("expected partition 1, got %d", partition)
+	}
+	if subset, _, _ := unstructured.NestedString(set.Object, "spec", "selector", "matchLabels", appsv1alpha1.SubSetNameLabelKey); subset != "subset-a" {
+		t.Fatalf("expected selector of subset-a, got %v", set.Object["spec"])
+	}
+	rawTemplate, _, _ := unstructured.NestedMap(set.Object, "spec", "template")
+	template := &corev1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawTemplate, template); err != nil {
+		t.Fatalf("failed to convert pod template: %v", err)
+	}
+	if template.Labels[appsv1alpha1.ControllerRevisionHashLabelKey] != "v2" || template.Spec.Affinity == nil || template.Spec.NodeSelector != nil {
+		t.Fatalf("unexpected pod template %v", template)
+	}
+	if a.IsExpected(set, "v2") || !a.IsExpected(set, "v3") {
+		t.Fatalf("unexpected revision check")
+	}
+
+	// status of Argo Rollout may record observedGeneration as string
+	_ = unstructured.SetNestedField(set.Object, "2", "status", "observedGeneration")
+	_ = unstructured.SetNestedField(set.Object, int64(2), "status", "replicas")
+	_ = unstructured.SetNestedField(set.Object, int64(1), "status", "readyReplicas")
+	if generation := a.GetStatusObservedGeneration(set); generation != 2 {
+		t.Fatalf("expected observed generation 2, got %d", generation)
+	}
+	specReplicas, specPartition, statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas, err := a.GetReplicaDetails(set, "v2")
+	if err != nil {
+		t.Fatalf("failed to get replica details: %v", err)
+	}
+	if *specReplicas != 3 || *specPartition != 1 || statusReplicas != 2 || statusReadyReplicas != 1 ||
+		statusUpdatedReplicas != 1 || statusUpdatedReadyReplicas != 1 {
+		t.Fatalf("unexpected replica details: %d %d %d %d %d %d", *specReplicas, *specPartition,
+			statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas)
+	}
+}
@@ -80,6 +80,11 @@ func (a *StatefulSetAdapter) GetReplicaDetails(obj metav1.Object, updatedRevisio
 	return
 }
 
+// GetSubsetPods returns all the pods of the subset.
+func (a *StatefulSetAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getStatefulSetPods(obj.(*appsv1.StatefulSet))
+}
+
 // GetSubsetFailure returns the failure information of the subset.
 // StatefulSet has no condition.
 func (a *StatefulSetAdapter) GetSubsetFailure() *string {
@@ -144,6 +149,9 @@ func (a *StatefulSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, su
 	}
 
 	set.Spec.Template = *ud.Spec.Template.StatefulSetTemplate.Spec.Template.DeepCopy()
+	if err := applySubsetPatch(&set.Spec.Template, subSetConfig); err != nil {
+		return err
+	}
 	if set.Spec.Template.Labels == nil {
 		set.Spec.Template.Labels = map[string]string{}
 	}
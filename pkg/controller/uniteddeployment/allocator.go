@@ -18,9 +18,11 @@ package uniteddeployment
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -58,6 +60,10 @@ func (n subsetInfos) Swap(i, j int) {
 // Next replicas is allocated by replicasAllocator, which will consider the current replicas of each subset and
 // new replicas indicated from UnitedDeployment.Spec.Topology.Subsets.
 func GetAllocatedReplicas(nameToSubset *map[string]*Subset, ud *appsv1alpha1.UnitedDeployment) (*map[string]int32, error) {
+	if isElasticAllocation(ud) {
+		return elasticAllocate(nameToSubset, ud), nil
+	}
+
 	subsetInfos := getSubsetInfos(nameToSubset, ud)
 	specifiedReplicas := getSpecifiedSubsetReplicas(ud)
 
@@ -213,3 +219,101 @@ func (s *replicasAllocator) String() string {
 
 	return result
 }
+
+// isElasticAllocation returns true if any subset indicates its minReplicas or maxReplicas.
+func isElasticAllocation(ud *appsv1alpha1.UnitedDeployment) bool {
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		if subsetDef.MinReplicas != nil || subsetDef.MaxReplicas != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// elasticAllocate allocates the replicas to subsets in order. Each subset gets its minReplicas first,
+// then the rest replicas fill the subsets one by one up to their maxReplicas.
+// With Adaptive schedule strategy, the subsets marked as unschedulable are limited to the replicas
+// which have been scheduled, so that the rest of them will overflow into the next subsets.
+func elasticAllocate(nameToSubset *map[string]*Subset, ud *appsv1alpha1.UnitedDeployment) *map[string]int32 {
+	subsets := ud.Spec.Topology.Subsets
+	minReplicas := make([]int32, len(subsets))
+	maxReplicas := make([]int32, len(subsets))
+	limitReplicas := make([]int32, len(subsets))
+	for i, subsetDef := range subsets {
+		minReplicas[i] = parseElasticReplicas(ud, subsetDef.MinReplicas, 0)
+		maxReplicas[i] = parseElasticReplicas(ud, subsetDef.MaxReplicas, math.MaxInt32)
+		if maxReplicas[i] < minReplicas[i] {
+			maxReplicas[i] = minReplicas[i]
+		}
+		limitReplicas[i] = maxReplicas[i]
+
+		if _, unschedulable := ud.Status.UnschedulableSubsets[subsetDef.Name]; unschedulable && isAdaptiveScheduleStrategy(ud) {
+			var scheduledReplicas int32
+			if subset, exist := (*nameToSubset)[subsetDef.Name]; exist {
+				scheduledReplicas = subset.Status.Replicas - subset.Status.UnschedulableStatus.PendingPods
+			}
+			if scheduledReplicas < 0 {
+				scheduledReplicas = 0
+			}
+			if scheduledReplicas < limitReplicas[i] {
+				limitReplicas[i] = scheduledReplicas
+			}
+		}
+	}
+
+	allocated := make([]int32, len(subsets))
+	leftReplicas := *ud.Spec.Replicas
+	fill := func(bound []int32) {
+		for i := range subsets {
+			if leftReplicas <= 0 {
+				return
+			}
+			if toAllocate := bound[i] - allocated[i]; toAllocate > 0 {
+				if toAllocate > leftReplicas {
+					toAllocate = leftReplicas
+				}
+				allocated[i] += toAllocate
+				leftReplicas -= toAllocate
+			}
+		}
+	}
+	// Step 1: satisfy the minReplicas of each subset, except the unschedulable ones.
+	fill(minOf(minReplicas, limitReplicas))
+	// Step 2: overflow the rest replicas into the subsets in order.
+	fill(limitReplicas)
+	// Step 3: if the other subsets could not hold the replicas shifted from the unschedulable ones,
+	// give them back to where they come from.
+	fill(maxReplicas)
+	if leftReplicas > 0 {
+		klog.Warningf("UnitedDeployment %s/%s has %d replicas beyond the maxReplicas of all subsets", ud.Namespace, ud.Name, leftReplicas)
+	}
+
+	allocatedReplicas := map[string]int32{}
+	for i, subsetDef := range subsets {
+		allocatedReplicas[subsetDef.Name] = allocated[i]
+	}
+	return &allocatedReplicas
+}
+
+func parseElasticReplicas(ud *appsv1alpha1.UnitedDeployment, replicas *intstr.IntOrString, defaultValue int32) int32 {
+	if replicas == nil {
+		return defaultValue
+	}
+	parsed, err := ParseSubsetReplicas(*ud.Spec.Replicas, *replicas)
+	if err != nil {
+		klog.Warningf("Fail to parse the elastic replicas %s of UnitedDeployment %s/%s: %s", replicas.String(), ud.Namespace, ud.Name, err)
+		return defaultValue
+	}
+	return parsed
+}
+
+func minOf(a, b []int32) []int32 {
+	result := make([]int32, len(a))
+	for i := range a {
+		result[i] = a[i]
+		if b[i] < result[i] {
+			result[i] = b[i]
+		}
+	}
+	return result
+}
@@ -17,7 +17,13 @@ limitations under the License.
 package uniteddeployment
 
 import (
+	"reflect"
 	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 )
 
 func TestScaleReplicas(t *testing.T) {
@@ -197,6 +203,114 @@ func TestSpecifyInvalidReplicas(t *testing.T) {
 	}
 }
 
+func TestElasticAllocate(t *testing.T) {
+	intOrStrPtr := func(v intstr.IntOrString) *intstr.IntOrString { return &v }
+	newUnitedDeployment := func(replicas int32, subsets ...appsv1alpha1.Subset) *appsv1alpha1.UnitedDeployment {
+		return &appsv1alpha1.UnitedDeployment{
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &replicas,
+				Topology: appsv1alpha1.Topology{Subsets: subsets},
+			},
+		}
+	}
+
+	cases := []struct {
+		name          string
+		ud            *appsv1alpha1.UnitedDeployment
+		unschedulable []string
+		nameToSubset  map[string]*Subset
+		expected      map[string]int32
+	}{
+		{
+			name: "fill on-demand up to max then spot",
+			ud: newUnitedDeployment(15,
+				appsv1alpha1.Subset{Name: "on-demand", MaxReplicas: intOrStrPtr(intstr.FromInt(10))},
+				appsv1alpha1.Subset{Name: "spot"},
+			),
+			expected: map[string]int32{"on-demand": 10, "spot": 5},
+		},
+		{
+			name: "replicas less than max",
+			ud: newUnitedDeployment(6,
+				appsv1alpha1.Subset{Name: "on-demand", MaxReplicas: intOrStrPtr(intstr.FromInt(10))},
+				appsv1alpha1.Subset{Name: "spot"},
+			),
+			expected: map[string]int32{"on-demand": 6, "spot": 0},
+		},
+		{
+			name: "min replicas first",
+			ud: newUnitedDeployment(6,
+				appsv1alpha1.Subset{Name: "on-demand", MaxReplicas: intOrStrPtr(intstr.FromInt(10))},
+				appsv1alpha1.Subset{Name: "spot", MinReplicas: intOrStrPtr(intstr.FromInt(2))},
+			),
+			expected: map[string]int32{"on-demand": 4, "spot": 2},
+		},
+		{
+			name: "percentage bounds",
+			ud: newUnitedDeployment(20,
+				appsv1alpha1.Subset{Name: "a", MinReplicas: intOrStrPtr(intstr.FromString("10%")), MaxReplicas: intOrStrPtr(intstr.FromString("50%"))},
+				appsv1alpha1.Subset{Name: "b", MaxReplicas: intOrStrPtr(intstr.FromString("25%"))},
+				appsv1alpha1.Subset{Name: "c"},
+			),
+			expected: map[string]int32{"a": 10, "b": 5, "c": 5},
+		},
+		{
+			name: "replicas beyond all max",
+			ud: newUnitedDeployment(10,
+				appsv1alpha1.Subset{Name: "a", MaxReplicas: intOrStrPtr(intstr.FromInt(3))},
+				appsv1alpha1.Subset{Name: "b", MaxReplicas: intOrStrPtr(intstr.FromInt(4))},
+			),
+			expected: map[string]int32{"a": 3, "b": 4},
+		},
+		{
+			name: "unschedulable subset shifts pending replicas",
+			ud: newUnitedDeployment(15,
+				appsv1alpha1.Subset{Name: "on-demand", MaxReplicas: intOrStrPtr(intstr.FromInt(10))},
+				appsv1alpha1.Subset{Name: "spot"},
+			),
+			unschedulable: []string{"on-demand"},
+			nameToSubset: map[string]*Subset{
+				"on-demand": {Status: SubsetStatus{Replicas: 10, UnschedulableStatus: SubsetUnschedulableStatus{PendingPods: 3}}},
+			},
+			expected: map[string]int32{"on-demand": 7, "spot": 8},
+		},
+		{
+			name: "unschedulable subset gets replicas back if no other subset could hold them",
+			ud: newUnitedDeployment(15,
+				appsv1alpha1.Subset{Name: "on-demand"},
+				appsv1alpha1.Subset{Name: "spot", MaxReplicas: intOrStrPtr(intstr.FromInt(5))},
+			),
+			unschedulable: []string{"on-demand"},
+			nameToSubset: map[string]*Subset{
+				"on-demand": {Status: SubsetStatus{Replicas: 10, UnschedulableStatus: SubsetUnschedulableStatus{PendingPods: 8}}},
+			},
+			expected: map[string]int32{"on-demand": 10, "spot": 5},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if len(tc.unschedulable) > 0 {
+				tc.ud.Spec.Topology.ScheduleStrategy.Type = appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType
+				tc.ud.Status.UnschedulableSubsets = map[string]metav1.Time{}
+				for _, name := range tc.unschedulable {
+					tc.ud.Status.UnschedulableSubsets[name] = metav1.Now()
+				}
+			}
+			if tc.nameToSubset == nil {
+				tc.nameToSubset = map[string]*Subset{}
+			}
+			allocated, err := GetAllocatedReplicas(&tc.nameToSubset, tc.ud)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*allocated, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, *allocated)
+			}
+		})
+	}
+}
+
 func createSubset(name string, replicas int32) *nameToReplicas {
 	return &nameToReplicas{
 		Replicas:   replicas,
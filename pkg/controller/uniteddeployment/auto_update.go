@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/integer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+// calcAutoUpdatePartitions calculates the partition of each subset for the Auto update strategy.
+// The global partition is reserved in the last subsets of topology, and the number of pods to be updated
+// is limited by the global maxUnavailable. With Ordered order, a subset will not be updated until all the
+// previous subsets have been updated and ready.
+func calcAutoUpdatePartitions(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas *map[string]int32) *map[string]int32 {
+	strategy := ud.Spec.UpdateStrategy.AutoUpdate
+	if strategy == nil {
+		strategy = &appsv1alpha1.AutoUpdate{}
+	}
+	subsets := ud.Spec.Topology.Subsets
+
+	// reserve the global partition from the last subset
+	partitions := map[string]int32{}
+	var remainingPartition int32
+	if strategy.Partition != nil {
+		remainingPartition = *strategy.Partition
+	}
+	for i := len(subsets) - 1; i >= 0; i-- {
+		partition := integer.Int32Max(integer.Int32Min(remainingPartition, (*nextReplicas)[subsets[i].Name]), 0)
+		partitions[subsets[i].Name] = partition
+		remainingPartition -= partition
+	}
+
+	// calculate how many pods can be updated for the time
+	var totalReplicas, unavailable int32
+	for _, subsetDef := range subsets {
+		replicas := (*nextReplicas)[subsetDef.Name]
+		totalReplicas += replicas
+		if subset, exist := (*nameToSubset)[subsetDef.Name]; exist && subset.Status.ReadyReplicas < replicas {
+			unavailable += replicas - subset.Status.ReadyReplicas
+		}
+	}
+	maxUnavailable := intstr.FromString(appsv1alpha1.DefaultUnitedDeploymentMaxUnavailable)
+	if strategy.MaxUnavailable != nil {
+		maxUnavailable = *strategy.MaxUnavailable
+	}
+	maxUnavailableCount, _ := intstr.GetValueFromIntOrPercent(&maxUnavailable, int(totalReplicas), true)
+	// at least one pod can be updated, otherwise the update will never progress
+	budget := integer.Int32Max(int32(integer.IntMax(maxUnavailableCount, 1))-unavailable, 0)
+
+	ordered := strategy.Order != appsv1alpha1.ParallelAutoUpdateOrderType
+	var blockedBy string
+	for _, subsetDef := range subsets {
+		subset, exist := (*nameToSubset)[subsetDef.Name]
+		if !exist {
+			// the subset to be created will be provisioned with the latest revision
+			continue
+		}
+		replicas := (*nextReplicas)[subsetDef.Name]
+		target := partitions[subsetDef.Name]
+		updated := integer.Int32Min(subset.Status.UpdatedReplicas, replicas)
+
+		// keep the updated pods, but do not update more pods until the previous subsets are finished
+		if blockedBy != "" {
+			partitions[subsetDef.Name] = integer.Int32Max(target, replicas-updated)
+			continue
+		}
+
+		var toUpdate int32
+		if waiting := replicas - updated - target; waiting > 0 {
+			toUpdate = integer.Int32Min(waiting, budget)
+			budget -= toUpdate
+		}
+		partitions[subsetDef.Name] = integer.Int32Max(target, replicas-updated-toUpdate)
+
+		if ordered && !isSubsetUpdateFinished(subset, replicas, target) {
+			blockedBy = subsetDef.Name
+		}
+	}
+	if blockedBy != "" {
+		klog.V(4).Infof("UnitedDeployment %s/%s waits for subset %s to be updated and ready", ud.Namespace, ud.Name, blockedBy)
+	}
+
+	return &partitions
+}
+
+// isSubsetUpdateFinished returns true if the pods expected to be updated in the subset are all updated and ready.
+func isSubsetUpdateFinished(subset *Subset, replicas, partition int32) bool {
+	if subset.Status.ObservedGeneration < subset.Generation {
+		return false
+	}
+	return subset.Status.UpdatedReadyReplicas >= replicas-partition
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestCalcAutoUpdatePartitions(t *testing.T) {
+	newSubset := func(ready, updated, updatedReady int32) *Subset {
+		return &Subset{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Status: SubsetStatus{
+				ObservedGeneration:   1,
+				ReadyReplicas:        ready,
+				UpdatedReplicas:      updated,
+				UpdatedReadyReplicas: updatedReady,
+			},
+		}
+	}
+	int32Ptr := func(i int32) *int32 { return &i }
+	intOrStrPtr := func(v intstr.IntOrString) *intstr.IntOrString { return &v }
+
+	cases := []struct {
+		name         string
+		autoUpdate   *appsv1alpha1.AutoUpdate
+		nameToSubset map[string]*Subset
+		expected     map[string]int32
+	}{
+		{
+			name: "parallel update limited by maxUnavailable",
+			autoUpdate: &appsv1alpha1.AutoUpdate{
+				Order:          appsv1alpha1.ParallelAutoUpdateOrderType,
+				MaxUnavailable: intOrStrPtr(intstr.FromInt(4)),
+			},
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(5, 2, 2),
+				"subset-b": newSubset(5, 0, 0),
+			},
+			expected: map[string]int32{"subset-a": 0, "subset-b": 4},
+		},
+		{
+			name: "ordered update moves to next subset when previous finished",
+			autoUpdate: &appsv1alpha1.AutoUpdate{
+				Order:          appsv1alpha1.OrderedAutoUpdateOrderType,
+				MaxUnavailable: intOrStrPtr(intstr.FromInt(2)),
+			},
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(5, 5, 5),
+				"subset-b": newSubset(5, 0, 0),
+			},
+			expected: map[string]int32{"subset-a": 0, "subset-b": 3},
+		},
+		{
+			name: "ordered update blocks next subset when previous not finished",
+			autoUpdate: &appsv1alpha1.AutoUpdate{
+				Order:          appsv1alpha1.OrderedAutoUpdateOrderType,
+				MaxUnavailable: intOrStrPtr(intstr.FromInt(2)),
+			},
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(4, 3, 2),
+				"subset-b": newSubset(5, 0, 0),
+			},
+			expected: map[string]int32{"subset-a": 1, "subset-b": 5},
+		},
+		{
+			name: "global partition reserved from the last subsets",
+			autoUpdate: &appsv1alpha1.AutoUpdate{
+				Order:          appsv1alpha1.OrderedAutoUpdateOrderType,
+				Partition:      int32Ptr(6),
+				MaxUnavailable: intOrStrPtr(intstr.FromString("20%")),
+			},
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(5, 4, 4),
+				"subset-b": newSubset(5, 0, 0),
+			},
+			expected: map[string]int32{"subset-a": 1, "subset-b": 5},
+		},
+		{
+			name: "subset not created yet",
+			autoUpdate: &appsv1alpha1.AutoUpdate{
+				Order:          appsv1alpha1.OrderedAutoUpdateOrderType,
+				Partition:      int32Ptr(2),
+				MaxUnavailable: intOrStrPtr(intstr.FromInt(1)),
+			},
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(5, 0, 0),
+			},
+			expected: map[string]int32{"subset-a": 4, "subset-b": 2},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ud := &appsv1alpha1.UnitedDeployment{
+				Spec: appsv1alpha1.UnitedDeploymentSpec{
+					UpdateStrategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+						Type:       appsv1alpha1.AutoUpdateStrategyType,
+						AutoUpdate: tc.autoUpdate,
+					},
+					Topology: appsv1alpha1.Topology{
+						Subsets: []appsv1alpha1.Subset{{Name: "subset-a"}, {Name: "subset-b"}},
+					},
+				},
+			}
+			nextReplicas := map[string]int32{"subset-a": 5, "subset-b": 5}
+			partitions := calcAutoUpdatePartitions(ud, &tc.nameToSubset, &nextReplicas)
+			if !reflect.DeepEqual(*partitions, tc.expected) {
+				t.Fatalf("expected partitions %v, got %v", tc.expected, *partitions)
+			}
+		})
+	}
+}
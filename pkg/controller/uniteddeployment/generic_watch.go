@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruiseclient "github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/controller/uniteddeployment/adapter"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// genericInformerSyncTimeout bounds the time to wait for the informer of a generic workload kind synced,
+// so that the reconcile will not be blocked by a kind that can not be listed.
+const genericInformerSyncTimeout = 10 * time.Second
+
+// watchGenericFailedReason is the reason of SubsetProvisioned condition when failed to watch the generic workload kind.
+const watchGenericFailedReason = "WatchGenericWorkloadFailed"
+
+// watchGenericKind starts watching the workload kind of the generic template, if it has not been watched yet.
+// The informer watches the whole objects rather than metadata only, for it is shared with genericClient
+// which needs the spec and status of workloads.
+// The controller waits for the informer synced without timeout once watching, so the kind is checked to be
+// served and allowed to list and watch, and its informer is synced in a bounded time before watching.
+func (r *ReconcileUnitedDeployment) watchGenericKind(template *appsv1alpha1.GenericTemplateSpec) error {
+	if r.controller == nil {
+		return nil
+	}
+
+	gvk := (&adapter.GenericAdapter{Template: template}).GroupVersionKind()
+	if _, ok := r.watchedGenericKinds.Load(gvk); ok {
+		return nil
+	}
+	r.watchGenericLock.Lock()
+	defer r.watchGenericLock.Unlock()
+	if _, ok := r.watchedGenericKinds.Load(gvk); ok {
+		return nil
+	}
+
+	if err := checkGenericKindAccess(gvk); err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if r.cache != nil {
+		ctx, cancel := context.WithTimeout(context.TODO(), genericInformerSyncTimeout)
+		defer cancel()
+		if _, err := r.cache.GetInformer(ctx, obj); err != nil {
+			return fmt.Errorf("failed to sync informer of %v: %v", gvk, err)
+		}
+	}
+
+	err := r.controller.Watch(&source.Kind{Type: obj}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &appsv1alpha1.UnitedDeployment{},
+	})
+	if err != nil {
+		return err
+	}
+	r.watchedGenericKinds.Store(gvk, struct{}{})
+	klog.V(4).Infof("Start watching generic workload %v for UnitedDeployment", gvk)
+	return nil
+}
+
+// checkGenericKindAccess returns error if the kind is not served by apiserver,
+// or kruise-manager is not allowed to list and watch it in all namespaces.
+func checkGenericKindAccess(gvk schema.GroupVersionKind) error {
+	genericClient := kruiseclient.GetGenericClient()
+	if genericClient == nil {
+		return nil
+	}
+
+	resourceList, err := genericClient.DiscoveryClient.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		return fmt.Errorf("failed to discover resources of %v: %v", gvk.GroupVersion(), err)
+	}
+	var resource string
+	for _, r := range resourceList.APIResources {
+		if r.Kind == gvk.Kind && !strings.Contains(r.Name, "/") {
+			resource = r.Name
+			break
+		}
+	}
+	if resource == "" {
+		return fmt.Errorf("kind %v is not served", gvk)
+	}
+
+	for _, verb := range []string{"list", "watch"} {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:     verb,
+					Group:    gvk.Group,
+					Version:  gvk.Version,
+					Resource: resource,
+				},
+			},
+		}
+		review, err = genericClient.KubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to review access of %s %v: %v", verb, gvk, err)
+		}
+		if !review.Status.Allowed {
+			return fmt.Errorf("kruise-manager is not allowed to %s %s.%s, please grant it in RBAC", verb, resource, gvk.Group)
+		}
+	}
+	return nil
+}
@@ -22,11 +22,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strconv"
 
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/controller/history"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -227,6 +231,8 @@ func (r *ReconcileUnitedDeployment) newRevision(ud *appsalphav1.UnitedDeployment
 		selectedLabels = ud.Spec.Template.AdvancedStatefulSetTemplate.Labels
 	} else if ud.Spec.Template.DeploymentTemplate != nil {
 		selectedLabels = ud.Spec.Template.DeploymentTemplate.Labels
+	} else if ud.Spec.Template.GenericTemplate != nil {
+		selectedLabels = ud.Spec.Template.GenericTemplate.Labels
 	}
 
 	cr, err := history.NewControllerRevision(ud,
@@ -276,3 +282,25 @@ func getUnitedDeploymentPatch(ud *appsalphav1.UnitedDeployment) ([]byte, error)
 	patch, err := json.Marshal(objCopy)
 	return patch, err
 }
+
+// getSubsetRevision returns the revision expected by the given subset. The patch of a subset is not part of
+// the UnitedDeployment revision, so a subset with patch gets a revision derived from both the UnitedDeployment
+// revision and its own patch. Changing the patch of one subset only rolls out the pods of that subset.
+func getSubsetRevision(ud *appsalphav1.UnitedDeployment, subsetName, revision string) string {
+	for i := range ud.Spec.Topology.Subsets {
+		subset := &ud.Spec.Topology.Subsets[i]
+		if subset.Name != subsetName || subset.Patch.Raw == nil {
+			continue
+		}
+
+		hf := fnv.New32()
+		hf.Write(subset.Patch.Raw)
+		hash := rand.SafeEncodeString(strconv.FormatUint(uint64(hf.Sum32()), 10))
+		// the revision is used as a label value
+		if maxLen := validation.LabelValueMaxLength - len(hash) - 1; len(revision) > maxLen {
+			revision = revision[:maxLen]
+		}
+		return fmt.Sprintf("%s-%s", revision, hash)
+	}
+	return revision
+}
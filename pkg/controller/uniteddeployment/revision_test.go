@@ -25,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -130,3 +131,33 @@ func TestRevisionManage(t *testing.T) {
 	g.Expect(c.List(context.TODO(), revisionList, &client.ListOptions{})).Should(gomega.BeNil())
 	g.Expect(len(revisionList.Items)).Should(gomega.BeEquivalentTo(2))
 }
+
+func TestGetSubsetRevision(t *testing.T) {
+	ud := &appsv1alpha1.UnitedDeployment{
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{
+					{Name: "subset-a"},
+					{Name: "subset-b", Patch: runtime.RawExtension{Raw: []byte(`{"metadata":{"labels":{"zone":"b"}}}`)}},
+				},
+			},
+		},
+	}
+
+	if revision := getSubsetRevision(ud, "subset-a", "foo-1"); revision != "foo-1" {
+		t.Fatalf("expect revision of subset without patch unchanged, got %s", revision)
+	}
+	revisionB := getSubsetRevision(ud, "subset-b", "foo-1")
+	if revisionB == "foo-1" {
+		t.Fatalf("expect revision of subset with patch to be changed")
+	}
+
+	// changing the patch of subset-b only changes the revision of subset-b
+	ud.Spec.Topology.Subsets[1].Patch.Raw = []byte(`{"metadata":{"labels":{"zone":"c"}}}`)
+	if revision := getSubsetRevision(ud, "subset-a", "foo-1"); revision != "foo-1" {
+		t.Fatalf("expect revision of subset-a unchanged, got %s", revision)
+	}
+	if revision := getSubsetRevision(ud, "subset-b", "foo-1"); revision == revisionB {
+		t.Fatalf("expect revision of subset-b to be changed with its patch")
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package uniteddeployment
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -45,6 +47,13 @@ type SubsetStatus struct {
 	ReadyReplicas        int32
 	UpdatedReplicas      int32
 	UpdatedReadyReplicas int32
+	UnschedulableStatus  SubsetUnschedulableStatus
+}
+
+// SubsetUnschedulableStatus stores the pods of the Subset which failed to be scheduled.
+type SubsetUnschedulableStatus struct {
+	PendingPods         int32
+	EarliestPendingTime time.Time
 }
 
 // SubsetUpdateStrategy stores the strategy detail of the Subset.
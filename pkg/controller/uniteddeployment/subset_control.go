@@ -68,10 +68,17 @@ func (m *SubsetControl) GetAllSubsets(ud *alpha1.UnitedDeployment, updatedRevisi
 	}
 
 	for _, claimedSet := range claimedSets {
-		subSet, err := m.convertToSubset(claimedSet, updatedRevision)
+		subSet, err := m.convertToSubset(ud, claimedSet, updatedRevision)
 		if err != nil {
 			return nil, err
 		}
+		if isAdaptiveScheduleStrategy(ud) {
+			pods, err := m.adapter.GetSubsetPods(claimedSet)
+			if err != nil {
+				return nil, err
+			}
+			subSet.Status.UnschedulableStatus = getUnschedulableStatus(pods)
+		}
 		subSets = append(subSets, subSet)
 	}
 	return subSets, nil
@@ -131,7 +138,7 @@ func (m *SubsetControl) IsExpected(subSet *Subset, revision string) bool {
 	return m.adapter.IsExpected(subSet.Spec.SubsetRef.Resources[0], revision)
 }
 
-func (m *SubsetControl) convertToSubset(set metav1.Object, updatedRevision string) (*Subset, error) {
+func (m *SubsetControl) convertToSubset(ud *alpha1.UnitedDeployment, set metav1.Object, updatedRevision string) (*Subset, error) {
 	subSetName, err := getSubsetNameFrom(set)
 	if err != nil {
 		return nil, err
@@ -157,7 +164,7 @@ func (m *SubsetControl) convertToSubset(set metav1.Object, updatedRevision strin
 	}
 	subset.Spec.SubsetName = subSetName
 
-	specReplicas, specPartition, statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas, err := m.adapter.GetReplicaDetails(set, updatedRevision)
+	specReplicas, specPartition, statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas, err := m.adapter.GetReplicaDetails(set, getSubsetRevision(ud, subSetName, updatedRevision))
 	if err != nil {
 		return subset, err
 	}
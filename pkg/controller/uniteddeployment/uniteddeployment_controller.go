@@ -21,11 +21,14 @@ import (
 	"flag"
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/controller/uniteddeployment/adapter"
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -33,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -69,6 +73,7 @@ const (
 	advancedStatefulSetSubSetType subSetType = "AdvancedStatefulSet"
 	cloneSetSubSetType            subSetType = "CloneSet"
 	deploymentSubSetType          subSetType = "Deployment"
+	genericSubSetType             subSetType = "Generic"
 )
 
 // Add creates a new UnitedDeployment Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
@@ -84,8 +89,10 @@ func Add(mgr manager.Manager) error {
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 	cli := util.NewClientFromManager(mgr, "uniteddeployment-controller")
 	return &ReconcileUnitedDeployment{
-		Client: cli,
-		scheme: mgr.GetScheme(),
+		Client:        cli,
+		genericClient: util.NewUnstructuredCachedClientFromManager(mgr, "uniteddeployment-controller"),
+		cache:         mgr.GetCache(),
+		scheme:        mgr.GetScheme(),
 
 		recorder: mgr.GetEventRecorderFor(controllerName),
 		subSetControls: map[subSetType]ControlInterface{
@@ -106,6 +113,9 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+	if reconciler, ok := r.(*ReconcileUnitedDeployment); ok {
+		reconciler.controller = c
+	}
 
 	// Watch for changes to UnitedDeployment
 	err = c.Watch(&source.Kind{Type: &appsv1alpha1.UnitedDeployment{}}, &handler.EnqueueRequestForObject{})
@@ -157,6 +167,17 @@ type ReconcileUnitedDeployment struct {
 
 	recorder       record.EventRecorder
 	subSetControls map[subSetType]ControlInterface
+
+	// genericClient reads the generic workloads from the informers started by watchGenericKind,
+	// instead of requesting apiserver on every reconcile.
+	genericClient client.Client
+	// controller is used to watch the workload kinds of generic templates on demand.
+	controller controller.Controller
+	// cache is used to sync the informers of generic workload kinds before watching.
+	cache cache.Cache
+	// watchedGenericKinds records the GroupVersionKinds of generic templates which have been watched.
+	watchedGenericKinds sync.Map
+	watchGenericLock    sync.Mutex
 }
 
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=uniteddeployments,verbs=get;list;watch;create;update;patch;delete
@@ -172,7 +193,12 @@ type ReconcileUnitedDeployment struct {
 
 // Reconcile reads that state of the cluster for a UnitedDeployment object and makes changes based on the state read
 // and what is in the UnitedDeployment.Spec
-func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconcile.Request) (res reconcile.Result, retErr error) {
+	startTime := time.Now()
+	defer func() {
+		metrics.ObserveReconcile("uniteddeployment", startTime, retErr)
+	}()
+
 	klog.V(4).Infof("Reconcile UnitedDeployment %s/%s", request.Namespace, request.Name)
 	// Fetch the UnitedDeployment instance
 	instance := &appsv1alpha1.UnitedDeployment{}
@@ -197,6 +223,20 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 	}
 
 	control, subsetType := r.getSubsetControls(instance)
+	if subsetType == genericSubSetType {
+		if err := r.watchGenericKind(instance.Spec.Template.GenericTemplate); err != nil {
+			klog.Errorf("Fail to watch generic workload of UnitedDeployment %s/%s: %s", instance.Namespace, instance.Name, err)
+			newStatus := oldStatus.DeepCopy()
+			SetUnitedDeploymentCondition(newStatus, NewUnitedDeploymentCondition(appsv1alpha1.SubsetProvisioned, corev1.ConditionFalse, watchGenericFailedReason, err.Error()))
+			if _, updateErr := r.updateUnitedDeployment(instance, oldStatus, newStatus); updateErr != nil {
+				klog.Errorf("Fail to update status of UnitedDeployment %s/%s: %s", instance.Namespace, instance.Name, updateErr)
+			}
+			return reconcile.Result{}, err
+		}
+		if cond := GetUnitedDeploymentCondition(instance.Status, appsv1alpha1.SubsetProvisioned); cond != nil && cond.Reason == watchGenericFailedReason {
+			RemoveUnitedDeploymentCondition(&instance.Status, appsv1alpha1.SubsetProvisioned)
+		}
+	}
 
 	klog.V(4).Infof("Get UnitedDeployment %s/%s all subsets", request.Namespace, request.Name)
 	expectedRevision := currentRevision.Name
@@ -211,6 +251,7 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, nil
 	}
 
+	requeueAfter := manageUnschedulableSubsets(instance, nameToSubset, time.Now())
 	nextReplicas, err := GetAllocatedReplicas(nameToSubset, instance)
 	klog.V(4).Infof("Get UnitedDeployment %s/%s next replicas %v", instance.Namespace, instance.Name, nextReplicas)
 	if err != nil {
@@ -221,16 +262,25 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, nil
 	}
 
-	nextPartitions := calcNextPartitions(instance, nextReplicas)
+	var nextPartitions *map[string]int32
+	if instance.Spec.UpdateStrategy.Type == appsv1alpha1.AutoUpdateStrategyType {
+		nextPartitions = calcAutoUpdatePartitions(instance, nameToSubset, nextReplicas)
+	} else {
+		nextPartitions = calcNextPartitions(instance, nextReplicas)
+	}
 	klog.V(4).Infof("Get UnitedDeployment %s/%s next partition %v", instance.Namespace, instance.Name, nextPartitions)
 
-	newStatus, err := r.manageSubsets(instance, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, subsetType)
+	newStatus, err := r.manageSubsets(instance, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, control, subsetType)
 	if err != nil {
 		klog.Errorf("Fail to update UnitedDeployment %s/%s: %s", instance.Namespace, instance.Name, err)
 		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeSubsetsUpdate), err.Error())
 	}
 
-	return r.updateStatus(instance, newStatus, oldStatus, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, collisionCount, control)
+	res, err = r.updateStatus(instance, newStatus, oldStatus, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, collisionCount, control)
+	if err == nil && requeueAfter > 0 {
+		res.RequeueAfter = requeueAfter
+	}
+	return res, err
 }
 
 func (r *ReconcileUnitedDeployment) getNameToSubset(instance *appsv1alpha1.UnitedDeployment, control ControlInterface, expectedRevision string) (*map[string]*Subset, error) {
@@ -313,6 +363,17 @@ func (r *ReconcileUnitedDeployment) getSubsetControls(instance *appsv1alpha1.Uni
 		return r.subSetControls[deploymentSubSetType], deploymentSubSetType
 	}
 
+	if instance.Spec.Template.GenericTemplate != nil {
+		cli := r.genericClient
+		if cli == nil {
+			cli = r.Client
+		}
+		// the adapter of generic workload depends on the template, so it can not be shared between UnitedDeployments
+		return &SubsetControl{Client: cli, scheme: r.scheme, adapter: &adapter.GenericAdapter{
+			Client: cli, Scheme: r.scheme, Template: instance.Spec.Template.GenericTemplate,
+		}}, genericSubSetType
+	}
+
 	// unexpected
 	return nil, statefulSetSubSetType
 }
@@ -414,6 +475,7 @@ func (r *ReconcileUnitedDeployment) updateUnitedDeployment(ud *appsv1alpha1.Unit
 		ud.Generation == newStatus.ObservedGeneration &&
 		reflect.DeepEqual(oldStatus.SubsetReplicas, newStatus.SubsetReplicas) &&
 		reflect.DeepEqual(oldStatus.UpdateStatus, newStatus.UpdateStatus) &&
+		reflect.DeepEqual(oldStatus.UnschedulableSubsets, newStatus.UnschedulableSubsets) &&
 		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) {
 		return ud, nil
 	}
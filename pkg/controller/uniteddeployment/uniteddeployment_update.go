@@ -30,9 +30,9 @@ import (
 	"github.com/openkruise/kruise/pkg/util"
 )
 
-func (r *ReconcileUnitedDeployment) manageSubsets(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas, nextPartitions *map[string]int32, currentRevision, updatedRevision *appsv1.ControllerRevision, subsetType subSetType) (newStatus *appsv1alpha1.UnitedDeploymentStatus, updateErr error) {
+func (r *ReconcileUnitedDeployment) manageSubsets(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas, nextPartitions *map[string]int32, currentRevision, updatedRevision *appsv1.ControllerRevision, control ControlInterface, subsetType subSetType) (newStatus *appsv1alpha1.UnitedDeploymentStatus, updateErr error) {
 	newStatus = ud.Status.DeepCopy()
-	exists, provisioned, err := r.manageSubsetProvision(ud, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, subsetType)
+	exists, provisioned, err := r.manageSubsetProvision(ud, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, control, subsetType)
 	if err != nil {
 		SetUnitedDeploymentCondition(newStatus, NewUnitedDeploymentCondition(appsv1alpha1.SubsetProvisioned, corev1.ConditionFalse, "Error", err.Error()))
 		return newStatus, fmt.Errorf("fail to manage Subset provision: %s", err)
@@ -50,7 +50,7 @@ func (r *ReconcileUnitedDeployment) manageSubsets(ud *appsv1alpha1.UnitedDeploym
 	var needUpdate []string
 	for _, name := range exists.List() {
 		subset := (*nameToSubset)[name]
-		if r.subSetControls[subsetType].IsExpected(subset, expectedRevision.Name) ||
+		if control.IsExpected(subset, getSubsetRevision(ud, name, expectedRevision.Name)) ||
 			subset.Spec.Replicas != (*nextReplicas)[name] ||
 			subset.Spec.UpdateStrategy.Partition != (*nextPartitions)[name] {
 			needUpdate = append(needUpdate, name)
@@ -63,9 +63,10 @@ func (r *ReconcileUnitedDeployment) manageSubsets(ud *appsv1alpha1.UnitedDeploym
 			subset := (*nameToSubset)[cell]
 			replicas := (*nextReplicas)[cell]
 			partition := (*nextPartitions)[cell]
+			revision := getSubsetRevision(ud, cell, expectedRevision.Name)
 
-			klog.V(0).Infof("UnitedDeployment %s/%s needs to update Subset (%s) %s/%s with revision %s, replicas %d, partition %d", ud.Namespace, ud.Name, subsetType, subset.Namespace, subset.Name, expectedRevision.Name, replicas, partition)
-			updateSubsetErr := r.subSetControls[subsetType].UpdateSubset(subset, ud, expectedRevision.Name, replicas, partition)
+			klog.V(0).Infof("UnitedDeployment %s/%s needs to update Subset (%s) %s/%s with revision %s, replicas %d, partition %d", ud.Namespace, ud.Name, subsetType, subset.Namespace, subset.Name, revision, replicas, partition)
+			updateSubsetErr := control.UpdateSubset(subset, ud, revision, replicas, partition)
 			if updateSubsetErr != nil {
 				r.recorder.Event(ud.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeSubsetsUpdate), fmt.Sprintf("Error updating PodSet (%s) %s when updating: %s", subsetType, subset.Name, updateSubsetErr))
 			}
@@ -81,7 +82,7 @@ func (r *ReconcileUnitedDeployment) manageSubsets(ud *appsv1alpha1.UnitedDeploym
 	return
 }
 
-func (r *ReconcileUnitedDeployment) manageSubsetProvision(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas, nextPartitions *map[string]int32, currentRevision, updatedRevision *appsv1.ControllerRevision, subsetType subSetType) (sets.String, bool, error) {
+func (r *ReconcileUnitedDeployment) manageSubsetProvision(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas, nextPartitions *map[string]int32, currentRevision, updatedRevision *appsv1.ControllerRevision, control ControlInterface, subsetType subSetType) (sets.String, bool, error) {
 	expectedSubsets := sets.String{}
 	gotSubsets := sets.String{}
 
@@ -134,7 +135,7 @@ func (r *ReconcileUnitedDeployment) manageSubsetProvision(ud *appsv1alpha1.Unite
 
 			replicas := (*nextReplicas)[subsetName]
 			partition := (*nextPartitions)[subsetName]
-			err := r.subSetControls[subsetType].CreateSubset(ud, subsetName, revision, replicas, partition)
+			err := control.CreateSubset(ud, subsetName, getSubsetRevision(ud, subsetName, revision), replicas, partition)
 			if err != nil {
 				if !errors.IsTimeout(err) {
 					return fmt.Errorf("fail to create Subset (%s) %s: %s", subsetType, subsetName, err.Error())
@@ -156,7 +157,7 @@ func (r *ReconcileUnitedDeployment) manageSubsetProvision(ud *appsv1alpha1.Unite
 		var deleteErrs []error
 		for _, subsetName := range deletes {
 			subset := (*nameToSubset)[subsetName]
-			if err := r.subSetControls[subsetType].DeleteSubset(subset); err != nil {
+			if err := control.DeleteSubset(subset); err != nil {
 				deleteErrs = append(deleteErrs, fmt.Errorf("fail to delete Subset (%s) %s/%s for %s: %s", subsetType, subset.Namespace, subset.Name, subsetName, err))
 			}
 		}
@@ -170,12 +171,12 @@ func (r *ReconcileUnitedDeployment) manageSubsetProvision(ud *appsv1alpha1.Unite
 
 	// clean the other kind of subsets
 	cleaned := false
-	for t, control := range r.subSetControls {
+	for t, otherControl := range r.subSetControls {
 		if t == subsetType {
 			continue
 		}
 
-		subsets, err := control.GetAllSubsets(ud, revision)
+		subsets, err := otherControl.GetAllSubsets(ud, revision)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("fail to list Subset of other type %s for UnitedDeployment %s/%s: %s", t, ud.Namespace, ud.Name, err))
 			continue
@@ -183,7 +184,7 @@ func (r *ReconcileUnitedDeployment) manageSubsetProvision(ud *appsv1alpha1.Unite
 
 		for _, subset := range subsets {
 			cleaned = true
-			if err := control.DeleteSubset(subset); err != nil {
+			if err := otherControl.DeleteSubset(subset); err != nil {
 				errs = append(errs, fmt.Errorf("fail to delete Subset %s of other type %s for UnitedDeployment %s/%s: %s", subset.Name, t, ud.Namespace, ud.Name, err))
 				continue
 			}
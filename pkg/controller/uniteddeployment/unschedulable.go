@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func isAdaptiveScheduleStrategy(ud *appsv1alpha1.UnitedDeployment) bool {
+	return ud.Spec.Topology.ScheduleStrategy.Type == appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType
+}
+
+func getRescheduleCriticalDuration(ud *appsv1alpha1.UnitedDeployment) time.Duration {
+	seconds := int32(appsv1alpha1.DefaultRescheduleCriticalSeconds)
+	if adaptive := ud.Spec.Topology.ScheduleStrategy.Adaptive; adaptive != nil && adaptive.RescheduleCriticalSeconds != nil {
+		seconds = *adaptive.RescheduleCriticalSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func getUnschedulableLastDuration(ud *appsv1alpha1.UnitedDeployment) time.Duration {
+	seconds := int32(appsv1alpha1.DefaultUnschedulableLastSeconds)
+	if adaptive := ud.Spec.Topology.ScheduleStrategy.Adaptive; adaptive != nil && adaptive.UnschedulableLastSeconds != nil {
+		seconds = *adaptive.UnschedulableLastSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// getUnschedulableStatus counts the pods which are pending because of failing to be scheduled.
+func getUnschedulableStatus(pods []*corev1.Pod) SubsetUnschedulableStatus {
+	status := SubsetUnschedulableStatus{}
+	for _, pod := range pods {
+		if !isPodUnschedulable(pod) {
+			continue
+		}
+		status.PendingPods++
+		if status.EarliestPendingTime.IsZero() || pod.CreationTimestamp.Time.Before(status.EarliestPendingTime) {
+			status.EarliestPendingTime = pod.CreationTimestamp.Time
+		}
+	}
+	return status
+}
+
+func isPodUnschedulable(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName != "" {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+// manageUnschedulableSubsets marks the subsets, whose pods have been pending for longer than rescheduleCriticalSeconds,
+// as unschedulable in the status, and unmarks them after unschedulableLastSeconds.
+// It returns the duration after which the UnitedDeployment should be reconciled again to check the subsets.
+func manageUnschedulableSubsets(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, now time.Time) time.Duration {
+	if !isAdaptiveScheduleStrategy(ud) {
+		ud.Status.UnschedulableSubsets = nil
+		return 0
+	}
+
+	criticalDuration := getRescheduleCriticalDuration(ud)
+	lastDuration := getUnschedulableLastDuration(ud)
+	var requeueAfter time.Duration
+	setRequeueAfter := func(duration time.Duration) {
+		if duration > 0 && (requeueAfter == 0 || duration < requeueAfter) {
+			requeueAfter = duration
+		}
+	}
+
+	unschedulableSubsets := map[string]metav1.Time{}
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		if markedTime, exist := ud.Status.UnschedulableSubsets[subsetDef.Name]; exist {
+			if remaining := markedTime.Add(lastDuration).Sub(now); remaining > 0 {
+				unschedulableSubsets[subsetDef.Name] = markedTime
+				setRequeueAfter(remaining)
+				continue
+			}
+			klog.V(3).Infof("UnitedDeployment %s/%s subset %s recovers from unschedulable", ud.Namespace, ud.Name, subsetDef.Name)
+		}
+
+		subset, exist := (*nameToSubset)[subsetDef.Name]
+		if !exist || subset.Status.UnschedulableStatus.PendingPods == 0 {
+			continue
+		}
+		if remaining := subset.Status.UnschedulableStatus.EarliestPendingTime.Add(criticalDuration).Sub(now); remaining > 0 {
+			setRequeueAfter(remaining)
+			continue
+		}
+
+		klog.V(3).Infof("UnitedDeployment %s/%s subset %s has %d pods pending for longer than %v, mark it unschedulable",
+			ud.Namespace, ud.Name, subsetDef.Name, subset.Status.UnschedulableStatus.PendingPods, criticalDuration)
+		unschedulableSubsets[subsetDef.Name] = metav1.NewTime(now)
+		setRequeueAfter(lastDuration)
+	}
+
+	if len(unschedulableSubsets) == 0 {
+		unschedulableSubsets = nil
+	}
+	ud.Status.UnschedulableSubsets = unschedulableSubsets
+	return requeueAfter
+}
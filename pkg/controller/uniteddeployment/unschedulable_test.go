@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilpointer "k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestGetUnschedulableStatus(t *testing.T) {
+	now := time.Now()
+	newPod := func(created time.Time, nodeName string, unschedulable bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		}
+		if unschedulable {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:   corev1.PodScheduled,
+				Status: corev1.ConditionFalse,
+				Reason: corev1.PodReasonUnschedulable,
+			}}
+		}
+		return pod
+	}
+
+	status := getUnschedulableStatus([]*corev1.Pod{
+		newPod(now.Add(-time.Minute), "", true),
+		newPod(now.Add(-2*time.Minute), "", true),
+		newPod(now.Add(-3*time.Minute), "", false),
+		newPod(now.Add(-4*time.Minute), "node-1", false),
+	})
+	if status.PendingPods != 2 {
+		t.Fatalf("expected 2 pending pods, got %d", status.PendingPods)
+	}
+	if !status.EarliestPendingTime.Equal(now.Add(-2 * time.Minute)) {
+		t.Fatalf("unexpected earliest pending time %v", status.EarliestPendingTime)
+	}
+}
+
+func TestManageUnschedulableSubsets(t *testing.T) {
+	now := time.Now()
+	ud := &appsv1alpha1.UnitedDeployment{
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+				ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+					Type: appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+					Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{
+						RescheduleCriticalSeconds: utilpointer.Int32Ptr(30),
+						UnschedulableLastSeconds:  utilpointer.Int32Ptr(300),
+					},
+				},
+			},
+		},
+	}
+	pendingSince := func(d time.Duration) *Subset {
+		return &Subset{Status: SubsetStatus{UnschedulableStatus: SubsetUnschedulableStatus{PendingPods: 1, EarliestPendingTime: now.Add(-d)}}}
+	}
+	nameToSubset := map[string]*Subset{
+		"a": pendingSince(time.Minute),
+		"b": pendingSince(10 * time.Second),
+		"c": {},
+	}
+
+	requeueAfter := manageUnschedulableSubsets(ud, &nameToSubset, now)
+	if _, ok := ud.Status.UnschedulableSubsets["a"]; !ok || len(ud.Status.UnschedulableSubsets) != 1 {
+		t.Fatalf("expected only subset a unschedulable, got %v", ud.Status.UnschedulableSubsets)
+	}
+	if requeueAfter != 20*time.Second {
+		t.Fatalf("expected requeue after 20s, got %v", requeueAfter)
+	}
+
+	// subset a recovers after unschedulableLastSeconds
+	nameToSubset["a"] = &Subset{}
+	nameToSubset["b"] = &Subset{}
+	requeueAfter = manageUnschedulableSubsets(ud, &nameToSubset, now.Add(100*time.Second))
+	if len(ud.Status.UnschedulableSubsets) != 1 || requeueAfter != 200*time.Second {
+		t.Fatalf("expected subset a still unschedulable and requeue after 200s, got %v, %v", ud.Status.UnschedulableSubsets, requeueAfter)
+	}
+	requeueAfter = manageUnschedulableSubsets(ud, &nameToSubset, now.Add(301*time.Second))
+	if ud.Status.UnschedulableSubsets != nil || requeueAfter != 0 {
+		t.Fatalf("expected no unschedulable subset, got %v, %v", ud.Status.UnschedulableSubsets, requeueAfter)
+	}
+
+	// Fixed strategy clears the status
+	ud.Status.UnschedulableSubsets = map[string]metav1.Time{"a": metav1.NewTime(now)}
+	ud.Spec.Topology.ScheduleStrategy = appsv1alpha1.UnitedDeploymentScheduleStrategy{}
+	if manageUnschedulableSubsets(ud, &nameToSubset, now); ud.Status.UnschedulableSubsets != nil {
+		t.Fatalf("expected no unschedulable subset for Fixed strategy, got %v", ud.Status.UnschedulableSubsets)
+	}
+}
@@ -24,7 +24,6 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,6 +35,12 @@ import (
 func (r *ReconcileWorkloadSpread) updateDeletionCost(ws *appsv1alpha1.WorkloadSpread,
 	podMap map[string][]*corev1.Pod,
 	workloadReplicas int32) error {
+	// StatefulSet scales in Pods by the descending order of ordinals regardless of deletion-cost,
+	// and its Pods have been placed into subsets by ordinals.
+	if wsutil.IsStatefulSetWorkload(ws.Spec.TargetReference) {
+		return nil
+	}
+
 	// update Pod's deletion-cost annotation in each subset
 	for idx, subset := range ws.Spec.Subsets {
 		if err := r.syncSubsetPodDeletionCost(ws, &subset, idx, podMap[subset.Name], workloadReplicas); err != nil {
@@ -83,17 +88,18 @@ func (r *ReconcileWorkloadSpread) syncSubsetPodDeletionCost(
 	replicas := len(activePods)
 
 	// First we partition Pods into two lists: positive, negative list.
-	if subset.MaxReplicas == nil {
+	// For weighted subsets, maxReplicas is the share of workload replicas, so the extra Pods will be deleted first
+	// to restore the ratio of weights.
+	subsetMaxReplicas, err := getSubsetMaxReplicas(ws, subset, workloadReplicas)
+	if err != nil {
+		klog.Errorf("failed to get maxReplicas value from subset (%s) of WorkloadSpread (%s/%s): %v",
+			subset.Name, ws.Namespace, ws.Name, err)
+		return nil
+	}
+	if subsetMaxReplicas == -1 {
 		// maxReplicas is nil, which means there is no limit to the number of Pods in this subset.
 		positivePods = activePods
 	} else {
-		subsetMaxReplicas, err := intstr.GetValueFromIntOrPercent(subset.MaxReplicas, int(workloadReplicas), true)
-		if err != nil || subsetMaxReplicas < 0 {
-			klog.Errorf("failed to get maxReplicas value from subset (%s) of WorkloadSpread (%s/%s)",
-				subset.Name, ws.Namespace, ws.Name)
-			return nil
-		}
-
 		if replicas <= subsetMaxReplicas {
 			positivePods = activePods
 		} else {
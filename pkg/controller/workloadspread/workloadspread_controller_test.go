@@ -675,6 +675,43 @@ func TestSubsetPodDeletionCost(t *testing.T) {
 				return pods
 			},
 		},
+		{
+			name: "weighted subsets, subsetsLen = 2, subsetIndex = 0, weights are 30 and 70, pods number is 4",
+			getPods: func() []*corev1.Pod {
+				pods := make([]*corev1.Pod, 4)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+				}
+				return pods
+			},
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.Subsets = []appsv1alpha1.WorkloadSpreadSubset{
+					{Name: "subset-a", Weight: utilpointer.Int32Ptr(30)},
+					{Name: "subset-b", Weight: utilpointer.Int32Ptr(70)},
+				}
+				return workloadSpread
+			},
+			expectPods: func() []*corev1.Pod {
+				// 5 replicas are split into 2 and 3 by weights, so the 2 extra Pods in subset-a should be deleted first.
+				pods := make([]*corev1.Pod, 4)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Annotations = map[string]string{
+						PodDeletionCostAnnotation: "200",
+					}
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+				}
+				pods[0].Annotations = map[string]string{
+					PodDeletionCostAnnotation: "-100",
+				}
+				pods[1].Annotations = map[string]string{
+					PodDeletionCostAnnotation: "-100",
+				}
+				return pods
+			},
+		},
 	}
 	for _, cs := range cases {
 		t.Run(cs.name, func(t *testing.T) {
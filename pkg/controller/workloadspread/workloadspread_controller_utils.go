@@ -17,10 +17,14 @@ limitations under the License.
 package workloadspread
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
 )
 
 // NewWorkloadSpreadSubsetCondition creates a new WorkloadSpreadSubset condition.
@@ -78,3 +82,28 @@ func filterOutCondition(conditions []appsv1alpha1.WorkloadSpreadSubsetCondition,
 	}
 	return newConditions
 }
+
+// getSubsetMaxReplicas returns the max replicas of the subset, -1 means there is no limit for the subset replicas.
+// If the subsets are weighted, the max replicas is the share of workload replicas by the ratio of weights.
+func getSubsetMaxReplicas(ws *appsv1alpha1.WorkloadSpread, subset *appsv1alpha1.WorkloadSpreadSubset, workloadReplicas int32) (int, error) {
+	if wsutil.IsWeightedSubsets(ws) {
+		weightedReplicas := wsutil.GetWeightedReplicas(ws.Spec.Subsets, workloadReplicas)
+		for i := range ws.Spec.Subsets {
+			if ws.Spec.Subsets[i].Name == subset.Name {
+				return weightedReplicas[i], nil
+			}
+		}
+		return 0, nil
+	}
+	if subset.MaxReplicas == nil {
+		return -1, nil
+	}
+	subsetMaxReplicas, err := intstr.GetValueFromIntOrPercent(subset.MaxReplicas, int(workloadReplicas), true)
+	if err != nil {
+		return -1, err
+	}
+	if subsetMaxReplicas < 0 {
+		return -1, fmt.Errorf("maxReplicas %s should not be negative", subset.MaxReplicas.String())
+	}
+	return subsetMaxReplicas, nil
+}
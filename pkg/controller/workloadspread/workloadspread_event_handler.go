@@ -35,6 +35,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appsalphav1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsbetav1 "github.com/openkruise/kruise/apis/apps/v1beta1"
 	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
 )
 
@@ -112,6 +113,14 @@ func (w workloadEventHandler) Update(evt event.UpdateEvent, q workqueue.RateLimi
 		oldReplicas = *evt.ObjectOld.(*appsv1.ReplicaSet).Spec.Replicas
 		newReplicas = *evt.ObjectNew.(*appsv1.ReplicaSet).Spec.Replicas
 		gvk = controllerKindRS
+	case *appsv1.StatefulSet:
+		oldReplicas = *evt.ObjectOld.(*appsv1.StatefulSet).Spec.Replicas
+		newReplicas = *evt.ObjectNew.(*appsv1.StatefulSet).Spec.Replicas
+		gvk = controllerKindSts
+	case *appsbetav1.StatefulSet:
+		oldReplicas = *evt.ObjectOld.(*appsbetav1.StatefulSet).Spec.Replicas
+		newReplicas = *evt.ObjectNew.(*appsbetav1.StatefulSet).Spec.Replicas
+		gvk = controllerKruiseKindSts
 	case *batchv1.Job:
 		oldReplicas = *evt.ObjectOld.(*batchv1.Job).Spec.Parallelism
 		newReplicas = *evt.ObjectNew.(*batchv1.Job).Spec.Parallelism
@@ -158,6 +167,10 @@ func (w *workloadEventHandler) handleWorkload(q workqueue.RateLimitingInterface,
 		gvk = controllerKindDep
 	case *appsv1.ReplicaSet:
 		gvk = controllerKindRS
+	case *appsv1.StatefulSet:
+		gvk = controllerKindSts
+	case *appsbetav1.StatefulSet:
+		gvk = controllerKruiseKindSts
 	case *batchv1.Job:
 		gvk = controllerKindJob
 	default:
@@ -27,12 +27,16 @@ import (
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/client"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	daemonruntime "github.com/openkruise/kruise/pkg/daemon/criruntime"
 	"github.com/openkruise/kruise/pkg/daemon/kuberuntime"
 	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,8 +44,10 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	criapi "k8s.io/cri-api/pkg/apis"
 	"k8s.io/klog/v2"
@@ -62,6 +68,7 @@ type Controller struct {
 	runtimeClient  runtimeclient.Client
 	podLister      corelisters.PodLister
 	runtimeFactory daemonruntime.Factory
+	eventRecorder  record.EventRecorder
 }
 
 // NewController returns the Controller for containermeta reporting
@@ -70,6 +77,11 @@ func NewController(opts daemonoptions.Options) (*Controller, error) {
 		return nil, fmt.Errorf("containermeta Controller can not run without pod informer")
 	}
 
+	genericClient := client.GetGenericClientWithName("kruise-daemon-containermeta")
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: genericClient.KubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(opts.Scheme, v1.EventSource{Component: "kruise-daemon-containermeta", Host: opts.NodeName})
+
 	queue := workqueue.NewNamedRateLimitingQueue(
 		// Backoff duration from 500ms to 50~55s
 		workqueue.NewItemExponentialFailureRateLimiter(500*time.Millisecond, 50*time.Second+time.Millisecond*time.Duration(rand.Intn(5000))),
@@ -108,6 +120,7 @@ func NewController(opts daemonoptions.Options) (*Controller, error) {
 		runtimeClient:  opts.RuntimeClient,
 		podLister:      corelisters.NewPodLister(opts.PodInformer.GetIndexer()),
 		runtimeFactory: opts.RuntimeFactory,
+		eventRecorder:  recorder,
 	}, nil
 }
 
@@ -137,6 +150,17 @@ func shouldReportMeta(pod *v1.Pod) bool {
 			return true
 		}
 	}
+
+	// Are there resources to be resized?
+	if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateContainerResources) && !isContainerResourcesResized(pod, containerMetaSet) {
+		return true
+	}
+
+	// Are there containers to be restarted for env from metadata?
+	if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateEnvFromMetadata) {
+		names, _ := inplaceupdate.GetContainersToRestartForEnvFromMetadata(pod, containerMetaSet)
+		return len(names) > 0
+	}
 	return false
 }
 
@@ -221,7 +245,7 @@ func (c *Controller) sync(key string) (retErr error) {
 		resourceVersionExpectation.Delete(pod)
 	}
 
-	_, kubeRuntime, err := c.getRuntimeForPod(pod)
+	runtimeService, kubeRuntime, err := c.getRuntimeForPod(pod)
 	if err != nil {
 		klog.Errorf("Failed to get runtime for Pod %s/%s: %v", namespace, name, err)
 		return nil
@@ -241,11 +265,26 @@ func (c *Controller) sync(key string) (retErr error) {
 		return fmt.Errorf("failed to GetPodStatus: %v", err)
 	}
 
-	containerMetaSet := c.generateContainerMetaSet(pod, kubePodStatus)
+	var resizedResources map[string]*v1.ResourceRequirements
+	if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateContainerResources) {
+		if resizedResources, err = resizeContainers(pod, runtimeService, kubePodStatus); err != nil {
+			return err
+		}
+	}
+
 	oldContainerMetaSet, err := appspub.GetRuntimeContainerMetaSet(pod)
 	if err != nil {
 		klog.Warningf("Failed to get old runtime meta from Pod %s/%s: %v", namespace, name, err)
-	} else if reflect.DeepEqual(containerMetaSet, oldContainerMetaSet) {
+	}
+	containerMetaSet := c.generateContainerMetaSet(pod, kubePodStatus, oldContainerMetaSet, resizedResources)
+
+	if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateEnvFromMetadata) {
+		if err := c.restartContainersForEnvFromMetadata(pod, kubeRuntime, kubePodStatus, containerMetaSet); err != nil {
+			return err
+		}
+	}
+
+	if err == nil && reflect.DeepEqual(containerMetaSet, oldContainerMetaSet) {
 		return nil
 	}
 
@@ -270,22 +309,68 @@ func (c *Controller) sync(key string) (retErr error) {
 	return nil
 }
 
-func (c *Controller) generateContainerMetaSet(pod *v1.Pod, kubePodStatus *kubeletcontainer.PodStatus) *appspub.RuntimeContainerMetaSet {
+func (c *Controller) generateContainerMetaSet(pod *v1.Pod, kubePodStatus *kubeletcontainer.PodStatus,
+	oldContainerMetaSet *appspub.RuntimeContainerMetaSet, resizedResources map[string]*v1.ResourceRequirements) *appspub.RuntimeContainerMetaSet {
 	s := appspub.RuntimeContainerMetaSet{Containers: make([]appspub.RuntimeContainerMeta, 0, len(pod.Status.ContainerStatuses))}
 	for _, cs := range pod.Status.ContainerStatuses {
 		status := kubePodStatus.FindContainerStatusByName(cs.Name)
 		if status != nil {
-			s.Containers = append(s.Containers, appspub.RuntimeContainerMeta{
+			containerMeta := appspub.RuntimeContainerMeta{
 				Name:         status.Name,
 				ContainerID:  status.ID.String(),
 				RestartCount: int32(status.RestartCount),
 				Hashes:       appspub.RuntimeContainerHashes{PlainHash: status.Hash},
-			})
+				Resources:    resizedResources[status.Name],
+			}
+			if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateEnvFromMetadata) {
+				containerMeta.Hashes.ExtractedEnvFromMetadataHash = getExtractedEnvFromMetadataHash(pod, &containerMeta, oldContainerMetaSet)
+			}
+			s.Containers = append(s.Containers, containerMeta)
 		}
 	}
 	return &s
 }
 
+// getExtractedEnvFromMetadataHash returns the hash of env from metadata when the container started.
+// For a container that has been reported, the hash should be kept as the old one,
+// otherwise it is calculated with the current metadata of Pod.
+func getExtractedEnvFromMetadataHash(pod *v1.Pod, containerMeta *appspub.RuntimeContainerMeta, oldContainerMetaSet *appspub.RuntimeContainerMetaSet) uint64 {
+	if oldContainerMetaSet != nil {
+		for i := range oldContainerMetaSet.Containers {
+			if oldContainerMetaSet.Containers[i].ContainerID == containerMeta.ContainerID {
+				return oldContainerMetaSet.Containers[i].Hashes.ExtractedEnvFromMetadataHash
+			}
+		}
+	}
+	containerSpec := kubeletcontainer.GetContainerSpec(pod, containerMeta.Name)
+	if containerSpec == nil {
+		return 0
+	}
+	return inplaceupdate.GetEnvFromMetadataHash(pod, containerSpec)
+}
+
+// restartContainersForEnvFromMetadata stops the running containers whose env from metadata has been changed by
+// in-place update, then kubelet will start them again with the new env.
+func (c *Controller) restartContainersForEnvFromMetadata(pod *v1.Pod, kubeRuntime kuberuntime.Runtime,
+	kubePodStatus *kubeletcontainer.PodStatus, containerMetaSet *appspub.RuntimeContainerMetaSet) error {
+	names, err := inplaceupdate.GetContainersToRestartForEnvFromMetadata(pod, containerMetaSet)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		status := kubePodStatus.FindContainerStatusByName(name)
+		if status == nil || status.State != kubeletcontainer.ContainerStateRunning {
+			continue
+		}
+		klog.Infof("Restarting container %s (%s) in Pod %s/%s for env from metadata changed", name, status.ID.ID, pod.Namespace, pod.Name)
+		msg := fmt.Sprintf("Container %s env from metadata changed, will be restarted", name)
+		if err := kubeRuntime.KillContainer(pod, status.ID, name, msg, nil); err != nil {
+			return fmt.Errorf("failed to restart container %s for env from metadata: %v", name, err)
+		}
+	}
+	return nil
+}
+
 func (c *Controller) getRuntimeForPod(pod *v1.Pod) (criapi.RuntimeService, kuberuntime.Runtime, error) {
 	if len(pod.Status.ContainerStatuses) == 0 {
 		return nil, nil, fmt.Errorf("empty containerStatuses in pod status")
@@ -304,5 +389,5 @@ func (c *Controller) getRuntimeForPod(pod *v1.Pod) (criapi.RuntimeService, kuber
 		return nil, nil, fmt.Errorf("not found runtime service for %s in daemon", runtimeName)
 	}
 
-	return runtimeService, kuberuntime.NewGenericRuntime(runtimeName, runtimeService, nil, &http.Client{}), nil
+	return runtimeService, kuberuntime.NewGenericRuntime(runtimeName, runtimeService, c.eventRecorder, &http.Client{}), nil
 }
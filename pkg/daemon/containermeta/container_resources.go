@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containermeta
+
+import (
+	"fmt"
+
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	criapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	"k8s.io/klog/v2"
+	kubeletcontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+// These are the same as kubelet uses to convert resources into cgroup values.
+const (
+	minShares     = 2
+	sharesPerCPU  = 1024
+	milliCPUToCPU = 1000
+
+	// 100000 is equivalent to 100ms
+	quotaPeriod    = 100000
+	minQuotaPeriod = 1000
+)
+
+// isContainerResourcesResized returns true if all resources in inplace-update-resources annotation
+// have been applied to the runtime containers recorded in meta.
+func isContainerResourcesResized(pod *v1.Pod, containerMetaSet *appspub.RuntimeContainerMetaSet) bool {
+	containerResources, err := appspub.GetInPlaceUpdateResources(pod)
+	if err != nil || len(containerResources) == 0 {
+		return true
+	}
+	if containerMetaSet == nil {
+		return false
+	}
+	for i := range containerMetaSet.Containers {
+		meta := &containerMetaSet.Containers[i]
+		if resources, ok := containerResources[meta.Name]; ok {
+			if meta.Resources == nil || !apiequality.Semantic.DeepEqual(*meta.Resources, resources) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resizeContainers applies the resources in inplace-update-resources annotation to the runtime containers
+// that have not been resized, and returns the resources that have been applied to each container.
+func resizeContainers(pod *v1.Pod, runtimeService criapi.RuntimeService, kubePodStatus *kubeletcontainer.PodStatus) (map[string]*v1.ResourceRequirements, error) {
+	containerResources, err := appspub.GetInPlaceUpdateResources(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", appspub.InPlaceUpdateResourcesKey, err)
+	} else if len(containerResources) == 0 {
+		return nil, nil
+	}
+
+	oldContainerMetaSet, _ := appspub.GetRuntimeContainerMetaSet(pod)
+	resized := make(map[string]*v1.ResourceRequirements, len(containerResources))
+	for name := range containerResources {
+		resources := containerResources[name]
+		status := kubePodStatus.FindContainerStatusByName(name)
+		if status == nil || status.State != kubeletcontainer.ContainerStateRunning {
+			continue
+		}
+
+		if oldContainerMetaSet != nil {
+			var applied bool
+			for i := range oldContainerMetaSet.Containers {
+				meta := &oldContainerMetaSet.Containers[i]
+				if meta.Name == name && meta.ContainerID == status.ID.String() && meta.Resources != nil &&
+					apiequality.Semantic.DeepEqual(*meta.Resources, resources) {
+					applied = true
+					break
+				}
+			}
+			if applied {
+				resized[name] = &resources
+				continue
+			}
+		}
+
+		klog.Infof("Resizing container %s (%s) in Pod %s/%s to %v", name, status.ID.ID, pod.Namespace, pod.Name, resources)
+		if err := runtimeService.UpdateContainerResources(status.ID.ID, generateLinuxContainerResources(&resources)); err != nil {
+			return nil, fmt.Errorf("failed to update resources of container %s: %v", name, err)
+		}
+		resized[name] = &resources
+	}
+	return resized, nil
+}
+
+// generateLinuxContainerResources converts resources of container into the cgroup values of runtime,
+// in the same way kubelet does when creating a container.
+func generateLinuxContainerResources(resources *v1.ResourceRequirements) *runtimeapi.LinuxContainerResources {
+	cpuRequest := resources.Requests.Cpu()
+	cpuLimit := resources.Limits.Cpu()
+
+	lcr := &runtimeapi.LinuxContainerResources{
+		MemoryLimitInBytes: resources.Limits.Memory().Value(),
+	}
+	// If request is not specified, but limit is, we want request to default to limit.
+	if cpuRequest.IsZero() && !cpuLimit.IsZero() {
+		lcr.CpuShares = milliCPUToShares(cpuLimit.MilliValue())
+	} else {
+		lcr.CpuShares = milliCPUToShares(cpuRequest.MilliValue())
+	}
+	if !cpuLimit.IsZero() {
+		lcr.CpuPeriod = quotaPeriod
+		lcr.CpuQuota = milliCPUToQuota(cpuLimit.MilliValue(), quotaPeriod)
+	}
+	return lcr
+}
+
+func milliCPUToShares(milliCPU int64) int64 {
+	if milliCPU == 0 {
+		// Return 2 here to really match kernel default for zero milliCPU.
+		return minShares
+	}
+	shares := (milliCPU * sharesPerCPU) / milliCPUToCPU
+	if shares < minShares {
+		return minShares
+	}
+	return shares
+}
+
+func milliCPUToQuota(milliCPU int64, period int64) int64 {
+	if milliCPU == 0 {
+		return 0
+	}
+	quota := (milliCPU * period) / milliCPUToCPU
+	if quota < minQuotaPeriod {
+		quota = minQuotaPeriod
+	}
+	return quota
+}
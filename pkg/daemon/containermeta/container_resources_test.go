@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containermeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	criapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	kubeletcontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+type fakeRuntimeService struct {
+	criapi.RuntimeService
+	updated map[string]*runtimeapi.LinuxContainerResources
+	err     error
+}
+
+func (f *fakeRuntimeService) UpdateContainerResources(containerID string, resources *runtimeapi.LinuxContainerResources) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.updated[containerID] = resources
+	return nil
+}
+
+func TestResizeContainers(t *testing.T) {
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	resourcesJSON, _ := json.Marshal(map[string]v1.ResourceRequirements{"c1": resources, "c2": resources, "c3": resources})
+	metaJSON, _ := json.Marshal(appspub.RuntimeContainerMetaSet{Containers: []appspub.RuntimeContainerMeta{
+		{Name: "c2", ContainerID: "containerd://c2", Resources: &resources},
+	}})
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "pod-0",
+		Annotations: map[string]string{
+			appspub.InPlaceUpdateResourcesKey: string(resourcesJSON),
+			appspub.RuntimeContainerMetaKey:   string(metaJSON),
+		},
+	}}
+	podStatus := &kubeletcontainer.PodStatus{ContainerStatuses: []*kubeletcontainer.Status{
+		{Name: "c1", ID: kubeletcontainer.ContainerID{Type: "containerd", ID: "c1"}, State: kubeletcontainer.ContainerStateRunning},
+		{Name: "c2", ID: kubeletcontainer.ContainerID{Type: "containerd", ID: "c2"}, State: kubeletcontainer.ContainerStateRunning},
+		{Name: "c3", ID: kubeletcontainer.ContainerID{Type: "containerd", ID: "c3"}, State: kubeletcontainer.ContainerStateExited},
+	}}
+
+	runtimeService := &fakeRuntimeService{updated: make(map[string]*runtimeapi.LinuxContainerResources)}
+	resized, err := resizeContainers(pod, runtimeService, podStatus)
+	if err != nil {
+		t.Fatalf("failed to resize containers: %v", err)
+	}
+	// c2 has been resized before and c3 is not running
+	expectedUpdated := map[string]*runtimeapi.LinuxContainerResources{
+		"c1": {CpuPeriod: 100000, CpuQuota: 100000, CpuShares: 512, MemoryLimitInBytes: 1024 * 1024 * 1024},
+	}
+	if !reflect.DeepEqual(runtimeService.updated, expectedUpdated) {
+		t.Fatalf("expected updated %v, got %v", expectedUpdated, runtimeService.updated)
+	}
+	if len(resized) != 2 || resized["c1"] == nil || resized["c2"] == nil {
+		t.Fatalf("expected c1 and c2 resized, got %v", resized)
+	}
+
+	runtimeService.err = fmt.Errorf("unavailable")
+	if _, err := resizeContainers(pod, runtimeService, podStatus); err == nil {
+		t.Fatalf("expected error from runtime")
+	}
+
+	delete(pod.Annotations, appspub.InPlaceUpdateResourcesKey)
+	if resized, err := resizeContainers(pod, runtimeService, podStatus); err != nil || resized != nil {
+		t.Fatalf("expected nothing resized, got %v, %v", resized, err)
+	}
+}
+
+func TestGenerateLinuxContainerResources(t *testing.T) {
+	cases := []struct {
+		name      string
+		resources v1.ResourceRequirements
+		expected  *runtimeapi.LinuxContainerResources
+	}{
+		{
+			name:      "best effort",
+			resources: v1.ResourceRequirements{},
+			expected:  &runtimeapi.LinuxContainerResources{CpuShares: minShares},
+		},
+		{
+			name:      "request defaults to limit",
+			resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+			expected:  &runtimeapi.LinuxContainerResources{CpuShares: 2048, CpuPeriod: quotaPeriod, CpuQuota: 200000},
+		},
+		{
+			name: "tiny cpu",
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1m")},
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1m")},
+			},
+			expected: &runtimeapi.LinuxContainerResources{CpuShares: minShares, CpuPeriod: quotaPeriod, CpuQuota: minQuotaPeriod},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := generateLinuxContainerResources(&tc.resources); !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
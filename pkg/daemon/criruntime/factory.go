@@ -68,7 +68,7 @@ type runtimeImpl struct {
 	runtimeService criapi.RuntimeService
 }
 
-func NewFactory(varRunPath string, accountManager daemonutil.ImagePullAccountManager) (Factory, error) {
+func NewFactory(varRunPath string, accountManager daemonutil.ImagePullAccountManager, mirrorManager daemonutil.ImageMirrorManager) (Factory, error) {
 	cfgs := detectRuntime(varRunPath)
 	if len(cfgs) == 0 {
 		return nil, fmt.Errorf("not found container runtime sock")
@@ -86,13 +86,13 @@ func NewFactory(varRunPath string, accountManager daemonutil.ImagePullAccountMan
 
 		switch cfg.runtimeType {
 		case ContainerRuntimeDocker:
-			imageService, err = runtimeimage.NewDockerImageService(cfg.runtimeURI, accountManager)
+			imageService, err = runtimeimage.NewDockerImageService(cfg.runtimeURI, accountManager, mirrorManager)
 			if err != nil {
 				klog.Warningf("Failed to new image service for %v (%s, %s): %v", cfg.runtimeType, cfg.runtimeURI, cfg.runtimeRemoteURI, err)
 				continue
 			}
 		case ContainerRuntimePouch:
-			imageService, err = runtimeimage.NewPouchImageService(cfg.runtimeURI, accountManager)
+			imageService, err = runtimeimage.NewPouchImageService(cfg.runtimeURI, accountManager, mirrorManager)
 			if err != nil {
 				klog.Warningf("Failed to new image service for %v (%s, %s): %v", cfg.runtimeType, cfg.runtimeURI, cfg.runtimeRemoteURI, err)
 				continue
@@ -105,7 +105,7 @@ func NewFactory(varRunPath string, accountManager daemonutil.ImagePullAccountMan
 				klog.Warningf("Failed to get connection for %v (%s, %s): %v", cfg.runtimeType, cfg.runtimeURI, cfg.runtimeRemoteURI, err)
 				continue
 			}
-			imageService, err = runtimeimage.NewContainerdImageService(conn, accountManager)
+			imageService, err = runtimeimage.NewContainerdImageService(conn, accountManager, mirrorManager)
 			if err != nil {
 				klog.Warningf("Failed to new image service for %v (%s, %s): %v", cfg.runtimeType, cfg.runtimeURI, cfg.runtimeRemoteURI, err)
 				continue
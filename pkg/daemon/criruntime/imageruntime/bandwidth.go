@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageruntime
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+type bandwidthLimiterKey struct{}
+
+// WithBandwidthLimiter returns a copy of ctx carrying the limiters, which limit the bytes per second of pulling image
+// all together. It is only honored by the runtimes that download image layers in kruise-daemon, see IsBandwidthLimitSupported.
+func WithBandwidthLimiter(ctx context.Context, limiters ...*rate.Limiter) context.Context {
+	return context.WithValue(ctx, bandwidthLimiterKey{}, limiters)
+}
+
+func bandwidthLimitersFrom(ctx context.Context) []*rate.Limiter {
+	limiters, _ := ctx.Value(bandwidthLimiterKey{}).([]*rate.Limiter)
+	return limiters
+}
+
+// IsBandwidthLimitSupported returns whether the image service honors the bandwidth limiters, i.e., containerd.
+func IsBandwidthLimitSupported(imageService ImageService) bool {
+	_, ok := imageService.(*containerdImageClient)
+	return ok
+}
+
+// rateLimitedTransport limits the speed of reading response bodies by the limiters.
+type rateLimitedTransport struct {
+	http.RoundTripper
+	limiters []*rate.Limiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &rateLimitedReadCloser{ReadCloser: resp.Body, ctx: req.Context(), limiters: t.limiters}
+	return resp, nil
+}
+
+type rateLimitedReadCloser struct {
+	io.ReadCloser
+	ctx      context.Context
+	limiters []*rate.Limiter
+}
+
+func (r *rateLimitedReadCloser) Read(p []byte) (int, error) {
+	// WaitN fails if n exceeds the burst of the limiter
+	for _, limiter := range r.limiters {
+		if burst := limiter.Burst(); len(p) > burst {
+			p = p[:burst]
+		}
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		for _, limiter := range r.limiters {
+			if waitErr := limiter.WaitN(r.ctx, n); waitErr != nil {
+				return n, waitErr
+			}
+		}
+	}
+	return n, err
+}
@@ -40,6 +40,7 @@ import (
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	"github.com/pkg/errors"
 	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	v1 "k8s.io/api/core/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -56,6 +57,7 @@ const (
 func NewContainerdImageService(
 	conn *grpc.ClientConn,
 	accountManager daemonutil.ImagePullAccountManager,
+	mirrorManager daemonutil.ImageMirrorManager,
 ) (ImageService, error) {
 	snapshotter, httpProxy, err := getDefaultValuesFromCRIStatus(conn)
 	if err != nil {
@@ -69,6 +71,7 @@ func NewContainerdImageService(
 
 	return &containerdImageClient{
 		accountManager: accountManager,
+		mirrorManager:  mirrorManager,
 		snapshotter:    snapshotter,
 		client:         client,
 		criImageClient: runtimeapi.NewImageServiceClient(conn),
@@ -78,6 +81,7 @@ func NewContainerdImageService(
 
 type containerdImageClient struct {
 	accountManager daemonutil.ImagePullAccountManager
+	mirrorManager  daemonutil.ImageMirrorManager
 	snapshotter    string
 	client         *containerd.Client
 	criImageClient runtimeapi.ImageServiceClient
@@ -98,11 +102,38 @@ func (d *containerdImageClient) PullImage(ctx context.Context, imageName, tag st
 		return nil, errors.Wrapf(err, "failed to parse image reference %q", imageRef)
 	}
 
-	resolver, isSchema1, err := d.getResolver(ctx, namedRef, pullSecrets)
-	if err != nil {
-		return nil, err
+	mirrors := getImageMirrors(d.mirrorManager, imageName)
+	if len(mirrors) == 0 {
+		resolver, isSchema1, err := d.getResolver(ctx, namedRef, pullSecrets)
+		if err != nil {
+			return nil, err
+		}
+		return d.doPullImage(ctx, namedRef, isSchema1, resolver), nil
 	}
-	return d.doPullImage(ctx, namedRef, isSchema1, resolver), nil
+
+	// isSchema1 of the image pulled at last
+	var isSchema1 bool
+	return pullImageWithMirrors(mirrors, imageName,
+		func(name string) (ImagePullStatusReader, error) {
+			ref, err := daemonutil.NormalizeImageRef(fmt.Sprintf("%s:%s", name, tag))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse image reference %q", name)
+			}
+			var resolver remotes.Resolver
+			resolver, isSchema1, err = d.getResolver(ctx, ref, pullSecrets)
+			if err != nil {
+				return nil, err
+			}
+			return d.doPullImage(ctx, ref, isSchema1, resolver), nil
+		},
+		func(mirror string) error {
+			mirrorRef, err := daemonutil.NormalizeImageRef(fmt.Sprintf("%s:%s", mirror, tag))
+			if err != nil {
+				return err
+			}
+			return d.tagImage(ctx, mirrorRef, namedRef, isSchema1)
+		},
+	)
 }
 
 // ListImages implements ImageService.ListImages.
@@ -124,6 +155,14 @@ func (d *containerdImageClient) ListImages(ctx context.Context) ([]ImageInfo, er
 	return collection, nil
 }
 
+// RemoveImage implements ImageService.RemoveImage.
+func (d *containerdImageClient) RemoveImage(ctx context.Context, imageName, tag string) error {
+	_, err := d.criImageClient.RemoveImage(ctx, &runtimeapi.RemoveImageRequest{
+		Image: &runtimeapi.ImageSpec{Image: fmt.Sprintf("%s:%s", imageName, tag)},
+	})
+	return err
+}
+
 // doPullImage returns pipe reader as ImagePullStatusReader to notify the progressing.
 func (d *containerdImageClient) doPullImage(ctx context.Context, ref reference.Named, isSchema1 bool, resolver remotes.Resolver) ImagePullStatusReader {
 	ongoing := newFetchJobs(ref.String())
@@ -189,6 +228,28 @@ func (d *containerdImageClient) doPullImage(ctx context.Context, ref reference.N
 	return newImagePullStatusReader(pipeR)
 }
 
+// tagImage creates or updates the image record of ref with the target of the source image.
+func (d *containerdImageClient) tagImage(ctx context.Context, source, ref reference.Named, isSchema1 bool) error {
+	img, err := d.client.ImageService().Get(ctx, source.String())
+	if err != nil {
+		return err
+	}
+
+	target := images.Image{
+		Name:   ref.String(),
+		Target: img.Target,
+	}
+	if _, err = d.client.ImageService().Create(ctx, target); err != nil {
+		if !errdefs.IsAlreadyExists(err) {
+			return err
+		}
+		if _, err = d.client.ImageService().Update(ctx, target, "target"); err != nil {
+			return err
+		}
+	}
+	return d.createRepoDigestRecord(ctx, ref, img.Target, isSchema1)
+}
+
 // getResolver returns fetchable resolver for pulling image.
 //
 // FIXME(yuge.fw): need extra config for insecure registry settings.
@@ -203,7 +264,7 @@ func (d *containerdImageClient) getResolver(ctx context.Context, ref reference.N
 		if lastErr == nil {
 			var pullErrs []error
 			for _, authInfo := range authInfos {
-				resolver := d.resolverGenerator(&authInfo)
+				resolver := d.resolverGenerator(&authInfo, bandwidthLimitersFrom(ctx))
 				_, desc, err := resolver.Resolve(ctx, ref.String())
 				if err == nil {
 					return resolver, desc.MediaType == images.MediaTypeDockerSchema1Manifest, nil
@@ -223,7 +284,7 @@ func (d *containerdImageClient) getResolver(ctx context.Context, ref reference.N
 
 	// NOTE: It maybe be slow if the GetAccountInfo fetches remote.
 	if d.accountManager != nil {
-		defaultAuthInfo, err := d.accountManager.GetAccountInfo(registry)
+		defaultAuthInfo, err := daemonutil.GetAccountInfoForImage(d.accountManager, ref.String())
 		if err != nil {
 			klog.Warningf("failed to get default account for registry %v: %v", registry, err)
 		} else if defaultAuthInfo != nil {
@@ -235,7 +296,7 @@ func (d *containerdImageClient) getResolver(ctx context.Context, ref reference.N
 	authInfos = append(authInfos, nil)
 
 	for _, authInfo := range authInfos {
-		resolver := d.resolverGenerator(authInfo)
+		resolver := d.resolverGenerator(authInfo, bandwidthLimitersFrom(ctx))
 		_, desc, err := resolver.Resolve(ctx, ref.String())
 		if err != nil {
 			if authInfo != nil {
@@ -251,8 +312,8 @@ func (d *containerdImageClient) getResolver(ctx context.Context, ref reference.N
 	return nil, false, lastErr
 }
 
-// resolverGenerator returns resolver based on authInfo.
-func (d *containerdImageClient) resolverGenerator(authInfo *daemonutil.AuthInfo) remotes.Resolver {
+// resolverGenerator returns resolver based on authInfo, and the downloading is limited by the limiters if any.
+func (d *containerdImageClient) resolverGenerator(authInfo *daemonutil.AuthInfo, limiters []*rate.Limiter) remotes.Resolver {
 	var username string
 	var secret string
 	var cfg = httpproxy.FromEnvironment()
@@ -282,12 +343,17 @@ func (d *containerdImageClient) resolverGenerator(authInfo *daemonutil.AuthInfo)
 		ExpectContinueTimeout: 5 * time.Second,
 	}
 
+	var transport http.RoundTripper = tr
+	if len(limiters) > 0 {
+		transport = &rateLimitedTransport{RoundTripper: tr, limiters: limiters}
+	}
+
 	return docker.NewResolver(docker.ResolverOptions{
 		Credentials: func(host string) (string, string, error) {
 			return username, secret, nil
 		},
 		Client: &http.Client{
-			Transport: tr,
+			Transport: transport,
 		},
 	})
 }
@@ -18,6 +18,7 @@ package imageruntime
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"sync"
 
@@ -30,8 +31,8 @@ import (
 )
 
 // NewDockerImageService create a docker runtime
-func NewDockerImageService(runtimeURI string, accountManager daemonutil.ImagePullAccountManager) (ImageService, error) {
-	r := &dockerImageService{runtimeURI: runtimeURI, accountManager: accountManager}
+func NewDockerImageService(runtimeURI string, accountManager daemonutil.ImagePullAccountManager, mirrorManager daemonutil.ImageMirrorManager) (ImageService, error) {
+	r := &dockerImageService{runtimeURI: runtimeURI, accountManager: accountManager, mirrorManager: mirrorManager}
 	if err := r.createRuntimeClientIfNecessary(); err != nil {
 		return nil, err
 	}
@@ -42,6 +43,7 @@ type dockerImageService struct {
 	sync.Mutex
 	runtimeURI     string
 	accountManager daemonutil.ImagePullAccountManager
+	mirrorManager  daemonutil.ImageMirrorManager
 
 	client *dockerapi.Client
 }
@@ -68,11 +70,36 @@ func (d *dockerImageService) handleRuntimeError(err error) {
 	}
 }
 
-func (d *dockerImageService) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (reader ImagePullStatusReader, err error) {
-	if err = d.createRuntimeClientIfNecessary(); err != nil {
+func (d *dockerImageService) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (ImagePullStatusReader, error) {
+	if err := d.createRuntimeClientIfNecessary(); err != nil {
 		return nil, err
 	}
 
+	mirrors := getImageMirrors(d.mirrorManager, imageName)
+	if len(mirrors) == 0 {
+		return d.pullImage(ctx, imageName, tag, pullSecrets)
+	}
+	return pullImageWithMirrors(mirrors, imageName,
+		func(name string) (ImagePullStatusReader, error) {
+			if err := d.createRuntimeClientIfNecessary(); err != nil {
+				return nil, err
+			}
+			return d.pullImage(ctx, name, tag, pullSecrets)
+		},
+		func(mirror string) error {
+			if err := d.createRuntimeClientIfNecessary(); err != nil {
+				return err
+			}
+			if err := d.client.ImageTag(ctx, mirror+":"+tag, imageName+":"+tag); err != nil {
+				d.handleRuntimeError(err)
+				return err
+			}
+			return nil
+		},
+	)
+}
+
+func (d *dockerImageService) pullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (reader ImagePullStatusReader, err error) {
 	registry := daemonutil.ParseRegistry(imageName)
 	fullName := imageName + ":" + tag
 	var ioReader io.ReadCloser
@@ -103,7 +130,7 @@ func (d *dockerImageService) PullImage(ctx context.Context, imageName, tag strin
 	if d.accountManager != nil {
 		var authInfo *daemonutil.AuthInfo
 		var defaultErr error
-		authInfo, defaultErr = d.accountManager.GetAccountInfo(registry)
+		authInfo, defaultErr = daemonutil.GetAccountInfoForImage(d.accountManager, fullName)
 		if defaultErr != nil {
 			klog.Warningf("Failed to get account for registry %v, err %v", registry, defaultErr)
 			// When the default account acquisition fails, try to pull anonymously
@@ -144,6 +171,18 @@ func (d *dockerImageService) ListImages(ctx context.Context) ([]ImageInfo, error
 	return newImageCollectionDocker(infos), nil
 }
 
+func (d *dockerImageService) RemoveImage(ctx context.Context, imageName, tag string) error {
+	if err := d.createRuntimeClientIfNecessary(); err != nil {
+		return err
+	}
+	_, err := d.client.ImageRemove(ctx, fmt.Sprintf("%s:%s", imageName, tag), dockertypes.ImageRemoveOptions{PruneChildren: true})
+	if err != nil {
+		d.handleRuntimeError(err)
+		return err
+	}
+	return nil
+}
+
 func newImageCollectionDocker(infos []dockertypes.ImageSummary) []ImageInfo {
 	collection := make([]ImageInfo, 0, len(infos))
 	for _, info := range infos {
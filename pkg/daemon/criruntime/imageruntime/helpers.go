@@ -169,15 +169,20 @@ func (r *imagePullStatusReader) Close() {
 }
 
 func (r *imagePullStatusReader) seedPullStatus(s ImagePullStatus) {
+	seedPullStatus(r.ch, s)
+}
+
+// seedPullStatus replaces the status in ch with s, so that the reader always gets the latest status.
+func seedPullStatus(ch chan ImagePullStatus, s ImagePullStatus) {
 	for {
 		// clean the channel
 		select {
-		case <-r.ch:
+		case <-ch:
 		default:
 		}
 		// send status
 		select {
-		case r.ch <- s:
+		case ch <- s:
 			return
 		default:
 		}
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageruntime
+
+import (
+	"fmt"
+
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	"k8s.io/klog/v2"
+)
+
+func getImageMirrors(mirrorManager daemonutil.ImageMirrorManager, imageName string) []string {
+	if mirrorManager == nil {
+		return nil
+	}
+	return mirrorManager.GetMirrors(imageName)
+}
+
+// pullImageWithMirrors pulls the image from its mirrors in order and falls back to the next one on failure,
+// the image itself is pulled at last. tagImage is called after the image pulled from a mirror successfully,
+// so that it could be found by its original name.
+func pullImageWithMirrors(mirrors []string, imageName string,
+	pull func(name string) (ImagePullStatusReader, error), tagImage func(mirror string) error) (ImagePullStatusReader, error) {
+
+	r := &mirrorImagePullStatusReader{
+		ch:       make(chan ImagePullStatus, 1),
+		done:     make(chan struct{}),
+		names:    append(append([]string{}, mirrors...), imageName),
+		pull:     pull,
+		tagImage: tagImage,
+	}
+	reader, index, err := r.start(0)
+	if err != nil {
+		return nil, err
+	}
+	go r.mainloop(reader, index)
+	return r, nil
+}
+
+type mirrorImagePullStatusReader struct {
+	ch   chan ImagePullStatus
+	done chan struct{}
+
+	// names are the mirrors and the original image at last
+	names    []string
+	pull     func(name string) (ImagePullStatusReader, error)
+	tagImage func(mirror string) error
+}
+
+func (r *mirrorImagePullStatusReader) C() <-chan ImagePullStatus {
+	return r.ch
+}
+
+func (r *mirrorImagePullStatusReader) Close() {
+	close(r.done)
+}
+
+// start pulls from names[index:] until one of them started successfully.
+func (r *mirrorImagePullStatusReader) start(index int) (ImagePullStatusReader, int, error) {
+	for ; index < len(r.names); index++ {
+		reader, err := r.pull(r.names[index])
+		if err == nil {
+			return reader, index, nil
+		}
+		if index == len(r.names)-1 {
+			return nil, index, err
+		}
+		klog.Warningf("Failed to pull image from mirror %v, fallback to the next one, err %v", r.names[index], err)
+	}
+	return nil, index, fmt.Errorf("no image to pull")
+}
+
+func (r *mirrorImagePullStatusReader) mainloop(reader ImagePullStatusReader, index int) {
+	for {
+		status, ok := r.forward(reader)
+		reader.Close()
+		if !ok {
+			return
+		}
+
+		isMirror := index < len(r.names)-1
+		if status.Err == nil && isMirror {
+			if err := r.tagImage(r.names[index]); err != nil {
+				status = ImagePullStatus{Err: fmt.Errorf("failed to tag image pulled from mirror %v: %v", r.names[index], err), Finish: true}
+			}
+		}
+		if status.Err == nil || !isMirror {
+			seedPullStatus(r.ch, status)
+			return
+		}
+
+		klog.Warningf("Failed to pull image from mirror %v, fallback to the next one, err %v", r.names[index], status.Err)
+		var err error
+		if reader, index, err = r.start(index + 1); err != nil {
+			seedPullStatus(r.ch, ImagePullStatus{Err: err, Finish: true})
+			return
+		}
+	}
+}
+
+// forward passes the progressing statuses of reader until it finished, returns false if r is closed.
+func (r *mirrorImagePullStatusReader) forward(reader ImagePullStatusReader) (ImagePullStatus, bool) {
+	for {
+		select {
+		case <-r.done:
+			return ImagePullStatus{}, false
+		case status := <-reader.C():
+			if status.Finish {
+				return status, true
+			}
+			seedPullStatus(r.ch, status)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageruntime
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPullImageWithMirrors(t *testing.T) {
+	cases := []struct {
+		name           string
+		failures       map[string]string
+		expectedPulled []string
+		expectedTagged []string
+		expectedErr    bool
+	}{
+		{
+			name:           "pulled from the first mirror",
+			expectedPulled: []string{"mirror1/nginx"},
+			expectedTagged: []string{"mirror1/nginx"},
+		},
+		{
+			name:           "fall back to the second mirror",
+			failures:       map[string]string{"mirror1/nginx": "start"},
+			expectedPulled: []string{"mirror1/nginx", "mirror2/nginx"},
+			expectedTagged: []string{"mirror2/nginx"},
+		},
+		{
+			name:           "fall back to the original image",
+			failures:       map[string]string{"mirror1/nginx": "pull", "mirror2/nginx": "pull"},
+			expectedPulled: []string{"mirror1/nginx", "mirror2/nginx", "nginx"},
+		},
+		{
+			name:           "all failed",
+			failures:       map[string]string{"mirror1/nginx": "pull", "mirror2/nginx": "start", "nginx": "pull"},
+			expectedPulled: []string{"mirror1/nginx", "mirror2/nginx", "nginx"},
+			expectedErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pulled, tagged []string
+			reader, err := pullImageWithMirrors([]string{"mirror1/nginx", "mirror2/nginx"}, "nginx",
+				func(name string) (ImagePullStatusReader, error) {
+					pulled = append(pulled, name)
+					switch tc.failures[name] {
+					case "start":
+						return nil, fmt.Errorf("failed to start")
+					case "pull":
+						return newImagePullStatusReader(ioutil.NopCloser(strings.NewReader(`{"error":"not found","errorDetail":{"message":"not found"}}`))), nil
+					}
+					return newImagePullStatusReader(ioutil.NopCloser(strings.NewReader(`{"status":"Downloading","id":"layer"}`))), nil
+				},
+				func(mirror string) error {
+					tagged = append(tagged, mirror)
+					return nil
+				},
+			)
+			if err != nil {
+				t.Fatalf("failed to pull: %v", err)
+			}
+			defer reader.Close()
+
+			var status ImagePullStatus
+			timeout := time.After(5 * time.Second)
+			for !status.Finish {
+				select {
+				case status = <-reader.C():
+				case <-timeout:
+					t.Fatalf("timeout waiting for pulling finished")
+				}
+			}
+			if (status.Err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, status.Err)
+			}
+			if !reflect.DeepEqual(pulled, tc.expectedPulled) {
+				t.Fatalf("expected pulled %v, got %v", tc.expectedPulled, pulled)
+			}
+			if !reflect.DeepEqual(tagged, tc.expectedTagged) {
+				t.Fatalf("expected tagged %v, got %v", tc.expectedTagged, tagged)
+			}
+		})
+	}
+}
@@ -32,8 +32,8 @@ import (
 )
 
 // NewPouchImageService create a pouch runtime client
-func NewPouchImageService(runtimeURI string, accountManager daemonutil.ImagePullAccountManager) (ImageService, error) {
-	r := &pouchImageService{runtimeURI: runtimeURI, accountManager: accountManager}
+func NewPouchImageService(runtimeURI string, accountManager daemonutil.ImagePullAccountManager, mirrorManager daemonutil.ImageMirrorManager) (ImageService, error) {
+	r := &pouchImageService{runtimeURI: runtimeURI, accountManager: accountManager, mirrorManager: mirrorManager}
 	if err := r.createRuntimeClientIfNecessary(); err != nil {
 		return nil, err
 	}
@@ -44,6 +44,7 @@ type pouchImageService struct {
 	sync.Mutex
 	runtimeURI     string
 	accountManager daemonutil.ImagePullAccountManager
+	mirrorManager  daemonutil.ImageMirrorManager
 
 	client pouchapi.ImageAPIClient
 }
@@ -70,11 +71,36 @@ func (d *pouchImageService) handleRuntimeError(err error) {
 	}
 }
 
-func (d *pouchImageService) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (reader ImagePullStatusReader, err error) {
-	if err = d.createRuntimeClientIfNecessary(); err != nil {
+func (d *pouchImageService) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (ImagePullStatusReader, error) {
+	if err := d.createRuntimeClientIfNecessary(); err != nil {
 		return nil, err
 	}
 
+	mirrors := getImageMirrors(d.mirrorManager, imageName)
+	if len(mirrors) == 0 {
+		return d.pullImage(ctx, imageName, tag, pullSecrets)
+	}
+	return pullImageWithMirrors(mirrors, imageName,
+		func(name string) (ImagePullStatusReader, error) {
+			if err := d.createRuntimeClientIfNecessary(); err != nil {
+				return nil, err
+			}
+			return d.pullImage(ctx, name, tag, pullSecrets)
+		},
+		func(mirror string) error {
+			if err := d.createRuntimeClientIfNecessary(); err != nil {
+				return err
+			}
+			if err := d.client.ImageTag(ctx, mirror+":"+tag, imageName+":"+tag); err != nil {
+				d.handleRuntimeError(err)
+				return err
+			}
+			return nil
+		},
+	)
+}
+
+func (d *pouchImageService) pullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (reader ImagePullStatusReader, err error) {
 	registry := daemonutil.ParseRegistry(imageName)
 	var ioReader io.ReadCloser
 
@@ -104,7 +130,7 @@ func (d *pouchImageService) PullImage(ctx context.Context, imageName, tag string
 	if d.accountManager != nil {
 		var authInfo *daemonutil.AuthInfo
 		var defaultErr error
-		authInfo, defaultErr = d.accountManager.GetAccountInfo(registry)
+		authInfo, defaultErr = daemonutil.GetAccountInfoForImage(d.accountManager, imageName+":"+tag)
 		if defaultErr != nil {
 			klog.Warningf("Failed to get account for registry %v, err %v", registry, defaultErr)
 			// When the default account acquisition fails, try to pull anonymously
@@ -146,6 +172,17 @@ func (d *pouchImageService) ListImages(ctx context.Context) ([]ImageInfo, error)
 	return newImageCollectionPouch(infos), nil
 }
 
+func (d *pouchImageService) RemoveImage(ctx context.Context, imageName, tag string) error {
+	if err := d.createRuntimeClientIfNecessary(); err != nil {
+		return err
+	}
+	if err := d.client.ImageRemove(ctx, fmt.Sprintf("%s:%s", imageName, tag), false); err != nil {
+		d.handleRuntimeError(err)
+		return err
+	}
+	return nil
+}
+
 func newImageCollectionPouch(infos []pouchtypes.ImageInfo) []ImageInfo {
 	collection := make([]ImageInfo, 0, len(infos))
 	for _, info := range infos {
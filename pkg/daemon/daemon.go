@@ -43,6 +43,7 @@ import (
 	daemonruntime "github.com/openkruise/kruise/pkg/daemon/criruntime"
 	"github.com/openkruise/kruise/pkg/daemon/imagepuller"
 	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
+	"github.com/openkruise/kruise/pkg/daemon/sidecarlifecycle"
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	"github.com/openkruise/kruise/pkg/features"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
@@ -86,8 +87,8 @@ type daemon struct {
 	errSignal *errSignaler
 }
 
-// NewDaemon create a daemon
-func NewDaemon(cfg *rest.Config, bindAddress string) (Daemon, error) {
+// NewDaemon create a daemon, the node, clients and runtime in opts are set by itself
+func NewDaemon(cfg *rest.Config, bindAddress string, opts daemonoptions.Options) (Daemon, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("cfg can not be nil")
 	}
@@ -121,21 +122,29 @@ func NewDaemon(cfg *rest.Config, bindAddress string) (Daemon, error) {
 	}
 
 	accountManager := daemonutil.NewImagePullAccountManager(genericClient.KubeClient)
-	runtimeFactory, err := daemonruntime.NewFactory(varRunMountPath, accountManager)
+	if opts.ImageCredentialProviderConfig != "" {
+		accountManager, err = daemonutil.NewCredentialProviderAccountManager(opts.ImageCredentialProviderConfig, opts.ImageCredentialProviderBinDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to new credential provider account manager: %v", err)
+		}
+	}
+	mirrorManager, err := daemonutil.NewImageMirrorManager(opts.RegistryMirrorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to new image mirror manager: %v", err)
+	}
+	runtimeFactory, err := daemonruntime.NewFactory(varRunMountPath, accountManager, mirrorManager)
 	if err != nil {
 		return nil, fmt.Errorf("failed to new runtime factory: %v", err)
 	}
 
 	secretManager := daemonutil.NewCacheBasedSecretManager(genericClient.KubeClient)
 
-	opts := daemonoptions.Options{
-		NodeName:       nodeName,
-		Scheme:         scheme,
-		RuntimeClient:  runtimeClient,
-		PodInformer:    podInformer,
-		RuntimeFactory: runtimeFactory,
-		Healthz:        healthz,
-	}
+	opts.NodeName = nodeName
+	opts.Scheme = scheme
+	opts.RuntimeClient = runtimeClient
+	opts.PodInformer = podInformer
+	opts.RuntimeFactory = runtimeFactory
+	opts.Healthz = healthz
 
 	puller, err := imagepuller.NewController(opts, secretManager)
 	if err != nil {
@@ -160,6 +169,14 @@ func NewDaemon(cfg *rest.Config, bindAddress string) (Daemon, error) {
 		runnables = append(runnables, containerMetaController)
 	}
 
+	if utilfeature.DefaultFeatureGate.Enabled(features.SidecarSetLifecycle) {
+		sidecarLifecycleController, err := sidecarlifecycle.NewController(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to new sidecarlifecycle controller: %v", err)
+		}
+		runnables = append(runnables, sidecarLifecycleController)
+	}
+
 	return &daemon{
 		runtimeFactory: runtimeFactory,
 		podInformer:    podInformer,
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepuller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	criapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultImageRemovingTimeout = time.Minute
+
+	// Events
+	RemoveImageSucceed = "RemoveImageSucceed"
+	RemoveImageFailed  = "RemoveImageFailed"
+)
+
+type cleaner interface {
+	Sync(obj *appsv1alpha1.NodeImage, ref *v1.ObjectReference) (map[string]appsv1alpha1.ImageRemoveStatus, error)
+}
+
+type realCleaner struct {
+	imageService   runtimeimage.ImageService
+	runtimeService criapi.RuntimeService
+	eventRecorder  record.EventRecorder
+}
+
+var _ cleaner = &realCleaner{}
+
+func newRealCleaner(imageService runtimeimage.ImageService, runtimeService criapi.RuntimeService, eventRecorder record.EventRecorder) *realCleaner {
+	return &realCleaner{
+		imageService:   imageService,
+		runtimeService: runtimeService,
+		eventRecorder:  eventRecorder,
+	}
+}
+
+// Sync removes the images in spec.imagesToRemove which are not used by any container, and returns their statuses.
+func (c *realCleaner) Sync(obj *appsv1alpha1.NodeImage, ref *v1.ObjectReference) (map[string]appsv1alpha1.ImageRemoveStatus, error) {
+	if len(obj.Spec.ImagesToRemove) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), defaultImageRemovingTimeout)
+	defer cancel()
+	images, err := c.imageService.ListImages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %v", err)
+	}
+	containers, err := c.runtimeService.ListContainers(&runtimeapi.ContainerFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %v", err)
+	}
+
+	// removing an image by any of its tags deletes the image, so the tags sharing an image with the desired ones should be kept
+	desiredImageIDs := make(map[string]struct{})
+	for imageName, imageSpec := range obj.Spec.Images {
+		for _, tagSpec := range imageSpec.Tags {
+			if image := findImage(images, imageName, tagSpec.Tag); image != nil {
+				desiredImageIDs[image.ID] = struct{}{}
+			}
+		}
+	}
+
+	statuses := make(map[string]appsv1alpha1.ImageRemoveStatus, len(obj.Spec.ImagesToRemove))
+	for imageName, removeSpec := range obj.Spec.ImagesToRemove {
+		desiredTags := make(map[string]struct{})
+		for _, tagSpec := range obj.Spec.Images[imageName].Tags {
+			desiredTags[tagSpec.Tag] = struct{}{}
+		}
+		previousTags := make(map[string]appsv1alpha1.ImageTagRemoveStatus)
+		for _, tagStatus := range obj.Status.RemovedImageStatuses[imageName].Tags {
+			previousTags[tagStatus.Tag] = tagStatus
+		}
+
+		removeStatus := appsv1alpha1.ImageRemoveStatus{}
+		for _, tag := range removeSpec.Tags {
+			tagStatus := appsv1alpha1.ImageTagRemoveStatus{Tag: tag}
+			if _, ok := desiredTags[tag]; ok {
+				tagStatus.Phase = appsv1alpha1.ImageRemovePhaseInUse
+				tagStatus.Message = "image is desired to be pulled on this node"
+				removeStatus.Tags = append(removeStatus.Tags, tagStatus)
+				continue
+			}
+
+			image := findImage(images, imageName, tag)
+			if image == nil {
+				tagStatus.Phase = appsv1alpha1.ImageRemovePhaseRemoved
+				if previous, ok := previousTags[tag]; ok && previous.Phase == appsv1alpha1.ImageRemovePhaseRemoved && previous.CompletionTime != nil {
+					tagStatus.CompletionTime = previous.CompletionTime
+				} else {
+					now := metav1.Now()
+					tagStatus.CompletionTime = &now
+				}
+				removeStatus.Tags = append(removeStatus.Tags, tagStatus)
+				continue
+			}
+
+			if _, ok := desiredImageIDs[image.ID]; ok {
+				tagStatus.Phase = appsv1alpha1.ImageRemovePhaseInUse
+				tagStatus.Message = fmt.Sprintf("image %s is desired to be pulled on this node by another tag", image.ID)
+				removeStatus.Tags = append(removeStatus.Tags, tagStatus)
+				continue
+			}
+
+			if container := findContainerUsingImage(containers, image); container != nil {
+				tagStatus.Phase = appsv1alpha1.ImageRemovePhaseInUse
+				tagStatus.Message = fmt.Sprintf("image is used by container %s", container.Id)
+				removeStatus.Tags = append(removeStatus.Tags, tagStatus)
+				continue
+			}
+
+			if err := c.imageService.RemoveImage(ctx, imageName, tag); err != nil {
+				klog.Warningf("Failed to remove image %v:%v: %v", imageName, tag, err)
+				tagStatus.Phase = appsv1alpha1.ImageRemovePhaseFailed
+				tagStatus.Message = err.Error()
+				c.eventRecorder.Eventf(ref, v1.EventTypeWarning, RemoveImageFailed, "Image %v:%v %v", imageName, tag, err.Error())
+			} else {
+				klog.V(2).Infof("Removed image %v:%v", imageName, tag)
+				now := metav1.Now()
+				tagStatus.Phase = appsv1alpha1.ImageRemovePhaseRemoved
+				tagStatus.CompletionTime = &now
+				c.eventRecorder.Eventf(ref, v1.EventTypeNormal, RemoveImageSucceed, "Image %v:%v removed", imageName, tag)
+			}
+			removeStatus.Tags = append(removeStatus.Tags, tagStatus)
+		}
+		statuses[imageName] = removeStatus
+	}
+	return statuses, nil
+}
+
+func findImage(images []runtimeimage.ImageInfo, name, tag string) *runtimeimage.ImageInfo {
+	for i := range images {
+		if images[i].ContainsImage(name, tag) {
+			return &images[i]
+		}
+	}
+	return nil
+}
+
+// findContainerUsingImage returns the first container with the image. The exited containers are counted as well,
+// for they might be restarted or inspected until kubelet garbage collects them.
+func findContainerUsingImage(containers []*runtimeapi.Container, image *runtimeimage.ImageInfo) *runtimeapi.Container {
+	refs := make(map[string]struct{}, 1+len(image.RepoDigests)+len(image.RepoTags))
+	refs[image.ID] = struct{}{}
+	for _, digest := range image.RepoDigests {
+		refs[digest] = struct{}{}
+	}
+	for _, repoTag := range image.RepoTags {
+		refs[repoTag] = struct{}{}
+	}
+
+	for _, c := range containers {
+		// docker reports the image ref with a 'docker-pullable://' prefix
+		if _, ok := refs[strings.TrimPrefix(c.ImageRef, "docker-pullable://")]; ok {
+			return c
+		}
+		if c.Image != nil {
+			if _, ok := refs[c.Image.Image]; ok {
+				return c
+			}
+		}
+	}
+	return nil
+}
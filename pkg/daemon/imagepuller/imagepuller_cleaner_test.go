@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepuller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	criapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+type fakeImageService struct {
+	runtimeimage.ImageService
+	images  []runtimeimage.ImageInfo
+	removed []string
+}
+
+func (f *fakeImageService) ListImages(ctx context.Context) ([]runtimeimage.ImageInfo, error) {
+	return f.images, nil
+}
+
+func (f *fakeImageService) RemoveImage(ctx context.Context, imageName, tag string) error {
+	f.removed = append(f.removed, fmt.Sprintf("%s:%s", imageName, tag))
+	return nil
+}
+
+type fakeRuntimeService struct {
+	criapi.RuntimeService
+	containers []*runtimeapi.Container
+}
+
+func (f *fakeRuntimeService) ListContainers(filter *runtimeapi.ContainerFilter) ([]*runtimeapi.Container, error) {
+	return f.containers, nil
+}
+
+func TestCleanerSync(t *testing.T) {
+	imageService := &fakeImageService{images: []runtimeimage.ImageInfo{
+		{ID: "sha256:nginx-1", RepoTags: []string{"nginx:1.9.1"}},
+		{ID: "sha256:nginx-2", RepoTags: []string{"nginx:1.9.2"}, RepoDigests: []string{"nginx@sha256:digest-2"}},
+		{ID: "sha256:nginx-3", RepoTags: []string{"nginx:1.9.3", "nginx:stable"}},
+		{ID: "sha256:nginx-5", RepoTags: []string{"nginx:1.9.5"}},
+		{ID: "sha256:busybox", RepoTags: []string{"busybox:1.32"}},
+	}}
+	runtimeService := &fakeRuntimeService{containers: []*runtimeapi.Container{
+		{Id: "c1", ImageRef: "docker-pullable://nginx@sha256:digest-2", State: runtimeapi.ContainerState_CONTAINER_RUNNING},
+		{Id: "c2", Image: &runtimeapi.ImageSpec{Image: "sha256:nginx-1"}, State: runtimeapi.ContainerState_CONTAINER_EXITED},
+	}}
+	c := newRealCleaner(imageService, runtimeService, record.NewFakeRecorder(10))
+
+	nodeImage := &appsv1alpha1.NodeImage{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Spec: appsv1alpha1.NodeImageSpec{
+			Images: map[string]appsv1alpha1.ImageSpec{
+				"busybox": {Tags: []appsv1alpha1.ImageTagSpec{{Tag: "1.32"}}},
+				"nginx":   {Tags: []appsv1alpha1.ImageTagSpec{{Tag: "stable"}}},
+			},
+			ImagesToRemove: map[string]appsv1alpha1.ImageRemoveSpec{
+				"nginx":   {Tags: []string{"1.9.1", "1.9.2", "1.9.3", "1.9.4", "1.9.5"}},
+				"busybox": {Tags: []string{"1.32"}},
+			},
+		},
+	}
+	statuses, err := c.Sync(nodeImage, &v1.ObjectReference{})
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+
+	expectedPhases := map[string]appsv1alpha1.ImageRemovePhase{
+		"nginx:1.9.1":  appsv1alpha1.ImageRemovePhaseInUse,
+		"nginx:1.9.2":  appsv1alpha1.ImageRemovePhaseInUse,
+		"nginx:1.9.3":  appsv1alpha1.ImageRemovePhaseInUse,
+		"nginx:1.9.4":  appsv1alpha1.ImageRemovePhaseRemoved,
+		"nginx:1.9.5":  appsv1alpha1.ImageRemovePhaseRemoved,
+		"busybox:1.32": appsv1alpha1.ImageRemovePhaseInUse,
+	}
+	for imageName, status := range statuses {
+		for _, tagStatus := range status.Tags {
+			fullName := fmt.Sprintf("%s:%s", imageName, tagStatus.Tag)
+			if tagStatus.Phase != expectedPhases[fullName] {
+				t.Fatalf("expected %s %s, got %s", fullName, expectedPhases[fullName], tagStatus.Phase)
+			}
+			delete(expectedPhases, fullName)
+		}
+	}
+	if len(expectedPhases) > 0 {
+		t.Fatalf("missing statuses for %v", expectedPhases)
+	}
+	if len(imageService.removed) != 1 || imageService.removed[0] != "nginx:1.9.5" {
+		t.Fatalf("expected only nginx:1.9.5 removed, got %v", imageService.removed)
+	}
+}
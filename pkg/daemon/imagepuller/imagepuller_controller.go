@@ -28,6 +28,7 @@ import (
 	"github.com/openkruise/kruise/pkg/client"
 	kruiseclient "github.com/openkruise/kruise/pkg/client/clientset/versioned"
 	listersalpha1 "github.com/openkruise/kruise/pkg/client/listers/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
 	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	utilimagejob "github.com/openkruise/kruise/pkg/util/imagejob"
@@ -50,6 +51,7 @@ type Controller struct {
 	scheme                *runtime.Scheme
 	queue                 workqueue.RateLimitingInterface
 	puller                puller
+	cleaner               cleaner
 	imagePullNodeInformer cache.SharedIndexInformer
 	imagePullNodeLister   listersalpha1.NodeImageLister
 	statusUpdater         *statusUpdater
@@ -93,11 +95,17 @@ func NewController(opts daemonoptions.Options, secretManager daemonutil.SecretMa
 		},
 	})
 
-	puller, err := newRealPuller(opts.RuntimeFactory.GetImageService(), secretManager, recorder)
+	if opts.MaxImagePullBytesPerSecond > 0 && !runtimeimage.IsBandwidthLimitSupported(opts.RuntimeFactory.GetImageService()) {
+		klog.Warningf("The bandwidth limit of pulling images is ignored, for it is only supported by the containerd runtime currently")
+	}
+	limiter := newPullLimiter(opts.MaxConcurrentImagePulls, opts.MaxImagePullBytesPerSecond)
+	puller, err := newRealPuller(opts.RuntimeFactory.GetImageService(), secretManager, recorder, limiter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to new puller: %v", err)
 	}
 
+	cleaner := newRealCleaner(opts.RuntimeFactory.GetImageService(), opts.RuntimeFactory.GetRuntimeService(), recorder)
+
 	opts.Healthz.RegisterFunc("nodeImageInformerSynced", func(_ *http.Request) error {
 		if !informer.HasSynced() {
 			return fmt.Errorf("not synced")
@@ -109,6 +117,7 @@ func NewController(opts daemonoptions.Options, secretManager daemonutil.SecretMa
 		scheme:                opts.Scheme,
 		queue:                 queue,
 		puller:                puller,
+		cleaner:               cleaner,
 		imagePullNodeInformer: informer,
 		imagePullNodeLister:   listersalpha1.NewNodeImageLister(informer.GetIndexer()),
 		statusUpdater:         newStatusUpdater(genericClient.KruiseClient.AppsV1alpha1().NodeImages()),
@@ -241,7 +250,7 @@ func (c *Controller) sync(key string) (retErr error) {
 				newStatus.Succeeded++
 			case appsv1alpha1.ImagePhaseFailed:
 				newStatus.Failed++
-			case appsv1alpha1.ImagePhasePulling:
+			case appsv1alpha1.ImagePhasePulling, appsv1alpha1.ImagePhaseWaiting:
 				newStatus.Pulling++
 			}
 		}
@@ -250,13 +259,21 @@ func (c *Controller) sync(key string) (retErr error) {
 		newStatus.ImageStatuses = nil
 	}
 
+	removedImageStatuses, cleanErr := c.cleaner.Sync(nodeImage.DeepCopy(), ref)
+	if cleanErr != nil {
+		// keep the previous removing statuses, so that the pulling statuses can still be updated
+		klog.Errorf("Failed to remove images for NodeImage %s: %v", name, cleanErr)
+		removedImageStatuses = nodeImage.Status.RemovedImageStatuses
+	}
+	newStatus.RemovedImageStatuses = removedImageStatuses
+
 	var limited bool
 	limited, retErr = c.statusUpdater.updateStatus(nodeImage, &newStatus)
 	if retErr != nil {
 		return retErr
 	}
 
-	if limited || isImageInPulling(&nodeImage.Spec, &newStatus) {
+	if limited || cleanErr != nil || isImageInPulling(&nodeImage.Spec, &newStatus) {
 		// 3~5s
 		c.queue.AddAfter(key, 3*time.Second+time.Millisecond*time.Duration(rand.Intn(2000)))
 	} else {
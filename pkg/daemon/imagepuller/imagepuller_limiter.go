@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepuller
+
+import (
+	"sync"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxBandwidthBurst limits the bytes read at once, so that the bandwidth is smoothed
+	maxBandwidthBurst = 1024 * 1024
+)
+
+// pullLimiter limits the concurrency and bandwidth of all the image pulling tasks on the node.
+type pullLimiter struct {
+	sync.Mutex
+	// maxConcurrent is 0 if there is no concurrency limit
+	maxConcurrent int
+	// active is the number of pulling tasks holding a token
+	active int
+	// released is closed and renewed each time a token is released, to wake up the waiting tasks
+	released chan struct{}
+	// bandwidth is nil if there is no bandwidth limit
+	bandwidth *rate.Limiter
+}
+
+func newPullLimiter(maxConcurrent int, maxBytesPerSecond int64) *pullLimiter {
+	l := &pullLimiter{released: make(chan struct{})}
+	if maxConcurrent > 0 {
+		l.maxConcurrent = maxConcurrent
+	}
+	if maxBytesPerSecond > 0 {
+		l.bandwidth = newBandwidthLimiter(maxBytesPerSecond)
+	}
+	return l
+}
+
+// tryAcquire returns true if the pulling task is allowed to start immediately.
+func (l *pullLimiter) tryAcquire(pullPolicy *appsv1alpha1.ImageTagPullPolicy) bool {
+	l.Lock()
+	defer l.Unlock()
+	if !l.allowed(pullPolicy) {
+		return false
+	}
+	l.active++
+	return true
+}
+
+// acquire blocks until the pulling task is allowed to start, it returns false if stopCh is closed.
+func (l *pullLimiter) acquire(pullPolicy *appsv1alpha1.ImageTagPullPolicy, stopCh <-chan struct{}) bool {
+	for {
+		l.Lock()
+		if l.allowed(pullPolicy) {
+			l.active++
+			l.Unlock()
+			return true
+		}
+		released := l.released
+		l.Unlock()
+
+		select {
+		case <-released:
+		case <-stopCh:
+			return false
+		}
+	}
+}
+
+func (l *pullLimiter) release() {
+	l.Lock()
+	defer l.Unlock()
+	if l.active > 0 {
+		l.active--
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// allowed returns true if one more task can start pulling. The MaxConcurrentPulls in pullPolicy only applies to
+// the task itself, which is still limited by the node-level limit.
+func (l *pullLimiter) allowed(pullPolicy *appsv1alpha1.ImageTagPullPolicy) bool {
+	if l.maxConcurrent > 0 && l.active >= l.maxConcurrent {
+		return false
+	}
+	if pullPolicy != nil && pullPolicy.MaxConcurrentPulls != nil && *pullPolicy.MaxConcurrentPulls > 0 &&
+		l.active >= int(*pullPolicy.MaxConcurrentPulls) {
+		return false
+	}
+	return true
+}
+
+// bandwidthFor returns the bandwidth limiters for the pulling task. The MaxBytesPerSecond in pullPolicy
+// limits the task itself, which is still limited by the node-level limit shared by all the tasks.
+func (l *pullLimiter) bandwidthFor(pullPolicy *appsv1alpha1.ImageTagPullPolicy) []*rate.Limiter {
+	var limiters []*rate.Limiter
+	if pullPolicy != nil && pullPolicy.MaxBytesPerSecond != nil && *pullPolicy.MaxBytesPerSecond > 0 {
+		if l.bandwidth == nil || rate.Limit(*pullPolicy.MaxBytesPerSecond) < l.bandwidth.Limit() {
+			limiters = append(limiters, newBandwidthLimiter(*pullPolicy.MaxBytesPerSecond))
+		}
+	}
+	if l.bandwidth != nil {
+		limiters = append(limiters, l.bandwidth)
+	}
+	return limiters
+}
+
+func newBandwidthLimiter(bytesPerSecond int64) *rate.Limiter {
+	burst := maxBandwidthBurst
+	if bytesPerSecond < int64(burst) {
+		burst = int(bytesPerSecond)
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+}
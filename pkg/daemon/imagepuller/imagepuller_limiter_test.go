@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepuller
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+type fakeStatusUpdater struct {
+	sync.Mutex
+	phases []appsv1alpha1.ImagePullPhase
+}
+
+func (f *fakeStatusUpdater) UpdateStatus(status *appsv1alpha1.ImageTagStatus) {
+	f.Lock()
+	defer f.Unlock()
+	f.phases = append(f.phases, status.Phase)
+}
+
+func (f *fakeStatusUpdater) getPhases() []appsv1alpha1.ImagePullPhase {
+	f.Lock()
+	defer f.Unlock()
+	return append([]appsv1alpha1.ImagePullPhase{}, f.phases...)
+}
+
+func TestPullWorkerWaitingForLimit(t *testing.T) {
+	limiter := newPullLimiter(1, 0)
+	if !limiter.tryAcquire(nil) {
+		t.Fatalf("expected to acquire the only token")
+	}
+
+	imageService := &fakeImageService{images: []runtimeimage.ImageInfo{{ID: "sha256:nginx", RepoTags: []string{"nginx:1.9.1"}}}}
+	statusUpdater := &fakeStatusUpdater{}
+	worker := newPullWorker("nginx", appsv1alpha1.ImageTagSpec{Tag: "1.9.1"}, nil, imageService, statusUpdater, nil, nil, limiter)
+	defer worker.Stop()
+
+	waitForPhases := func(expected ...appsv1alpha1.ImagePullPhase) {
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return len(statusUpdater.getPhases()) == len(expected), nil
+		}); err != nil {
+			t.Fatalf("expected phases %v, got %v", expected, statusUpdater.getPhases())
+		}
+		for i, phase := range statusUpdater.getPhases() {
+			if phase != expected[i] {
+				t.Fatalf("expected phases %v, got %v", expected, statusUpdater.getPhases())
+			}
+		}
+	}
+	waitForPhases(appsv1alpha1.ImagePhaseWaiting)
+
+	limiter.release()
+	waitForPhases(appsv1alpha1.ImagePhaseWaiting, appsv1alpha1.ImagePhaseSucceeded)
+
+	// the token has been released by the worker
+	if !limiter.tryAcquire(nil) {
+		t.Fatalf("expected token released after pulling")
+	}
+}
+
+func TestBandwidthFor(t *testing.T) {
+	if l := newPullLimiter(0, 0); l.bandwidthFor(nil) != nil || !l.tryAcquire(nil) {
+		t.Fatalf("expected no limit")
+	}
+
+	l := newPullLimiter(0, 100)
+	if b := l.bandwidthFor(&appsv1alpha1.ImageTagPullPolicy{}); len(b) != 1 || b[0] != l.bandwidth || b[0].Limit() != rate.Limit(100) || b[0].Burst() != 100 {
+		t.Fatalf("expected node-level limit")
+	}
+	if b := l.bandwidthFor(&appsv1alpha1.ImageTagPullPolicy{MaxBytesPerSecond: utilpointer.Int64Ptr(10 * 1024 * 1024)}); len(b) != 1 || b[0] != l.bandwidth {
+		t.Fatalf("expected limit of pull policy clamped to the node-level limit")
+	}
+	b := l.bandwidthFor(&appsv1alpha1.ImageTagPullPolicy{MaxBytesPerSecond: utilpointer.Int64Ptr(10)})
+	if len(b) != 2 || b[0].Limit() != rate.Limit(10) || b[1] != l.bandwidth {
+		t.Fatalf("expected limit of pull policy shared with the node-level limit")
+	}
+
+	l = newPullLimiter(0, 0)
+	b = l.bandwidthFor(&appsv1alpha1.ImageTagPullPolicy{MaxBytesPerSecond: utilpointer.Int64Ptr(10 * 1024 * 1024)})
+	if len(b) != 1 || b[0].Limit() != rate.Limit(10*1024*1024) || b[0].Burst() != maxBandwidthBurst {
+		t.Fatalf("expected limit of pull policy")
+	}
+}
+
+func TestConcurrencyOfPullPolicy(t *testing.T) {
+	policy := &appsv1alpha1.ImageTagPullPolicy{MaxConcurrentPulls: utilpointer.Int32Ptr(1)}
+
+	l := newPullLimiter(2, 0)
+	if !l.tryAcquire(nil) {
+		t.Fatalf("expected to acquire under the node-level limit")
+	}
+	if l.tryAcquire(policy) {
+		t.Fatalf("expected limit of pull policy to tighten the node-level limit")
+	}
+	if !l.tryAcquire(&appsv1alpha1.ImageTagPullPolicy{MaxConcurrentPulls: utilpointer.Int32Ptr(3)}) {
+		t.Fatalf("expected to acquire under the limits")
+	}
+	if l.tryAcquire(&appsv1alpha1.ImageTagPullPolicy{MaxConcurrentPulls: utilpointer.Int32Ptr(3)}) {
+		t.Fatalf("expected limit of pull policy not to loosen the node-level limit")
+	}
+
+	stopCh := make(chan struct{})
+	acquired := make(chan bool)
+	go func() {
+		acquired <- l.acquire(policy, stopCh)
+	}()
+	l.release()
+	select {
+	case <-acquired:
+		t.Fatalf("expected to wait for the limit of pull policy")
+	case <-time.After(100 * time.Millisecond):
+	}
+	l.release()
+	if !<-acquired {
+		t.Fatalf("expected to acquire after all tokens released")
+	}
+
+	go func() {
+		acquired <- l.acquire(policy, stopCh)
+	}()
+	close(stopCh)
+	if <-acquired {
+		t.Fatalf("expected waiting stopped")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -56,17 +57,19 @@ type realPuller struct {
 	runtime       runtimeimage.ImageService
 	secretManager daemonutil.SecretManager
 	eventRecorder record.EventRecorder
+	limiter       *pullLimiter
 
 	workerPools map[string]workerPool
 }
 
 var _ puller = &realPuller{}
 
-func newRealPuller(runtime runtimeimage.ImageService, secretManager daemonutil.SecretManager, eventRecorder record.EventRecorder) (*realPuller, error) {
+func newRealPuller(runtime runtimeimage.ImageService, secretManager daemonutil.SecretManager, eventRecorder record.EventRecorder, limiter *pullLimiter) (*realPuller, error) {
 	p := &realPuller{
 		runtime:       runtime,
 		secretManager: secretManager,
 		eventRecorder: eventRecorder,
+		limiter:       limiter,
 		workerPools:   make(map[string]workerPool),
 	}
 	return p, nil
@@ -92,7 +95,7 @@ func (p *realPuller) Sync(obj *appsv1alpha1.NodeImage, ref *v1.ObjectReference)
 		pool, ok := p.workerPools[imageName]
 		if !ok {
 			klog.V(3).Infof("starting new workerpool for %v", imageName)
-			pool = newRealWorkerPool(imageName, p.runtime, p.secretManager, p.eventRecorder)
+			pool = newRealWorkerPool(imageName, p.runtime, p.secretManager, p.eventRecorder, p.limiter)
 			p.workerPools[imageName] = pool
 		}
 		var imageStatus *appsv1alpha1.ImageStatus
@@ -133,6 +136,7 @@ type realWorkerPool struct {
 	runtime       runtimeimage.ImageService
 	secretManager daemonutil.SecretManager
 	eventRecorder record.EventRecorder
+	limiter       *pullLimiter
 	pullWorkers   map[string]*pullWorker
 	tagStatuses   map[string]*appsv1alpha1.ImageTagStatus
 	active        bool
@@ -140,12 +144,13 @@ type realWorkerPool struct {
 	lastSyncSpec *appsv1alpha1.ImageSpec
 }
 
-func newRealWorkerPool(name string, runtime runtimeimage.ImageService, secretManager daemonutil.SecretManager, eventRecorder record.EventRecorder) *realWorkerPool {
+func newRealWorkerPool(name string, runtime runtimeimage.ImageService, secretManager daemonutil.SecretManager, eventRecorder record.EventRecorder, limiter *pullLimiter) *realWorkerPool {
 	w := &realWorkerPool{
 		name:          name,
 		runtime:       runtime,
 		secretManager: secretManager,
 		eventRecorder: eventRecorder,
+		limiter:       limiter,
 		pullWorkers:   make(map[string]*pullWorker),
 		tagStatuses:   make(map[string]*appsv1alpha1.ImageTagStatus),
 		active:        true,
@@ -214,7 +219,7 @@ func (w *realWorkerPool) Sync(spec *appsv1alpha1.ImageSpec, status *appsv1alpha1
 		_, ok := w.pullWorkers[tagSpec.Tag]
 
 		if !ok {
-			worker := newPullWorker(w.name, tagSpec, secrets, w.runtime, w, ref, w.eventRecorder)
+			worker := newPullWorker(w.name, tagSpec, secrets, w.runtime, w, ref, w.eventRecorder, w.limiter)
 			w.pullWorkers[tagSpec.Tag] = worker
 		}
 	}
@@ -262,7 +267,7 @@ func (w *realWorkerPool) UpdateStatus(status *appsv1alpha1.ImageTagStatus) {
 	w.tagStatuses[status.Tag] = status
 }
 
-func newPullWorker(name string, tagSpec appsv1alpha1.ImageTagSpec, secrets []v1.Secret, runtime runtimeimage.ImageService, statusUpdater imageStatusUpdater, ref *v1.ObjectReference, eventRecorder record.EventRecorder) *pullWorker {
+func newPullWorker(name string, tagSpec appsv1alpha1.ImageTagSpec, secrets []v1.Secret, runtime runtimeimage.ImageService, statusUpdater imageStatusUpdater, ref *v1.ObjectReference, eventRecorder record.EventRecorder, limiter *pullLimiter) *pullWorker {
 	o := &pullWorker{
 		name:          name,
 		tagSpec:       tagSpec,
@@ -271,6 +276,7 @@ func newPullWorker(name string, tagSpec appsv1alpha1.ImageTagSpec, secrets []v1.
 		statusUpdater: statusUpdater,
 		ref:           ref,
 		eventRecorder: eventRecorder,
+		limiter:       limiter,
 		active:        true,
 		stopCh:        make(chan struct{}),
 	}
@@ -288,6 +294,7 @@ type pullWorker struct {
 	statusUpdater imageStatusUpdater
 	ref           *v1.ObjectReference
 	eventRecorder record.EventRecorder
+	limiter       *pullLimiter
 
 	active bool
 	stopCh chan struct{}
@@ -322,6 +329,30 @@ func (w *pullWorker) Run() {
 		StartTime: &startTime,
 		Version:   w.tagSpec.Version,
 	}
+
+	// report the Waiting phase if the pulling is queued behind the concurrency limit of the node
+	var acquired bool
+	if w.limiter != nil {
+		if !w.limiter.tryAcquire(w.tagSpec.PullPolicy) {
+			klog.V(3).Infof("Worker %v is waiting for the concurrency limit of image pulling", w.ImageRef())
+			newStatus.Phase = appsv1alpha1.ImagePhaseWaiting
+			w.statusUpdater.UpdateStatus(newStatus)
+			if !w.limiter.acquire(w.tagSpec.PullPolicy, w.stopCh) {
+				klog.V(2).Infof("Waiting to pull image %v is stopped", w.ImageRef())
+				return
+			}
+			newStatus.Phase = appsv1alpha1.ImagePhasePulling
+			// the time waiting in queue is not counted in the deadline and the cost of pulling
+			startTime = metav1.Now()
+		}
+		acquired = true
+		defer func() {
+			if acquired {
+				w.limiter.release()
+			}
+		}()
+	}
+
 	defer func() {
 		cost := time.Since(startTime.Time)
 		if newStatus.Phase == appsv1alpha1.ImagePhaseFailed {
@@ -329,6 +360,9 @@ func (w *pullWorker) Run() {
 		} else {
 			klog.Infof("Successfully pull image %s:%s, cost %vs", w.name, tag, cost)
 		}
+		if w.ref != nil {
+			metrics.ObserveImagePull(w.ref.Name, cost, newStatus.Phase != appsv1alpha1.ImagePhaseFailed)
+		}
 		if w.IsActive() {
 			w.statusUpdater.UpdateStatus(newStatus)
 		}
@@ -369,6 +403,11 @@ func (w *pullWorker) Run() {
 		}
 
 		pullContext, cancel := context.WithTimeout(context.Background(), onceTimeout)
+		if w.limiter != nil {
+			if bandwidth := w.limiter.bandwidthFor(w.tagSpec.PullPolicy); len(bandwidth) > 0 {
+				pullContext = runtimeimage.WithBandwidthLimiter(pullContext, bandwidth...)
+			}
+		}
 		lastError = w.doPullImage(pullContext, newStatus)
 		if lastError != nil {
 			cancel()
@@ -377,8 +416,20 @@ func (w *pullWorker) Run() {
 			}
 
 			klog.Warningf("Pulling image %s:%s backoff %d, error %v", w.name, tag, i+1, lastError)
+			// give the concurrency token to other workers during backoff
+			if acquired {
+				w.limiter.release()
+				acquired = false
+			}
 			time.Sleep(step)
 			step = minDuration(2*step, maxBackoff)
+			if w.limiter != nil {
+				if !w.limiter.acquire(w.tagSpec.PullPolicy, w.stopCh) {
+					klog.V(2).Infof("Waiting to pull image %v is stopped", w.ImageRef())
+					break
+				}
+				acquired = true
+			}
 			continue
 		}
 
@@ -32,4 +32,16 @@ type Options struct {
 
 	RuntimeFactory daemonruntime.Factory
 	Healthz        *daemonutil.Healthz
+
+	// MaxConcurrentImagePulls is the maximum number of images pulling concurrently on the node, 0 means no limit.
+	MaxConcurrentImagePulls int
+	// MaxImagePullBytesPerSecond is the maximum bytes per second of pulling images on the node, 0 means no limit.
+	MaxImagePullBytesPerSecond int64
+
+	// RegistryMirrorConfig is the path of registry mirror config file.
+	RegistryMirrorConfig string
+	// ImageCredentialProviderConfig is the path of credential provider config file.
+	ImageCredentialProviderConfig string
+	// ImageCredentialProviderBinDir is the path of the directory where credential provider plugin binaries are located.
+	ImageCredentialProviderBinDir string
 }
@@ -0,0 +1,277 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarlifecycle
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	daemonruntime "github.com/openkruise/kruise/pkg/daemon/criruntime"
+	"github.com/openkruise/kruise/pkg/daemon/kuberuntime"
+	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	kubeletcontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+var (
+	// TODO: make it a configurable flag
+	workers = 5
+)
+
+// Controller stops the sidecar containers once the app containers of Job pod have exited.
+// The app containers waiting for sidecar containers are released by the sidecar-barrier-controller in kruise-manager.
+type Controller struct {
+	queue          workqueue.RateLimitingInterface
+	podLister      corelisters.PodLister
+	runtimeFactory daemonruntime.Factory
+	eventRecorder  record.EventRecorder
+}
+
+// NewController returns the Controller for sidecar lifecycle
+func NewController(opts daemonoptions.Options) (*Controller, error) {
+	if opts.PodInformer == nil {
+		return nil, fmt.Errorf("sidecarlifecycle Controller can not run without pod informer")
+	}
+
+	genericClient := client.GetGenericClientWithName("kruise-daemon-sidecarlifecycle")
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: genericClient.KubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(opts.Scheme, v1.EventSource{Component: "kruise-daemon-sidecarlifecycle", Host: opts.NodeName})
+
+	queue := workqueue.NewNamedRateLimitingQueue(
+		// Backoff duration from 500ms to 50~55s
+		workqueue.NewItemExponentialFailureRateLimiter(500*time.Millisecond, 50*time.Second+time.Millisecond*time.Duration(rand.Intn(5000))),
+		"sidecarlifecycle",
+	)
+
+	opts.PodInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			pod, ok := obj.(*v1.Pod)
+			if ok && shouldSync(pod) {
+				enqueue(queue, pod)
+			}
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			pod, ok := newObj.(*v1.Pod)
+			if ok && shouldSync(pod) {
+				enqueue(queue, pod)
+			}
+		},
+	})
+
+	return &Controller{
+		queue:          queue,
+		podLister:      corelisters.NewPodLister(opts.PodInformer.GetIndexer()),
+		runtimeFactory: opts.RuntimeFactory,
+		eventRecorder:  recorder,
+	}, nil
+}
+
+func shouldSync(pod *v1.Pod) bool {
+	if _, injectedBySidecarSet := pod.Annotations[sidecarcontrol.SidecarSetHashAnnotation]; !injectedBySidecarSet {
+		return false
+	}
+	if pod.DeletionTimestamp != nil || len(pod.Status.ContainerStatuses) == 0 {
+		return false
+	}
+	return len(getSidecarsToTerminate(pod)) > 0
+}
+
+// getSidecarsToTerminate returns the running sidecar containers that should be stopped,
+// when all the app containers of the Job pod have completed.
+func getSidecarsToTerminate(pod *v1.Pod) []string {
+	if pod.Spec.RestartPolicy == v1.RestartPolicyAlways {
+		return nil
+	}
+	var sidecars []string
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		status := getContainerStatus(pod, container.Name)
+		if status == nil {
+			return nil
+		}
+		if sidecarcontrol.IsInjectedSidecarContainerInPod(container) {
+			if sidecarcontrol.IsSidecarTerminateWhenJobExit(container) && status.State.Running != nil {
+				sidecars = append(sidecars, container.Name)
+			}
+			continue
+		}
+		terminated := status.State.Terminated
+		if terminated == nil {
+			return nil
+		}
+		// the failed app container will be restarted by kubelet with OnFailure policy
+		if terminated.ExitCode != 0 && pod.Spec.RestartPolicy == v1.RestartPolicyOnFailure {
+			return nil
+		}
+	}
+	return sidecars
+}
+
+func getContainerStatus(pod *v1.Pod, name string) *v1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == name {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+func enqueue(q workqueue.Interface, pod *v1.Pod) {
+	q.Add(pod.Namespace + "/" + pod.Name)
+}
+
+func (c *Controller) Run(stop <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting sidecarlifecycle Controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(func() {
+			for c.processNextWorkItem() {
+			}
+		}, time.Second, stop)
+	}
+
+	klog.Info("Started sidecarlifecycle Controller successfully")
+	<-stop
+}
+
+// processNextWorkItem will read a single work item off the workqueue and
+// attempt to process it, by calling the syncHandler.
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.sync(key.(string))
+
+	if err == nil {
+		// No error, tell the queue to stop tracking history
+		c.queue.Forget(key)
+	} else {
+		// requeue the item to work on later
+		c.queue.AddRateLimited(key)
+	}
+
+	return true
+}
+
+func (c *Controller) sync(key string) (retErr error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.Warningf("Invalid key: %s", key)
+		return nil
+	}
+
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("Failed to get Pod %s/%s from lister: %v", namespace, name, err)
+		return err
+	} else if !shouldSync(pod) {
+		return nil
+	}
+
+	klog.V(3).Infof("Start syncing for %s/%s", namespace, name)
+	defer func() {
+		if retErr != nil {
+			klog.Errorf("Failed to sync for %s/%s: %v", namespace, name, retErr)
+		} else {
+			klog.V(3).Infof("Finished syncing for %s/%s", namespace, name)
+		}
+	}()
+
+	sidecars := getSidecarsToTerminate(pod)
+	if len(sidecars) == 0 {
+		return nil
+	}
+	return c.terminateSidecars(pod, sidecars)
+}
+
+// terminateSidecars gracefully stops the running sidecar containers, which runs the preStop hook
+// and sends SIGTERM with the termination grace period of pod, in case kubelet has not stopped them.
+// The pod phase is still derived by kubelet, so the sidecar containers should exit with code 0 on SIGTERM.
+func (c *Controller) terminateSidecars(pod *v1.Pod, sidecars []string) error {
+	kubeRuntime, err := c.getRuntimeForPod(pod)
+	if err != nil {
+		klog.Errorf("Failed to get runtime for Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return nil
+	}
+	kubePodStatus, err := kubeRuntime.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to GetPodStatus: %v", err)
+	}
+
+	for _, name := range sidecars {
+		status := kubePodStatus.FindContainerStatusByName(name)
+		if status == nil || status.State != kubeletcontainer.ContainerStateRunning {
+			continue
+		}
+		klog.Infof("Stopping sidecar container %s (%s) in Pod %s/%s for app containers exited", name, status.ID.ID, pod.Namespace, pod.Name)
+		msg := fmt.Sprintf("Sidecar container %s will be stopped for app containers exited", name)
+		if err := kubeRuntime.KillContainer(pod, status.ID, name, msg, nil); err != nil {
+			return fmt.Errorf("failed to stop sidecar container %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) getRuntimeForPod(pod *v1.Pod) (kuberuntime.Runtime, error) {
+	var rawContainerID string
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].ContainerID != "" {
+			rawContainerID = pod.Status.ContainerStatuses[i].ContainerID
+			break
+		}
+	}
+	if rawContainerID == "" {
+		return nil, fmt.Errorf("no containerID in pod status")
+	}
+
+	containerID := kubeletcontainer.ContainerID{}
+	if err := containerID.ParseString(rawContainerID); err != nil {
+		return nil, fmt.Errorf("failed to parse containerID %s: %v", rawContainerID, err)
+	} else if containerID.Type == "" {
+		return nil, fmt.Errorf("no runtime name in containerID %s", rawContainerID)
+	}
+
+	runtimeName := containerID.Type
+	runtimeService := c.runtimeFactory.GetRuntimeServiceByName(runtimeName)
+	if runtimeService == nil {
+		return nil, fmt.Errorf("not found runtime service for %s in daemon", runtimeName)
+	}
+
+	return kuberuntime.NewGenericRuntime(runtimeName, runtimeService, c.eventRecorder, &http.Client{}), nil
+}
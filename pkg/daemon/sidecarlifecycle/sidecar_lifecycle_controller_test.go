@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarlifecycle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPod(restartPolicy v1.RestartPolicy, sidecarStatus, appStatus v1.ContainerStatus) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job-pod"},
+		Spec: v1.PodSpec{
+			RestartPolicy: restartPolicy,
+			Containers: []v1.Container{
+				{
+					Name: "sidecar",
+					Env: []v1.EnvVar{
+						{Name: sidecarcontrol.SidecarEnvKey, Value: "true"},
+						{Name: sidecarcontrol.SidecarStartBeforeAppEnvKey, Value: "true"},
+						{Name: sidecarcontrol.SidecarTerminateWhenJobExitEnvKey, Value: "true"},
+					},
+				},
+				{Name: "main"},
+			},
+		},
+	}
+	sidecarcontrol.InjectSidecarBarrier(pod, "")
+	sidecarStatus.Name = "sidecar"
+	appStatus.Name = "main"
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{sidecarStatus, appStatus}
+	return pod
+}
+
+func TestGetSidecarsToTerminate(t *testing.T) {
+	running := v1.ContainerStatus{State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Ready: true}
+	succeeded := v1.ContainerStatus{State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 0}}}
+	failed := v1.ContainerStatus{State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1}}}
+	cases := []struct {
+		name          string
+		restartPolicy v1.RestartPolicy
+		sidecarStatus v1.ContainerStatus
+		appStatus     v1.ContainerStatus
+		expected      []string
+	}{
+		{
+			name:          "app still running",
+			restartPolicy: v1.RestartPolicyNever,
+			sidecarStatus: running,
+			appStatus:     running,
+		},
+		{
+			name:          "app succeeded",
+			restartPolicy: v1.RestartPolicyNever,
+			sidecarStatus: running,
+			appStatus:     succeeded,
+			expected:      []string{"sidecar"},
+		},
+		{
+			name:          "app failed with restartPolicy Never",
+			restartPolicy: v1.RestartPolicyNever,
+			sidecarStatus: running,
+			appStatus:     failed,
+			expected:      []string{"sidecar"},
+		},
+		{
+			name:          "app succeeded with restartPolicy OnFailure",
+			restartPolicy: v1.RestartPolicyOnFailure,
+			sidecarStatus: running,
+			appStatus:     succeeded,
+			expected:      []string{"sidecar"},
+		},
+		{
+			name:          "app failed with restartPolicy OnFailure",
+			restartPolicy: v1.RestartPolicyOnFailure,
+			sidecarStatus: running,
+			appStatus:     failed,
+		},
+		{
+			name:          "sidecar has stopped",
+			restartPolicy: v1.RestartPolicyNever,
+			sidecarStatus: succeeded,
+			appStatus:     succeeded,
+		},
+		{
+			name:          "restartPolicy Always",
+			restartPolicy: v1.RestartPolicyAlways,
+			sidecarStatus: running,
+			appStatus:     succeeded,
+		},
+	}
+
+	for _, tc := range cases {
+		pod := newTestPod(tc.restartPolicy, tc.sidecarStatus, tc.appStatus)
+		got := getSidecarsToTerminate(pod)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
@@ -42,6 +42,20 @@ type ImagePullAccountManager interface {
 	GetAccountInfo(repo string) (*AuthInfo, error)
 }
 
+// imageAccountGetter is implemented by the ImagePullAccountManager which gets the account by the full image reference.
+type imageAccountGetter interface {
+	GetImageAccountInfo(image string) (*AuthInfo, error)
+}
+
+// GetAccountInfoForImage returns the account to pull the image, the full image reference is passed to
+// the account manager if it supports, otherwise the registry of the image.
+func GetAccountInfoForImage(m ImagePullAccountManager, image string) (*AuthInfo, error) {
+	if getter, ok := m.(imageAccountGetter); ok {
+		return getter.GetImageAccountInfo(image)
+	}
+	return m.GetAccountInfo(ParseRegistry(image))
+}
+
 // NewImagePullAccountManager returns an ImagePullAccountManager, defaults to be nil
 func NewImagePullAccountManager(kubeClient clientset.Interface) ImagePullAccountManager {
 	return nil
@@ -0,0 +1,356 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+)
+
+const (
+	credentialProviderAPIVersion = "credentialprovider.kubelet.k8s.io/v1alpha1"
+	credentialProviderTimeout    = time.Minute
+
+	// the cacheKeyType in the response of plugins
+	cacheKeyTypeImage    = "Image"
+	cacheKeyTypeRegistry = "Registry"
+	cacheKeyTypeGlobal   = "Global"
+)
+
+// CredentialProviderConfig is the configuration of exec credential providers, which has the same format
+// as the CredentialProviderConfig (kubelet.config.k8s.io/v1alpha1) of kubelet, so that the plugins
+// implementing the kubelet credential provider protocol can be used by kruise-daemon directly.
+type CredentialProviderConfig struct {
+	Providers []CredentialProvider `json:"providers"`
+}
+
+// CredentialProvider represents an exec plugin to be invoked for the credentials of registries.
+type CredentialProvider struct {
+	// Name is the name of the plugin binary in the bin dir.
+	Name string `json:"name"`
+	// MatchImages is a list of patterns to match the registries, such as '*.registry.io' or 'registry.io:8080'.
+	// Each dot-separated part of the host is matched as a glob, and the path of the pattern is ignored
+	// because the credentials are requested per registry.
+	MatchImages []string `json:"matchImages"`
+	// DefaultCacheDuration is used to cache the credentials if the plugin response does not specify one.
+	DefaultCacheDuration *metav1.Duration `json:"defaultCacheDuration,omitempty"`
+	// APIVersion is the version of the protocol, only credentialprovider.kubelet.k8s.io/v1alpha1 is supported.
+	APIVersion string `json:"apiVersion"`
+	// Args are the arguments to pass when executing the plugin.
+	Args []string `json:"args,omitempty"`
+	// Env are the additional environment variables to expose to the plugin.
+	Env []ExecEnvVar `json:"env,omitempty"`
+}
+
+// ExecEnvVar is an environment variable used when executing the plugin.
+type ExecEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type credentialProviderRequest struct {
+	metav1.TypeMeta `json:",inline"`
+	Image           string `json:"image"`
+}
+
+type credentialProviderResponse struct {
+	metav1.TypeMeta `json:",inline"`
+	CacheKeyType    string                            `json:"cacheKeyType"`
+	CacheDuration   *metav1.Duration                  `json:"cacheDuration,omitempty"`
+	Auth            map[string]credentialProviderAuth `json:"auth,omitempty"`
+}
+
+type credentialProviderAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type cachedAuthInfo struct {
+	authInfo   *AuthInfo
+	expiration time.Time
+}
+
+// credentialCacheKey is the key of cached credentials, the key is the image, the registry or empty
+// for the Image, Registry and Global cacheKeyType respectively.
+type credentialCacheKey struct {
+	provider string
+	keyType  string
+	key      string
+}
+
+// NewCredentialProviderAccountManager returns an ImagePullAccountManager which gets the accounts of
+// registries by executing the credential provider plugins in binDir.
+func NewCredentialProviderAccountManager(configFile, binDir string) (ImagePullAccountManager, error) {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential provider config %s: %v", configFile, err)
+	}
+	config := &CredentialProviderConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse credential provider config %s: %v", configFile, err)
+	}
+	if binDir == "" {
+		return nil, fmt.Errorf("bin dir of credential providers can not be empty")
+	}
+	if err := validateCredentialProviderConfig(config); err != nil {
+		return nil, err
+	}
+	for _, provider := range config.Providers {
+		if _, err := os.Stat(filepath.Join(binDir, provider.Name)); err != nil {
+			return nil, fmt.Errorf("failed to find credential provider %s: %v", provider.Name, err)
+		}
+	}
+	return &credentialProviderAccountManager{
+		binDir:    binDir,
+		providers: config.Providers,
+		cache:     make(map[credentialCacheKey]cachedAuthInfo),
+		execFn:    execCredentialProvider,
+	}, nil
+}
+
+func validateCredentialProviderConfig(config *CredentialProviderConfig) error {
+	if len(config.Providers) == 0 {
+		return fmt.Errorf("at least one credential provider is required")
+	}
+	names := make(map[string]struct{}, len(config.Providers))
+	for _, provider := range config.Providers {
+		if provider.Name == "" || strings.ContainsAny(provider.Name, "/\\") || provider.Name == "." || provider.Name == ".." {
+			return fmt.Errorf("invalid credential provider name %q", provider.Name)
+		}
+		if _, ok := names[provider.Name]; ok {
+			return fmt.Errorf("duplicate credential provider name %q", provider.Name)
+		}
+		names[provider.Name] = struct{}{}
+		if len(provider.MatchImages) == 0 {
+			return fmt.Errorf("matchImages of credential provider %s can not be empty", provider.Name)
+		}
+		if provider.APIVersion != credentialProviderAPIVersion {
+			return fmt.Errorf("unsupported apiVersion %q of credential provider %s", provider.APIVersion, provider.Name)
+		}
+		if provider.DefaultCacheDuration != nil && provider.DefaultCacheDuration.Duration < 0 {
+			return fmt.Errorf("defaultCacheDuration of credential provider %s can not be negative", provider.Name)
+		}
+	}
+	return nil
+}
+
+type credentialProviderAccountManager struct {
+	binDir    string
+	providers []CredentialProvider
+
+	cacheLock sync.Mutex
+	cache     map[credentialCacheKey]cachedAuthInfo
+
+	execFn func(binDir string, provider *CredentialProvider, request []byte) ([]byte, error)
+}
+
+// GetAccountInfo gets the account by the registry, prefer GetImageAccountInfo if the image is known.
+func (m *credentialProviderAccountManager) GetAccountInfo(repo string) (*AuthInfo, error) {
+	return m.getAccountInfo(repo, normalizeRegistry(repo))
+}
+
+// GetImageAccountInfo gets the account of the image from the first provider matching its registry,
+// the full image reference is sent to the plugin as kubelet does.
+func (m *credentialProviderAccountManager) GetImageAccountInfo(image string) (*AuthInfo, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image %s: %v", image, err)
+	}
+	return m.getAccountInfo(image, reference.Domain(named))
+}
+
+func (m *credentialProviderAccountManager) getAccountInfo(image, registry string) (*AuthInfo, error) {
+	var errs []error
+	for i := range m.providers {
+		provider := &m.providers[i]
+		if !matchRegistry(provider.MatchImages, registry) {
+			continue
+		}
+		if authInfo, ok := m.getCache(provider.Name, image, registry); ok {
+			return authInfo, nil
+		}
+		authInfo, cacheKey, cacheDuration, err := m.getAccountFromProvider(provider, image, registry)
+		if err != nil {
+			klog.Warningf("Failed to get account for image %v from credential provider %v: %v", image, provider.Name, err)
+			errs = append(errs, err)
+			continue
+		}
+		if authInfo == nil {
+			continue
+		}
+		m.setCache(cacheKey, authInfo, cacheDuration)
+		return authInfo, nil
+	}
+	return nil, utilerrors.NewAggregate(errs)
+}
+
+func (m *credentialProviderAccountManager) getAccountFromProvider(provider *CredentialProvider, image, registry string) (*AuthInfo, credentialCacheKey, time.Duration, error) {
+	cacheKey := credentialCacheKey{provider: provider.Name}
+	request, err := json.Marshal(&credentialProviderRequest{
+		TypeMeta: metav1.TypeMeta{APIVersion: provider.APIVersion, Kind: "CredentialProviderRequest"},
+		Image:    image,
+	})
+	if err != nil {
+		return nil, cacheKey, 0, err
+	}
+	output, err := m.execFn(m.binDir, provider, request)
+	if err != nil {
+		return nil, cacheKey, 0, err
+	}
+
+	response := &credentialProviderResponse{}
+	if err := json.Unmarshal(output, response); err != nil {
+		return nil, cacheKey, 0, fmt.Errorf("failed to decode response: %v", err)
+	}
+	if response.APIVersion != provider.APIVersion || response.Kind != "CredentialProviderResponse" {
+		return nil, cacheKey, 0, fmt.Errorf("unexpected response %s/%s", response.APIVersion, response.Kind)
+	}
+
+	cacheKey.keyType = response.CacheKeyType
+	switch response.CacheKeyType {
+	case cacheKeyTypeImage:
+		cacheKey.key = image
+	case cacheKeyTypeRegistry:
+		cacheKey.key = registry
+	case cacheKeyTypeGlobal:
+	default:
+		return nil, cacheKey, 0, fmt.Errorf("unsupported cacheKeyType %q", response.CacheKeyType)
+	}
+
+	var cacheDuration time.Duration
+	if response.CacheDuration != nil {
+		cacheDuration = response.CacheDuration.Duration
+	} else if provider.DefaultCacheDuration != nil {
+		cacheDuration = provider.DefaultCacheDuration.Duration
+	}
+	for pattern, auth := range response.Auth {
+		if matchRegistry([]string{pattern}, registry) {
+			return &AuthInfo{Username: auth.Username, Password: auth.Password}, cacheKey, cacheDuration, nil
+		}
+	}
+	return nil, cacheKey, 0, nil
+}
+
+// getCache looks up the credentials cached by the provider for the image, the registry and globally in order.
+func (m *credentialProviderAccountManager) getCache(provider, image, registry string) (*AuthInfo, bool) {
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+	for _, key := range []credentialCacheKey{
+		{provider: provider, keyType: cacheKeyTypeImage, key: image},
+		{provider: provider, keyType: cacheKeyTypeRegistry, key: registry},
+		{provider: provider, keyType: cacheKeyTypeGlobal},
+	} {
+		cached, ok := m.cache[key]
+		if !ok {
+			continue
+		}
+		if time.Now().After(cached.expiration) {
+			delete(m.cache, key)
+			continue
+		}
+		return cached.authInfo, true
+	}
+	return nil, false
+}
+
+func (m *credentialProviderAccountManager) setCache(key credentialCacheKey, authInfo *AuthInfo, cacheDuration time.Duration) {
+	if cacheDuration <= 0 {
+		return
+	}
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+	m.cache[key] = cachedAuthInfo{authInfo: authInfo, expiration: time.Now().Add(cacheDuration)}
+}
+
+func execCredentialProvider(binDir string, provider *CredentialProvider, request []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), credentialProviderTimeout)
+	defer cancel()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, filepath.Join(binDir, provider.Name), provider.Args...)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+	for _, env := range provider.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", env.Name, env.Value))
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to exec credential provider %s: %v, stderr: %s", provider.Name, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// normalizeRegistry returns docker.io for the images without registry, such as nginx and library/nginx.
+func normalizeRegistry(registry string) string {
+	if !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		return "docker.io"
+	}
+	return registry
+}
+
+// matchRegistry returns true if the registry matches any of the patterns.
+func matchRegistry(patterns []string, registry string) bool {
+	host, port := splitHostPort(registry)
+	hostParts := strings.Split(host, ".")
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "https://"), "http://")
+		patternHost, patternPort := splitHostPort(strings.SplitN(pattern, "/", 2)[0])
+		if patternPort != port {
+			continue
+		}
+		patternParts := strings.Split(patternHost, ".")
+		if len(patternParts) != len(hostParts) {
+			continue
+		}
+		matched := true
+		for i := range patternParts {
+			if ok, err := filepath.Match(patternParts[i], hostParts[i]); err != nil || !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func splitHostPort(hostPort string) (string, string) {
+	if host, port, err := net.SplitHostPort(hostPort); err == nil {
+		return host, port
+	}
+	return hostPort, ""
+}
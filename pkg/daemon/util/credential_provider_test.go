@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchRegistry(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		registry string
+		expected bool
+	}{
+		{patterns: []string{"*.dkr.ecr.*.amazonaws.com"}, registry: "123.dkr.ecr.us-west-2.amazonaws.com", expected: true},
+		{patterns: []string{"*.dkr.ecr.*.amazonaws.com"}, registry: "dkr.ecr.us-west-2.amazonaws.com", expected: false},
+		{patterns: []string{"registry.io:8080/foo"}, registry: "registry.io:8080", expected: true},
+		{patterns: []string{"registry.io:8080"}, registry: "registry.io", expected: false},
+		{patterns: []string{"other.io", "https://*.io"}, registry: "registry.io", expected: true},
+	}
+	for i, tc := range cases {
+		if got := matchRegistry(tc.patterns, tc.registry); got != tc.expected {
+			t.Errorf("case #%d: expected %v, got %v", i, tc.expected, got)
+		}
+	}
+}
+
+func TestCredentialProviderGetAccountInfo(t *testing.T) {
+	cases := []struct {
+		cacheKeyType      string
+		images            []string
+		expectedExecTimes int
+	}{
+		{cacheKeyType: "Image", images: []string{"nginx:latest", "nginx:latest", "busybox:latest"}, expectedExecTimes: 2},
+		{cacheKeyType: "Registry", images: []string{"nginx:latest", "busybox:latest", "docker.io/library/nginx:1.9"}, expectedExecTimes: 1},
+		{cacheKeyType: "Global", images: []string{"nginx:latest", "busybox:latest"}, expectedExecTimes: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.cacheKeyType, func(t *testing.T) {
+			var requestedImages []string
+			m := &credentialProviderAccountManager{
+				providers: []CredentialProvider{
+					{Name: "other", MatchImages: []string{"other.io"}, APIVersion: credentialProviderAPIVersion},
+					{Name: "dockerhub", MatchImages: []string{"docker.io"}, APIVersion: credentialProviderAPIVersion, DefaultCacheDuration: &metav1.Duration{Duration: time.Hour}},
+				},
+				cache: make(map[credentialCacheKey]cachedAuthInfo),
+				execFn: func(binDir string, provider *CredentialProvider, request []byte) ([]byte, error) {
+					req := credentialProviderRequest{}
+					if err := json.Unmarshal(request, &req); err != nil {
+						t.Fatalf("failed to decode request: %v", err)
+					}
+					if provider.Name != "dockerhub" || req.Kind != "CredentialProviderRequest" {
+						t.Fatalf("unexpected request %s to provider %s", request, provider.Name)
+					}
+					requestedImages = append(requestedImages, req.Image)
+					return json.Marshal(&credentialProviderResponse{
+						TypeMeta:     metav1.TypeMeta{APIVersion: credentialProviderAPIVersion, Kind: "CredentialProviderResponse"},
+						CacheKeyType: tc.cacheKeyType,
+						Auth:         map[string]credentialProviderAuth{"docker.io": {Username: "foo", Password: "bar"}},
+					})
+				},
+			}
+
+			for _, image := range tc.images {
+				authInfo, err := GetAccountInfoForImage(m, image)
+				if err != nil {
+					t.Fatalf("failed to get account: %v", err)
+				}
+				if authInfo == nil || authInfo.Username != "foo" || authInfo.Password != "bar" {
+					t.Fatalf("unexpected account %v", authInfo)
+				}
+			}
+			if len(requestedImages) != tc.expectedExecTimes || requestedImages[0] != tc.images[0] {
+				t.Fatalf("expected %d requests starting with the full image %s, got %v", tc.expectedExecTimes, tc.images[0], requestedImages)
+			}
+
+			if authInfo, err := m.GetAccountInfo("unknown.io"); err != nil || authInfo != nil {
+				t.Fatalf("expected no account, got %v, %v", authInfo, err)
+			}
+		})
+	}
+}
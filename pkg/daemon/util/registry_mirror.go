@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// RegistryMirrorConfig is the configuration of registry mirrors for pulling images in kruise-daemon.
+//
+// For example, the config below makes docker.io/library/nginx pulled from mirror.local/library/nginx
+// and then mirror.local:5000/dockerhub/library/nginx, and from docker.io if both of them failed:
+//
+//	mirrors:
+//	  docker.io:
+//	  - mirror.local
+//	  - mirror.local:5000/dockerhub
+type RegistryMirrorConfig struct {
+	// Mirrors maps a registry to its mirror endpoints, which are in the form of host[:port][/path]
+	// and will be tried in order before the registry itself.
+	Mirrors map[string][]string `json:"mirrors,omitempty"`
+}
+
+// ImageMirrorManager rewrites the images to their mirrors.
+type ImageMirrorManager interface {
+	// GetMirrors returns the names of the image in its registry mirrors, in the order to be tried.
+	GetMirrors(imageName string) []string
+}
+
+// NewImageMirrorManager returns an ImageMirrorManager with the config file, and returns nil if configFile is empty.
+func NewImageMirrorManager(configFile string) (ImageMirrorManager, error) {
+	if configFile == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry mirror config %s: %v", configFile, err)
+	}
+	config := &RegistryMirrorConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse registry mirror config %s: %v", configFile, err)
+	}
+	return newImageMirrorManager(config)
+}
+
+func newImageMirrorManager(config *RegistryMirrorConfig) (*imageMirrorManager, error) {
+	m := &imageMirrorManager{mirrors: make(map[string][]string, len(config.Mirrors))}
+	for registry, endpoints := range config.Mirrors {
+		if registry == "" {
+			return nil, fmt.Errorf("registry of mirrors can not be empty")
+		}
+		for _, endpoint := range endpoints {
+			endpoint = strings.TrimSuffix(endpoint, "/")
+			if endpoint == "" || strings.Contains(endpoint, "://") {
+				return nil, fmt.Errorf("invalid mirror %q of registry %s, should be host[:port][/path]", endpoint, registry)
+			}
+			if _, err := reference.ParseNamed(endpoint + "/image"); err != nil {
+				return nil, fmt.Errorf("invalid mirror %q of registry %s: %v", endpoint, registry, err)
+			}
+			m.mirrors[registry] = append(m.mirrors[registry], endpoint)
+		}
+	}
+	return m, nil
+}
+
+type imageMirrorManager struct {
+	mirrors map[string][]string
+}
+
+func (m *imageMirrorManager) GetMirrors(imageName string) []string {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return nil
+	}
+	domain, path := reference.Domain(named), reference.Path(named)
+
+	var names []string
+	for _, endpoint := range m.mirrors[domain] {
+		if endpoint == domain {
+			continue
+		}
+		names = append(names, endpoint+"/"+path)
+	}
+	return names
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetMirrors(t *testing.T) {
+	m, err := newImageMirrorManager(&RegistryMirrorConfig{Mirrors: map[string][]string{
+		"docker.io":   {"mirror.local", "mirror.local:5000/dockerhub/"},
+		"registry.io": {"registry.io"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to new mirror manager: %v", err)
+	}
+
+	cases := map[string][]string{
+		"nginx":                       {"mirror.local/library/nginx", "mirror.local:5000/dockerhub/library/nginx"},
+		"docker.io/openkruise/kruise": {"mirror.local/openkruise/kruise", "mirror.local:5000/dockerhub/openkruise/kruise"},
+		"registry.io/foo/bar":         nil,
+		"other.io/foo":                nil,
+		"INVALID":                     nil,
+	}
+	for imageName, expected := range cases {
+		if got := m.GetMirrors(imageName); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected mirrors of %s %v, got %v", imageName, expected, got)
+		}
+	}
+
+	if _, err := newImageMirrorManager(&RegistryMirrorConfig{Mirrors: map[string][]string{"docker.io": {"https://mirror.local"}}}); err == nil {
+		t.Errorf("expected error for mirror with scheme")
+	}
+}
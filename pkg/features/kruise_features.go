@@ -75,6 +75,30 @@ const (
 	// and pvc default fields into pvc template.
 	// If TemplateNoDefaults is false, webhook should inject default fields only when the template changed.
 	TemplateNoDefaults featuregate.Feature = "TemplateNoDefaults"
+
+	// InPlaceUpdateContainerResources enables workloads to in-place update the resources of containers,
+	// which will be applied to runtime containers by kruise-daemon without recreating Pods.
+	// Note that the resources in Pod spec are not changed, so scheduler, quota and eviction still use the old ones.
+	// Changes that remove limits or increase limits over the pod-level cgroup can not be updated in-place.
+	InPlaceUpdateContainerResources featuregate.Feature = "InPlaceUpdateContainerResources"
+
+	// InPlaceUpdateEnvFromMetadata enables workloads to in-place update the env that refers to labels/annotations
+	// of Pod, by which kruise-daemon will restart the containers in-place after the metadata is updated.
+	InPlaceUpdateEnvFromMetadata featuregate.Feature = "InPlaceUpdateEnvFromMetadata"
+
+	// PersistentPodState enables PersistentPodState to record the node topology of Advanced StatefulSet Pods,
+	// and inject node affinity into the recreated Pods by webhook.
+	PersistentPodState featuregate.Feature = "PersistentPodState"
+
+	// StatefulSetAutoDeletePVC enables policies controlling deletion of PVCs created by a StatefulSet.
+	StatefulSetAutoDeletePVC featuregate.Feature = "StatefulSetAutoDeletePVC"
+
+	// StatefulSetStartOrdinal enables Advanced StatefulSet to number its Pods from spec.ordinals.start.
+	StatefulSetStartOrdinal featuregate.Feature = "StatefulSetStartOrdinal"
+
+	// SidecarSetLifecycle enables the sidecar containers of SidecarSet to start before the app containers,
+	// and be stopped by kruise-daemon when the app containers of a Job Pod have exited.
+	SidecarSetLifecycle featuregate.Feature = "SidecarSetLifecycle"
 )
 
 var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
@@ -91,6 +115,12 @@ var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	PodUnavailableBudgetDeleteGate:   {Default: false, PreRelease: featuregate.Alpha},
 	PodUnavailableBudgetUpdateGate:   {Default: false, PreRelease: featuregate.Alpha},
 	TemplateNoDefaults:               {Default: false, PreRelease: featuregate.Alpha},
+	InPlaceUpdateContainerResources:  {Default: false, PreRelease: featuregate.Alpha},
+	InPlaceUpdateEnvFromMetadata:     {Default: false, PreRelease: featuregate.Alpha},
+	PersistentPodState:               {Default: false, PreRelease: featuregate.Alpha},
+	StatefulSetAutoDeletePVC:         {Default: false, PreRelease: featuregate.Alpha},
+	StatefulSetStartOrdinal:          {Default: false, PreRelease: featuregate.Alpha},
+	SidecarSetLifecycle:              {Default: false, PreRelease: featuregate.Alpha},
 }
 
 func init() {
@@ -117,9 +147,15 @@ func SetDefaultFeatureGates() {
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PodUnavailableBudgetDeleteGate))
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PodUnavailableBudgetUpdateGate))
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", WorkloadSpread))
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PersistentPodState))
 	}
 	if !utilfeature.DefaultFeatureGate.Enabled(KruiseDaemon) {
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PreDownloadImageForInPlaceUpdate))
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", DaemonWatchingPod))
 	}
+	if !utilfeature.DefaultFeatureGate.Enabled(DaemonWatchingPod) {
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", InPlaceUpdateContainerResources))
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", InPlaceUpdateEnvFromMetadata))
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", SidecarSetLifecycle))
+	}
 }
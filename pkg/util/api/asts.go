@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+)
+
+// ParseRange parses the ordinal range in the format of "start-end", both of them are inclusive.
+func ParseRange(s string) (start, end int, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q, must be in the format of start-end", s)
+	}
+	if start, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %v", s, err)
+	}
+	if end, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %v", s, err)
+	}
+	if start < 0 || start > end {
+		return 0, 0, fmt.Errorf("invalid range %q, start must be >= 0 and not greater than end", s)
+	}
+	return start, end, nil
+}
+
+// ReserveOrdinalSet is the set of reserved ordinals, in which the ranges are kept without being expanded.
+type ReserveOrdinalSet struct {
+	ordinals sets.Int
+	ranges   [][2]int
+}
+
+// Has returns true if the ordinal is reserved.
+func (s *ReserveOrdinalSet) Has(ordinal int) bool {
+	_, ok := s.lastReserved(ordinal)
+	return ok
+}
+
+// lastReserved returns the end of the reserved item that contains the ordinal, so that the ordinals
+// in a range can be skipped together.
+func (s *ReserveOrdinalSet) lastReserved(ordinal int) (int, bool) {
+	last, ok := ordinal, s.ordinals.Has(ordinal)
+	for _, r := range s.ranges {
+		if r[0] <= ordinal && ordinal <= r[1] {
+			ok = true
+			if r[1] > last {
+				last = r[1]
+			}
+		}
+	}
+	return last, ok
+}
+
+// GetReserveOrdinalSet returns the set of ordinals in reserveOrdinals, the invalid items are ignored.
+func GetReserveOrdinalSet(reserveOrdinals []intstr.IntOrString) *ReserveOrdinalSet {
+	s := &ReserveOrdinalSet{ordinals: sets.NewInt()}
+	for _, item := range reserveOrdinals {
+		if item.Type == intstr.Int {
+			s.ordinals.Insert(item.IntValue())
+			continue
+		}
+		if ordinal, err := strconv.Atoi(item.StrVal); err == nil {
+			s.ordinals.Insert(ordinal)
+			continue
+		}
+		if start, end, err := ParseRange(item.StrVal); err == nil {
+			s.ranges = append(s.ranges, [2]int{start, end})
+		}
+	}
+	return s
+}
+
+// GetStartOrdinal returns the ordinal of the first replica of Advanced StatefulSet,
+// which is always 0 unless StatefulSetStartOrdinal feature-gate is enabled.
+func GetStartOrdinal(set *appsv1beta1.StatefulSet) int {
+	if utilfeature.DefaultFeatureGate.Enabled(features.StatefulSetStartOrdinal) && set.Spec.Ordinals != nil {
+		return int(set.Spec.Ordinals.Start)
+	}
+	return 0
+}
+
+// GetOrdinalRange returns the range [start, end) of ordinals that the replicas of Advanced StatefulSet
+// may use, and the reserved ordinals that should be skipped in the range.
+func GetOrdinalRange(set *appsv1beta1.StatefulSet) (start, end int, reserveOrdinals *ReserveOrdinalSet) {
+	replicas := 1
+	if set.Spec.Replicas != nil {
+		replicas = int(*set.Spec.Replicas)
+	}
+	reserveOrdinals = GetReserveOrdinalSet(set.Spec.ReserveOrdinals)
+	start = GetStartOrdinal(set)
+	end = start
+	for count := 0; count < replicas; end++ {
+		if last, ok := reserveOrdinals.lastReserved(end); ok {
+			end = last
+		} else {
+			count++
+		}
+	}
+	return start, end, reserveOrdinals
+}
+
+// GetValidOrdinals returns the ordinals of Pods that Advanced StatefulSet expects to have,
+// which are in the ordinal range and not reserved.
+func GetValidOrdinals(set *appsv1beta1.StatefulSet) sets.Int {
+	start, end, reserveOrdinals := GetOrdinalRange(set)
+	validOrdinals := sets.NewInt()
+	for ordinal := start; ordinal < end; ordinal++ {
+		if last, ok := reserveOrdinals.lastReserved(ordinal); ok {
+			ordinal = last
+		} else {
+			validOrdinals.Insert(ordinal)
+		}
+	}
+	return validOrdinals
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilpointer "k8s.io/utils/pointer"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		input      string
+		start, end int
+		expectErr  bool
+	}{
+		{input: "1-3", start: 1, end: 3},
+		{input: "2-2", start: 2, end: 2},
+		{input: "3-1", expectErr: true},
+		{input: "1", expectErr: true},
+		{input: "a-3", expectErr: true},
+		{input: "1-2-3", expectErr: true},
+	}
+	for _, cs := range cases {
+		start, end, err := ParseRange(cs.input)
+		if cs.expectErr != (err != nil) {
+			t.Fatalf("%s: expected error %v, got %v", cs.input, cs.expectErr, err)
+		}
+		if err == nil && (start != cs.start || end != cs.end) {
+			t.Fatalf("%s: expected [%d, %d], got [%d, %d]", cs.input, cs.start, cs.end, start, end)
+		}
+	}
+}
+
+func TestGetReserveOrdinalSet(t *testing.T) {
+	reserveOrdinals := []intstr.IntOrString{intstr.FromInt(1), intstr.FromString("3-5"), intstr.FromString("7"), intstr.FromString("x"),
+		intstr.FromString("100-1000000000")}
+	s := GetReserveOrdinalSet(reserveOrdinals)
+	var got []int
+	for ordinal := 0; ordinal < 10; ordinal++ {
+		if s.Has(ordinal) {
+			got = append(got, ordinal)
+		}
+	}
+	expected := []int{1, 3, 4, 5, 7}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	if !s.Has(100) || !s.Has(1000000000) || s.Has(1000000001) {
+		t.Fatalf("expected range 100-1000000000 reserved")
+	}
+}
+
+func TestGetValidOrdinals(t *testing.T) {
+	cases := []struct {
+		name            string
+		enableStart     bool
+		start           *int32
+		replicas        int32
+		reserveOrdinals []intstr.IntOrString
+		expected        []int
+	}{
+		{
+			name:     "default",
+			replicas: 3,
+			expected: []int{0, 1, 2},
+		},
+		{
+			name:            "reserve ordinals",
+			replicas:        3,
+			reserveOrdinals: []intstr.IntOrString{intstr.FromInt(0), intstr.FromString("2-3")},
+			expected:        []int{1, 4, 5},
+		},
+		{
+			name:            "wide reserved range",
+			replicas:        3,
+			reserveOrdinals: []intstr.IntOrString{intstr.FromString("1-1000000000"), intstr.FromInt(1000000001)},
+			expected:        []int{0, 1000000002, 1000000003},
+		},
+		{
+			name:     "start ordinal with feature-gate disabled",
+			start:    utilpointer.Int32Ptr(5),
+			replicas: 2,
+			expected: []int{0, 1},
+		},
+		{
+			name:            "start ordinal and reserve ordinals",
+			enableStart:     true,
+			start:           utilpointer.Int32Ptr(5),
+			replicas:        3,
+			reserveOrdinals: []intstr.IntOrString{intstr.FromInt(1), intstr.FromString("6-7")},
+			expected:        []int{5, 8, 9},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.StatefulSetStartOrdinal))
+			_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=%v", features.StatefulSetStartOrdinal, cs.enableStart))
+
+			set := &appsv1beta1.StatefulSet{Spec: appsv1beta1.StatefulSetSpec{
+				Replicas:        utilpointer.Int32Ptr(cs.replicas),
+				ReserveOrdinals: cs.reserveOrdinals,
+			}}
+			if cs.start != nil {
+				set.Spec.Ordinals = &appsv1beta1.StatefulSetOrdinals{Start: *cs.start}
+			}
+			if got := GetValidOrdinals(set).List(); !reflect.DeepEqual(got, cs.expected) {
+				t.Fatalf("expected %v, got %v", cs.expected, got)
+			}
+		})
+	}
+}
@@ -19,11 +19,22 @@ package util
 import (
 	"fmt"
 
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func NewClientFromManager(mgr manager.Manager, name string) client.Client {
+	return newClientFromManager(mgr, name, false)
+}
+
+// NewUnstructuredCachedClientFromManager returns a client that also reads unstructured objects from the cache
+// of manager, which starts the informers of their kinds on demand.
+func NewUnstructuredCachedClientFromManager(mgr manager.Manager, name string) client.Client {
+	return newClientFromManager(mgr, name, true)
+}
+
+func newClientFromManager(mgr manager.Manager, name string, cacheUnstructured bool) client.Client {
 	cfg := *mgr.GetConfig()
 	cfg.UserAgent = fmt.Sprintf("kruise-manager/%s", name)
 
@@ -33,8 +44,23 @@ func NewClientFromManager(mgr manager.Manager, name string) client.Client {
 	}
 
 	delegatingClient, _ := client.NewDelegatingClient(client.NewDelegatingClientInput{
-		CacheReader: mgr.GetCache(),
-		Client:      c,
+		CacheReader:       mgr.GetCache(),
+		Client:            c,
+		CacheUnstructured: cacheUnstructured,
+	})
+	return delegatingClient
+}
+
+// NewUnstructuredCachedClient wraps the client to read objects including unstructured ones from the cache,
+// which starts the informers of their kinds on demand. It returns the client itself if cache is nil.
+func NewUnstructuredCachedClient(c client.Client, cache cache.Cache) client.Client {
+	if cache == nil {
+		return c
+	}
+	delegatingClient, _ := client.NewDelegatingClient(client.NewDelegatingClientInput{
+		CacheReader:       cache,
+		Client:            c,
+		CacheUnstructured: true,
 	})
 	return delegatingClient
 }
@@ -22,9 +22,12 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	scaleclient "k8s.io/client-go/scale"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -60,12 +63,18 @@ type PodControllerFinder func(ref ControllerReference, namespace string) (*Scale
 
 type ControllerFinder struct {
 	client.Client
+
+	discoveryClient discovery.DiscoveryInterface
+	mapper          meta.RESTMapper
+	scaleNamespacer scaleclient.ScalesGetter
 }
 
 func NewControllerFinder(c client.Client) *ControllerFinder {
-	return &ControllerFinder{
+	finder := &ControllerFinder{
 		Client: c,
 	}
+	finder.discoveryClient, finder.mapper, finder.scaleNamespacer = getScaleClients()
+	return finder
 }
 
 func (r *ControllerFinder) GetScaleAndSelectorForRef(apiVersion, kind, ns, name string, uid types.UID) (*ScaleAndSelector, error) {
@@ -84,19 +93,37 @@ func (r *ControllerFinder) GetScaleAndSelectorForRef(apiVersion, kind, ns, name
 	case controllerKindRC.Kind:
 		return r.getPodReplicationController(targetRef, ns)
 	case controllerKindSS.Kind:
+		// StatefulSet and Advanced StatefulSet have the same kind
+		if ok, _ := verifyGroupKind(targetRef, controllerKruiseKindSS.Kind, []string{controllerKruiseKindSS.Group}); ok {
+			return r.getPodKruiseStatefulSet(targetRef, ns)
+		}
 		return r.getPodStatefulSet(targetRef, ns)
 	case controllerKruiseKindCS.Kind:
 		return r.getPodKruiseCloneSet(targetRef, ns)
-	case controllerKruiseKindSS.Kind:
-		return r.getPodKruiseStatefulSet(targetRef, ns)
 	default:
 		return nil, nil
 	}
 }
 
+// GetScaleAndSelectorForTargetRef is similar to GetScaleAndSelectorForRef, but it also finds the workloads with
+// scale subresource, such as UnitedDeployment or third-party CRDs. It should only be used for the targetRef
+// of PodUnavailableBudget or WorkloadSpread, which are configured by users explicitly.
+func (r *ControllerFinder) GetScaleAndSelectorForTargetRef(apiVersion, kind, ns, name string, uid types.UID) (*ScaleAndSelector, error) {
+	targetRef := ControllerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		UID:        uid,
+	}
+	if IsKnownWorkload(apiVersion, kind) {
+		return r.GetScaleAndSelectorForRef(apiVersion, kind, ns, name, uid)
+	}
+	return r.getScaleController(targetRef, ns)
+}
+
 func (r *ControllerFinder) Finders() []PodControllerFinder {
 	return []PodControllerFinder{r.getPodReplicationController, r.getPodDeployment, r.getPodReplicaSet,
-		r.getPodStatefulSet, r.getPodKruiseCloneSet, r.getPodKruiseStatefulSet}
+		r.getPodStatefulSet, r.getPodKruiseCloneSet, r.getPodKruiseStatefulSet, r.getScaleController}
 }
 
 var (
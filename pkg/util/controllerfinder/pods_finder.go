@@ -70,13 +70,21 @@ func (r *ControllerFinder) GetPodsForRef(apiVersion, kind, name, ns string, acti
 		}
 	// others, e.g. rc, cloneset, statefulset...
 	default:
-		obj, err := r.GetScaleAndSelectorForRef(apiVersion, kind, ns, name, "")
+		obj, err := r.GetScaleAndSelectorForTargetRef(apiVersion, kind, ns, name, "")
 		if err != nil {
 			return nil, -1, err
 		}
 		if obj == nil {
 			return nil, 0, nil
 		}
+		// workloads found by scale subresource, whose Pods may be owned by intermediate owners
+		if !IsKnownWorkload(apiVersion, kind) {
+			pods, err := r.getPodsForScaleController(obj, ns, active)
+			if err != nil {
+				return nil, -1, err
+			}
+			return pods, obj.Scale, nil
+		}
 		workloadReplicas = obj.Scale
 		workloadUIDs = append(workloadUIDs, obj.UID)
 	}
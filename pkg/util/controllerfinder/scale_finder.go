@@ -0,0 +1,347 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerfinder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	scaleclient "k8s.io/client-go/scale"
+	"k8s.io/klog/v2"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kruiseclient "github.com/openkruise/kruise/pkg/client"
+)
+
+// Generic workloads are got by unstructured client and their scale subresource, which are only used for
+// the targetRef of PodUnavailableBudget and WorkloadSpread. Only the permissions of the workloads in Kruise
+// are granted by default, the permissions of get/list/watch on third-party workloads, their scale subresources
+// and intermediate owners should be granted to kruise-manager by users explicitly.
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=uniteddeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=uniteddeployments/scale,verbs=get
+
+const (
+	// maxOwnerDepth is the max depth of ownerReferences to walk through intermediate owners.
+	maxOwnerDepth = 5
+
+	// getWorkloadTimeout limits the time of getting generic workload, which may wait for the informer
+	// of its kind to be synced if the client reads unstructured objects from cache.
+	getWorkloadTimeout = 10 * time.Second
+)
+
+var (
+	scaleClientLock sync.Mutex
+	discoveryClient discovery.CachedDiscoveryInterface
+	restMapper      meta.RESTMapper
+	scaleNamespacer scaleclient.ScalesGetter
+)
+
+// getScaleClients returns the shared discovery client, RESTMapper and scale client.
+// They are nil if the generic clientset has not been initialized, which means the generic scale finder is disabled.
+func getScaleClients() (discovery.CachedDiscoveryInterface, meta.RESTMapper, scaleclient.ScalesGetter) {
+	scaleClientLock.Lock()
+	defer scaleClientLock.Unlock()
+	if scaleNamespacer != nil {
+		return discoveryClient, restMapper, scaleNamespacer
+	}
+
+	genericClient := kruiseclient.GetGenericClient()
+	if genericClient == nil {
+		return nil, nil, nil
+	}
+	discoveryClient = memory.NewMemCacheClient(genericClient.DiscoveryClient)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	restMapper = mapper
+	scaleNamespacer = scaleclient.New(genericClient.KubeClient.CoreV1().RESTClient(), mapper,
+		dynamic.LegacyAPIPathResolverFunc, scaleclient.NewDiscoveryScaleKindResolver(discoveryClient))
+	return discoveryClient, restMapper, scaleNamespacer
+}
+
+// isIgnoredScaleError returns true if the error means no workload can be found by scale subresource,
+// e.g., the kind or object does not exist, or kruise-manager is not authorized to get it.
+func isIgnoredScaleError(err error) bool {
+	return errors.IsNotFound(err) || errors.IsForbidden(err) || meta.IsNoMatchError(err)
+}
+
+// IsKnownWorkload returns true if the workload can be found by GetScaleAndSelectorForRef.
+func IsKnownWorkload(apiVersion, kind string) bool {
+	ref := ControllerReference{APIVersion: apiVersion, Kind: kind}
+	for _, gvk := range []schema.GroupVersionKind{controllerKindRS, controllerKindSS, controllerKindRC, controllerKindDep,
+		controllerKruiseKindCS, controllerKruiseKindSS} {
+		if ok, _ := verifyGroupKind(ref, gvk.Kind, []string{gvk.Group}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getScaleController finds any workload with scale subresource, such as UnitedDeployment or third-party CRDs,
+// through discovery and the scale subresource.
+// If the referenced object has no scale subresource, it walks through the controller ownerReferences
+// to find the first owner that has scale subresource.
+func (r *ControllerFinder) getScaleController(ref ControllerReference, namespace string) (*ScaleAndSelector, error) {
+	obj, gr, knownRef, err := r.findScaleWorkload(ref, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if knownRef != nil {
+		return r.GetScaleAndSelectorForRef(knownRef.APIVersion, knownRef.Kind, namespace, knownRef.Name, knownRef.UID)
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	return r.getScaleAndSelector(obj, *gr, namespace)
+}
+
+// GetScaleControllerReference returns the reference of the workload with scale subresource that the referenced
+// object belongs to, walking through the intermediate owners without scale subresource. Unlike
+// GetScaleAndSelectorForTargetRef, it does not get the scale subresource, so the workloads are read from cache
+// if the client of ControllerFinder reads unstructured objects from cache.
+// It returns nil if the referenced object is a known workload or belongs to none workload with scale subresource.
+func (r *ControllerFinder) GetScaleControllerReference(apiVersion, kind, ns, name string, uid types.UID) (*ControllerReference, error) {
+	obj, _, knownRef, err := r.findScaleWorkload(ControllerReference{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid}, ns)
+	if err != nil || knownRef != nil {
+		return knownRef, err
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	return &ControllerReference{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		UID:        obj.GetUID(),
+	}, nil
+}
+
+// findScaleWorkload returns the first workload with scale subresource and its resource, walking through the
+// controller ownerReferences from ref. If a known workload is met on the way, its reference is returned instead.
+// All of them are nil if ref is a known workload or no workload with scale subresource is found.
+func (r *ControllerFinder) findScaleWorkload(ref ControllerReference, namespace string) (*unstructured.Unstructured, *schema.GroupResource, *ControllerReference, error) {
+	if r.discoveryClient == nil || r.mapper == nil || r.scaleNamespacer == nil {
+		return nil, nil, nil, nil
+	}
+
+	for i := 0; i < maxOwnerDepth; i++ {
+		if IsKnownWorkload(ref.APIVersion, ref.Kind) {
+			if i == 0 {
+				return nil, nil, nil, nil
+			}
+			return nil, nil, &ref, nil
+		}
+
+		// This error is irreversible, so there is no need to return error
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || ref.Kind == "" || ref.Name == "" {
+			return nil, nil, nil, nil
+		}
+		mapping, err := r.mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: ref.Kind}, gv.Version)
+		if err != nil {
+			if isIgnoredScaleError(err) {
+				return nil, nil, nil, nil
+			}
+			return nil, nil, nil, err
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gv.WithKind(ref.Kind))
+		ctx, cancel := context.WithTimeout(context.TODO(), getWorkloadTimeout)
+		err = r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, obj)
+		cancel()
+		if err != nil {
+			if isIgnoredScaleError(err) {
+				klog.V(4).Infof("Ignore %s %s/%s for finding scale controller: %v", ref.Kind, namespace, ref.Name, err)
+				return nil, nil, nil, nil
+			}
+			return nil, nil, nil, err
+		}
+		if ref.UID != "" && obj.GetUID() != ref.UID {
+			return nil, nil, nil, nil
+		}
+
+		hasScale, err := r.hasScaleSubresource(mapping.Resource)
+		if err != nil {
+			if isIgnoredScaleError(err) {
+				return nil, nil, nil, nil
+			}
+			return nil, nil, nil, err
+		}
+		if hasScale {
+			gr := mapping.Resource.GroupResource()
+			return obj, &gr, nil, nil
+		}
+
+		// walk through the intermediate owner without scale subresource
+		owner := metav1.GetControllerOf(obj)
+		if owner == nil {
+			return nil, nil, nil, nil
+		}
+		ref = ControllerReference{
+			APIVersion: owner.APIVersion,
+			Kind:       owner.Kind,
+			Name:       owner.Name,
+			UID:        owner.UID,
+		}
+	}
+
+	klog.Warningf("Stop finding scale controller for %s %s/%s, ownerReferences are deeper than %d", ref.Kind, namespace, ref.Name, maxOwnerDepth)
+	return nil, nil, nil, nil
+}
+
+// HasScaleSubresource returns true if the kind can be found by its scale subresource.
+func (r *ControllerFinder) HasScaleSubresource(apiVersion, kind string) (bool, error) {
+	if r.discoveryClient == nil || r.mapper == nil || r.scaleNamespacer == nil {
+		return false, nil
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false, err
+	}
+	mapping, err := r.mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: kind}, gv.Version)
+	if err != nil {
+		if isIgnoredScaleError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return r.hasScaleSubresource(mapping.Resource)
+}
+
+func (r *ControllerFinder) hasScaleSubresource(gvr schema.GroupVersionResource) (bool, error) {
+	resourceList, err := r.discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if isIgnoredScaleError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	scaleName := fmt.Sprintf("%s/scale", gvr.Resource)
+	for _, resource := range resourceList.APIResources {
+		if resource.Name == scaleName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *ControllerFinder) getScaleAndSelector(obj *unstructured.Unstructured, gr schema.GroupResource, namespace string) (*ScaleAndSelector, error) {
+	scale, err := r.scaleNamespacer.Scales(namespace).Get(context.TODO(), gr, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if isIgnoredScaleError(err) {
+			klog.V(4).Infof("Ignore %s %s/%s for getting scale subresource: %v", obj.GetKind(), namespace, obj.GetName(), err)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if scale.UID != obj.GetUID() {
+		return nil, nil
+	}
+
+	var selector *metav1.LabelSelector
+	if scale.Status.Selector != "" {
+		if selector, err = metav1.ParseToLabelSelector(scale.Status.Selector); err != nil {
+			// This error is irreversible, so there is no need to return error
+			klog.Errorf("Failed to parse selector %s of %s %s/%s: %v", scale.Status.Selector, obj.GetKind(), namespace, obj.GetName(), err)
+			return nil, nil
+		}
+	}
+
+	metadata := metav1.ObjectMeta{
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		UID:               obj.GetUID(),
+		ResourceVersion:   obj.GetResourceVersion(),
+		Generation:        obj.GetGeneration(),
+		CreationTimestamp: obj.GetCreationTimestamp(),
+		DeletionTimestamp: obj.GetDeletionTimestamp(),
+		Labels:            obj.GetLabels(),
+		Annotations:       obj.GetAnnotations(),
+		OwnerReferences:   obj.GetOwnerReferences(),
+	}
+
+	return &ScaleAndSelector{
+		Scale:    scale.Spec.Replicas,
+		Selector: selector,
+		ControllerReference: ControllerReference{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			UID:        obj.GetUID(),
+		},
+		Metadata: metadata,
+	}, nil
+}
+
+// getPodsForScaleController returns the pods that belong to the workload found by getScaleController,
+// including those owned by intermediate owners of the workload.
+func (r *ControllerFinder) getPodsForScaleController(workload *ScaleAndSelector, namespace string, active bool) ([]*corev1.Pod, error) {
+	selector := labels.Everything()
+	if workload.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(workload.Selector); err != nil {
+			klog.Errorf("%s (%s/%s) get labelSelector failed: %s", workload.Kind, namespace, workload.Name, err.Error())
+			return nil, nil
+		}
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(context.TODO(), podList, &client.ListOptions{Namespace: namespace, LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	// the owners that have been checked whether belong to the workload
+	owners := map[types.UID]bool{workload.UID: true}
+	matchedPods := make([]*corev1.Pod, 0)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		// filter not active Pod if active is true.
+		if active && !kubecontroller.IsPodActive(pod) {
+			continue
+		}
+		ref := metav1.GetControllerOf(pod)
+		if ref == nil {
+			continue
+		}
+		matched, ok := owners[ref.UID]
+		if !ok {
+			controller, err := r.getScaleController(ControllerReference{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name, UID: ref.UID}, namespace)
+			if err != nil {
+				return nil, err
+			}
+			matched = controller != nil && controller.UID == workload.UID
+			owners[ref.UID] = matched
+		}
+		if matched {
+			matchedPods = append(matchedPods, pod)
+		}
+	}
+	return matchedPods, nil
+}
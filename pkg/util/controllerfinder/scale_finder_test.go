@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerfinder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	scaleclient "k8s.io/client-go/scale"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var (
+	appGVK        = schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "App"}
+	podSetGVK     = schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "PodSet"}
+	appGVR        = schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "apps"}
+	podSetGVR     = schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "podsets"}
+	appUID        = types.UID("app-uid")
+	podSetUID     = types.UID("podset-uid")
+	testNamespace = "default"
+)
+
+type fakeScales struct {
+	scales map[string]*autoscalingv1.Scale
+}
+
+func (f *fakeScales) Scales(namespace string) scaleclient.ScaleInterface {
+	return f
+}
+
+func (f *fakeScales) Get(_ context.Context, resource schema.GroupResource, name string, _ metav1.GetOptions) (*autoscalingv1.Scale, error) {
+	if name == "forbidden" {
+		return nil, errors.NewForbidden(resource, name, fmt.Errorf("not authorized"))
+	}
+	if s, ok := f.scales[resource.String()+"/"+name]; ok {
+		return s.DeepCopy(), nil
+	}
+	return nil, errors.NewNotFound(resource, name)
+}
+
+func (f *fakeScales) Update(context.Context, schema.GroupResource, *autoscalingv1.Scale, metav1.UpdateOptions) (*autoscalingv1.Scale, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f *fakeScales) Patch(context.Context, schema.GroupVersionResource, string, types.PatchType, []byte, metav1.PatchOptions) (*autoscalingv1.Scale, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func newUnstructured(gvk schema.GroupVersionKind, name string, uid types.UID, owner *metav1.OwnerReference) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(testNamespace)
+	obj.SetName(name)
+	obj.SetUID(uid)
+	if owner != nil {
+		obj.SetOwnerReferences([]metav1.OwnerReference{*owner})
+	}
+	return obj
+}
+
+func newTestFinder(objs ...runtime.Object) *ControllerFinder {
+	isController := true
+	app := newUnstructured(appGVK, "demo", appUID, nil)
+	podSet := newUnstructured(podSetGVK, "demo-podset", podSetUID, &metav1.OwnerReference{
+		APIVersion: appGVK.GroupVersion().String(), Kind: appGVK.Kind, Name: "demo", UID: appUID, Controller: &isController,
+	})
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appGVK.GroupVersion()})
+	mapper.Add(appGVK, meta.RESTScopeNamespace)
+	mapper.Add(podSetGVK, meta.RESTScopeNamespace)
+
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: appGVK.GroupVersion().String(),
+		APIResources: []metav1.APIResource{
+			{Name: appGVR.Resource, Kind: appGVK.Kind, Namespaced: true},
+			{Name: appGVR.Resource + "/scale", Kind: "Scale", Group: "autoscaling", Version: "v1", Namespaced: true},
+			{Name: podSetGVR.Resource, Kind: podSetGVK.Kind, Namespaced: true},
+		},
+	}}}}
+
+	scales := &fakeScales{scales: map[string]*autoscalingv1.Scale{
+		appGVR.GroupResource().String() + "/demo": {
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "demo", UID: appUID},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: 3},
+			Status:     autoscalingv1.ScaleStatus{Selector: "app=demo"},
+		},
+	}}
+
+	objs = append(objs, app, podSet)
+	return &ControllerFinder{
+		Client:          fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithRuntimeObjects(objs...).Build(),
+		discoveryClient: discoveryClient,
+		mapper:          mapper,
+		scaleNamespacer: scales,
+	}
+}
+
+func TestGetScaleAndSelectorForScaleController(t *testing.T) {
+	finder := newTestFinder(newUnstructured(appGVK, "forbidden", "forbidden-uid", nil))
+
+	cases := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		objName    string
+		uid        types.UID
+		expectNil  bool
+	}{
+		{
+			name:       "workload with scale subresource",
+			apiVersion: "example.io/v1",
+			kind:       "App",
+			objName:    "demo",
+		},
+		{
+			name:       "intermediate owner without scale subresource",
+			apiVersion: "example.io/v1",
+			kind:       "PodSet",
+			objName:    "demo-podset",
+			uid:        podSetUID,
+		},
+		{
+			name:       "uid not matched",
+			apiVersion: "example.io/v1",
+			kind:       "App",
+			objName:    "demo",
+			uid:        "other-uid",
+			expectNil:  true,
+		},
+		{
+			name:       "kind not registered",
+			apiVersion: "example.io/v1",
+			kind:       "Unknown",
+			objName:    "demo",
+			expectNil:  true,
+		},
+		{
+			name:       "workload not found",
+			apiVersion: "example.io/v1",
+			kind:       "App",
+			objName:    "not-found",
+			expectNil:  true,
+		},
+		{
+			name:       "scale subresource forbidden",
+			apiVersion: "example.io/v1",
+			kind:       "App",
+			objName:    "forbidden",
+			expectNil:  true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			// owners of unknown kinds are not looked up by scale subresource
+			if workload, err := finder.GetScaleAndSelectorForRef(cs.apiVersion, cs.kind, testNamespace, cs.objName, cs.uid); err != nil || workload != nil {
+				t.Fatalf("expected nil for owner reference, got %v, %v", workload, err)
+			}
+
+			workload, err := finder.GetScaleAndSelectorForTargetRef(cs.apiVersion, cs.kind, testNamespace, cs.objName, cs.uid)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cs.expectNil {
+				if workload != nil {
+					t.Fatalf("expected nil, got %v", workload)
+				}
+				return
+			}
+			if workload == nil {
+				t.Fatalf("expected workload, got nil")
+			}
+			if workload.Kind != "App" || workload.Name != "demo" || workload.UID != appUID || workload.Scale != 3 {
+				t.Fatalf("unexpected workload %+v", workload.ControllerReference)
+			}
+			if workload.Selector == nil || workload.Selector.MatchLabels["app"] != "demo" {
+				t.Fatalf("unexpected selector %v", workload.Selector)
+			}
+		})
+	}
+}
+
+func TestGetPodsForScaleController(t *testing.T) {
+	isController := true
+	newPod := func(name string, owner metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       testNamespace,
+				Name:            name,
+				Labels:          map[string]string{"app": "demo"},
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	finder := newTestFinder(
+		newPod("pod-0", metav1.OwnerReference{APIVersion: "example.io/v1", Kind: "App", Name: "demo", UID: appUID, Controller: &isController}),
+		newPod("pod-1", metav1.OwnerReference{APIVersion: "example.io/v1", Kind: "PodSet", Name: "demo-podset", UID: podSetUID, Controller: &isController}),
+		newPod("pod-2", metav1.OwnerReference{APIVersion: "example.io/v1", Kind: "PodSet", Name: "other-podset", UID: "other-uid", Controller: &isController}),
+	)
+
+	pods, replicas, err := finder.GetPodsForRef("example.io/v1", "App", "demo", testNamespace, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replicas != 3 {
+		t.Fatalf("expected replicas 3, got %d", replicas)
+	}
+	if len(pods) != 2 || pods[0].Name != "pod-0" || pods[1].Name != "pod-1" {
+		var names []string
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		t.Fatalf("expected pod-0 and pod-1, got %v", names)
+	}
+}
+
+func TestGetScaleControllerReference(t *testing.T) {
+	finder := newTestFinder(newUnstructured(appGVK, "forbidden", "forbidden-uid", nil))
+
+	// the scale subresource is not got, so the workload is found even if its scale is forbidden
+	for _, ref := range []ControllerReference{
+		{APIVersion: "example.io/v1", Kind: "App", Name: "demo", UID: appUID},
+		{APIVersion: "example.io/v1", Kind: "PodSet", Name: "demo-podset", UID: podSetUID},
+		{APIVersion: "example.io/v1", Kind: "App", Name: "forbidden"},
+	} {
+		workload, err := finder.GetScaleControllerReference(ref.APIVersion, ref.Kind, testNamespace, ref.Name, ref.UID)
+		if err != nil || workload == nil {
+			t.Fatalf("expected workload for %v, got %v, %v", ref, workload, err)
+		}
+		if workload.Kind != "App" || (ref.Name != "forbidden" && (workload.Name != "demo" || workload.UID != appUID)) {
+			t.Fatalf("unexpected workload %+v for %v", workload, ref)
+		}
+	}
+
+	// known workloads are not looked up by scale subresource
+	if workload, err := finder.GetScaleControllerReference("apps/v1", "ReplicaSet", testNamespace, "demo", ""); err != nil || workload != nil {
+		t.Fatalf("expected nil for known workload, got %v, %v", workload, err)
+	}
+	if workload, err := finder.GetScaleControllerReference("example.io/v1", "App", testNamespace, "not-found", ""); err != nil || workload != nil {
+		t.Fatalf("expected nil for not found workload, got %v, %v", workload, err)
+	}
+}
@@ -76,11 +76,14 @@ func RegisterFieldIndexes(c cache.Cache) error {
 				return
 			}
 		}
-		// imagepulljob active
+		// imagepulljob active and owner
 		if utildiscovery.DiscoverObject(&appsv1alpha1.ImagePullJob{}) {
 			if err = indexImagePullJobActive(c); err != nil {
 				return
 			}
+			if err = indexImagePullCronJob(c); err != nil {
+				return
+			}
 		}
 	})
 	return err
@@ -137,6 +140,25 @@ func indexBroadcastCronJob(c cache.Cache) error {
 	})
 }
 
+func indexImagePullCronJob(c cache.Cache) error {
+	return c.IndexField(context.TODO(), &appsv1alpha1.ImagePullJob{}, IndexNameForController, func(rawObj client.Object) []string {
+		// grab the job object, extract the owner...
+		job := rawObj.(*appsv1alpha1.ImagePullJob)
+		owner := metav1.GetControllerOf(job)
+		if owner == nil {
+			return nil
+		}
+
+		// ...make sure it's a AdvancedCronJob...
+		if owner.APIVersion != apiGVStr || owner.Kind != appsv1alpha1.AdvancedCronJobKind {
+			return nil
+		}
+
+		// ...and if so, return it
+		return []string{owner.Name}
+	})
+}
+
 func indexImagePullJobActive(c cache.Cache) error {
 	return c.IndexField(context.TODO(), &appsv1alpha1.ImagePullJob{}, IndexNameForIsActive, func(rawObj client.Object) []string {
 		obj := rawObj.(*appsv1alpha1.ImagePullJob)
@@ -63,6 +63,10 @@ func (rh *realHistory) CreateControllerRevision(parent metav1.Object, revision *
 		return nil, fmt.Errorf("collisionCount should not be nil")
 	}
 	ns := parent.GetNamespace()
+	// revisions of cluster-scoped parent are stored in the namespace specified by the revision
+	if ns == "" {
+		ns = revision.Namespace
+	}
 
 	// Clone the input
 	clone := revision.DeepCopy()
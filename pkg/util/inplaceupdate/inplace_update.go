@@ -24,11 +24,13 @@ import (
 	"time"
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	"github.com/openkruise/kruise/pkg/util/metrics"
 	"github.com/openkruise/kruise/pkg/util/podadapter"
 	"github.com/openkruise/kruise/pkg/util/revisionadapter"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
@@ -37,7 +39,8 @@ import (
 )
 
 var (
-	inPlaceUpdatePatchRexp = regexp.MustCompile("^/spec/containers/([0-9]+)/image$")
+	inPlaceUpdatePatchRexp          = regexp.MustCompile("^/spec/containers/([0-9]+)/image$")
+	inPlaceUpdateResourcesPatchRexp = regexp.MustCompile("^/spec/containers/([0-9]+)/resources(/.*)?$")
 )
 
 type RefreshResult struct {
@@ -73,9 +76,12 @@ type UpdateSpec struct {
 	Revision    string            `json:"revision"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 
-	ContainerImages map[string]string `json:"containerImages,omitempty"`
-	MetaDataPatch   []byte            `json:"metaDataPatch,omitempty"`
-	GraceSeconds    int32             `json:"graceSeconds,omitempty"`
+	ContainerImages    map[string]string                  `json:"containerImages,omitempty"`
+	ContainerResources map[string]v1.ResourceRequirements `json:"containerResources,omitempty"`
+	// ContainerRefMetadata contains the names of containers whose env refers to the changed labels/annotations.
+	ContainerRefMetadata []string `json:"containerRefMetadata,omitempty"`
+	MetaDataPatch        []byte   `json:"metaDataPatch,omitempty"`
+	GraceSeconds         int32    `json:"graceSeconds,omitempty"`
 
 	OldTemplate *v1.PodTemplateSpec `json:"oldTemplate,omitempty"`
 	NewTemplate *v1.PodTemplateSpec `json:"newTemplate,omitempty"`
@@ -112,6 +118,10 @@ func (c *realControl) Refresh(pod *v1.Pod, opts *UpdateOptions) RefreshResult {
 		return RefreshResult{RefreshErr: err}
 	}
 
+	if err := c.finishEnvFromMetadata(pod, opts); err != nil {
+		return RefreshResult{RefreshErr: err}
+	}
+
 	var delayDuration time.Duration
 	var err error
 	if gracePeriod, _ := appspub.GetInPlaceUpdateGrace(pod); gracePeriod != "" {
@@ -217,7 +227,7 @@ func (c *realControl) CanUpdateInPlace(oldRevision, newRevision *apps.Controller
 	return opts.CalculateSpec(oldRevision, newRevision, opts) != nil
 }
 
-func (c *realControl) Update(pod *v1.Pod, oldRevision, newRevision *apps.ControllerRevision, opts *UpdateOptions) UpdateResult {
+func (c *realControl) Update(pod *v1.Pod, oldRevision, newRevision *apps.ControllerRevision, opts *UpdateOptions) (result UpdateResult) {
 	opts = SetOptionsDefaults(opts)
 
 	// 1. calculate inplace update spec
@@ -226,6 +236,14 @@ func (c *realControl) Update(pod *v1.Pod, oldRevision, newRevision *apps.Control
 		return UpdateResult{}
 	}
 
+	defer func() {
+		var ownerKind string
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			ownerKind = owner.Kind
+		}
+		metrics.ObserveInPlaceUpdate(ownerKind, pod.Namespace, result.UpdateErr)
+	}()
+
 	// TODO(FillZpp): maybe we should check if the previous in-place update has completed
 
 	// 2. update condition for pod with readiness-gate
@@ -274,9 +292,11 @@ func (c *realControl) updatePodInPlace(pod *v1.Pod, spec *UpdateSpec, opts *Upda
 			Revision:              spec.Revision,
 			UpdateTimestamp:       c.now(),
 			LastContainerStatuses: make(map[string]appspub.InPlaceUpdateContainerStatus, len(spec.ContainerImages)),
+			UpdateEnvFromMetadata: len(spec.ContainerRefMetadata) > 0,
 		}
+		refMetadataContainers := sets.NewString(spec.ContainerRefMetadata...)
 		for _, c := range clone.Status.ContainerStatuses {
-			if _, ok := spec.ContainerImages[c.Name]; ok {
+			if _, ok := spec.ContainerImages[c.Name]; ok || refMetadataContainers.Has(c.Name) {
 				inPlaceUpdateState.LastContainerStatuses[c.Name] = appspub.InPlaceUpdateContainerStatus{
 					ImageID: c.ImageID,
 				}
@@ -24,10 +24,15 @@ import (
 
 	"github.com/appscode/jsonpatch"
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
 	kubeletcontainer "k8s.io/kubernetes/pkg/kubelet/container"
 )
 
@@ -70,11 +75,29 @@ func defaultPatchUpdateSpecToPod(pod *v1.Pod, spec *UpdateSpec) (*v1.Pod, error)
 			pod.Spec.Containers[i].Image = newImage
 		}
 	}
+
+	// resources in pod spec are immutable, so record them in annotation for kruise-daemon to resize containers
+	if len(spec.ContainerResources) > 0 {
+		containerResources, err := appspub.GetInPlaceUpdateResources(pod)
+		if err != nil || containerResources == nil {
+			containerResources = make(map[string]v1.ResourceRequirements, len(spec.ContainerResources))
+		}
+		for name, resources := range spec.ContainerResources {
+			containerResources[name] = resources
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		resourcesJSON, _ := json.Marshal(containerResources)
+		pod.Annotations[appspub.InPlaceUpdateResourcesKey] = string(resourcesJSON)
+	}
 	return pod, nil
 }
 
 // defaultCalculateInPlaceUpdateSpec calculates diff between old and update revisions.
 // If the diff just contains replace operation of spec.containers[x].image, it will returns an UpdateSpec.
+// If InPlaceUpdateContainerResources enabled, changes of spec.containers[x].resources that keep the QoS class
+// of Pod unchanged can also be updated in-place, unless they remove limits or increase limits over the pod-level cgroup.
 // Otherwise, it returns nil which means can not use in-place update.
 func defaultCalculateInPlaceUpdateSpec(oldRevision, newRevision *apps.ControllerRevision, opts *UpdateOptions) *UpdateSpec {
 	if oldRevision == nil || newRevision == nil {
@@ -114,6 +137,21 @@ func defaultCalculateInPlaceUpdateSpec(oldRevision, newRevision *apps.Controller
 			metadataChanged = true
 			continue
 		}
+		if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateContainerResources) &&
+			inPlaceUpdateResourcesPatchRexp.MatchString(jsonPatchOperation.Path) {
+			// for example: /spec/containers/0/resources/limits/cpu
+			words := strings.Split(jsonPatchOperation.Path, "/")
+			idx, _ := strconv.Atoi(words[3])
+			if len(oldTemp.Spec.Containers) <= idx || len(newTemp.Spec.Containers) <= idx ||
+				oldTemp.Spec.Containers[idx].Name != newTemp.Spec.Containers[idx].Name {
+				return nil
+			}
+			if updateSpec.ContainerResources == nil {
+				updateSpec.ContainerResources = make(map[string]v1.ResourceRequirements)
+			}
+			updateSpec.ContainerResources[newTemp.Spec.Containers[idx].Name] = newTemp.Spec.Containers[idx].Resources
+			continue
+		}
 		if jsonPatchOperation.Operation != "replace" || !inPlaceUpdatePatchRexp.MatchString(jsonPatchOperation.Path) {
 			return nil
 		}
@@ -125,6 +163,14 @@ func defaultCalculateInPlaceUpdateSpec(oldRevision, newRevision *apps.Controller
 		}
 		updateSpec.ContainerImages[oldTemp.Spec.Containers[idx].Name] = jsonPatchOperation.Value.(string)
 	}
+	// changing QoS class requires to recreate the pod-level cgroup, which can not be done in-place
+	if len(updateSpec.ContainerResources) > 0 &&
+		qos.GetPodQOS(&v1.Pod{Spec: oldTemp.Spec}) != qos.GetPodQOS(&v1.Pod{Spec: newTemp.Spec}) {
+		return nil
+	}
+	if len(updateSpec.ContainerResources) > 0 && !isContainerLimitsResizable(&oldTemp.Spec, &newTemp.Spec) {
+		return nil
+	}
 	if metadataChanged {
 		oldBytes, _ := json.Marshal(v1.Pod{ObjectMeta: oldTemp.ObjectMeta})
 		newBytes, _ := json.Marshal(v1.Pod{ObjectMeta: newTemp.ObjectMeta})
@@ -133,10 +179,61 @@ func defaultCalculateInPlaceUpdateSpec(oldRevision, newRevision *apps.Controller
 			return nil
 		}
 		updateSpec.MetaDataPatch = patchBytes
+
+		if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateEnvFromMetadata) {
+			for i := range newTemp.Spec.Containers {
+				c := &newTemp.Spec.Containers[i]
+				if IsEnvFromMetadataChanged(c, &oldTemp.ObjectMeta, &newTemp.ObjectMeta) {
+					updateSpec.ContainerRefMetadata = append(updateSpec.ContainerRefMetadata, c.Name)
+				}
+			}
+		}
 	}
 	return updateSpec
 }
 
+// isContainerLimitsResizable returns false if the limits of containers can not be resized by kruise-daemon,
+// which only updates the container-level cgroups:
+// 1. removing a limit is ignored by runtime, for the zero value means no change in CRI;
+// 2. the sum of limits can not exceed the pod-level cgroup, which is set by kubelet only if all containers have the limit.
+func isContainerLimitsResizable(oldSpec, newSpec *v1.PodSpec) bool {
+	for _, resourceName := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		oldLimits := make(map[string]resource.Quantity, len(oldSpec.Containers))
+		for i := range oldSpec.Containers {
+			if q, ok := oldSpec.Containers[i].Resources.Limits[resourceName]; ok && !q.IsZero() {
+				oldLimits[oldSpec.Containers[i].Name] = q
+			}
+		}
+
+		newSum := resource.Quantity{}
+		for i := range newSpec.Containers {
+			q, ok := newSpec.Containers[i].Resources.Limits[resourceName]
+			if _, hadLimit := oldLimits[newSpec.Containers[i].Name]; hadLimit && (!ok || q.IsZero()) {
+				return false
+			}
+			newSum.Add(q)
+		}
+
+		// the pod-level cgroup is unlimited if any container has no limit
+		if len(oldLimits) < len(oldSpec.Containers) {
+			continue
+		}
+		podLimit := resource.Quantity{}
+		for _, q := range oldLimits {
+			podLimit.Add(q)
+		}
+		for i := range oldSpec.InitContainers {
+			if q, ok := oldSpec.InitContainers[i].Resources.Limits[resourceName]; ok && q.Cmp(podLimit) > 0 {
+				podLimit = q.DeepCopy()
+			}
+		}
+		if newSum.Cmp(podLimit) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // DefaultCheckInPlaceUpdateCompleted checks whether imageID in pod status has been changed since in-place update.
 // If the imageID in containerStatuses has not been changed, we assume that kubelet has not updated
 // containers in Pod.
@@ -149,6 +246,17 @@ func DefaultCheckInPlaceUpdateCompleted(pod *v1.Pod) error {
 	if err != nil {
 		return err
 	}
+
+	if err := checkContainerResourcesResized(pod, runtimeContainerMetaSet); err != nil {
+		return err
+	}
+
+	if utilfeature.DefaultFeatureGate.Enabled(features.InPlaceUpdateEnvFromMetadata) {
+		if err := checkEnvFromMetadataUpdated(pod, runtimeContainerMetaSet); err != nil {
+			return err
+		}
+	}
+
 	if runtimeContainerMetaSet != nil {
 		if checkAllContainersHashConsistent(pod, runtimeContainerMetaSet) {
 			klog.V(5).Infof("Check Pod %s/%s in-place update completed for all container hash consistent", pod.Namespace, pod.Name)
@@ -239,3 +347,46 @@ func checkAllContainersHashConsistent(pod *v1.Pod, runtimeContainerMetaSet *apps
 
 	return true
 }
+
+// checkContainerResourcesResized checks whether kruise-daemon has applied the resources in
+// inplace-update-resources annotation to all current runtime containers.
+func checkContainerResourcesResized(pod *v1.Pod, runtimeContainerMetaSet *appspub.RuntimeContainerMetaSet) error {
+	containerResources, err := appspub.GetInPlaceUpdateResources(pod)
+	if err != nil {
+		return err
+	} else if len(containerResources) == 0 {
+		return nil
+	}
+	if runtimeContainerMetaSet == nil {
+		return fmt.Errorf("waiting for runtime-container-meta to report resized resources")
+	}
+
+	for name, resources := range containerResources {
+		var containerStatus *v1.ContainerStatus
+		for i := range pod.Status.ContainerStatuses {
+			if pod.Status.ContainerStatuses[i].Name == name {
+				containerStatus = &pod.Status.ContainerStatuses[i]
+				break
+			}
+		}
+		if containerStatus == nil {
+			// the container may have been removed from pod
+			continue
+		}
+
+		var containerMeta *appspub.RuntimeContainerMeta
+		for i := range runtimeContainerMetaSet.Containers {
+			if runtimeContainerMetaSet.Containers[i].Name == name {
+				containerMeta = &runtimeContainerMetaSet.Containers[i]
+				break
+			}
+		}
+		if containerMeta == nil || containerMeta.ContainerID != containerStatus.ContainerID {
+			return fmt.Errorf("container %s has not been reported in runtime-container-meta", name)
+		}
+		if containerMeta.Resources == nil || !apiequality.Semantic.DeepEqual(*containerMeta.Resources, resources) {
+			return fmt.Errorf("container %s resources have not been resized", name)
+		}
+	}
+	return nil
+}
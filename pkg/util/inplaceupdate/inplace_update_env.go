@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inplaceupdate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kubernetes/pkg/fieldpath"
+	kubeletcontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+const (
+	fieldPathLabels      = "metadata.labels"
+	fieldPathAnnotations = "metadata.annotations"
+)
+
+// getEnvMetadataRef returns the path and key of metadata that the env refers to via fieldRef,
+// such as metadata.labels['key'] or metadata.annotations['key'].
+func getEnvMetadataRef(env *v1.EnvVar) (path, key string, ok bool) {
+	if env.ValueFrom == nil || env.ValueFrom.FieldRef == nil {
+		return "", "", false
+	}
+	path, key, ok = fieldpath.SplitMaybeSubscriptedPath(env.ValueFrom.FieldRef.FieldPath)
+	if !ok || (path != fieldPathLabels && path != fieldPathAnnotations) {
+		return "", "", false
+	}
+	return path, key, true
+}
+
+func getMetadataValue(objMeta *metav1.ObjectMeta, path, key string) (string, bool) {
+	if path == fieldPathLabels {
+		v, ok := objMeta.Labels[key]
+		return v, ok
+	}
+	v, ok := objMeta.Annotations[key]
+	return v, ok
+}
+
+// HasEnvFromMetadata returns true if the container has env that refers to metadata labels or annotations.
+func HasEnvFromMetadata(container *v1.Container) bool {
+	for i := range container.Env {
+		if _, _, ok := getEnvMetadataRef(&container.Env[i]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnvFromMetadataChanged returns true if any label or annotation that the container env refers to has been changed.
+func IsEnvFromMetadataChanged(container *v1.Container, oldMeta, newMeta *metav1.ObjectMeta) bool {
+	for i := range container.Env {
+		path, key, ok := getEnvMetadataRef(&container.Env[i])
+		if !ok {
+			continue
+		}
+		oldValue, oldExists := getMetadataValue(oldMeta, path, key)
+		newValue, newExists := getMetadataValue(newMeta, path, key)
+		if oldValue != newValue || oldExists != newExists {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEnvFromMetadataHash returns the hash of the container, whose env from metadata labels/annotations
+// has been converted into values with the current metadata of Pod.
+// It returns 0 if the container has no env from metadata.
+func GetEnvFromMetadataHash(pod *v1.Pod, container *v1.Container) uint64 {
+	if !HasEnvFromMetadata(container) {
+		return 0
+	}
+
+	extracted := container.DeepCopy()
+	for i := range extracted.Env {
+		env := &extracted.Env[i]
+		path, key, ok := getEnvMetadataRef(env)
+		if !ok {
+			continue
+		}
+		env.Value, _ = getMetadataValue(&pod.ObjectMeta, path, key)
+		env.ValueFrom = nil
+	}
+	return kubeletcontainer.HashContainer(extracted)
+}
+
+// GetContainersToRestartForEnvFromMetadata returns the names of containers that should be restarted,
+// because the labels/annotations referred by their env have been changed by in-place update
+// since they started.
+func GetContainersToRestartForEnvFromMetadata(pod *v1.Pod, runtimeContainerMetaSet *appspub.RuntimeContainerMetaSet) ([]string, error) {
+	state, err := getInPlaceUpdateState(pod)
+	if err != nil || state == nil || !state.UpdateEnvFromMetadata {
+		return nil, err
+	}
+
+	var names []string
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if _, ok := state.LastContainerStatuses[c.Name]; !ok || !HasEnvFromMetadata(c) {
+			continue
+		}
+
+		var containerMeta *appspub.RuntimeContainerMeta
+		if runtimeContainerMetaSet != nil {
+			for j := range runtimeContainerMetaSet.Containers {
+				if runtimeContainerMetaSet.Containers[j].Name == c.Name {
+					containerMeta = &runtimeContainerMetaSet.Containers[j]
+					break
+				}
+			}
+		}
+		if containerMeta == nil || containerMeta.Hashes.ExtractedEnvFromMetadataHash != GetEnvFromMetadataHash(pod, c) {
+			names = append(names, c.Name)
+		}
+	}
+	return names, nil
+}
+
+// finishEnvFromMetadata clears UpdateEnvFromMetadata in the in-place update state once the update has completed,
+// so that kruise-daemon will not restart the containers when the labels/annotations are changed by others later.
+func (c *realControl) finishEnvFromMetadata(pod *v1.Pod, opts *UpdateOptions) error {
+	if state, err := getInPlaceUpdateState(pod); err != nil || state == nil || !state.UpdateEnvFromMetadata {
+		return err
+	}
+	if checkErr := opts.CheckUpdateCompleted(pod); checkErr != nil {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		clone, err := c.podAdapter.GetPod(pod.Namespace, pod.Name)
+		if err != nil {
+			return err
+		}
+		state, err := getInPlaceUpdateState(clone)
+		if err != nil || state == nil || !state.UpdateEnvFromMetadata {
+			return err
+		}
+		state.UpdateEnvFromMetadata = false
+		stateJSON, _ := json.Marshal(state)
+		clone.Annotations[appspub.InPlaceUpdateStateKey] = string(stateJSON)
+		return c.podAdapter.UpdatePod(clone)
+	})
+}
+
+func getInPlaceUpdateState(pod *v1.Pod) (*appspub.InPlaceUpdateState, error) {
+	stateStr, ok := appspub.GetInPlaceUpdateState(pod)
+	if !ok {
+		return nil, nil
+	}
+	state := appspub.InPlaceUpdateState{}
+	if err := json.Unmarshal([]byte(stateStr), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// checkEnvFromMetadataUpdated checks whether all containers, whose env refers to the metadata that have been
+// changed by in-place update, have been restarted by kruise-daemon.
+func checkEnvFromMetadataUpdated(pod *v1.Pod, runtimeContainerMetaSet *appspub.RuntimeContainerMetaSet) error {
+	names, err := GetContainersToRestartForEnvFromMetadata(pod, runtimeContainerMetaSet)
+	if err != nil {
+		return err
+	} else if len(names) > 0 {
+		return fmt.Errorf("waiting for containers %v to restart for env from metadata", names)
+	}
+
+	if runtimeContainerMetaSet == nil {
+		return nil
+	}
+	// make sure the hashes reported are for the current containers
+	for _, cs := range pod.Status.ContainerStatuses {
+		for i := range runtimeContainerMetaSet.Containers {
+			meta := &runtimeContainerMetaSet.Containers[i]
+			if meta.Name == cs.Name && meta.Hashes.ExtractedEnvFromMetadataHash > 0 && meta.ContainerID != cs.ContainerID {
+				return fmt.Errorf("container %s has not been reported in runtime-container-meta", cs.Name)
+			}
+		}
+	}
+	return nil
+}
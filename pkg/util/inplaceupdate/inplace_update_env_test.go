@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inplaceupdate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	"github.com/openkruise/kruise/pkg/util/revisionadapter"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCalculateInPlaceUpdateSpecWithEnvFromMetadata(t *testing.T) {
+	_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.InPlaceUpdateEnvFromMetadata))
+	defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.InPlaceUpdateEnvFromMetadata))
+
+	containers := `"containers":[{"name":"c1","image":"foo","env":[{"name":"CONFIG","valueFrom":{"fieldRef":{"fieldPath":"metadata.labels['config']"}}}]},{"name":"c2","image":"bar"}]`
+	oldRevision := &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "old-revision"},
+		Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","metadata":{"labels":{"config":"v1"}},"spec":{` + containers + `}}}}`)},
+	}
+	newRevision := &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+		Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","metadata":{"labels":{"config":"v2"}},"spec":{` + containers + `}}}}`)},
+	}
+
+	expectedSpec := &UpdateSpec{
+		Revision:             "new-revision",
+		ContainerImages:      map[string]string{},
+		ContainerRefMetadata: []string{"c1"},
+		MetaDataPatch:        []byte(`{"metadata":{"labels":{"config":"v2"}}}`),
+	}
+	res := defaultCalculateInPlaceUpdateSpec(oldRevision, newRevision, nil)
+	if !reflect.DeepEqual(res, expectedSpec) {
+		t.Fatalf("expected %v, got %v", util.DumpJSON(expectedSpec), util.DumpJSON(res))
+	}
+}
+
+func TestGetContainersToRestartForEnvFromMetadata(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"config": "v2"},
+			Annotations: map[string]string{appspub.InPlaceUpdateStateKey: util.DumpJSON(appspub.InPlaceUpdateState{
+				Revision:              "new-revision",
+				LastContainerStatuses: map[string]appspub.InPlaceUpdateContainerStatus{"c1": {ImageID: "foo-id"}},
+				UpdateEnvFromMetadata: true,
+			})},
+		},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "c1", Image: "foo", Env: []v1.EnvVar{{Name: "CONFIG", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.labels['config']"}}}}},
+			{Name: "c2", Image: "bar"},
+		}},
+		Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{Name: "c1", ContainerID: "containerd://c1-new"},
+			{Name: "c2", ContainerID: "containerd://c2"},
+		}},
+	}
+
+	oldPod := pod.DeepCopy()
+	oldPod.Labels["config"] = "v1"
+	metaSet := &appspub.RuntimeContainerMetaSet{Containers: []appspub.RuntimeContainerMeta{
+		{Name: "c1", ContainerID: "containerd://c1", Hashes: appspub.RuntimeContainerHashes{ExtractedEnvFromMetadataHash: GetEnvFromMetadataHash(oldPod, &pod.Spec.Containers[0])}},
+		{Name: "c2", ContainerID: "containerd://c2"},
+	}}
+
+	names, err := GetContainersToRestartForEnvFromMetadata(pod, metaSet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"c1"}) {
+		t.Fatalf("expected to restart c1, got %v", names)
+	}
+	if err := checkEnvFromMetadataUpdated(pod, metaSet); err == nil {
+		t.Fatalf("expected not completed before c1 restarted")
+	}
+
+	metaSet.Containers[0] = appspub.RuntimeContainerMeta{
+		Name:        "c1",
+		ContainerID: "containerd://c1-new",
+		Hashes:      appspub.RuntimeContainerHashes{ExtractedEnvFromMetadataHash: GetEnvFromMetadataHash(pod, &pod.Spec.Containers[0])},
+	}
+	if err := checkEnvFromMetadataUpdated(pod, metaSet); err != nil {
+		t.Fatalf("expected completed after c1 restarted, got %v", err)
+	}
+}
+
+func TestRefreshFinishEnvFromMetadata(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-0",
+			Annotations: map[string]string{appspub.InPlaceUpdateStateKey: util.DumpJSON(appspub.InPlaceUpdateState{
+				Revision:              "new-revision",
+				LastContainerStatuses: map[string]appspub.InPlaceUpdateContainerStatus{"c1": {ImageID: "foo-id"}},
+				UpdateEnvFromMetadata: true,
+			})},
+		},
+	}
+	cli := fake.NewClientBuilder().WithObjects(pod).Build()
+	ctrl := NewForTest(cli, revisionadapter.NewDefaultImpl(), metav1.Now)
+
+	getState := func() *appspub.InPlaceUpdateState {
+		got := &v1.Pod{}
+		if err := cli.Get(context.TODO(), types.NamespacedName{Name: pod.Name}, got); err != nil {
+			t.Fatalf("failed to get pod: %v", err)
+		}
+		state, err := getInPlaceUpdateState(got)
+		if err != nil || state == nil {
+			t.Fatalf("failed to get state: %v", err)
+		}
+		return state
+	}
+
+	notCompleted := &UpdateOptions{CheckUpdateCompleted: func(*v1.Pod) error { return fmt.Errorf("not completed") }}
+	if res := ctrl.Refresh(pod, notCompleted); res.RefreshErr != nil {
+		t.Fatalf("failed to refresh: %v", res.RefreshErr)
+	}
+	if !getState().UpdateEnvFromMetadata {
+		t.Fatalf("expected updateEnvFromMetadata kept before completed")
+	}
+
+	completed := &UpdateOptions{CheckUpdateCompleted: func(*v1.Pod) error { return nil }}
+	if res := ctrl.Refresh(pod, completed); res.RefreshErr != nil {
+		t.Fatalf("failed to refresh: %v", res.RefreshErr)
+	}
+	if state := getState(); state.UpdateEnvFromMetadata || state.Revision != "new-revision" {
+		t.Fatalf("expected updateEnvFromMetadata cleared after completed, got %v", util.DumpJSON(state))
+	}
+}
@@ -24,10 +24,13 @@ import (
 	"time"
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/util/revisionadapter"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -127,6 +130,212 @@ func TestCalculateInPlaceUpdateSpec(t *testing.T) {
 	}
 }
 
+func TestCalculateInPlaceUpdateSpecWithResources(t *testing.T) {
+	oldRevision := &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "old-revision"},
+		Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"requests":{"cpu":"1","memory":"1Gi"}}}]}}}}`)},
+	}
+	limitedRevision := &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "old-revision"},
+		Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"requests":{"cpu":"1","memory":"1Gi"},"limits":{"cpu":"2","memory":"2Gi"}}}]}}}}`)},
+	}
+	cases := []struct {
+		name         string
+		enabled      bool
+		oldRevision  *apps.ControllerRevision
+		newRevision  *apps.ControllerRevision
+		expectedSpec *UpdateSpec
+	}{
+		{
+			name:    "feature disabled",
+			enabled: false,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"requests":{"cpu":"2","memory":"1Gi"}}}]}}}}`)},
+			},
+			expectedSpec: nil,
+		},
+		{
+			name:    "resize cpu and update image",
+			enabled: true,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo2","resources":{"requests":{"cpu":"2","memory":"1Gi"}}}]}}}}`)},
+			},
+			expectedSpec: &UpdateSpec{
+				Revision:        "new-revision",
+				ContainerImages: map[string]string{"c1": "foo2"},
+				ContainerResources: map[string]v1.ResourceRequirements{"c1": {Requests: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("2"),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+				}}},
+			},
+		},
+		{
+			name:        "remove the limit of memory",
+			enabled:     true,
+			oldRevision: limitedRevision,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"requests":{"cpu":"1","memory":"1Gi"},"limits":{"cpu":"2"}}}]}}}}`)},
+			},
+			expectedSpec: nil,
+		},
+		{
+			name:        "increase the limit of cpu",
+			enabled:     true,
+			oldRevision: limitedRevision,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"requests":{"cpu":"1","memory":"1Gi"},"limits":{"cpu":"3","memory":"2Gi"}}}]}}}}`)},
+			},
+			expectedSpec: nil,
+		},
+		{
+			name:        "decrease the limit of cpu",
+			enabled:     true,
+			oldRevision: limitedRevision,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"requests":{"cpu":"1","memory":"1Gi"},"limits":{"cpu":"1500m","memory":"2Gi"}}}]}}}}`)},
+			},
+			expectedSpec: &UpdateSpec{
+				Revision:        "new-revision",
+				ContainerImages: map[string]string{},
+				ContainerResources: map[string]v1.ResourceRequirements{"c1": {
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+					Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m"), v1.ResourceMemory: resource.MustParse("2Gi")},
+				}},
+			},
+		},
+		{
+			name:    "change QoS class from burstable to guaranteed",
+			enabled: true,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"requests":{"cpu":"1","memory":"1Gi"},"limits":{"cpu":"1","memory":"1Gi"}}}]}}}}`)},
+			},
+			expectedSpec: nil,
+		},
+	}
+
+	defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.InPlaceUpdateContainerResources))
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=%v", features.InPlaceUpdateContainerResources, tc.enabled))
+			if tc.oldRevision == nil {
+				tc.oldRevision = oldRevision
+			}
+			res := defaultCalculateInPlaceUpdateSpec(tc.oldRevision, tc.newRevision, nil)
+			if tc.expectedSpec == nil || res == nil {
+				if tc.expectedSpec != res {
+					t.Fatalf("expected %v, got %v", util.DumpJSON(tc.expectedSpec), util.DumpJSON(res))
+				}
+				return
+			}
+			if util.DumpJSON(res) != util.DumpJSON(tc.expectedSpec) {
+				t.Fatalf("expected %v, got %v", util.DumpJSON(tc.expectedSpec), util.DumpJSON(res))
+			}
+		})
+	}
+}
+
+func TestIsContainerLimitsResizable(t *testing.T) {
+	newContainer := func(name string, limits v1.ResourceList) v1.Container {
+		return v1.Container{Name: name, Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("100Mi")},
+			Limits:   limits,
+		}}
+	}
+	cpu := func(q string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: resource.MustParse(q)}
+	}
+
+	cases := []struct {
+		name     string
+		oldSpec  v1.PodSpec
+		newSpec  v1.PodSpec
+		expected bool
+	}{
+		{
+			name:     "decrease limit",
+			oldSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("2")), newContainer("c2", cpu("1"))}},
+			newSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("1")), newContainer("c2", cpu("1"))}},
+			expected: true,
+		},
+		{
+			name:     "increase limit over pod cgroup",
+			oldSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("2")), newContainer("c2", cpu("1"))}},
+			newSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("3")), newContainer("c2", cpu("1"))}},
+			expected: false,
+		},
+		{
+			name:     "move limit between containers",
+			oldSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("2")), newContainer("c2", cpu("1"))}},
+			newSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("1")), newContainer("c2", cpu("2"))}},
+			expected: true,
+		},
+		{
+			name: "increase limit within init container limit",
+			oldSpec: v1.PodSpec{
+				InitContainers: []v1.Container{newContainer("init", cpu("4"))},
+				Containers:     []v1.Container{newContainer("c1", cpu("2"))},
+			},
+			newSpec: v1.PodSpec{
+				InitContainers: []v1.Container{newContainer("init", cpu("4"))},
+				Containers:     []v1.Container{newContainer("c1", cpu("3"))},
+			},
+			expected: true,
+		},
+		{
+			name:     "increase limit without pod cgroup limit",
+			oldSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("2")), newContainer("c2", nil)}},
+			newSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("3")), newContainer("c2", nil)}},
+			expected: true,
+		},
+		{
+			name:     "remove limit",
+			oldSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", cpu("2")), newContainer("c2", nil)}},
+			newSpec:  v1.PodSpec{Containers: []v1.Container{newContainer("c1", nil), newContainer("c2", nil)}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isContainerLimitsResizable(&tc.oldSpec, &tc.newSpec); got != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCheckContainerResourcesResized(t *testing.T) {
+	resources := v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{appspub.InPlaceUpdateResourcesKey: util.DumpJSON(map[string]v1.ResourceRequirements{"c1": resources})},
+		},
+		Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{Name: "c1", ContainerID: "containerd://c1"}}},
+	}
+
+	if err := checkContainerResourcesResized(pod, nil); err == nil {
+		t.Fatalf("expected not resized without runtime-container-meta")
+	}
+	metaSet := &appspub.RuntimeContainerMetaSet{Containers: []appspub.RuntimeContainerMeta{{Name: "c1", ContainerID: "containerd://c1"}}}
+	if err := checkContainerResourcesResized(pod, metaSet); err == nil {
+		t.Fatalf("expected not resized without resources in runtime-container-meta")
+	}
+	metaSet.Containers[0].Resources = &v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2000m")}}
+	if err := checkContainerResourcesResized(pod, metaSet); err != nil {
+		t.Fatalf("expected resized, got %v", err)
+	}
+	metaSet.Containers[0].ContainerID = "containerd://c2"
+	if err := checkContainerResourcesResized(pod, metaSet); err == nil {
+		t.Fatalf("expected not resized for a new container")
+	}
+}
+
 func TestCheckInPlaceUpdateCompleted(t *testing.T) {
 	succeedPods := []*v1.Pod{
 		{
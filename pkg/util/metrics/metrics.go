@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "kruise"
+
+	// ResultSucceeded and ResultFailed are the values of the result label.
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+var (
+	// ReconcileDuration is the histogram of the time each reconcile of a kruise controller takes.
+	ReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "controller",
+			Name:      "reconcile_duration_seconds",
+			Help:      "Duration of reconciliations of kruise controllers.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		},
+		[]string{"controller"},
+	)
+
+	// ReconcileErrors is the counter of reconciliations of a kruise controller that returned an error.
+	ReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "controller",
+			Name:      "reconcile_errors_total",
+			Help:      "Total number of failed reconciliations of kruise controllers.",
+		},
+		[]string{"controller"},
+	)
+
+	// WorkloadReplicas is the gauge of replicas in the status of a workload, such as replicas, ready and updated.
+	WorkloadReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "workload",
+			Name:      "replicas",
+			Help:      "Number of replicas of kruise workloads reported in status, partitioned by type.",
+		},
+		[]string{"kind", "namespace", "name", "type"},
+	)
+
+	// WorkloadRevision is an info gauge, whose value is always 1, with the current and update revisions of a workload.
+	WorkloadRevision = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "workload",
+			Name:      "revision_info",
+			Help:      "Current and update revisions of kruise workloads.",
+		},
+		[]string{"kind", "namespace", "name", "current_revision", "update_revision"},
+	)
+
+	// WorkloadRolloutInProgress is 1 if the current revision of a workload is not equal to its update revision.
+	WorkloadRolloutInProgress = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "workload",
+			Name:      "rollout_in_progress",
+			Help:      "Whether the kruise workload is rolling out a new revision (1) or not (0).",
+		},
+		[]string{"kind", "namespace", "name"},
+	)
+
+	// InPlaceUpdateTotal is the counter of in-place update attempts on Pods.
+	InPlaceUpdateTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "inplace_update",
+			Name:      "total",
+			Help:      "Total number of in-place update attempts on Pods, partitioned by owner kind and result.",
+		},
+		[]string{"kind", "namespace", "result"},
+	)
+
+	// PodUnavailableBudgetRejectedTotal is the counter of Pod operations rejected by PodUnavailableBudget.
+	PodUnavailableBudgetRejectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pub",
+			Name:      "rejected_total",
+			Help:      "Total number of Pod operations rejected by PodUnavailableBudget.",
+		},
+		[]string{"namespace", "name", "operation"},
+	)
+
+	// ImagePullDuration is the histogram of the time kruise-daemon takes to pull an image on a node.
+	ImagePullDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "daemon",
+			Name:      "image_pull_duration_seconds",
+			Help:      "Duration of image pulls by kruise-daemon, partitioned by node and result.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+		},
+		[]string{"node", "result"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ReconcileDuration,
+		ReconcileErrors,
+		WorkloadReplicas,
+		WorkloadRevision,
+		WorkloadRolloutInProgress,
+		InPlaceUpdateTotal,
+		PodUnavailableBudgetRejectedTotal,
+		ImagePullDuration,
+	)
+}
+
+// ObserveReconcile records the duration and the result of a reconciliation that started at startTime.
+func ObserveReconcile(controller string, startTime time.Time, err error) {
+	ReconcileDuration.WithLabelValues(controller).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		ReconcileErrors.WithLabelValues(controller).Inc()
+	}
+}
+
+// ObserveInPlaceUpdate records an in-place update attempt on a Pod owned by the given kind.
+func ObserveInPlaceUpdate(kind, namespace string, err error) {
+	result := ResultSucceeded
+	if err != nil {
+		result = ResultFailed
+	}
+	InPlaceUpdateTotal.WithLabelValues(kind, namespace, result).Inc()
+}
+
+// ObserveImagePull records the duration of an image pull on the given node.
+func ObserveImagePull(node string, cost time.Duration, succeeded bool) {
+	result := ResultSucceeded
+	if !succeeded {
+		result = ResultFailed
+	}
+	ImagePullDuration.WithLabelValues(node, result).Observe(cost.Seconds())
+}
+
+// workloadCache keeps the last recorded label values of each workload,
+// so that stale series can be deleted when revisions change or the workload is deleted.
+var workloadCache = struct {
+	sync.Mutex
+	records map[workloadKey]workloadRecord
+}{records: make(map[workloadKey]workloadRecord)}
+
+type workloadKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+type workloadRecord struct {
+	currentRevision string
+	updateRevision  string
+	replicaTypes    []string
+}
+
+// RecordWorkloadStatus sets the replicas and revisions gauges of a workload.
+// The keys of replicas are used as the type label, e.g. replicas, ready, updated.
+func RecordWorkloadStatus(kind, namespace, name string, replicas map[string]int32, currentRevision, updateRevision string) {
+	record := workloadRecord{currentRevision: currentRevision, updateRevision: updateRevision}
+	for t, count := range replicas {
+		WorkloadReplicas.WithLabelValues(kind, namespace, name, t).Set(float64(count))
+		record.replicaTypes = append(record.replicaTypes, t)
+	}
+
+	rolling := 0.0
+	if currentRevision != updateRevision {
+		rolling = 1
+	}
+	WorkloadRolloutInProgress.WithLabelValues(kind, namespace, name).Set(rolling)
+
+	key := workloadKey{kind: kind, namespace: namespace, name: name}
+	workloadCache.Lock()
+	defer workloadCache.Unlock()
+	if last, ok := workloadCache.records[key]; ok && (last.currentRevision != currentRevision || last.updateRevision != updateRevision) {
+		WorkloadRevision.DeleteLabelValues(kind, namespace, name, last.currentRevision, last.updateRevision)
+	}
+	WorkloadRevision.WithLabelValues(kind, namespace, name, currentRevision, updateRevision).Set(1)
+	workloadCache.records[key] = record
+}
+
+// DeleteWorkloadMetrics removes all series of a workload that has been deleted.
+func DeleteWorkloadMetrics(kind, namespace, name string) {
+	key := workloadKey{kind: kind, namespace: namespace, name: name}
+	workloadCache.Lock()
+	defer workloadCache.Unlock()
+	last, ok := workloadCache.records[key]
+	if !ok {
+		return
+	}
+	for _, t := range last.replicaTypes {
+		WorkloadReplicas.DeleteLabelValues(kind, namespace, name, t)
+	}
+	WorkloadRevision.DeleteLabelValues(kind, namespace, name, last.currentRevision, last.updateRevision)
+	WorkloadRolloutInProgress.DeleteLabelValues(kind, namespace, name)
+	delete(workloadCache.records, key)
+}
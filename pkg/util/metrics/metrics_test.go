@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordWorkloadStatus(t *testing.T) {
+	RecordWorkloadStatus("CloneSet", "default", "demo", map[string]int32{"replicas": 3, "updated": 1}, "v1", "v2")
+	if v := testutil.ToFloat64(WorkloadReplicas.WithLabelValues("CloneSet", "default", "demo", "updated")); v != 1 {
+		t.Fatalf("expected updated replicas 1, got %v", v)
+	}
+	if v := testutil.ToFloat64(WorkloadRolloutInProgress.WithLabelValues("CloneSet", "default", "demo")); v != 1 {
+		t.Fatalf("expected rollout in progress, got %v", v)
+	}
+
+	RecordWorkloadStatus("CloneSet", "default", "demo", map[string]int32{"replicas": 3, "updated": 3}, "v2", "v2")
+	if v := testutil.ToFloat64(WorkloadRolloutInProgress.WithLabelValues("CloneSet", "default", "demo")); v != 0 {
+		t.Fatalf("expected rollout finished, got %v", v)
+	}
+	if n := testutil.CollectAndCount(WorkloadRevision); n != 1 {
+		t.Fatalf("expected stale revision series to be deleted, got %d series", n)
+	}
+
+	DeleteWorkloadMetrics("CloneSet", "default", "demo")
+	for _, c := range []prometheus.Collector{WorkloadReplicas, WorkloadRevision, WorkloadRolloutInProgress} {
+		if n := testutil.CollectAndCount(c); n != 0 {
+			t.Fatalf("expected all series to be deleted, got %d", n)
+		}
+	}
+}
+
+func TestObserveReconcile(t *testing.T) {
+	ObserveReconcile("test", time.Now(), nil)
+	ObserveReconcile("test", time.Now(), fmt.Errorf("failed"))
+	if v := testutil.ToFloat64(ReconcileErrors.WithLabelValues("test")); v != 1 {
+		t.Fatalf("expected 1 reconcile error, got %v", v)
+	}
+}
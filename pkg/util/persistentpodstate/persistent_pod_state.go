@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentpodstate
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+)
+
+// KruiseKindSts is the kind of Advanced StatefulSet, the only workload supported by PersistentPodState currently.
+var KruiseKindSts = appsv1beta1.SchemeGroupVersion.WithKind("StatefulSet")
+
+// IsKruiseStatefulSet returns true if the apiVersion and kind refer to Advanced StatefulSet.
+func IsKruiseStatefulSet(apiVersion, kind string) bool {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false
+	}
+	return gv.Group == KruiseKindSts.Group && kind == KruiseKindSts.Kind
+}
+
+// GetPodOwnerStatefulSet returns the controller reference of pod if it is owned by Advanced StatefulSet.
+func GetPodOwnerStatefulSet(pod *corev1.Pod) *metav1.OwnerReference {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || !IsKruiseStatefulSet(ref.APIVersion, ref.Kind) {
+		return nil
+	}
+	return ref
+}
+
+// FetchPersistentPodStateByWorkload returns the PersistentPodState whose targetRef is the Advanced StatefulSet with the name.
+// A workload should be targeted by one PersistentPodState only, which is ensured by the validating webhook.
+// If there are still several of them, e.g. created before the webhook enabled, the oldest one is returned.
+func FetchPersistentPodStateByWorkload(c client.Client, namespace, workloadName string) (*appsv1alpha1.PersistentPodState, error) {
+	ppsList := &appsv1alpha1.PersistentPodStateList{}
+	if err := c.List(context.TODO(), ppsList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var matched *appsv1alpha1.PersistentPodState
+	for i := range ppsList.Items {
+		pps := &ppsList.Items[i]
+		if !pps.DeletionTimestamp.IsZero() || !IsTargetWorkload(pps, workloadName) {
+			continue
+		}
+		if matched == nil || pps.CreationTimestamp.Before(&matched.CreationTimestamp) ||
+			(pps.CreationTimestamp.Equal(&matched.CreationTimestamp) && pps.Name < matched.Name) {
+			matched = pps
+		}
+	}
+	return matched, nil
+}
+
+// IsTargetWorkload returns true if the targetRef of PersistentPodState is the Advanced StatefulSet with the name.
+func IsTargetWorkload(pps *appsv1alpha1.PersistentPodState, workloadName string) bool {
+	ref := pps.Spec.TargetReference
+	return ref.Name == workloadName && IsKruiseStatefulSet(ref.APIVersion, ref.Kind)
+}
+
+// GetTopologyKeys returns all the node topology keys specified in required and preferred persistent topology.
+func GetTopologyKeys(pps *appsv1alpha1.PersistentPodState) []string {
+	var keys []string
+	exists := map[string]struct{}{}
+	addKeys := func(term appsv1alpha1.NodeTopologyTerm) {
+		for _, key := range term.NodeTopologyKeys {
+			if _, ok := exists[key]; !ok {
+				exists[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+	if pps.Spec.RequiredPersistentTopology != nil {
+		addKeys(*pps.Spec.RequiredPersistentTopology)
+	}
+	for _, term := range pps.Spec.PreferredPersistentTopology {
+		addKeys(term.Preference)
+	}
+	return keys
+}
+
+// RelaxPodStateOfDeletedNode removes the topology labels specific to the node, i.e. the hostname, from the state
+// whose node has been deleted, so that the recreated Pod will not be required to be scheduled to the deleted node.
+// It returns true if the state has been changed.
+func RelaxPodStateOfDeletedNode(state *appsv1alpha1.PodState) bool {
+	if _, ok := state.NodeTopologyLabels[corev1.LabelHostname]; !ok {
+		return false
+	}
+	labels := make(map[string]string, len(state.NodeTopologyLabels))
+	for key, value := range state.NodeTopologyLabels {
+		if key != corev1.LabelHostname {
+			labels[key] = value
+		}
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+	state.NodeTopologyLabels = labels
+	return true
+}
@@ -19,6 +19,9 @@ package workloadspread
 import (
 	"context"
 	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -27,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/util/retry"
@@ -35,6 +39,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	utilapi "github.com/openkruise/kruise/pkg/util/api"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 )
 
 const (
@@ -48,10 +55,12 @@ const (
 )
 
 var (
-	controllerKruiseKindCS = appsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")
-	controllerKindRS       = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
-	controllerKindDep      = appsv1.SchemeGroupVersion.WithKind("Deployment")
-	controllerKindJob      = batchv1.SchemeGroupVersion.WithKind("Job")
+	controllerKruiseKindCS  = appsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")
+	controllerKindRS        = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
+	controllerKindDep       = appsv1.SchemeGroupVersion.WithKind("Deployment")
+	controllerKindJob       = batchv1.SchemeGroupVersion.WithKind("Job")
+	controllerKindSts       = appsv1.SchemeGroupVersion.WithKind("StatefulSet")
+	controllerKruiseKindSts = appsv1beta1.SchemeGroupVersion.WithKind("StatefulSet")
 )
 
 type Operation string
@@ -72,15 +81,17 @@ var (
 		{Kind: controllerKruiseKindCS.Kind, Groups: []string{controllerKruiseKindCS.Group}},
 		{Kind: controllerKindRS.Kind, Groups: []string{controllerKindRS.Group}},
 		{Kind: controllerKindJob.Kind, Groups: []string{controllerKindJob.Group}},
+		{Kind: controllerKindSts.Kind, Groups: []string{controllerKindSts.Group, controllerKruiseKindSts.Group}},
 	}
 )
 
 type Handler struct {
 	client.Client
+	controllerFinder *controllerfinder.ControllerFinder
 }
 
 func NewWorkloadSpreadHandler(c client.Client) *Handler {
-	return &Handler{Client: c}
+	return &Handler{Client: c, controllerFinder: controllerfinder.NewControllerFinder(c)}
 }
 
 type InjectWorkloadSpread struct {
@@ -129,6 +140,55 @@ func VerifyGroupKind(ref interface{}, expectedKind string, expectedGroups []stri
 	return false, nil
 }
 
+// IsStatefulSetWorkload returns true if the target is native StatefulSet or Advanced StatefulSet,
+// whose Pods are placed into subsets by their ordinals and scaled in by the descending order of ordinals.
+func IsStatefulSetWorkload(target *appsv1alpha1.TargetReference) bool {
+	matched, _ := VerifyGroupKind(target, controllerKindSts.Kind, []string{controllerKindSts.Group, controllerKruiseKindSts.Group})
+	return matched
+}
+
+// IsWeightedSubsets returns true if Pods are distributed to the subsets of WorkloadSpread by the ratio of their weights.
+func IsWeightedSubsets(ws *appsv1alpha1.WorkloadSpread) bool {
+	return len(ws.Spec.Subsets) > 0 && ws.Spec.Subsets[0].Weight != nil
+}
+
+// GetWeightedReplicas splits the replicas to the subsets by the ratio of their weights using the largest remainder
+// method, and the front subsets take the remaining replicas first when their remainders are equal.
+func GetWeightedReplicas(subsets []appsv1alpha1.WorkloadSpreadSubset, replicas int32) []int {
+	weightedReplicas := make([]int, len(subsets))
+	var totalWeight int64
+	for _, subset := range subsets {
+		if subset.Weight != nil {
+			totalWeight += int64(*subset.Weight)
+		}
+	}
+	if totalWeight <= 0 || replicas <= 0 {
+		return weightedReplicas
+	}
+
+	var assigned int
+	remainders := make([]int64, len(subsets))
+	indexes := make([]int, len(subsets))
+	for i, subset := range subsets {
+		var weight int64
+		if subset.Weight != nil {
+			weight = int64(*subset.Weight)
+		}
+		weightedReplicas[i] = int(weight * int64(replicas) / totalWeight)
+		remainders[i] = weight * int64(replicas) % totalWeight
+		indexes[i] = i
+		assigned += weightedReplicas[i]
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return remainders[indexes[i]] > remainders[indexes[j]]
+	})
+	for i := 0; assigned < int(replicas); i++ {
+		weightedReplicas[indexes[i]]++
+		assigned++
+	}
+	return weightedReplicas
+}
+
 // matchReference return true if Pod has ownerReference matched workloads.
 func matchReference(ref *metav1.OwnerReference) (bool, error) {
 	if ref == nil {
@@ -153,13 +213,17 @@ func (h *Handler) HandlePodCreation(pod *corev1.Pod) error {
 	// 1. Deletion pod
 	// 2. Pod.Status.Phase = Succeeded or Failed
 	// 3. Pod.OwnerReference is nil
-	// 4. Pod.OwnerReference is not one of workloads, such as CloneSet, Deployment, ReplicaSet.
+	// 4. Pod.OwnerReference is not one of workloads, such as CloneSet, Deployment, ReplicaSet, StatefulSet,
+	//    and not owned by other workloads with scale subresource.
 	if !kubecontroller.IsPodActive(pod) {
 		return nil
 	}
 	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil
+	}
 	matched, err := matchReference(ref)
-	if err != nil || !matched {
+	if err != nil {
 		return nil
 	}
 
@@ -173,7 +237,8 @@ func (h *Handler) HandlePodCreation(pod *corev1.Pod) error {
 			continue
 		}
 		// determine if the reference of workloadSpread and pod is equal
-		if h.isReferenceEqual(ws.Spec.TargetReference, ref, pod.Namespace) {
+		if (matched && h.isReferenceEqual(ws.Spec.TargetReference, ref, pod.Namespace)) ||
+			(isScaleTarget(ws.Spec.TargetReference) && h.isScaleReferenceEqual(ws.Spec.TargetReference, ref, pod.Namespace)) {
 			matchedWS = &ws
 			// pod has at most one matched workloadSpread
 			break
@@ -309,7 +374,7 @@ func (h *Handler) updateSubsetForPod(ws *appsv1alpha1.WorkloadSpread,
 			}
 		}
 
-		suitableSubset = h.getSuitableSubset(ws)
+		suitableSubset = h.getSuitableSubsetForPod(ws, pod)
 		if suitableSubset == nil {
 			klog.V(5).Infof("WorkloadSpread (%s/%s) don't have a suitable subset for Pod (%s)",
 				ws.Namespace, ws.Name, pod.Name)
@@ -458,18 +523,127 @@ func getSpecificSubset(ws *appsv1alpha1.WorkloadSpread, specifySubset string) *a
 	return nil
 }
 
-func (h *Handler) getSuitableSubset(ws *appsv1alpha1.WorkloadSpread) *appsv1alpha1.WorkloadSpreadSubsetStatus {
-	for i := range ws.Status.SubsetStatuses {
-		subset := &ws.Status.SubsetStatuses[i]
-		canSchedule := true
-		for _, condition := range subset.Conditions {
-			if condition.Type == appsv1alpha1.SubsetSchedulable && condition.Status == corev1.ConditionFalse {
-				canSchedule = false
-				break
+// getSuitableSubsetForPod returns the subset for the Pod. Pods of StatefulSet are placed into subsets by their
+// ordinals, so that a Pod always comes back to the same subset after recreated, and the StatefulSet scales in
+// from the back subset to the front subset following the ordinal order. If the subset of the ordinal is
+// unschedulable, it falls back to the first suitable subset like other workloads.
+func (h *Handler) getSuitableSubsetForPod(ws *appsv1alpha1.WorkloadSpread, pod *corev1.Pod) *appsv1alpha1.WorkloadSpreadSubsetStatus {
+	if index, workloadReplicas, ok := h.getStatefulSetPodIndex(pod); ok {
+		if subset := getSubsetByPodIndex(ws, index, workloadReplicas); subset != nil && isSubsetSchedulable(subset) {
+			return subset
+		}
+	}
+	return h.getSuitableSubset(ws)
+}
+
+// getStatefulSetPodIndex returns the index of the Pod among all the Pods that its StatefulSet expects to have
+// in the ordinal order, and the replicas of the StatefulSet. It returns false if the Pod is not owned by
+// StatefulSet or its ordinal is not expected by the StatefulSet. The StatefulSet is read from the informer
+// cache behind the client of Handler, so creating Pods does not request apiserver for each of them.
+func (h *Handler) getStatefulSetPodIndex(pod *corev1.Pod) (int, int32, bool) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || pod.Name == "" {
+		return -1, 0, false
+	}
+
+	if matched, _ := VerifyGroupKind(ref, controllerKindSts.Kind, []string{controllerKindSts.Group}); matched {
+		sts := &appsv1.StatefulSet{}
+		if err := h.Get(context.TODO(), client.ObjectKey{Namespace: pod.Namespace, Name: ref.Name}, sts); err != nil || sts.UID != ref.UID {
+			return -1, 0, false
+		}
+		replicas := int32(1)
+		if sts.Spec.Replicas != nil {
+			replicas = *sts.Spec.Replicas
+		}
+		ordinal, ok := getPodOrdinal(sts.Name, pod.Name)
+		if !ok || ordinal >= int(replicas) {
+			return -1, 0, false
+		}
+		return ordinal, replicas, true
+	}
+
+	if matched, _ := VerifyGroupKind(ref, controllerKruiseKindSts.Kind, []string{controllerKruiseKindSts.Group}); matched {
+		sts := &appsv1beta1.StatefulSet{}
+		if err := h.Get(context.TODO(), client.ObjectKey{Namespace: pod.Namespace, Name: ref.Name}, sts); err != nil || sts.UID != ref.UID {
+			return -1, 0, false
+		}
+		ordinal, ok := getPodOrdinal(sts.Name, pod.Name)
+		if !ok {
+			return -1, 0, false
+		}
+		// Advanced StatefulSet may start from a non-zero ordinal and skip the reserved ordinals
+		validOrdinals := utilapi.GetValidOrdinals(sts).List()
+		index := sort.SearchInts(validOrdinals, ordinal)
+		if index >= len(validOrdinals) || validOrdinals[index] != ordinal {
+			return -1, 0, false
+		}
+		return index, int32(len(validOrdinals)), true
+	}
+
+	return -1, 0, false
+}
+
+// getPodOrdinal returns the ordinal of Pod name that belongs to the StatefulSet.
+func getPodOrdinal(stsName, podName string) (int, bool) {
+	prefix := stsName + "-"
+	if !strings.HasPrefix(podName, prefix) {
+		return -1, false
+	}
+	ordinal, err := strconv.Atoi(strings.TrimPrefix(podName, prefix))
+	if err != nil || ordinal < 0 {
+		return -1, false
+	}
+	return ordinal, true
+}
+
+// getSubsetByPodIndex returns the subset that the Pod with the index should be placed into. The subsets take
+// the indexes in sequence according to their maxReplicas, e.g., subset-a with maxReplicas 2 takes the Pods
+// with index 0 and 1, then subset-b takes the following ones.
+func getSubsetByPodIndex(ws *appsv1alpha1.WorkloadSpread, index int, workloadReplicas int32) *appsv1alpha1.WorkloadSpreadSubsetStatus {
+	var upperBound int
+	if IsWeightedSubsets(ws) {
+		for i, replicas := range GetWeightedReplicas(ws.Spec.Subsets, workloadReplicas) {
+			upperBound += replicas
+			if index < upperBound {
+				return getSpecificSubset(ws, ws.Spec.Subsets[i].Name)
 			}
 		}
+		return nil
+	}
 
-		if canSchedule && (subset.MissingReplicas > 0 || subset.MissingReplicas == -1) {
+	for _, subset := range ws.Spec.Subsets {
+		if subset.MaxReplicas == nil {
+			return getSpecificSubset(ws, subset.Name)
+		}
+		// percent maxReplicas is rejected by webhook for StatefulSet, for it has no stable ordinal boundary
+		if subset.MaxReplicas.Type != intstr.Int {
+			return nil
+		}
+		upperBound += subset.MaxReplicas.IntValue()
+		if index < upperBound {
+			return getSpecificSubset(ws, subset.Name)
+		}
+	}
+	return nil
+}
+
+func isSubsetSchedulable(subset *appsv1alpha1.WorkloadSpreadSubsetStatus) bool {
+	for _, condition := range subset.Conditions {
+		if condition.Type == appsv1alpha1.SubsetSchedulable && condition.Status == corev1.ConditionFalse {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Handler) getSuitableSubset(ws *appsv1alpha1.WorkloadSpread) *appsv1alpha1.WorkloadSpreadSubsetStatus {
+	if IsWeightedSubsets(ws) {
+		return getWeightedSubset(ws)
+	}
+
+	for i := range ws.Status.SubsetStatuses {
+		subset := &ws.Status.SubsetStatuses[i]
+		if isSubsetSchedulable(subset) && (subset.MissingReplicas > 0 || subset.MissingReplicas == -1) {
 			// TODO simulation schedule
 			// scheduleStrategy.Type = Adaptive
 			// Webhook will simulate a schedule in order to check whether Pod can run in this subset,
@@ -483,6 +657,50 @@ func (h *Handler) getSuitableSubset(ws *appsv1alpha1.WorkloadSpread) *appsv1alph
 	return nil
 }
 
+// getWeightedSubset returns the schedulable subset whose current replicas fall behind its weight the most after
+// the new Pod is added, so that the distribution of Pods keeps close to the ratio of weights at every replica count.
+func getWeightedSubset(ws *appsv1alpha1.WorkloadSpread) *appsv1alpha1.WorkloadSpreadSubsetStatus {
+	subsetStatuses := make([]*appsv1alpha1.WorkloadSpreadSubsetStatus, len(ws.Spec.Subsets))
+	subsetReplicas := make([]int64, len(ws.Spec.Subsets))
+	var totalWeight, totalReplicas int64
+	for i, subset := range ws.Spec.Subsets {
+		if subset.Weight != nil {
+			totalWeight += int64(*subset.Weight)
+		}
+		for j := range ws.Status.SubsetStatuses {
+			if ws.Status.SubsetStatuses[j].Name == subset.Name {
+				subsetStatuses[i] = &ws.Status.SubsetStatuses[j]
+				break
+			}
+		}
+		if subsetStatuses[i] == nil {
+			continue
+		}
+		// the Pods being created are counted in and the Pods being deleted are not
+		replicas := int64(subsetStatuses[i].Replicas) + int64(len(subsetStatuses[i].CreatingPods)) - int64(len(subsetStatuses[i].DeletingPods))
+		if replicas < 0 {
+			replicas = 0
+		}
+		subsetReplicas[i] = replicas
+		totalReplicas += replicas
+	}
+
+	var suitableSubset *appsv1alpha1.WorkloadSpreadSubsetStatus
+	var maxLack int64
+	for i, subset := range ws.Spec.Subsets {
+		if subset.Weight == nil || *subset.Weight <= 0 || subsetStatuses[i] == nil || !isSubsetSchedulable(subsetStatuses[i]) {
+			continue
+		}
+		// lack = weight / totalWeight * (totalReplicas + 1) - replicas, multiplied by totalWeight to avoid float
+		lack := int64(*subset.Weight)*(totalReplicas+1) - subsetReplicas[i]*totalWeight
+		if suitableSubset == nil || lack > maxLack {
+			suitableSubset = subsetStatuses[i]
+			maxLack = lack
+		}
+	}
+	return suitableSubset
+}
+
 func (h Handler) isReferenceEqual(target *appsv1alpha1.TargetReference, owner *metav1.OwnerReference, namespace string) bool {
 	targetGv, err := schema.ParseGroupVersion(target.APIVersion)
 	if err != nil {
@@ -518,3 +736,36 @@ func (h Handler) isReferenceEqual(target *appsv1alpha1.TargetReference, owner *m
 
 	return targetGv.Group == ownerGv.Group && target.Kind == owner.Kind && target.Name == owner.Name
 }
+
+// isScaleTarget returns true if the target is not a workload that WorkloadSpread supports natively,
+// which should be found by its scale subresource.
+func isScaleTarget(target *appsv1alpha1.TargetReference) bool {
+	if matched, _ := VerifyGroupKind(target, controllerKindJob.Kind, []string{controllerKindJob.Group}); matched {
+		return false
+	}
+	return !controllerfinder.IsKnownWorkload(target.APIVersion, target.Kind)
+}
+
+// isScaleReferenceEqual returns true if the owner of Pod, or the first owner with scale subresource
+// through its intermediate owners, is the target workload.
+func (h Handler) isScaleReferenceEqual(target *appsv1alpha1.TargetReference, owner *metav1.OwnerReference, namespace string) bool {
+	workload, err := h.controllerFinder.GetScaleControllerReference(owner.APIVersion, owner.Kind, namespace, owner.Name, owner.UID)
+	if err != nil {
+		klog.Errorf("get workload for OwnerReference %s (%s/%s) failed: %s", owner.Kind, namespace, owner.Name, err.Error())
+		return false
+	}
+	if workload == nil {
+		return false
+	}
+
+	targetGv, err := schema.ParseGroupVersion(target.APIVersion)
+	if err != nil {
+		klog.Errorf("parse TargetReference apiVersion (%s) failed: %s", target.APIVersion, err.Error())
+		return false
+	}
+	workloadGv, err := schema.ParseGroupVersion(workload.APIVersion)
+	if err != nil {
+		return false
+	}
+	return targetGv.Group == workloadGv.Group && target.Kind == workload.Kind && target.Name == workload.Name
+}
@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
 )
 
 var (
@@ -152,6 +154,8 @@ var (
 func init() {
 	scheme = runtime.NewScheme()
 	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = appsv1beta1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
 	_ = corev1.AddToScheme(scheme)
 }
 
@@ -190,6 +194,208 @@ func TestWorkloadSpreadCreatePodWithoutFullName(t *testing.T) {
 	}
 }
 
+func TestWorkloadSpreadCreateStatefulSetPod(t *testing.T) {
+	asts := &appsv1beta1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "asts-test", Namespace: "default", UID: "asts-uid"},
+		Spec: appsv1beta1.StatefulSetSpec{
+			Replicas:        utilpointer.Int32Ptr(5),
+			ReserveOrdinals: []intstr.IntOrString{intstr.FromInt(1)},
+		},
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts-test", Namespace: "default", UID: "sts-uid"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: utilpointer.Int32Ptr(3)},
+	}
+	newPod := func(apiVersion, owner string, uid types.UID, ordinal int) *corev1.Pod {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("%s-%d", owner, ordinal)
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: apiVersion,
+			Kind:       "StatefulSet",
+			Name:       owner,
+			UID:        uid,
+			Controller: utilpointer.BoolPtr(true),
+		}}
+		return pod
+	}
+	newWorkloadSpread := func() *appsv1alpha1.WorkloadSpread {
+		ws := &appsv1alpha1.WorkloadSpread{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-ws", Namespace: "default"},
+			Spec: appsv1alpha1.WorkloadSpreadSpec{
+				Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+					{Name: "subset-a", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 2}},
+					{Name: "subset-b", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 2}},
+					{Name: "subset-c"},
+				},
+			},
+			Status: appsv1alpha1.WorkloadSpreadStatus{
+				SubsetStatuses: []appsv1alpha1.WorkloadSpreadSubsetStatus{
+					{Name: "subset-a"},
+					{Name: "subset-b"},
+					{Name: "subset-c", MissingReplicas: -1},
+				},
+			},
+		}
+		return ws
+	}
+
+	cases := []struct {
+		name           string
+		getPod         func() *corev1.Pod
+		getWS          func() *appsv1alpha1.WorkloadSpread
+		expectedSubset string
+	}{
+		{
+			name: "first ordinal of Advanced StatefulSet",
+			getPod: func() *corev1.Pod {
+				return newPod("apps.kruise.io/v1beta1", "asts-test", "asts-uid", 0)
+			},
+			getWS:          newWorkloadSpread,
+			expectedSubset: "subset-a",
+		},
+		{
+			name: "ordinal of Advanced StatefulSet after reserved ordinal",
+			getPod: func() *corev1.Pod {
+				return newPod("apps.kruise.io/v1beta1", "asts-test", "asts-uid", 2)
+			},
+			getWS:          newWorkloadSpread,
+			expectedSubset: "subset-a",
+		},
+		{
+			name: "ordinal of Advanced StatefulSet in the second subset",
+			getPod: func() *corev1.Pod {
+				return newPod("apps.kruise.io/v1beta1", "asts-test", "asts-uid", 4)
+			},
+			getWS:          newWorkloadSpread,
+			expectedSubset: "subset-b",
+		},
+		{
+			name: "ordinal of Advanced StatefulSet in the last subset",
+			getPod: func() *corev1.Pod {
+				return newPod("apps.kruise.io/v1beta1", "asts-test", "asts-uid", 5)
+			},
+			getWS:          newWorkloadSpread,
+			expectedSubset: "subset-c",
+		},
+		{
+			name: "ordinal of native StatefulSet",
+			getPod: func() *corev1.Pod {
+				return newPod("apps/v1", "sts-test", "sts-uid", 1)
+			},
+			getWS:          newWorkloadSpread,
+			expectedSubset: "subset-a",
+		},
+		{
+			name: "subset of ordinal is unschedulable",
+			getPod: func() *corev1.Pod {
+				return newPod("apps/v1", "sts-test", "sts-uid", 1)
+			},
+			getWS: func() *appsv1alpha1.WorkloadSpread {
+				ws := newWorkloadSpread()
+				ws.Status.SubsetStatuses[0].Conditions = []appsv1alpha1.WorkloadSpreadSubsetCondition{
+					{Type: appsv1alpha1.SubsetSchedulable, Status: corev1.ConditionFalse},
+				}
+				return ws
+			},
+			expectedSubset: "subset-c",
+		},
+		{
+			name: "percent maxReplicas is not placed by ordinal",
+			getPod: func() *corev1.Pod {
+				return newPod("apps.kruise.io/v1beta1", "asts-test", "asts-uid", 0)
+			},
+			getWS: func() *appsv1alpha1.WorkloadSpread {
+				ws := newWorkloadSpread()
+				for i := range ws.Spec.Subsets[:2] {
+					ws.Spec.Subsets[i].MaxReplicas = &intstr.IntOrString{Type: intstr.String, StrVal: "40%"}
+				}
+				return ws
+			},
+			expectedSubset: "subset-c",
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(asts, sts).Build()
+			handler := NewWorkloadSpreadHandler(fakeClient)
+			_, suitableSubset, _ := handler.updateSubsetForPod(cs.getWS(), cs.getPod(), nil, CreateOperation)
+			if suitableSubset == nil || suitableSubset.Name != cs.expectedSubset {
+				t.Fatalf("expected subset %s, got %v", cs.expectedSubset, suitableSubset)
+			}
+		})
+	}
+}
+
+func TestGetWeightedReplicas(t *testing.T) {
+	cases := []struct {
+		weights  []int32
+		replicas int32
+		expected []int
+	}{
+		{weights: []int32{30, 70}, replicas: 0, expected: []int{0, 0}},
+		{weights: []int32{30, 70}, replicas: 1, expected: []int{0, 1}},
+		{weights: []int32{30, 70}, replicas: 5, expected: []int{2, 3}},
+		{weights: []int32{30, 70}, replicas: 10, expected: []int{3, 7}},
+		{weights: []int32{1, 1, 1}, replicas: 4, expected: []int{2, 1, 1}},
+		{weights: []int32{0, 1}, replicas: 3, expected: []int{0, 3}},
+	}
+	for _, cs := range cases {
+		var subsets []appsv1alpha1.WorkloadSpreadSubset
+		for i := range cs.weights {
+			subsets = append(subsets, appsv1alpha1.WorkloadSpreadSubset{Name: fmt.Sprintf("subset-%d", i), Weight: &cs.weights[i]})
+		}
+		if got := GetWeightedReplicas(subsets, cs.replicas); !reflect.DeepEqual(got, cs.expected) {
+			t.Fatalf("weights %v, replicas %d: expected %v, got %v", cs.weights, cs.replicas, cs.expected, got)
+		}
+	}
+}
+
+func TestWorkloadSpreadCreatePodWithWeightedSubsets(t *testing.T) {
+	handler := NewWorkloadSpreadHandler(nil)
+	ws := &appsv1alpha1.WorkloadSpread{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ws", Namespace: "default"},
+		Spec: appsv1alpha1.WorkloadSpreadSpec{
+			Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+				{Name: "subset-a", Weight: utilpointer.Int32Ptr(30)},
+				{Name: "subset-b", Weight: utilpointer.Int32Ptr(70)},
+			},
+		},
+		Status: appsv1alpha1.WorkloadSpreadStatus{
+			SubsetStatuses: []appsv1alpha1.WorkloadSpreadSubsetStatus{
+				{Name: "subset-a"},
+				{Name: "subset-b"},
+			},
+		},
+	}
+
+	// keep the distribution close to 30:70 at every replica count
+	expected := []string{"subset-b", "subset-a", "subset-b", "subset-b", "subset-a", "subset-b", "subset-b", "subset-b", "subset-a", "subset-b"}
+	for i, expectedSubset := range expected {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("test-pod-%d", i)
+		changed, suitableSubset, _ := handler.updateSubsetForPod(ws, pod, nil, CreateOperation)
+		if !changed || suitableSubset == nil || suitableSubset.Name != expectedSubset {
+			t.Fatalf("pod %d: expected subset %s, got %v", i, expectedSubset, suitableSubset)
+		}
+	}
+	if len(ws.Status.SubsetStatuses[0].CreatingPods) != 3 || len(ws.Status.SubsetStatuses[1].CreatingPods) != 7 {
+		t.Fatalf("expected 3 and 7 creating pods, got %d and %d",
+			len(ws.Status.SubsetStatuses[0].CreatingPods), len(ws.Status.SubsetStatuses[1].CreatingPods))
+	}
+
+	// Pods are placed into subset-b only if subset-a is unschedulable
+	ws.Status.SubsetStatuses[0].Conditions = []appsv1alpha1.WorkloadSpreadSubsetCondition{
+		{Type: appsv1alpha1.SubsetSchedulable, Status: corev1.ConditionFalse},
+	}
+	ws.Status.SubsetStatuses[1].DeletingPods = map[string]metav1.Time{"test-pod-0": {Time: defaultTime}, "test-pod-2": {Time: defaultTime}}
+	pod := podDemo.DeepCopy()
+	pod.Name = "test-pod-10"
+	if _, suitableSubset, _ := handler.updateSubsetForPod(ws, pod, nil, CreateOperation); suitableSubset == nil || suitableSubset.Name != "subset-b" {
+		t.Fatalf("expected subset subset-b, got %v", suitableSubset)
+	}
+}
+
 func TestWorkloadSpreadMutatingPod(t *testing.T) {
 	cases := []struct {
 		name                 string
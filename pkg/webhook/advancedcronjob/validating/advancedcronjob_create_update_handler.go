@@ -23,6 +23,7 @@ import (
 	"regexp"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	"github.com/robfig/cron"
 	admissionv1 "k8s.io/api/admission/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
@@ -105,12 +106,17 @@ func validateAdvancedCronJobSpecTemplate(spec *appsv1alpha1.AdvancedCronJobSpec,
 		allErrs = append(allErrs, validateBroadcastJobTemplateSpec(spec.Template.BroadcastJobTemplate, fldPath)...)
 	}
 
+	if spec.Template.ImagePullJobTemplate != nil {
+		templateCount++
+		allErrs = append(allErrs, validateImagePullJobTemplateSpec(spec.Template.ImagePullJobTemplate, fldPath)...)
+	}
+
 	if templateCount == 0 {
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("template"),
-			"spec must have one template, either JobTemplate or BroadcastJobTemplate should be provided"))
+			"spec must have one template, either JobTemplate, BroadcastJobTemplate or ImagePullJobTemplate should be provided"))
 	} else if templateCount > 1 {
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("template"),
-			"spec can have only one template, either JobTemplate or BroadcastJobTemplate should be provided"))
+			"spec can have only one template, either JobTemplate, BroadcastJobTemplate or ImagePullJobTemplate should be provided"))
 	}
 	return allErrs
 }
@@ -135,6 +141,31 @@ func validateBroadcastJobTemplateSpec(brJobSpec *appsv1alpha1.BroadcastJobTempla
 	return append(allErrs, corevalidation.ValidatePodTemplateSpec(coreTemplate, fldPath.Child("template"), corevalidation.PodValidationOptions{AllowDownwardAPIHugePages: true, AllowMultipleHugePageResources: true})...)
 }
 
+func validateImagePullJobTemplateSpec(imagePullJobSpec *appsv1alpha1.ImagePullJobTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := &imagePullJobSpec.Spec
+	specPath := fldPath.Child("template", "imagePullJobTemplate", "spec")
+	if spec.Image == "" && len(spec.Images) == 0 && spec.ImagesFromWorkload == nil {
+		allErrs = append(allErrs, field.Required(specPath, "one of image, images and imagesFromWorkload should be provided"))
+	}
+	if spec.Image != "" {
+		if _, err := daemonutil.NormalizeImageRef(spec.Image); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("image"), spec.Image, err.Error()))
+		}
+	}
+	for i, image := range spec.Images {
+		if _, err := daemonutil.NormalizeImageRef(image); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("images").Index(i), image, err.Error()))
+		}
+	}
+	// the imagepulljob with Never completionPolicy will never finish, which blocks the next schedule
+	if spec.CompletionPolicy.Type == appsv1alpha1.Never {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("completionPolicy", "type"),
+			spec.CompletionPolicy.Type, "completionPolicy of imagepulljob template must be Always"))
+	}
+	return allErrs
+}
+
 func convertPodTemplateSpec(template *v1.PodTemplateSpec) (*core.PodTemplateSpec, error) {
 	coreTemplate := &core.PodTemplateSpec{}
 	if err := corev1.Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec(template.DeepCopy(), coreTemplate, nil); err != nil {
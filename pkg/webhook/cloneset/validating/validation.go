@@ -8,6 +8,7 @@ import (
 	clonesetcore "github.com/openkruise/kruise/pkg/controller/cloneset/core"
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/webhook/util/convertor"
+	lifecyclevalidation "github.com/openkruise/kruise/pkg/webhook/util/lifecycle"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
@@ -90,6 +91,7 @@ func (h *CloneSetCreateUpdateHandler) validateCloneSetSpec(spec, oldSpec *appsv1
 
 	allErrs = append(allErrs, h.validateScaleStrategy(&spec.ScaleStrategy, oldScaleStrategy, metadata, fldPath.Child("scaleStrategy"))...)
 	allErrs = append(allErrs, h.validateUpdateStrategy(&spec.UpdateStrategy, int(*spec.Replicas), fldPath.Child("updateStrategy"))...)
+	allErrs = append(allErrs, lifecyclevalidation.ValidateLifecycle(spec.Lifecycle, &spec.Template, fldPath.Child("lifecycle"))...)
 
 	return allErrs
 }
@@ -179,6 +181,58 @@ func (h *CloneSetCreateUpdateHandler) validateUpdateStrategy(strategy *appsv1alp
 			"maxUnavailable and maxSurge should not both be less than 1"))
 	}
 
+	allErrs = append(allErrs, validateUpdateSteps(strategy, replicas, fldPath)...)
+
+	return allErrs
+}
+
+func validateUpdateSteps(strategy *appsv1alpha1.CloneSetUpdateStrategy, replicas int, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(strategy.Steps) == 0 {
+		if strategy.RollbackPolicy != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("rollbackPolicy"), "rollbackPolicy can only be used with steps"))
+		}
+		return allErrs
+	}
+
+	if partition, _ := intstrutil.GetValueFromIntOrPercent(strategy.Partition, replicas, true); partition != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("partition"), "partition should be 0 when steps are used"))
+	}
+	var lastStepReplicas int
+	for i := range strategy.Steps {
+		step := &strategy.Steps[i]
+		stepPath := fldPath.Child("steps").Index(i)
+		stepReplicas, err := intstrutil.GetValueFromIntOrPercent(&step.Replicas, replicas, true)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(stepPath.Child("replicas"), step.Replicas.String(),
+				fmt.Sprintf("failed getValueFromIntOrPercent for replicas: %v", err)))
+			continue
+		}
+		allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(stepReplicas), stepPath.Child("replicas"))...)
+		if stepReplicas < lastStepReplicas {
+			allErrs = append(allErrs, field.Invalid(stepPath.Child("replicas"), step.Replicas.String(),
+				"replicas of steps should not be decreasing"))
+		}
+		lastStepReplicas = stepReplicas
+		if step.Pause != nil && step.Pause.DurationSeconds != nil {
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*step.Pause.DurationSeconds), stepPath.Child("pause", "durationSeconds"))...)
+		}
+	}
+
+	if policy := strategy.RollbackPolicy; policy != nil {
+		if policy.MaxFailedPods != nil {
+			maxFailedPods, err := intstrutil.GetValueFromIntOrPercent(policy.MaxFailedPods, replicas, true)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackPolicy", "maxFailedPods"), policy.MaxFailedPods.String(),
+					fmt.Sprintf("failed getValueFromIntOrPercent for maxFailedPods: %v", err)))
+			}
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(maxFailedPods), fldPath.Child("rollbackPolicy", "maxFailedPods"))...)
+		}
+		if policy.NotReadyTimeoutSeconds != nil && *policy.NotReadyTimeoutSeconds <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackPolicy", "notReadyTimeoutSeconds"), *policy.NotReadyTimeoutSeconds,
+				"must be greater than 0"))
+		}
+	}
 	return allErrs
 }
 
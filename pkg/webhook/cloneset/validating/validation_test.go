@@ -13,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -225,6 +226,24 @@ func TestValidate(t *testing.T) {
 				},
 			},
 		},
+		{
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+					Partition:      util.GetIntOrStrPointer(intstr.FromInt(0)),
+					Steps: []appsv1alpha1.CloneSetUpdateStep{
+						{Replicas: intstr.FromString("10%"), Pause: &appsv1alpha1.CloneSetUpdateStepPause{DurationSeconds: utilpointer.Int32Ptr(600)}},
+						{Replicas: intstr.FromString("50%"), Pause: &appsv1alpha1.CloneSetUpdateStepPause{}},
+						{Replicas: intstr.FromString("100%")},
+					},
+					RollbackPolicy: &appsv1alpha1.CloneSetRollbackPolicy{MaxFailedPods: util.GetIntOrStrPointer(intstr.FromString("20%"))},
+				},
+			},
+		},
 	}
 
 	for i, successCase := range successCases {
@@ -409,6 +428,81 @@ func TestValidate(t *testing.T) {
 				},
 			},
 		},
+		"partition-with-steps": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+					Partition:      util.GetIntOrStrPointer(intstr.FromInt(1)),
+					Steps:          []appsv1alpha1.CloneSetUpdateStep{{Replicas: intstr.FromString("100%")}},
+				},
+			},
+		},
+		"decreasing-steps": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+					Partition:      util.GetIntOrStrPointer(intstr.FromInt(0)),
+					Steps:          []appsv1alpha1.CloneSetUpdateStep{{Replicas: intstr.FromString("50%")}, {Replicas: intstr.FromInt(0)}},
+				},
+			},
+		},
+		"rollback-policy-without-steps": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+					Partition:      util.GetIntOrStrPointer(intstr.FromInt(0)),
+					RollbackPolicy: &appsv1alpha1.CloneSetRollbackPolicy{MaxFailedPods: util.GetIntOrStrPointer(intstr.FromInt(1))},
+				},
+			},
+		},
+		"empty-pre-normal-hook": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+				},
+				Lifecycle: &appspub.Lifecycle{PreNormal: &appspub.LifecycleHook{}},
+			},
+		},
+		"pre-normal-hook-satisfied-by-template": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+				},
+				Lifecycle: &appspub.Lifecycle{PreNormal: &appspub.LifecycleHook{LabelsHandler: validLabels}},
+			},
+		},
+		"invalid-pre-normal-hook-finalizer": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+				},
+				Lifecycle: &appspub.Lifecycle{PreNormal: &appspub.LifecycleHook{FinalizersHandler: []string{"invalid/finalizer/name"}}},
+			},
+		},
 	}
 
 	for k, v := range errorCases {
@@ -28,6 +28,7 @@ import (
 	"github.com/openkruise/kruise/pkg/features"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -86,12 +87,42 @@ func validate(obj *appsv1alpha1.ImagePullJob) error {
 		}
 	}
 
-	if len(obj.Spec.Image) == 0 {
-		return fmt.Errorf("image can not be empty")
+	if len(obj.Spec.Image) == 0 && len(obj.Spec.Images) == 0 && obj.Spec.ImagesFromWorkload == nil {
+		return fmt.Errorf("image, images and imagesFromWorkload can not be all empty")
 	}
 
-	if _, err := daemonutil.NormalizeImageRef(obj.Spec.Image); err != nil {
-		return fmt.Errorf("invalid image %s: %v", obj.Spec.Image, err)
+	if len(obj.Spec.Image) > 0 {
+		if _, err := daemonutil.NormalizeImageRef(obj.Spec.Image); err != nil {
+			return fmt.Errorf("invalid image %s: %v", obj.Spec.Image, err)
+		}
+	}
+	for _, image := range obj.Spec.Images {
+		if len(image) == 0 {
+			return fmt.Errorf("image in images can not be empty")
+		}
+		if _, err := daemonutil.NormalizeImageRef(image); err != nil {
+			return fmt.Errorf("invalid image %s: %v", image, err)
+		}
+	}
+
+	if ref := obj.Spec.ImagesFromWorkload; ref != nil {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return fmt.Errorf("invalid apiVersion %s in imagesFromWorkload: %v", ref.APIVersion, err)
+		}
+		if gv.Group != appsv1alpha1.GroupVersion.Group || (ref.Kind != "CloneSet" && ref.Kind != "StatefulSet") {
+			return fmt.Errorf("imagesFromWorkload only supports CloneSet and Advanced StatefulSet")
+		}
+		if len(ref.Name) == 0 {
+			return fmt.Errorf("name of imagesFromWorkload can not be empty")
+		}
+	}
+
+	if obj.Spec.PullPolicy != nil && obj.Spec.PullPolicy.MaxBytesPerSecond != nil && *obj.Spec.PullPolicy.MaxBytesPerSecond <= 0 {
+		return fmt.Errorf("pullPolicy.maxBytesPerSecond must be positive")
+	}
+	if obj.Spec.PullPolicy != nil && obj.Spec.PullPolicy.MaxConcurrentPulls != nil && *obj.Spec.PullPolicy.MaxConcurrentPulls <= 0 {
+		return fmt.Errorf("pullPolicy.maxConcurrentPulls must be positive")
 	}
 
 	switch obj.Spec.CompletionPolicy.Type {
@@ -93,7 +93,33 @@ func validate(obj *appsv1alpha1.NodeImage) error {
 			if tagSpec.PullPolicy.TTLSecondsAfterFinished == nil {
 				return fmt.Errorf("pullPolicy.ttlSecondsAfterFinished for %s must set", fullName)
 			}
+			if tagSpec.PullPolicy.MaxBytesPerSecond != nil && *tagSpec.PullPolicy.MaxBytesPerSecond <= 0 {
+				return fmt.Errorf("pullPolicy.maxBytesPerSecond for %s must be positive", fullName)
+			}
+			if tagSpec.PullPolicy.MaxConcurrentPulls != nil && *tagSpec.PullPolicy.MaxConcurrentPulls <= 0 {
+				return fmt.Errorf("pullPolicy.maxConcurrentPulls for %s must be positive", fullName)
+			}
+
+		}
+	}
+
+	if len(obj.Spec.ImagesToRemove) > defaultMaxImagesPerNode {
+		return fmt.Errorf("spec imagesToRemove length %v can not be more than %v", len(obj.Spec.ImagesToRemove), defaultMaxImagesPerNode)
+	}
 
+	for name, removeSpec := range obj.Spec.ImagesToRemove {
+		if len(name) <= 0 {
+			return fmt.Errorf("image name to remove can not be empty")
+		}
+		existingTags := sets.NewString()
+		for _, tag := range removeSpec.Tags {
+			if len(tag) <= 0 {
+				return fmt.Errorf("tag to remove for image %s can not be empty", name)
+			}
+			if existingTags.Has(tag) {
+				return fmt.Errorf("duplicated tag %s to remove for image %s", tag, name)
+			}
+			existingTags.Insert(tag)
 		}
 	}
 
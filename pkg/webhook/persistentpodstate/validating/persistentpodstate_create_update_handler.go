@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	ppsutil "github.com/openkruise/kruise/pkg/util/persistentpodstate"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// PersistentPodStateCreateUpdateHandler handles PersistentPodState
+type PersistentPodStateCreateUpdateHandler struct {
+	Client client.Client
+
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &PersistentPodStateCreateUpdateHandler{}
+
+// Handle handles admission requests.
+func (h *PersistentPodStateCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.PersistentPodState) {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("feature PersistentPodState is invalid, please open via feature-gate(%s)",
+			features.PersistentPodState))
+	}
+
+	obj := &appsv1alpha1.PersistentPodState{}
+	if err := h.Decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	var old *appsv1alpha1.PersistentPodState
+	//when Operation is update, decode older object
+	if req.AdmissionRequest.Operation == admissionv1.Update {
+		old = new(appsv1alpha1.PersistentPodState)
+		if err := h.Decoder.Decode(
+			admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Object: req.AdmissionRequest.OldObject}},
+			old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	allErrs := h.validatingPersistentPodStateFn(obj, old)
+	if len(allErrs) != 0 {
+		return admission.Errored(http.StatusBadRequest, allErrs.ToAggregate())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+func (h *PersistentPodStateCreateUpdateHandler) validatingPersistentPodStateFn(obj, old *appsv1alpha1.PersistentPodState) field.ErrorList {
+	allErrs := validatePersistentPodStateSpec(obj, field.NewPath("spec"))
+	// targetRef can't be changed, for the recorded states belong to the pods of the workload
+	if old != nil && !reflect.DeepEqual(obj.Spec.TargetReference, old.Spec.TargetReference) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "targetRef"), "targetRef cannot be modified"))
+	}
+	// the workload cannot be targeted by multiple PersistentPodStates
+	ppsList := &appsv1alpha1.PersistentPodStateList{}
+	if err := h.Client.List(context.TODO(), ppsList, &client.ListOptions{Namespace: obj.Namespace}); err != nil {
+		allErrs = append(allErrs, field.InternalError(field.NewPath(""), fmt.Errorf("query other PersistentPodState failed, err: %v", err)))
+	} else {
+		allErrs = append(allErrs, validatePersistentPodStateConflict(obj, ppsList.Items, field.NewPath("spec", "targetRef"))...)
+	}
+	return allErrs
+}
+
+func validatePersistentPodStateSpec(obj *appsv1alpha1.PersistentPodState, fldPath *field.Path) field.ErrorList {
+	spec := &obj.Spec
+	allErrs := field.ErrorList{}
+
+	ref := spec.TargetReference
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("targetRef", "name"), "name of targetRef is required"))
+	}
+	if !ppsutil.IsKruiseStatefulSet(ref.APIVersion, ref.Kind) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("targetRef"), ref, []string{ppsutil.KruiseKindSts.String()}))
+	}
+
+	keys := sets.NewString()
+	for i, annotation := range spec.PersistentPodAnnotations {
+		keyPath := fldPath.Child("persistentPodAnnotations").Index(i).Child("key")
+		if annotation.Key == "" {
+			allErrs = append(allErrs, field.Required(keyPath, "annotation key is required"))
+		} else if keys.Has(annotation.Key) {
+			allErrs = append(allErrs, field.Duplicate(keyPath, annotation.Key))
+		}
+		keys.Insert(annotation.Key)
+	}
+
+	if spec.RequiredPersistentTopology != nil {
+		allErrs = append(allErrs, validateNodeTopologyTerm(spec.RequiredPersistentTopology, fldPath.Child("requiredPersistentTopology"))...)
+	}
+	for i := range spec.PreferredPersistentTopology {
+		term := &spec.PreferredPersistentTopology[i]
+		termPath := fldPath.Child("preferredPersistentTopology").Index(i)
+		if term.Weight < 1 || term.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(termPath.Child("weight"), term.Weight, "must be in the range 1-100"))
+		}
+		allErrs = append(allErrs, validateNodeTopologyTerm(&term.Preference, termPath.Child("preference"))...)
+	}
+	return allErrs
+}
+
+func validateNodeTopologyTerm(term *appsv1alpha1.NodeTopologyTerm, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	keysPath := fldPath.Child("nodeTopologyKeys")
+	if len(term.NodeTopologyKeys) == 0 {
+		allErrs = append(allErrs, field.Required(keysPath, "at least one node topology key is required"))
+	}
+	for i, key := range term.NodeTopologyKeys {
+		if key == "" {
+			allErrs = append(allErrs, field.Required(keysPath.Index(i), "node topology key must be non-empty"))
+			continue
+		}
+		allErrs = append(allErrs, metavalidation.ValidateLabelName(key, keysPath.Index(i))...)
+	}
+	return allErrs
+}
+
+func validatePersistentPodStateConflict(pps *appsv1alpha1.PersistentPodState, others []appsv1alpha1.PersistentPodState, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range others {
+		other := &others[i]
+		if pps.Name == other.Name || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if ppsutil.IsTargetWorkload(other, pps.Spec.TargetReference.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath, pps.Spec.TargetReference, fmt.Sprintf(
+				"pps.spec.targetRef is in conflict with other PersistentPodState %s", other.Name)))
+			return allErrs
+		}
+	}
+	return allErrs
+}
+
+var _ inject.Client = &PersistentPodStateCreateUpdateHandler{}
+
+// InjectClient injects the client into the PersistentPodStateCreateUpdateHandler
+func (h *PersistentPodStateCreateUpdateHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &PersistentPodStateCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the PersistentPodStateCreateUpdateHandler
+func (h *PersistentPodStateCreateUpdateHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
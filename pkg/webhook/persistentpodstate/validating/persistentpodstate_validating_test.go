@@ -0,0 +1,192 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func init() {
+	scheme = runtime.NewScheme()
+	_ = appsv1alpha1.AddToScheme(scheme)
+}
+
+var (
+	scheme *runtime.Scheme
+
+	ppsDemo = appsv1alpha1.PersistentPodState{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pps-test",
+		},
+		Spec: appsv1alpha1.PersistentPodStateSpec{
+			TargetReference: appsv1alpha1.TargetReference{
+				APIVersion: "apps.kruise.io/v1beta1",
+				Kind:       "StatefulSet",
+				Name:       "sts-test",
+			},
+			RequiredPersistentTopology: &appsv1alpha1.NodeTopologyTerm{
+				NodeTopologyKeys: []string{corev1.LabelHostname},
+			},
+			PreferredPersistentTopology: []appsv1alpha1.PreferredTopologyTerm{
+				{
+					Weight:     100,
+					Preference: appsv1alpha1.NodeTopologyTerm{NodeTopologyKeys: []string{corev1.LabelTopologyZone}},
+				},
+			},
+			PersistentPodAnnotations: []appsv1alpha1.PersistentPodAnnotation{{Key: "ip"}},
+		},
+	}
+)
+
+func TestValidatingPersistentPodState(t *testing.T) {
+	cases := []struct {
+		name          string
+		pps           func() *appsv1alpha1.PersistentPodState
+		old           func() *appsv1alpha1.PersistentPodState
+		others        func() []*appsv1alpha1.PersistentPodState
+		expectErrList int
+	}{
+		{
+			name: "valid pps",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				return ppsDemo.DeepCopy()
+			},
+			expectErrList: 0,
+		},
+		{
+			name: "invalid pps, targetRef is not Advanced StatefulSet",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.TargetReference.APIVersion = "apps/v1"
+				return pps
+			},
+			expectErrList: 1,
+		},
+		{
+			name: "invalid pps, empty targetRef name",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.TargetReference.Name = ""
+				return pps
+			},
+			expectErrList: 1,
+		},
+		{
+			name: "invalid pps, weight out of range",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.PreferredPersistentTopology[0].Weight = 0
+				pps.Spec.PreferredPersistentTopology = append(pps.Spec.PreferredPersistentTopology, appsv1alpha1.PreferredTopologyTerm{
+					Weight:     101,
+					Preference: appsv1alpha1.NodeTopologyTerm{NodeTopologyKeys: []string{corev1.LabelHostname}},
+				})
+				return pps
+			},
+			expectErrList: 2,
+		},
+		{
+			name: "invalid pps, empty topology keys",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.RequiredPersistentTopology.NodeTopologyKeys = nil
+				pps.Spec.PreferredPersistentTopology[0].Preference.NodeTopologyKeys = []string{""}
+				return pps
+			},
+			expectErrList: 2,
+		},
+		{
+			name: "invalid pps, empty and duplicated annotation keys",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.PersistentPodAnnotations = []appsv1alpha1.PersistentPodAnnotation{{Key: "ip"}, {Key: "ip"}, {Key: ""}}
+				return pps
+			},
+			expectErrList: 2,
+		},
+		{
+			name: "invalid pps, targetRef is modified",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				pps := ppsDemo.DeepCopy()
+				pps.Spec.TargetReference.Name = "sts-test1"
+				return pps
+			},
+			old: func() *appsv1alpha1.PersistentPodState {
+				return ppsDemo.DeepCopy()
+			},
+			expectErrList: 1,
+		},
+		{
+			name: "no conflict with other pps",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				return ppsDemo.DeepCopy()
+			},
+			others: func() []*appsv1alpha1.PersistentPodState {
+				pps1 := ppsDemo.DeepCopy()
+				pps1.Name = "pps1"
+				pps1.Spec.TargetReference.Name = "sts-test1"
+				return []*appsv1alpha1.PersistentPodState{pps1}
+			},
+			expectErrList: 0,
+		},
+		{
+			name: "invalid pps, conflict with other pps",
+			pps: func() *appsv1alpha1.PersistentPodState {
+				return ppsDemo.DeepCopy()
+			},
+			others: func() []*appsv1alpha1.PersistentPodState {
+				pps1 := ppsDemo.DeepCopy()
+				pps1.Name = "pps1"
+				pps1.Spec.TargetReference.APIVersion = "apps.kruise.io/v1alpha1"
+				return []*appsv1alpha1.PersistentPodState{pps1}
+			},
+			expectErrList: 1,
+		},
+	}
+
+	decoder, _ := admission.NewDecoder(scheme)
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if cs.others != nil {
+				for _, other := range cs.others() {
+					builder.WithObjects(other)
+				}
+			}
+			handler := PersistentPodStateCreateUpdateHandler{
+				Client:  builder.Build(),
+				Decoder: decoder,
+			}
+			var old *appsv1alpha1.PersistentPodState
+			if cs.old != nil {
+				old = cs.old()
+			}
+			errList := handler.validatingPersistentPodStateFn(cs.pps(), old)
+			if len(errList) != cs.expectErrList {
+				t.Fatalf("expect errList(%d) but get(%d) error: %v", cs.expectErrList, len(errList), errList.ToAggregate())
+			}
+		})
+	}
+}
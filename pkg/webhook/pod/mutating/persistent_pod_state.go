@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	ppsutil "github.com/openkruise/kruise/pkg/util/persistentpodstate"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// persistentPodStateMutatingPod injects the node affinity and annotations recorded in PersistentPodState
+// into the recreated Pod of Advanced StatefulSet.
+func (h *PodCreateHandler) persistentPodStateMutatingPod(ctx context.Context, req admission.Request, pod *corev1.Pod) error {
+	if len(req.AdmissionRequest.SubResource) > 0 ||
+		req.AdmissionRequest.Operation != admissionv1.Create ||
+		req.AdmissionRequest.Resource.Resource != "pods" {
+		return nil
+	}
+	ref := ppsutil.GetPodOwnerStatefulSet(pod)
+	if ref == nil {
+		return nil
+	}
+
+	pps, err := ppsutil.FetchPersistentPodStateByWorkload(h.Client, pod.Namespace, ref.Name)
+	if err != nil {
+		return err
+	}
+	if pps == nil {
+		return nil
+	}
+	state, ok := pps.Status.PodStates[pod.Name]
+	if !ok {
+		return nil
+	}
+	// the Pod can never be scheduled to the deleted node, which may have not been removed from the state yet
+	if _, ok := state.NodeTopologyLabels[corev1.LabelHostname]; ok && state.NodeName != "" {
+		if err := h.Client.Get(ctx, client.ObjectKey{Name: state.NodeName}, &corev1.Node{}); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			ppsutil.RelaxPodStateOfDeletedNode(&state)
+		}
+	}
+
+	injectPersistentPodState(pps, &state, pod)
+	klog.V(3).Infof("Inject PersistentPodState (%s/%s) into Pod (%s/%s): %s", pps.Namespace, pps.Name, pod.Namespace, pod.Name, util.DumpJSON(state))
+	return nil
+}
+
+func injectPersistentPodState(pps *appsv1alpha1.PersistentPodState, state *appsv1alpha1.PodState, pod *corev1.Pod) {
+	// restore the recorded annotations, unless the Pod has been specified
+	for key, value := range state.Annotations {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		if _, ok := pod.Annotations[key]; !ok {
+			pod.Annotations[key] = value
+		}
+	}
+
+	if required := pps.Spec.RequiredPersistentTopology; required != nil {
+		if requirements := getNodeSelectorRequirements(*required, state); len(requirements) > 0 {
+			if pod.Spec.Affinity == nil {
+				pod.Spec.Affinity = &corev1.Affinity{}
+			}
+			if pod.Spec.Affinity.NodeAffinity == nil {
+				pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+			}
+			nodeAffinity := pod.Spec.Affinity.NodeAffinity
+			if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+				nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+			}
+			nodeSelector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+			// the terms are ORed, so the requirements should be added into each of them
+			if len(nodeSelector.NodeSelectorTerms) == 0 {
+				nodeSelector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+			}
+			for i := range nodeSelector.NodeSelectorTerms {
+				term := &nodeSelector.NodeSelectorTerms[i]
+				term.MatchExpressions = append(term.MatchExpressions, requirements...)
+			}
+		}
+	}
+
+	for _, preferred := range pps.Spec.PreferredPersistentTopology {
+		requirements := getNodeSelectorRequirements(preferred.Preference, state)
+		if len(requirements) == 0 {
+			continue
+		}
+		if pod.Spec.Affinity == nil {
+			pod.Spec.Affinity = &corev1.Affinity{}
+		}
+		if pod.Spec.Affinity.NodeAffinity == nil {
+			pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+		}
+		nodeAffinity := pod.Spec.Affinity.NodeAffinity
+		nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			corev1.PreferredSchedulingTerm{
+				Weight:     preferred.Weight,
+				Preference: corev1.NodeSelectorTerm{MatchExpressions: requirements},
+			})
+	}
+}
+
+// getNodeSelectorRequirements returns the requirements of the topology keys that have been recorded in the state.
+func getNodeSelectorRequirements(term appsv1alpha1.NodeTopologyTerm, state *appsv1alpha1.PodState) []corev1.NodeSelectorRequirement {
+	var requirements []corev1.NodeSelectorRequirement
+	for _, key := range term.NodeTopologyKeys {
+		value, ok := state.NodeTopologyLabels[key]
+		if !ok {
+			continue
+		}
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{value},
+		})
+	}
+	return requirements
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestPersistentPodStateMutatingPod(t *testing.T) {
+	isController := true
+	pps := &appsv1alpha1.PersistentPodState{
+		ObjectMeta: metav1.ObjectMeta{Name: "pps-demo", Namespace: defaultNs},
+		Spec: appsv1alpha1.PersistentPodStateSpec{
+			TargetReference: appsv1alpha1.TargetReference{
+				APIVersion: "apps.kruise.io/v1beta1",
+				Kind:       "StatefulSet",
+				Name:       "sts-demo",
+			},
+			PersistentPodAnnotations:   []appsv1alpha1.PersistentPodAnnotation{{Key: "ip"}},
+			RequiredPersistentTopology: &appsv1alpha1.NodeTopologyTerm{NodeTopologyKeys: []string{"kubernetes.io/hostname"}},
+			PreferredPersistentTopology: []appsv1alpha1.PreferredTopologyTerm{{
+				Weight:     100,
+				Preference: appsv1alpha1.NodeTopologyTerm{NodeTopologyKeys: []string{"topology.kubernetes.io/zone"}},
+			}},
+		},
+		Status: appsv1alpha1.PersistentPodStateStatus{
+			PodStates: map[string]appsv1alpha1.PodState{
+				"sts-demo-0": {
+					NodeName:           "node-a",
+					NodeTopologyLabels: map[string]string{"kubernetes.io/hostname": "node-a", "topology.kubernetes.io/zone": "zone-a"},
+					Annotations:        map[string]string{"ip": "10.0.0.1"},
+				},
+			},
+		},
+	}
+	podDemo := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sts-demo-0",
+			Namespace: defaultNs,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps.kruise.io/v1beta1",
+				Kind:       "StatefulSet",
+				Name:       "sts-demo",
+				Controller: &isController,
+			}},
+		},
+		Spec: corev1.PodSpec{
+			Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+				}},
+			}},
+		},
+	}
+	hostnameRequirement := corev1.NodeSelectorRequirement{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-a"}}
+
+	cases := []struct {
+		name        string
+		nodeDeleted bool
+		getPod      func() *corev1.Pod
+		expectedPod func() *corev1.Pod
+	}{
+		{
+			name:   "inject recorded state into recreated pod",
+			getPod: func() *corev1.Pod { return podDemo.DeepCopy() },
+			expectedPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Annotations = map[string]string{"ip": "10.0.0.1"}
+				nodeAffinity := pod.Spec.Affinity.NodeAffinity
+				for i := range nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+					term := &nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[i]
+					term.MatchExpressions = append(term.MatchExpressions, hostnameRequirement)
+				}
+				nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []corev1.PreferredSchedulingTerm{{
+					Weight: 100,
+					Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+					}},
+				}}
+				return pod
+			},
+		},
+		{
+			name: "inject required node affinity into pod without affinity",
+			getPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Annotations = map[string]string{"ip": "10.0.0.2"}
+				pod.Spec.Affinity = nil
+				return pod
+			},
+			expectedPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Annotations = map[string]string{"ip": "10.0.0.2"}
+				pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{MatchExpressions: []corev1.NodeSelectorRequirement{hostnameRequirement}},
+					}},
+					PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{
+						Weight: 100,
+						Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+						}},
+					}},
+				}}
+				return pod
+			},
+		},
+		{
+			name:        "not require the deleted node",
+			nodeDeleted: true,
+			getPod:      func() *corev1.Pod { return podDemo.DeepCopy() },
+			expectedPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Annotations = map[string]string{"ip": "10.0.0.1"}
+				pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []corev1.PreferredSchedulingTerm{{
+					Weight: 100,
+					Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+					}},
+				}}
+				return pod
+			},
+		},
+		{
+			name: "pod without recorded state",
+			getPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Name = "sts-demo-1"
+				return pod
+			},
+			expectedPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Name = "sts-demo-1"
+				return pod
+			},
+		},
+		{
+			name: "pod not owned by Advanced StatefulSet",
+			getPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.OwnerReferences[0].APIVersion = "apps/v1"
+				return pod
+			},
+			expectedPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.OwnerReferences[0].APIVersion = "apps/v1"
+				return pod
+			},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			decoder, _ := admission.NewDecoder(scheme.Scheme)
+			builder := fake.NewClientBuilder().WithObjects(pps.DeepCopy())
+			if !cs.nodeDeleted {
+				builder = builder.WithObjects(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}})
+			}
+			client := builder.Build()
+			podHandler := &PodCreateHandler{Decoder: decoder, Client: client}
+			req := newAdmission(admissionv1.Create, runtime.RawExtension{}, runtime.RawExtension{}, "")
+
+			podOut := cs.getPod()
+			if err := podHandler.persistentPodStateMutatingPod(context.Background(), req, podOut); err != nil {
+				t.Fatalf("inject PersistentPodState into pod failed, err: %v", err)
+			}
+			if expected := cs.expectedPod(); !reflect.DeepEqual(podOut, expected) {
+				t.Fatalf("expected pod %s, got %s", util.DumpJSON(expected), util.DumpJSON(podOut))
+			}
+		})
+	}
+}
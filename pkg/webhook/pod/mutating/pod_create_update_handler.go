@@ -26,6 +26,7 @@ import (
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -41,6 +42,9 @@ type PodCreateHandler struct {
 
 	// Decoder decodes objects
 	Decoder *admission.Decoder
+
+	// Cache is used to read the workloads of WorkloadSpread, including the unstructured ones
+	Cache cache.Cache
 }
 
 var _ admission.Handler = &PodCreateHandler{}
@@ -68,6 +72,13 @@ func (h *PodCreateHandler) Handle(ctx context.Context, req admission.Request) ad
 		}
 	}
 
+	if utilfeature.DefaultFeatureGate.Enabled(features.PersistentPodState) {
+		err = h.persistentPodStateMutatingPod(ctx, req, obj)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+
 	err = h.sidecarsetMutatingPod(ctx, req, obj)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
@@ -91,6 +102,14 @@ func (h *PodCreateHandler) InjectClient(c client.Client) error {
 	return nil
 }
 
+var _ inject.Cache = &PodCreateHandler{}
+
+// InjectCache injects the cache into the PodCreateHandler
+func (h *PodCreateHandler) InjectCache(c cache.Cache) error {
+	h.Cache = c
+	return nil
+}
+
 var _ admission.DecoderInjector = &PodCreateHandler{}
 
 // InjectDecoder injects the decoder into the PodCreateHandler
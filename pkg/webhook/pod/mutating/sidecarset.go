@@ -25,7 +25,9 @@ import (
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -74,14 +76,24 @@ func (h *PodCreateHandler) sidecarsetMutatingPod(ctx context.Context, req admiss
 		if sidecarSet.Spec.InjectionStrategy.Paused {
 			continue
 		}
-		if matched, err := sidecarcontrol.PodMatchedSidecarSet(pod, sidecarSet); err != nil {
+		if matched, err := sidecarcontrol.PodMatchedSidecarSet(h.Client, pod, sidecarSet); err != nil {
 			return err
 		} else if !matched {
 			continue
 		}
+		// if the injection revision is specified, the sidecar containers injected into the newly created pod
+		// will be restored from the revision
+		injectedSidecarSet := sidecarSet.DeepCopy()
+		if revisionName := sidecarSet.Spec.InjectionStrategy.RevisionName; !isUpdated && revisionName != nil && *revisionName != "" {
+			historySidecarSet, err := sidecarcontrol.NewHistoryControl(h.Client).GetHistorySidecarSet(injectedSidecarSet, *revisionName)
+			if err != nil {
+				return fmt.Errorf("failed to get revision %s of sidecarSet %s: %v", *revisionName, sidecarSet.Name, err)
+			}
+			injectedSidecarSet = historySidecarSet
+		}
 		// check whether sidecarSet is active
 		// when sidecarSet is not active, it will not perform injections and upgrades process.
-		control := sidecarcontrol.New(sidecarSet.DeepCopy())
+		control := sidecarcontrol.New(injectedSidecarSet)
 		if !control.IsActiveSidecarSet() {
 			continue
 		}
@@ -124,6 +136,9 @@ func (h *PodCreateHandler) sidecarsetMutatingPod(ctx context.Context, req admiss
 	}
 	// 2. inject containers
 	pod.Spec.Containers = mergeSidecarContainers(pod.Spec.Containers, sidecarContainers)
+	if !isUpdated && utilfeature.DefaultFeatureGate.Enabled(features.SidecarSetLifecycle) {
+		sidecarcontrol.InjectSidecarBarrier(pod, req.UID)
+	}
 	// 3. inject volumes
 	pod.Spec.Volumes = util.MergeVolumes(pod.Spec.Volumes, volumesInSidecar)
 	// 4. inject imagePullSecrets
@@ -305,6 +320,15 @@ func buildSidecars(isUpdated bool, pod *corev1.Pod, oldPod *corev1.Pod, matchedS
 			sidecarContainer.VolumeMounts = util.MergeVolumeMounts(sidecarContainer.VolumeMounts, injectedMounts)
 			// add the "Injected" env to the sidecar container
 			sidecarContainer.Env = append(sidecarContainer.Env, corev1.EnvVar{Name: sidecarcontrol.SidecarEnvKey, Value: "true"})
+			// mark the sidecar container for kruise-daemon to control its start and exit
+			if utilfeature.DefaultFeatureGate.Enabled(features.SidecarSetLifecycle) {
+				if sidecarContainer.StartBeforeAppContainers {
+					sidecarContainer.Env = append(sidecarContainer.Env, corev1.EnvVar{Name: sidecarcontrol.SidecarStartBeforeAppEnvKey, Value: "true"})
+				}
+				if sidecarContainer.TerminateWhenJobExit {
+					sidecarContainer.Env = append(sidecarContainer.Env, corev1.EnvVar{Name: sidecarcontrol.SidecarTerminateWhenJobExitEnvKey, Value: "true"})
+				}
+			}
 			// merged Env from sidecar.Env and transfer envs
 			sidecarContainer.Env = util.MergeEnvVar(sidecarContainer.Env, transferEnvs)
 
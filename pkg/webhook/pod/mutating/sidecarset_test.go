@@ -19,6 +19,7 @@ package mutating
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -26,7 +27,9 @@ import (
 	"github.com/openkruise/kruise/apis"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -382,6 +385,161 @@ func testPodHasNoMatchedSidecarSet(t *testing.T, sidecarSetIn *appsv1alpha1.Side
 	}
 }
 
+func TestSidecarSetNamespaceSelector(t *testing.T) {
+	sidecarSetIn := sidecarSet1.DeepCopy()
+	sidecarSetIn.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"sidecar-injection": "enabled"},
+	}
+	testSidecarSetNamespaceSelector(t, sidecarSetIn)
+}
+
+func testSidecarSetNamespaceSelector(t *testing.T, sidecarSetIn *appsv1alpha1.SidecarSet) {
+	cases := []struct {
+		name                 string
+		namespaceLabels      map[string]string
+		expectContainerCount int
+	}{
+		{
+			name:                 "namespace matched",
+			namespaceLabels:      map[string]string{"sidecar-injection": "enabled"},
+			expectContainerCount: len(pod1.Spec.Containers) + len(sidecarSetIn.Spec.Containers),
+		},
+		{
+			name:                 "namespace not matched",
+			namespaceLabels:      map[string]string{"sidecar-injection": "disabled"},
+			expectContainerCount: len(pod1.Spec.Containers),
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: defaultNs, Labels: cs.namespaceLabels},
+			}
+			podOut := pod1.DeepCopy()
+			decoder, _ := admission.NewDecoder(scheme.Scheme)
+			client := fake.NewClientBuilder().WithObjects(sidecarSetIn, ns).Build()
+			podHandler := &PodCreateHandler{Decoder: decoder, Client: client}
+			req := newAdmission(admissionv1.Create, runtime.RawExtension{}, runtime.RawExtension{}, "")
+			_ = podHandler.sidecarsetMutatingPod(context.Background(), req, podOut)
+
+			if len(podOut.Spec.Containers) != cs.expectContainerCount {
+				t.Fatalf("expect %v containers but got %v", cs.expectContainerCount, len(podOut.Spec.Containers))
+			}
+		})
+	}
+}
+
+func TestSidecarSetInjectRevision(t *testing.T) {
+	sidecarSetIn := sidecarSet1.DeepCopy()
+	testSidecarSetInjectRevision(t, sidecarSetIn)
+}
+
+func testSidecarSetInjectRevision(t *testing.T, sidecarSetIn *appsv1alpha1.SidecarSet) {
+	decoder, _ := admission.NewDecoder(scheme.Scheme)
+	client := fake.NewClientBuilder().Build()
+	historyControl := sidecarcontrol.NewHistoryControl(client)
+	// record the revision of current sidecarSet
+	collisionCount := int32(0)
+	revision, err := historyControl.NewRevision(sidecarSetIn, 1, &collisionCount)
+	if err != nil {
+		t.Fatalf("new revision failed: %v", err)
+	}
+	if revision, err = historyControl.CreateControllerRevision(sidecarSetIn, revision, &collisionCount); err != nil {
+		t.Fatalf("create revision failed: %v", err)
+	}
+
+	// update the image of sidecar container, and inject the old revision
+	sidecarSetIn.Annotations[sidecarcontrol.SidecarSetHashAnnotation] = "new-hash"
+	sidecarSetIn.Spec.Containers[0].Image = "dns-f-image:2.0"
+	sidecarSetIn.Spec.InjectionStrategy.RevisionName = &revision.Name
+	if err = client.Create(context.TODO(), sidecarSetIn); err != nil {
+		t.Fatalf("create sidecarSet failed: %v", err)
+	}
+
+	podOut := pod1.DeepCopy()
+	podHandler := &PodCreateHandler{Decoder: decoder, Client: client}
+	req := newAdmission(admissionv1.Create, runtime.RawExtension{}, runtime.RawExtension{}, "")
+	if err = podHandler.sidecarsetMutatingPod(context.Background(), req, podOut); err != nil {
+		t.Fatalf("inject sidecar into pod failed: %v", err)
+	}
+
+	if podOut.Spec.Containers[0].Image != "dns-f-image:1.0" {
+		t.Fatalf("expect injected image dns-f-image:1.0, but got %s", podOut.Spec.Containers[0].Image)
+	}
+	if hash := sidecarcontrol.GetPodSidecarSetRevision(sidecarSetIn.Name, podOut); hash != "c4k2dbb95d" {
+		t.Fatalf("expect injected sidecarSet hash c4k2dbb95d, but got %s", hash)
+	}
+}
+
+func TestSidecarSetLifecycle(t *testing.T) {
+	sidecarSetIn := sidecarSet1.DeepCopy()
+	testSidecarSetLifecycle(t, sidecarSetIn)
+}
+
+func testSidecarSetLifecycle(t *testing.T, sidecarSetIn *appsv1alpha1.SidecarSet) {
+	_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.SidecarSetLifecycle))
+	defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.SidecarSetLifecycle))
+
+	sidecarSetIn.Spec.Containers[0].StartBeforeAppContainers = true
+	sidecarSetIn.Spec.Containers[0].TerminateWhenJobExit = true
+	decoder, _ := admission.NewDecoder(scheme.Scheme)
+	client := fake.NewClientBuilder().WithObjects(sidecarSetIn).Build()
+	podHandler := &PodCreateHandler{Decoder: decoder, Client: client}
+	req := newAdmission(admissionv1.Create, runtime.RawExtension{}, runtime.RawExtension{}, "")
+	req.UID = "e4b5b9a4-0f0e-4a55-9b7e-0e9b1c6c0d6a"
+
+	podOut := pod1.DeepCopy()
+	podOut.Name = ""
+	podOut.GenerateName = "test-pod-"
+	if err := podHandler.sidecarsetMutatingPod(context.Background(), req, podOut); err != nil {
+		t.Fatalf("inject sidecar into pod failed: %v", err)
+	}
+	if podOut.Name != "" {
+		t.Fatalf("expect pod name left to apiserver, but got %s", podOut.Name)
+	}
+	if name := sidecarcontrol.GetSidecarBarrierConfigMapName(podOut); name != "kruise-sidecar-barrier-"+string(req.UID) {
+		t.Fatalf("expect barrier ConfigMap derived from request uid, but got %s", name)
+	}
+
+	// the barrier ConfigMap is derived from the pod name if it has one
+	namedPod := pod1.DeepCopy()
+	namedPod.Annotations = map[string]string{sidecarcontrol.SidecarBarrierAnnotation: "copied-from-another-pod"}
+	if err := podHandler.sidecarsetMutatingPod(context.Background(), req, namedPod); err != nil {
+		t.Fatalf("inject sidecar into pod failed: %v", err)
+	}
+	if name := sidecarcontrol.GetSidecarBarrierConfigMapName(namedPod); name != "kruise-sidecar-barrier-"+pod1.Name {
+		t.Fatalf("expect barrier ConfigMap derived from pod name, but got %s", name)
+	}
+
+	sidecar := util.GetContainer("dns-f", podOut)
+	if !sidecarcontrol.IsSidecarStartBeforeApp(sidecar) || !sidecarcontrol.IsSidecarTerminateWhenJobExit(sidecar) {
+		t.Fatalf("expect lifecycle env in sidecar container, but got %v", sidecar.Env)
+	}
+	if util.GetContainerEnvVar(sidecar, sidecarcontrol.SidecarBarrierEnvKey) != nil {
+		t.Fatalf("expect no barrier env in sidecar container")
+	}
+	barrier := util.GetContainerEnvVar(util.GetContainer("nginx", podOut), sidecarcontrol.SidecarBarrierEnvKey)
+	if barrier == nil || barrier.ValueFrom == nil || barrier.ValueFrom.ConfigMapKeyRef == nil {
+		t.Fatalf("expect barrier env in app container, but got %v", barrier)
+	}
+	if name := barrier.ValueFrom.ConfigMapKeyRef.Name; name != sidecarcontrol.GetSidecarBarrierConfigMapName(podOut) {
+		t.Fatalf("expect barrier ConfigMap %s, but got %s", sidecarcontrol.GetSidecarBarrierConfigMapName(podOut), name)
+	}
+
+	// the app containers should not wait for the sidecar that does not start before them
+	sidecarSetIn.Spec.Containers[0].StartBeforeAppContainers = false
+	client = fake.NewClientBuilder().WithObjects(sidecarSetIn).Build()
+	podHandler = &PodCreateHandler{Decoder: decoder, Client: client}
+	podOut = pod1.DeepCopy()
+	if err := podHandler.sidecarsetMutatingPod(context.Background(), req, podOut); err != nil {
+		t.Fatalf("inject sidecar into pod failed: %v", err)
+	}
+	if sidecarcontrol.IsSidecarBarrierInjected(podOut) {
+		t.Fatalf("expect no barrier env in pod")
+	}
+}
+
 func TestMergeSidecarSecrets(t *testing.T) {
 	sidecarSetIn := sidecarSet1.DeepCopy()
 	testMergeSidecarSecrets(t, sidecarSetIn)
@@ -19,6 +19,7 @@ package mutating
 import (
 	"context"
 
+	"github.com/openkruise/kruise/pkg/util"
 	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -35,7 +36,8 @@ func (h *PodCreateHandler) workloadSpreadMutatingPod(ctx context.Context, req ad
 		return nil
 	}
 
-	workloadSpreadHandler := wsutil.NewWorkloadSpreadHandler(h.Client)
+	// read the workloads from cache, so that no request is sent to apiserver for each Pod creation
+	workloadSpreadHandler := wsutil.NewWorkloadSpreadHandler(util.NewUnstructuredCachedClient(h.Client, h.Cache))
 	var dryRun bool
 
 	switch req.AdmissionRequest.Operation {
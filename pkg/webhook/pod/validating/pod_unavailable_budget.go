@@ -46,6 +46,7 @@ var (
 func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context, req admission.Request) (bool, string, error) {
 	var newPod, oldPod *corev1.Pod
 	var dryRun bool
+	var operation pubcontrol.Operation
 	// ignore kube-system, kube-public
 	for _, namespace := range IgnoredNamespaces {
 		if req.Namespace == namespace {
@@ -77,6 +78,7 @@ func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context
 		}
 		// if dry run
 		dryRun = dryrun.IsDryRun(options.DryRun)
+		operation = pubcontrol.UpdateOperation
 
 	// filter out invalid Delete operation, only validate delete pods resources
 	case admissionv1.Delete:
@@ -94,6 +96,7 @@ func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context
 		}
 		// if dry run
 		dryRun = dryrun.IsDryRun(deletion.DryRun)
+		operation = pubcontrol.DeleteOperation
 
 		// Get the workload corresponding to the pod, if it has been deleted then it is not protected
 		if ref := metav1.GetControllerOf(newPod); ref != nil {
@@ -126,6 +129,7 @@ func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context
 		if eviction.DeleteOptions != nil {
 			dryRun = dryrun.IsDryRun(eviction.DeleteOptions.DryRun)
 		}
+		operation = pubcontrol.EvictOperation
 		key := types.NamespacedName{
 			Namespace: req.AdmissionRequest.Namespace,
 			Name:      req.AdmissionRequest.Name,
@@ -151,13 +155,13 @@ func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context
 		return true, "", nil
 	}
 	control := pubcontrol.NewPubControl(pub)
-	klog.V(3).Infof("validating pod(%s.%s) operation(%s) for pub(%s.%s)", newPod.Namespace, newPod.Name, req.Operation, pub.Namespace, pub.Name)
+	klog.V(3).Infof("validating pod(%s.%s) operation(%s) for pub(%s.%s)", newPod.Namespace, newPod.Name, operation, pub.Namespace, pub.Name)
 
 	// the change will not cause pod unavailability, then pass
-	if req.Operation == admissionv1.Update && !control.IsPodUnavailableChanged(oldPod, newPod) {
+	if operation == pubcontrol.UpdateOperation && !control.IsPodUnavailableChanged(oldPod, newPod) {
 		klog.V(3).Infof("validate pod(%s.%s) changed cannot cause unavailability, then don't need check pub", newPod.Namespace, newPod.Name)
 		return true, "", nil
 	}
 
-	return pubcontrol.PodUnavailableBudgetValidatePod(p.Client, newPod, control, pubcontrol.Operation(req.Operation), dryRun)
+	return pubcontrol.PodUnavailableBudgetValidatePod(p.Client, newPod, control, operation, dryRun)
 }
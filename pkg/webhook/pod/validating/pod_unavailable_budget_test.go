@@ -36,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/pkg/apis/policy"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -680,6 +681,27 @@ func TestValidateDeletePodForPub(t *testing.T) {
 				return pubStatus
 			},
 		},
+		{
+			name: "delete pod owned by other kinds, allow",
+			newPod: func() *corev1.Pod {
+				podIn := podDemo.DeepCopy()
+				podIn.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "ds", UID: "ds-uid", Controller: utilpointer.BoolPtr(true)}}
+				return podIn
+			},
+			deletion: func() *metav1.DeleteOptions {
+				return &metav1.DeleteOptions{}
+			},
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				return pub
+			},
+			subresource: "",
+			expectAllow: true,
+			expectPubStatus: func() *policyv1alpha1.PodUnavailableBudgetStatus {
+				pubStatus := pubDemo.Status.DeepCopy()
+				return pubStatus
+			},
+		},
 		{
 			name: "delete pod, dry run",
 			newPod: func() *corev1.Pod {
@@ -24,10 +24,14 @@ import (
 	"regexp"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	"github.com/openkruise/kruise/pkg/util"
+	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	genericvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
@@ -77,6 +81,8 @@ func (h *SidecarSetCreateUpdateHandler) validateSidecarSet(obj *appsv1alpha1.Sid
 	allErrs := genericvalidation.ValidateObjectMeta(&obj.ObjectMeta, false, validateSidecarSetName, field.NewPath("metadata"))
 	// validating spec
 	allErrs = append(allErrs, validateSidecarSetSpec(obj, field.NewPath("spec"))...)
+	// validating the revisions referenced by sidecarSet
+	allErrs = append(allErrs, h.validateSidecarSetRevisions(obj, field.NewPath("spec"))...)
 	// when operation is update, older isn't empty, and validating whether old and new containers conflict
 	if older != nil {
 		allErrs = append(allErrs, validateSidecarContainerConflict(obj.Spec.Containers, older.Spec.Containers, field.NewPath("spec.containers"))...)
@@ -90,6 +96,41 @@ func (h *SidecarSetCreateUpdateHandler) validateSidecarSet(obj *appsv1alpha1.Sid
 	return allErrs
 }
 
+func (h *SidecarSetCreateUpdateHandler) validateSidecarSetRevisions(obj *appsv1alpha1.SidecarSet, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	revisions := []struct {
+		path         *field.Path
+		revisionName *string
+	}{
+		{fldPath.Child("injectionStrategy", "revisionName"), obj.Spec.InjectionStrategy.RevisionName},
+		{fldPath.Child("updateStrategy", "revisionName"), obj.Spec.UpdateStrategy.RevisionName},
+	}
+	for _, r := range revisions {
+		revisionPath, revisionName := r.path, r.revisionName
+		if revisionName == nil {
+			continue
+		}
+		if *revisionName == "" {
+			allErrs = append(allErrs, field.Invalid(revisionPath, *revisionName, "revisionName can not be empty"))
+			continue
+		}
+		revision := &apps.ControllerRevision{}
+		err := h.Client.Get(context.TODO(), client.ObjectKey{Namespace: webhookutil.GetNamespace(), Name: *revisionName}, revision)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				allErrs = append(allErrs, field.NotFound(revisionPath, *revisionName))
+			} else {
+				allErrs = append(allErrs, field.InternalError(revisionPath, fmt.Errorf("query revision %s failed, err: %v", *revisionName, err)))
+			}
+			continue
+		}
+		if revision.Labels[sidecarcontrol.SidecarSetKindName] != obj.Name {
+			allErrs = append(allErrs, field.Invalid(revisionPath, *revisionName, fmt.Sprintf("revision does not belong to sidecarset %s", obj.Name)))
+		}
+	}
+	return allErrs
+}
+
 func validateSidecarSetName(name string, prefix bool) (allErrs []string) {
 	if !validateSidecarSetNameRegex.MatchString(name) {
 		allErrs = append(allErrs, validationutil.RegexError(validateSidecarSetNameMsg, validSidecarSetNameFmt, "example-com"))
@@ -110,6 +151,14 @@ func validateSidecarSetSpec(obj *appsv1alpha1.SidecarSet, fldPath *field.Path) f
 	} else {
 		allErrs = append(allErrs, validateSelector(spec.Selector, fldPath.Child("selector"))...)
 	}
+	//validate spec namespaceSelector
+	if spec.NamespaceSelector != nil {
+		if spec.Namespace != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespaceSelector"), "namespace and namespaceSelector are mutually exclusive"))
+		} else {
+			allErrs = append(allErrs, validateSelector(spec.NamespaceSelector, fldPath.Child("namespaceSelector"))...)
+		}
+	}
 	//validating SidecarSetUpdateStrategy
 	allErrs = append(allErrs, validateSidecarSetUpdateStrategy(&spec.UpdateStrategy, fldPath.Child("strategy"))...)
 	//validating volumes
@@ -152,6 +201,9 @@ func validateSidecarSetUpdateStrategy(strategy *appsv1alpha1.SidecarSetUpdateStr
 		if strategy.MaxUnavailable != nil {
 			allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*(strategy.MaxUnavailable), fldPath.Child("maxUnavailable"))...)
 		}
+		if err := strategy.PriorityStrategy.FieldsValidation(); err != nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("priorityStrategy"), err.Error()))
+		}
 		if strategy.ScatterStrategy != nil {
 			if err := strategy.ScatterStrategy.FieldsValidation(); err != nil {
 				allErrs = append(allErrs, field.Required(fldPath.Child("scatterStrategy"), err.Error()))
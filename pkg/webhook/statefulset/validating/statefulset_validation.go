@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 
 	"github.com/appscode/jsonpatch"
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
@@ -15,12 +17,15 @@ import (
 	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
 	apivalidation "k8s.io/kubernetes/pkg/apis/core/validation"
 
+	"github.com/openkruise/kruise/pkg/features"
+	utilapi "github.com/openkruise/kruise/pkg/util/api"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/webhook/util/convertor"
+	lifecyclevalidation "github.com/openkruise/kruise/pkg/webhook/util/lifecycle"
 )
 
 var inPlaceUpdateTemplateSpecPatchRexp = regexp.MustCompile("/containers/([0-9]+)/image")
@@ -38,15 +43,11 @@ func validateStatefulSetSpec(spec *appsv1beta1.StatefulSetSpec, fldPath *field.P
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("podManagementPolicy"), spec.PodManagementPolicy, fmt.Sprintf("must be '%s' or '%s'", apps.OrderedReadyPodManagement, apps.ParallelPodManagement)))
 	}
 
-	if spec.ReserveOrdinals != nil {
-		orders := sets.NewInt(spec.ReserveOrdinals...)
-		if orders.Len() != len(spec.ReserveOrdinals) {
-			allErrs = append(allErrs, field.Invalid(fldPath.Root(), spec.ReserveOrdinals, "reserveOrdinals contains duplicated items"))
-		}
-		for _, i := range spec.ReserveOrdinals {
-			if i < 0 {
-				allErrs = append(allErrs, field.Invalid(fldPath.Root(), spec.ReserveOrdinals, fmt.Sprintf("reserveOrdinals contains %d which must be order >= 0", i)))
-			}
+	if spec.Ordinals != nil {
+		if !utilfeature.DefaultFeatureGate.Enabled(features.StatefulSetStartOrdinal) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ordinals"), fmt.Sprintf("ordinals requires feature-gate %s enabled", features.StatefulSetStartOrdinal)))
+		} else if spec.Ordinals.Start < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ordinals", "start"), spec.Ordinals.Start, "must be greater than or equal to 0"))
 		}
 	}
 
@@ -56,6 +57,18 @@ func validateStatefulSetSpec(spec *appsv1beta1.StatefulSetSpec, fldPath *field.P
 		}
 	}
 
+	allErrs = append(allErrs, validatePersistentVolumeClaimRetentionPolicy(spec.PersistentVolumeClaimRetentionPolicy, fldPath.Child("persistentVolumeClaimRetentionPolicy"))...)
+	allErrs = append(allErrs, lifecyclevalidation.ValidateLifecycle(spec.Lifecycle, &spec.Template, fldPath.Child("lifecycle"))...)
+
+	if spec.VolumeClaimUpdateStrategy != nil {
+		switch spec.VolumeClaimUpdateStrategy.Type {
+		case "", appsv1beta1.OnDeleteVolumeClaimUpdateStrategyType, appsv1beta1.RecreateVolumeClaimUpdateStrategyType:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("volumeClaimUpdateStrategy", "type"), spec.VolumeClaimUpdateStrategy.Type,
+				[]string{string(appsv1beta1.OnDeleteVolumeClaimUpdateStrategyType), string(appsv1beta1.RecreateVolumeClaimUpdateStrategyType)}))
+		}
+	}
+
 	switch spec.UpdateStrategy.Type {
 	case "":
 		allErrs = append(allErrs, field.Required(fldPath.Child("updateStrategy"), ""))
@@ -197,16 +210,112 @@ func validateMaxUnavailableField(maxUnavailable *intstr.IntOrString, spec *appsv
 	return allErrs
 }
 
+func validatePersistentVolumeClaimRetentionPolicyType(policy appsv1beta1.PersistentVolumeClaimRetentionPolicyType, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch policy {
+	case "", appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType, appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath, policy, []string{
+			string(appsv1beta1.RetainPersistentVolumeClaimRetentionPolicyType),
+			string(appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType),
+		}))
+	}
+	return allErrs
+}
+
+// validateReserveOrdinals validates the reserved ordinals and ranges without expanding them, and the count of
+// reserved ordinals can not be more than maxCount, so that the controller will not handle too wide ranges.
+func validateReserveOrdinals(reserveOrdinals []intstr.IntOrString, maxCount int, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	var ranges [][2]int
+	var count int
+	for i, item := range reserveOrdinals {
+		start, end := item.IntValue(), item.IntValue()
+		if item.Type == intstr.String {
+			if _, err := strconv.Atoi(item.StrVal); err != nil {
+				if start, end, err = utilapi.ParseRange(item.StrVal); err != nil {
+					allErrs = append(allErrs, field.Invalid(fldPath.Index(i), item.StrVal, "must be an ordinal or a range in the format of start-end"))
+					continue
+				}
+			}
+		}
+		if start < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), item.String(), "must be order >= 0"))
+			continue
+		}
+		if end-start >= maxCount-count {
+			allErrs = append(allErrs, field.Invalid(fldPath, reserveOrdinals, fmt.Sprintf(
+				"reserveOrdinals can not contain more than %d ordinals, which is replicas plus current replicas", maxCount)))
+			return allErrs
+		}
+		count += end - start + 1
+		ranges = append(ranges, [2]int{start, end})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i][0] <= ranges[i-1][1] {
+			allErrs = append(allErrs, field.Invalid(fldPath, reserveOrdinals, fmt.Sprintf("reserveOrdinals contains duplicated ordinal %d", ranges[i][0])))
+			return allErrs
+		}
+	}
+	return allErrs
+}
+
+func validatePersistentVolumeClaimRetentionPolicy(policy *appsv1beta1.StatefulSetPersistentVolumeClaimRetentionPolicy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if policy != nil {
+		allErrs = append(allErrs, validatePersistentVolumeClaimRetentionPolicyType(policy.WhenDeleted, fldPath.Child("whenDeleted"))...)
+		allErrs = append(allErrs, validatePersistentVolumeClaimRetentionPolicyType(policy.WhenScaled, fldPath.Child("whenScaled"))...)
+	}
+	return allErrs
+}
+
+// validateVolumeClaimTemplatesUpdate only allows to update the spec of existing templates,
+// for the Pods can not change their volumes.
+// The templates can be changed only if the claims will be recreated in rolling update, which means
+// Recreate volumeClaimUpdateStrategy with RollingUpdate updateStrategy, or the change never takes effect
+// on the existing claims.
+func validateVolumeClaimTemplatesUpdate(spec *appsv1beta1.StatefulSetSpec, oldTemplates []v1.PersistentVolumeClaim, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	templates := spec.VolumeClaimTemplates
+	if len(templates) != len(oldTemplates) {
+		return append(allErrs, field.Forbidden(fldPath, "volumeClaimTemplates can not be added or removed"))
+	}
+	recreateEnabled := spec.VolumeClaimUpdateStrategy != nil &&
+		spec.VolumeClaimUpdateStrategy.Type == appsv1beta1.RecreateVolumeClaimUpdateStrategyType &&
+		spec.UpdateStrategy.Type != apps.OnDeleteStatefulSetStrategyType
+	for i := range templates {
+		if templates[i].Name != oldTemplates[i].Name {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("metadata", "name"), "name of volumeClaimTemplates can not be changed"))
+		} else if !recreateEnabled && !apiequality.Semantic.DeepEqual(templates[i].Spec, oldTemplates[i].Spec) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("spec"),
+				"spec of volumeClaimTemplates can be changed only with Recreate volumeClaimUpdateStrategy and RollingUpdate updateStrategy"))
+		}
+	}
+	return allErrs
+}
+
 // ValidateStatefulSet validates a StatefulSet.
 func validateStatefulSet(statefulSet *appsv1beta1.StatefulSet) field.ErrorList {
 	allErrs := apivalidation.ValidateObjectMeta(&statefulSet.ObjectMeta, true, appsvalidation.ValidateStatefulSetName, field.NewPath("metadata"))
 	allErrs = append(allErrs, validateStatefulSetSpec(&statefulSet.Spec, field.NewPath("spec"))...)
+	if statefulSet.Spec.ReserveOrdinals != nil {
+		// only the existing and expected replicas can be reserved
+		maxCount := int(statefulSet.Status.Replicas)
+		if statefulSet.Spec.Replicas != nil {
+			maxCount += int(*statefulSet.Spec.Replicas)
+		}
+		allErrs = append(allErrs, validateReserveOrdinals(statefulSet.Spec.ReserveOrdinals, maxCount, field.NewPath("spec", "reserveOrdinals"))...)
+	}
 	return allErrs
 }
 
 // ValidateStatefulSetUpdate tests if required fields in the StatefulSet are set.
 func ValidateStatefulSetUpdate(statefulSet, oldStatefulSet *appsv1beta1.StatefulSet) field.ErrorList {
 	allErrs := apivalidation.ValidateObjectMetaUpdate(&statefulSet.ObjectMeta, &oldStatefulSet.ObjectMeta, field.NewPath("metadata"))
+	// validate the volumeClaimTemplates with the new strategies, before the spec fields are restored to the old ones
+	allErrs = append(allErrs, validateVolumeClaimTemplatesUpdate(&statefulSet.Spec, oldStatefulSet.Spec.VolumeClaimTemplates, field.NewPath("spec", "volumeClaimTemplates"))...)
 
 	restoreReplicas := statefulSet.Spec.Replicas
 	statefulSet.Spec.Replicas = oldStatefulSet.Spec.Replicas
@@ -222,16 +331,31 @@ func ValidateStatefulSetUpdate(statefulSet, oldStatefulSet *appsv1beta1.Stateful
 
 	restoreReserveOrdinals := statefulSet.Spec.ReserveOrdinals
 	statefulSet.Spec.ReserveOrdinals = oldStatefulSet.Spec.ReserveOrdinals
+	restoreOrdinals := statefulSet.Spec.Ordinals
+	statefulSet.Spec.Ordinals = oldStatefulSet.Spec.Ordinals
 	statefulSet.Spec.Lifecycle = oldStatefulSet.Spec.Lifecycle
 
+	restorePersistentVolumeClaimRetentionPolicy := statefulSet.Spec.PersistentVolumeClaimRetentionPolicy
+	statefulSet.Spec.PersistentVolumeClaimRetentionPolicy = oldStatefulSet.Spec.PersistentVolumeClaimRetentionPolicy
+
+	restoreVolumeClaimUpdateStrategy := statefulSet.Spec.VolumeClaimUpdateStrategy
+	statefulSet.Spec.VolumeClaimUpdateStrategy = oldStatefulSet.Spec.VolumeClaimUpdateStrategy
+
+	restoreVolumeClaimTemplates := statefulSet.Spec.VolumeClaimTemplates
+	statefulSet.Spec.VolumeClaimTemplates = oldStatefulSet.Spec.VolumeClaimTemplates
+
 	if !apiequality.Semantic.DeepEqual(statefulSet.Spec, oldStatefulSet.Spec) {
-		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas', 'template', 'reserveOrdinals', 'lifecycle' and 'updateStrategy' are forbidden"))
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas', 'template', 'reserveOrdinals', 'ordinals', 'lifecycle', 'updateStrategy', 'persistentVolumeClaimRetentionPolicy', 'volumeClaimUpdateStrategy' and 'volumeClaimTemplates' are forbidden"))
 	}
 	statefulSet.Spec.Replicas = restoreReplicas
 	statefulSet.Spec.Template = restoreTemplate
 	statefulSet.Spec.UpdateStrategy = restoreStrategy
 	statefulSet.Spec.ScaleStrategy = restoreScaleStrategy
 	statefulSet.Spec.ReserveOrdinals = restoreReserveOrdinals
+	statefulSet.Spec.Ordinals = restoreOrdinals
+	statefulSet.Spec.PersistentVolumeClaimRetentionPolicy = restorePersistentVolumeClaimRetentionPolicy
+	statefulSet.Spec.VolumeClaimUpdateStrategy = restoreVolumeClaimUpdateStrategy
+	statefulSet.Spec.VolumeClaimTemplates = restoreVolumeClaimTemplates
 
 	allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*statefulSet.Spec.Replicas), field.NewPath("spec", "replicas"))...)
 	return allErrs
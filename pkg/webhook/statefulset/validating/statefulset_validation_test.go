@@ -17,19 +17,26 @@ limitations under the License.
 package validating
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"testing"
 
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilpointer "k8s.io/utils/pointer"
 )
 
 func TestValidateStatefulSet(t *testing.T) {
+	_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.StatefulSetStartOrdinal))
+	defer utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.StatefulSetStartOrdinal))
 	validLabels := map[string]string{"a": "b"}
 	validPodTemplate := v1.PodTemplate{
 		Template: v1.PodTemplateSpec{
@@ -133,6 +140,19 @@ func TestValidateStatefulSet(t *testing.T) {
 					}()},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				ReserveOrdinals:     []intstr.IntOrString{intstr.FromInt(1), intstr.FromString("3-5"), intstr.FromString("7")},
+				Ordinals:            &appsv1beta1.StatefulSetOrdinals{Start: 1},
+			},
+			Status: appsv1beta1.StatefulSetStatus{Replicas: 3},
+		},
 	}
 
 	for i, successCase := range successCases {
@@ -438,6 +458,76 @@ func TestValidateStatefulSet(t *testing.T) {
 				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
 			},
 		},
+		"invalid persistent volume claim retention policy": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				PersistentVolumeClaimRetentionPolicy: &appsv1beta1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+					WhenDeleted: "foo",
+					WhenScaled:  appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType,
+				},
+			},
+		},
+		"invalid volume claim update strategy": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy:       apps.OrderedReadyPodManagement,
+				Selector:                  &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:                  validPodTemplate.Template,
+				Replicas:                  &val3,
+				UpdateStrategy:            appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				VolumeClaimUpdateStrategy: &appsv1beta1.VolumeClaimUpdateStrategy{Type: "foo"},
+			},
+		},
+		"invalid reserve ordinals range": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				ReserveOrdinals:     []intstr.IntOrString{intstr.FromString("5-3")},
+			},
+		},
+		"duplicated reserve ordinals": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				ReserveOrdinals:     []intstr.IntOrString{intstr.FromInt(4), intstr.FromString("3-5")},
+			},
+		},
+		"too wide reserve ordinals range": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				ReserveOrdinals:     []intstr.IntOrString{intstr.FromInt(1), intstr.FromString("10-9223372036854775807")},
+			},
+			Status: appsv1beta1.StatefulSetStatus{Replicas: 3},
+		},
+		"negative start ordinal": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				Ordinals:            &appsv1beta1.StatefulSetOrdinals{Start: -1},
+			},
+		},
 	}
 
 	for k, v := range errorCases {
@@ -469,6 +559,12 @@ func TestValidateStatefulSet(t *testing.T) {
 					field != "spec.updateStrategy.rollingUpdate.podUpdatePolicy" &&
 					field != "spec.template.spec.readinessGates" &&
 					field != "spec.podManagementPolicy" &&
+					field != "spec.persistentVolumeClaimRetentionPolicy.whenDeleted" &&
+					field != "spec.volumeClaimUpdateStrategy.type" &&
+					field != "spec.reserveOrdinals" &&
+					field != "spec.reserveOrdinals[0]" &&
+					field != "spec.ordinals.start" &&
+					field != "spec.ordinals" &&
 					field != "spec.template.spec.activeDeadlineSeconds" {
 					t.Errorf("%s: missing prefix for: %v", k, errs[i])
 				}
@@ -477,6 +573,97 @@ func TestValidateStatefulSet(t *testing.T) {
 	}
 }
 
+func TestValidateStatefulSetUpdateVolumeClaimTemplates(t *testing.T) {
+	newClaim := func(name, size string) v1.PersistentVolumeClaim {
+		return v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.PersistentVolumeClaimSpec{
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(size)}},
+			},
+		}
+	}
+	oldSet := &appsv1beta1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault, ResourceVersion: "1"},
+		Spec: appsv1beta1.StatefulSetSpec{
+			Replicas:             utilpointer.Int32Ptr(3),
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{newClaim("data", "10Gi")},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		update    func(set *appsv1beta1.StatefulSet)
+		expectErr bool
+	}{
+		{
+			name: "update size of template",
+			update: func(set *appsv1beta1.StatefulSet) {
+				set.Spec.VolumeClaimTemplates[0] = newClaim("data", "20Gi")
+				set.Spec.VolumeClaimUpdateStrategy = &appsv1beta1.VolumeClaimUpdateStrategy{Type: appsv1beta1.RecreateVolumeClaimUpdateStrategyType}
+				set.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1beta1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+					WhenDeleted: appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType,
+					WhenScaled:  appsv1beta1.DeletePersistentVolumeClaimRetentionPolicyType,
+				}
+			},
+		},
+		{
+			name: "update size of template with OnDelete volumeClaimUpdateStrategy",
+			update: func(set *appsv1beta1.StatefulSet) {
+				set.Spec.VolumeClaimTemplates[0] = newClaim("data", "20Gi")
+			},
+			expectErr: true,
+		},
+		{
+			name: "update size of template with OnDelete updateStrategy",
+			update: func(set *appsv1beta1.StatefulSet) {
+				set.Spec.VolumeClaimTemplates[0] = newClaim("data", "20Gi")
+				set.Spec.VolumeClaimUpdateStrategy = &appsv1beta1.VolumeClaimUpdateStrategy{Type: appsv1beta1.RecreateVolumeClaimUpdateStrategyType}
+				set.Spec.UpdateStrategy.Type = apps.OnDeleteStatefulSetStrategyType
+			},
+			expectErr: true,
+		},
+		{
+			name: "rename template",
+			update: func(set *appsv1beta1.StatefulSet) {
+				set.Spec.VolumeClaimTemplates[0] = newClaim("log", "10Gi")
+			},
+			expectErr: true,
+		},
+		{
+			name: "add template",
+			update: func(set *appsv1beta1.StatefulSet) {
+				set.Spec.VolumeClaimTemplates = append(set.Spec.VolumeClaimTemplates, newClaim("log", "10Gi"))
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			newSet := oldSet.DeepCopy()
+			cs.update(newSet)
+			errs := ValidateStatefulSetUpdate(newSet, oldSet)
+			if cs.expectErr != (len(errs) > 0) {
+				t.Fatalf("expected error %v, got %v", cs.expectErr, errs)
+			}
+		})
+	}
+}
+
+func TestValidateStatefulSetOrdinalsFeatureGate(t *testing.T) {
+	spec := &appsv1beta1.StatefulSetSpec{Replicas: utilpointer.Int32Ptr(1), Ordinals: &appsv1beta1.StatefulSetOrdinals{Start: 1}}
+	errs := validateStatefulSetSpec(spec, field.NewPath("spec"))
+	var forbidden bool
+	for _, err := range errs {
+		if err.Field == "spec.ordinals" && err.Type == field.ErrorTypeForbidden {
+			forbidden = true
+		}
+	}
+	if !forbidden {
+		t.Fatalf("expected ordinals forbidden with feature-gate disabled, got %v", errs)
+	}
+}
+
 func setTestDefault(obj *appsv1beta1.StatefulSet) {
 	if obj.Spec.Replicas == nil {
 		obj.Spec.Replicas = new(int32)
@@ -17,16 +17,22 @@ limitations under the License.
 package validating
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
 	"k8s.io/kubernetes/pkg/apis/core"
@@ -66,6 +72,8 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 	}
 	subSetNames := sets.String{}
 	count := 0
+	hasElasticSubset := false
+	podTemplate := getSubsetPodTemplate(&spec.Template)
 	for i, subset := range spec.Topology.Subsets {
 		if len(subset.Name) == 0 {
 			allErrs = append(allErrs, field.Required(fldPath.Child("topology", "subsets").Index(i).Child("name"), ""))
@@ -101,6 +109,15 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 			allErrs = append(allErrs, apivalidation.ValidateTolerations(coreTolerations, fldPath.Child("topology", "subsets").Index(i).Child("tolerations"))...)
 		}
 
+		if subset.Patch.Raw != nil {
+			allErrs = append(allErrs, validateSubsetPatch(podTemplate, subset.Patch.Raw, fldPath.Child("topology", "subsets").Index(i).Child("patch"))...)
+		}
+
+		if subset.MinReplicas != nil || subset.MaxReplicas != nil {
+			allErrs = append(allErrs, validateSubsetElasticReplicas(expectedReplicas, &subset, fldPath.Child("topology", "subsets").Index(i))...)
+			hasElasticSubset = true
+		}
+
 		if subset.Replicas == nil {
 			continue
 		}
@@ -123,6 +140,12 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets"), sumReplicas, fmt.Sprintf("if replicas of all subsets are provided, the sum of indicated subset replicas %d should equal UnitedDeployment replicas %d", sumReplicas, expectedReplicas)))
 	}
 
+	if hasElasticSubset && count > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets"), count, "replicas of subset can not be used together with minReplicas or maxReplicas"))
+	}
+
+	allErrs = append(allErrs, validateScheduleStrategy(&spec.Topology.ScheduleStrategy, fldPath.Child("topology", "scheduleStrategy"))...)
+
 	if spec.UpdateStrategy.ManualUpdate != nil {
 		for subset := range spec.UpdateStrategy.ManualUpdate.Partitions {
 			if !subSetNames.Has(subset) {
@@ -131,6 +154,163 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 		}
 	}
 
+	switch spec.UpdateStrategy.Type {
+	case "", appsv1alpha1.ManualUpdateStrategyType:
+	case appsv1alpha1.AutoUpdateStrategyType:
+		allErrs = append(allErrs, validateAutoUpdate(spec.UpdateStrategy.AutoUpdate, fldPath.Child("updateStrategy", "autoUpdate"))...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("updateStrategy", "type"), spec.UpdateStrategy.Type,
+			[]string{string(appsv1alpha1.ManualUpdateStrategyType), string(appsv1alpha1.AutoUpdateStrategyType)}))
+	}
+
+	if spec.Template.GenericTemplate != nil && spec.Template.GenericTemplate.Paths.PartitionPath == "" {
+		if spec.UpdateStrategy.Type == appsv1alpha1.AutoUpdateStrategyType {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("updateStrategy", "type"), spec.UpdateStrategy.Type, "genericTemplate without partitionPath does not support Auto update strategy"))
+		}
+		if spec.UpdateStrategy.ManualUpdate != nil {
+			for subset, partition := range spec.UpdateStrategy.ManualUpdate.Partitions {
+				if partition != 0 {
+					allErrs = append(allErrs, field.Invalid(fldPath.Child("updateStrategy", "partitions"), spec.UpdateStrategy.ManualUpdate.Partitions, fmt.Sprintf("partition of subset %s is not supported by genericTemplate without partitionPath", subset)))
+				}
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateSubsetElasticReplicas(udReplicas int32, subset *appsv1alpha1.Subset, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	var minReplicas, maxReplicas int32 = 0, -1
+	if subset.MinReplicas != nil {
+		replicas, err := udctrl.ParseSubsetReplicas(udReplicas, *subset.MinReplicas)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), subset.MinReplicas, fmt.Sprintf("invalid minReplicas %s", subset.MinReplicas.String())))
+		}
+		minReplicas = replicas
+	}
+	if subset.MaxReplicas != nil {
+		replicas, err := udctrl.ParseSubsetReplicas(udReplicas, *subset.MaxReplicas)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), subset.MaxReplicas, fmt.Sprintf("invalid maxReplicas %s", subset.MaxReplicas.String())))
+		}
+		maxReplicas = replicas
+	}
+	if len(allErrs) == 0 && maxReplicas >= 0 && minReplicas > maxReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), subset.MinReplicas, fmt.Sprintf("minReplicas %d should not be greater than maxReplicas %d", minReplicas, maxReplicas)))
+	}
+	return allErrs
+}
+
+func validateScheduleStrategy(strategy *appsv1alpha1.UnitedDeploymentScheduleStrategy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch strategy.Type {
+	case "", appsv1alpha1.FixedUnitedDeploymentScheduleStrategyType, appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), strategy.Type,
+			[]string{string(appsv1alpha1.FixedUnitedDeploymentScheduleStrategyType), string(appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType)}))
+	}
+
+	if strategy.Adaptive == nil {
+		return allErrs
+	}
+	if strategy.Type != appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("type"), strategy.Type, "the type must be Adaptive when using adaptive scheduleStrategy"))
+	}
+	if strategy.Adaptive.RescheduleCriticalSeconds != nil && *strategy.Adaptive.RescheduleCriticalSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("adaptive", "rescheduleCriticalSeconds"), *strategy.Adaptive.RescheduleCriticalSeconds, "rescheduleCriticalSeconds <= 0 is not permitted"))
+	}
+	if strategy.Adaptive.UnschedulableLastSeconds != nil && *strategy.Adaptive.UnschedulableLastSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("adaptive", "unschedulableLastSeconds"), *strategy.Adaptive.UnschedulableLastSeconds, "unschedulableLastSeconds <= 0 is not permitted"))
+	}
+	return allErrs
+}
+
+// validateSubsetPatch applies the patch to the pod template of UnitedDeployment in the same way as the
+// controller does, and validates the pod template that the subset will really get.
+func validateSubsetPatch(template *v1.PodTemplateSpec, patch []byte, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	original := []byte("{}")
+	if template != nil {
+		var err error
+		if original, err = json.Marshal(template); err != nil {
+			return append(allErrs, field.InternalError(fldPath, err))
+		}
+	}
+	modified, err := strategicpatch.StrategicMergePatch(original, patch, &v1.PodTemplateSpec{})
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(patch), fmt.Sprintf("invalid strategic merge patch: %v", err)))
+	}
+	patched := &v1.PodTemplateSpec{}
+	if err = json.Unmarshal(modified, patched); err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(patch), fmt.Sprintf("patch is not applicable to pod template: %v", err)))
+	}
+	// the template itself is invalid and has been reported
+	if template == nil {
+		return allErrs
+	}
+
+	coreTemplate, err := convertor.ConvertPodTemplateSpec(patched)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(patch), fmt.Sprintf("Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec failed: %v", err)))
+	}
+	allErrs = append(allErrs, apivalidation.ValidatePodTemplateSpec(coreTemplate, fldPath, apivalidation.PodValidationOptions{AllowMultipleHugePageResources: true, AllowDownwardAPIHugePages: true})...)
+	return allErrs
+}
+
+// getSubsetPodTemplate returns the pod template in the subset template, or nil if it can not be found.
+func getSubsetPodTemplate(template *appsv1alpha1.SubsetTemplate) *v1.PodTemplateSpec {
+	switch {
+	case template.StatefulSetTemplate != nil:
+		return &template.StatefulSetTemplate.Spec.Template
+	case template.AdvancedStatefulSetTemplate != nil:
+		return &template.AdvancedStatefulSetTemplate.Spec.Template
+	case template.CloneSetTemplate != nil:
+		return &template.CloneSetTemplate.Spec.Template
+	case template.DeploymentTemplate != nil:
+		return &template.DeploymentTemplate.Spec.Template
+	case template.GenericTemplate != nil && template.GenericTemplate.Spec.Raw != nil:
+		generic := template.GenericTemplate
+		spec := map[string]interface{}{}
+		if err := json.Unmarshal(generic.Spec.Raw, &spec); err != nil {
+			return nil
+		}
+		templatePath := generic.Paths.TemplatePath
+		if templatePath == "" {
+			templatePath = appsv1alpha1.DefaultGenericTemplatePath
+		}
+		rawTemplate, found, err := unstructured.NestedMap(map[string]interface{}{"spec": spec}, strings.Split(strings.TrimPrefix(templatePath, "."), ".")...)
+		if err != nil || !found {
+			return nil
+		}
+		podTemplate := &v1.PodTemplateSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawTemplate, podTemplate); err != nil {
+			return nil
+		}
+		return podTemplate
+	}
+	return nil
+}
+
+func validateAutoUpdate(autoUpdate *appsv1alpha1.AutoUpdate, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if autoUpdate == nil {
+		return allErrs
+	}
+
+	switch autoUpdate.Order {
+	case "", appsv1alpha1.OrderedAutoUpdateOrderType, appsv1alpha1.ParallelAutoUpdateOrderType:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("order"), autoUpdate.Order,
+			[]string{string(appsv1alpha1.OrderedAutoUpdateOrderType), string(appsv1alpha1.ParallelAutoUpdateOrderType)}))
+	}
+	if autoUpdate.Partition != nil {
+		allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*autoUpdate.Partition), fldPath.Child("partition"))...)
+	}
+	if autoUpdate.MaxUnavailable != nil {
+		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*autoUpdate.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*autoUpdate.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+	}
 	return allErrs
 }
 
@@ -198,6 +378,10 @@ func validateSubsetTemplateUpdate(template, oldTemplate *appsv1alpha1.SubsetTemp
 		allErrs = append(allErrs, validateAdvancedStatefulSetUpdate(template.AdvancedStatefulSetTemplate, oldTemplate.AdvancedStatefulSetTemplate, fldPath.Child("advancedStatefulSetTemplate"))...)
 	} else if template.DeploymentTemplate != nil && oldTemplate.DeploymentTemplate != nil {
 		allErrs = append(allErrs, validateDeploymentUpdate(template.DeploymentTemplate, oldTemplate.DeploymentTemplate, fldPath.Child("deploymentTemplate"))...)
+	} else if template.GenericTemplate != nil && oldTemplate.GenericTemplate != nil {
+		allErrs = append(allErrs, validateGenericTemplateUpdate(template.GenericTemplate, oldTemplate.GenericTemplate, fldPath.Child("genericTemplate"))...)
+	} else if template.GenericTemplate == nil && oldTemplate.GenericTemplate != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("genericTemplate"), "may not be changed to other kind of template in an update"))
 	}
 
 	return allErrs
@@ -219,10 +403,13 @@ func validateSubsetTemplate(template *appsv1alpha1.SubsetTemplate, selector labe
 	if template.DeploymentTemplate != nil {
 		templateCount++
 	}
+	if template.GenericTemplate != nil {
+		templateCount++
+	}
 	if templateCount < 1 {
-		allErrs = append(allErrs, field.Required(fldPath, "should provide one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, or deploymentTemplate"))
+		allErrs = append(allErrs, field.Required(fldPath, "should provide one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, deploymentTemplate, or genericTemplate"))
 	} else if templateCount > 1 {
-		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, or deploymentTemplate"))
+		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, deploymentTemplate, or genericTemplate"))
 	}
 
 	if template.StatefulSetTemplate != nil {
@@ -264,11 +451,83 @@ func validateSubsetTemplate(template *appsv1alpha1.SubsetTemplate, selector labe
 			return allErrs
 		}
 		allErrs = append(allErrs, appsvalidation.ValidatePodTemplateSpecForReplicaSet(coreTemplate, selector, 0, fldPath.Child("deploymentTemplate", "spec", "template"), apivalidation.PodValidationOptions{AllowMultipleHugePageResources: true, AllowDownwardAPIHugePages: true})...)
+	} else if template.GenericTemplate != nil {
+		labels := labels.Set(template.GenericTemplate.Labels)
+		if !selector.Matches(labels) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("genericTemplate", "metadata", "labels"), template.GenericTemplate.Labels, "`selector` does not match template `labels`"))
+		}
+		allErrs = append(allErrs, validateGenericTemplate(template.GenericTemplate, selector, fldPath.Child("genericTemplate"))...)
 	}
 
 	return allErrs
 }
 
+func validateGenericTemplate(generic *appsv1alpha1.GenericTemplateSpec, selector labels.Selector, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(generic.APIVersion) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("apiVersion"), ""))
+	} else if _, err := schema.ParseGroupVersion(generic.APIVersion); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("apiVersion"), generic.APIVersion, err.Error()))
+	}
+	if len(generic.Kind) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	}
+
+	paths := map[string]string{
+		"replicasPath":            generic.Paths.ReplicasPath,
+		"partitionPath":           generic.Paths.PartitionPath,
+		"selectorPath":            generic.Paths.SelectorPath,
+		"templatePath":            generic.Paths.TemplatePath,
+		"statusReplicasPath":      generic.Paths.StatusReplicasPath,
+		"statusReadyReplicasPath": generic.Paths.StatusReadyReplicasPath,
+		"observedGenerationPath":  generic.Paths.ObservedGenerationPath,
+	}
+	for name, path := range paths {
+		if len(path) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(path, ".") || strings.Contains(path, "..") || strings.HasSuffix(path, ".") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("paths", name), path, "should be a dot-separated path like .spec.replicas"))
+		}
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	if generic.Spec.Raw == nil {
+		return append(allErrs, field.Required(fldPath.Child("spec"), ""))
+	}
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(generic.Spec.Raw, &spec); err != nil {
+		return append(allErrs, field.Invalid(fldPath.Child("spec"), string(generic.Spec.Raw), fmt.Sprintf("should be a JSON object: %v", err)))
+	}
+
+	templatePath := generic.Paths.TemplatePath
+	if templatePath == "" {
+		templatePath = appsv1alpha1.DefaultGenericTemplatePath
+	}
+	templateFields := strings.Split(strings.TrimPrefix(templatePath, "."), ".")
+	rawTemplate, found, err := unstructured.NestedMap(map[string]interface{}{"spec": spec}, templateFields...)
+	if err != nil || !found {
+		return append(allErrs, field.Required(fldPath.Child("paths", "templatePath"), fmt.Sprintf("pod template not found in %s", templatePath)))
+	}
+	template := &v1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawTemplate, template); err != nil {
+		return append(allErrs, field.Invalid(fldPath.Child("paths", "templatePath"), templatePath, fmt.Sprintf("invalid pod template: %v", err)))
+	}
+	coreTemplate, err := convertor.ConvertPodTemplateSpec(template)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Root(), template, fmt.Sprintf("Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec failed: %v", err)))
+		return allErrs
+	}
+	templateFldPath := fldPath
+	for _, f := range templateFields {
+		templateFldPath = templateFldPath.Child(f)
+	}
+	allErrs = append(allErrs, appsvalidation.ValidatePodTemplateSpecForReplicaSet(coreTemplate, selector, 0, templateFldPath, apivalidation.PodValidationOptions{AllowMultipleHugePageResources: true, AllowDownwardAPIHugePages: true})...)
+	return allErrs
+}
+
 func validateStatefulSet(statefulSet *appsv1alpha1.StatefulSetTemplateSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if statefulSet.Spec.Replicas != nil {
@@ -353,6 +612,17 @@ func validateAdvancedStatefulSetUpdate(statefulSet, oldStatefulSet *appsv1alpha1
 	return allErrs
 }
 
+func validateGenericTemplateUpdate(generic, oldGeneric *appsv1alpha1.GenericTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if generic.APIVersion != oldGeneric.APIVersion {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("apiVersion"), "may not be changed in an update"))
+	}
+	if generic.Kind != oldGeneric.Kind {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("kind"), "may not be changed in an update"))
+	}
+	return allErrs
+}
+
 func validateDeploymentUpdate(deployment, oldDeployment *appsv1alpha1.DeploymentTemplateSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -17,6 +17,7 @@ limitations under the License.
 package validating
 
 import (
+	"encoding/json"
 	"strconv"
 	"strings"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -50,7 +52,36 @@ func TestValidateUnitedDeployment(t *testing.T) {
 	replicas2 := intstr.FromString("90%")
 	replicas3 := intstr.FromString("71%")
 	replicas4 := intstr.FromString("29%")
+	genericSpec, _ := json.Marshal(map[string]interface{}{"template": validPodTemplate.Template})
+	genericPodSpec, _ := json.Marshal(map[string]interface{}{"podTemplate": validPodTemplate.Template})
 	successCases := []appsv1alpha1.UnitedDeployment{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+							Patch: runtime.RawExtension{
+								Raw: []byte(`{"spec":{"containers":[{"name":"abc","image":"image:v2"}]}}`),
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
 			Spec: appsv1alpha1.UnitedDeploymentSpec{
@@ -92,6 +123,38 @@ func TestValidateUnitedDeployment(t *testing.T) {
 				},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:        "subset1",
+							MinReplicas: &replicas1,
+							MaxReplicas: &replicas3,
+						},
+						{
+							Name: "subset2",
+						},
+					},
+					ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+						Type: appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+					},
+				},
+			},
+		},
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
 			Spec: appsv1alpha1.UnitedDeploymentSpec{
@@ -195,6 +258,47 @@ func TestValidateUnitedDeployment(t *testing.T) {
 				},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					GenericTemplate: &appsv1alpha1.GenericTemplateSpec{
+						APIVersion: "argoproj.io/v1alpha1",
+						Kind:       "Rollout",
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: runtime.RawExtension{Raw: genericSpec},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					GenericTemplate: &appsv1alpha1.GenericTemplateSpec{
+						APIVersion: "example.io/v1",
+						Kind:       "Workload",
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: runtime.RawExtension{Raw: genericPodSpec},
+						Paths: appsv1alpha1.GenericWorkloadPaths{
+							TemplatePath:  ".spec.podTemplate",
+							PartitionPath: ".spec.strategy.partition",
+						},
+					},
+				},
+				UpdateStrategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+					Type: appsv1alpha1.AutoUpdateStrategyType,
+				},
+			},
+		},
 	}
 
 	for i, successCase := range successCases {
@@ -206,6 +310,8 @@ func TestValidateUnitedDeployment(t *testing.T) {
 		})
 	}
 
+	var negativePartition int32 = -1
+	invalidMaxUnavailable := intstr.FromString("120%")
 	errorCases := map[string]appsv1alpha1.UnitedDeployment{
 		"no pod template label": {
 			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
@@ -460,6 +566,189 @@ func TestValidateUnitedDeployment(t *testing.T) {
 				},
 			},
 		},
+		"invalid auto update": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				UpdateStrategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+					Type: appsv1alpha1.AutoUpdateStrategyType,
+					AutoUpdate: &appsv1alpha1.AutoUpdate{
+						Order:          "Random",
+						Partition:      &negativePartition,
+						MaxUnavailable: &invalidMaxUnavailable,
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:     "subset1",
+							Replicas: &replicas3,
+						},
+						{
+							Name:     "subset2",
+							Replicas: &replicas2,
+						},
+					},
+				},
+			},
+		},
+		"invalid subset patch": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+							Patch: runtime.RawExtension{
+								Raw: []byte(`{"spec":{"containers":"invalid"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"invalid pod template after subset patch": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+							Patch: runtime.RawExtension{
+								Raw: []byte(`{"spec":{"containers":[{"name":"abc","image":""}]}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"elastic replicas used with replicas": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:        "subset1",
+							MaxReplicas: &replicas1,
+						},
+						{
+							Name:     "subset2",
+							Replicas: &replicas1,
+						},
+					},
+				},
+			},
+		},
+		"min replicas greater than max replicas": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:        "subset1",
+							MinReplicas: &replicas2,
+							MaxReplicas: &replicas1,
+						},
+						{
+							Name: "subset2",
+						},
+					},
+				},
+			},
+		},
+		"invalid schedule strategy": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:        "subset1",
+							MaxReplicas: &replicas1,
+						},
+						{
+							Name: "subset2",
+						},
+					},
+					ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+						Type: appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+						Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{
+							RescheduleCriticalSeconds: &negativePartition,
+						},
+					},
+				},
+			},
+		},
 		"duplicated templates": {
 			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
 			Spec: appsv1alpha1.UnitedDeploymentSpec{
@@ -566,6 +855,79 @@ func TestValidateUnitedDeployment(t *testing.T) {
 				},
 			},
 		},
+		"generic template without kind": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					GenericTemplate: &appsv1alpha1.GenericTemplateSpec{
+						APIVersion: "argoproj.io/v1alpha1",
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: runtime.RawExtension{Raw: genericSpec},
+					},
+				},
+			},
+		},
+		"generic template with invalid path": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					GenericTemplate: &appsv1alpha1.GenericTemplateSpec{
+						APIVersion: "argoproj.io/v1alpha1",
+						Kind:       "Rollout",
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: runtime.RawExtension{Raw: genericSpec},
+						Paths: appsv1alpha1.GenericWorkloadPaths{
+							ReplicasPath: "spec..replicas",
+						},
+					},
+				},
+			},
+		},
+		"generic template without pod template": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					GenericTemplate: &appsv1alpha1.GenericTemplateSpec{
+						APIVersion: "argoproj.io/v1alpha1",
+						Kind:       "Rollout",
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: runtime.RawExtension{Raw: genericPodSpec},
+					},
+				},
+			},
+		},
+		"generic template without partition path": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					GenericTemplate: &appsv1alpha1.GenericTemplateSpec{
+						APIVersion: "argoproj.io/v1alpha1",
+						Kind:       "Rollout",
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: runtime.RawExtension{Raw: genericSpec},
+					},
+				},
+				UpdateStrategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+					Type: appsv1alpha1.AutoUpdateStrategyType,
+				},
+			},
+		},
 	}
 
 	for k, v := range errorCases {
@@ -583,7 +945,12 @@ func TestValidateUnitedDeployment(t *testing.T) {
 					field != "spec.topology.subsets" &&
 					field != "spec.topology.subsets[0]" &&
 					field != "spec.topology.subsets[0].name" &&
+					!strings.HasPrefix(field, "spec.topology.subsets[0].patch") &&
+					field != "spec.topology.subsets[0].minReplicas" &&
+					!strings.HasPrefix(field, "spec.topology.scheduleStrategy") &&
 					field != "spec.updateStrategy.partitions" &&
+					field != "spec.updateStrategy.type" &&
+					!strings.HasPrefix(field, "spec.updateStrategy.autoUpdate") &&
 					field != "spec.topology.subsets[0].nodeSelectorTerm.matchExpressions[0].values" {
 					t.Errorf("%s: missing prefix for: %v", k, errs[i])
 				}
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	v1 "k8s.io/api/core/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateLifecycle validates the lifecycle hooks of workload with its pod template.
+func ValidateLifecycle(lifecycle *appspub.Lifecycle, template *v1.PodTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if lifecycle == nil || lifecycle.PreNormal == nil {
+		return allErrs
+	}
+
+	hook := lifecycle.PreNormal
+	hookPath := fldPath.Child("preNormal")
+	if len(hook.LabelsHandler) == 0 && len(hook.FinalizersHandler) == 0 {
+		return append(allErrs, field.Required(hookPath, "labelsHandler or finalizersHandler must be set"))
+	}
+	allErrs = append(allErrs, metavalidation.ValidateLabels(hook.LabelsHandler, hookPath.Child("labelsHandler"))...)
+	for i, f := range hook.FinalizersHandler {
+		for _, msg := range validation.IsQualifiedName(f) {
+			allErrs = append(allErrs, field.Invalid(hookPath.Child("finalizersHandler").Index(i), f, msg))
+		}
+	}
+
+	// the hook would be satisfied as soon as Pod created, if the template already has all of its labels and finalizers
+	if template != nil && isTemplateHooked(hook, template) {
+		allErrs = append(allErrs, field.Forbidden(hookPath, "should not be satisfied by the labels and finalizers in pod template"))
+	}
+	return allErrs
+}
+
+func isTemplateHooked(hook *appspub.LifecycleHook, template *v1.PodTemplateSpec) bool {
+	for k, v := range hook.LabelsHandler {
+		if template.Labels[k] != v {
+			return false
+		}
+	}
+	if !sets.NewString(template.Finalizers...).HasAll(hook.FinalizersHandler...) {
+		return false
+	}
+	return true
+}
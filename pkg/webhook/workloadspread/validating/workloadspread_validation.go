@@ -33,13 +33,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 )
 
 var (
-	controllerKruiseKindCS = appsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")
-	controllerKindRS       = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
-	controllerKindDep      = appsv1.SchemeGroupVersion.WithKind("Deployment")
-	controllerKindJob      = batchv1.SchemeGroupVersion.WithKind("Job")
+	controllerKruiseKindCS  = appsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")
+	controllerKindRS        = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
+	controllerKindDep       = appsv1.SchemeGroupVersion.WithKind("Deployment")
+	controllerKindJob       = batchv1.SchemeGroupVersion.WithKind("Job")
+	controllerKindSts       = appsv1.SchemeGroupVersion.WithKind("StatefulSet")
+	controllerKruiseKindSts = appsv1beta1.SchemeGroupVersion.WithKind("StatefulSet")
 )
 
 func verifyGroupKind(ref *appsv1alpha1.TargetReference, expectedKind string, expectedGroups []string) (bool, error) {
@@ -64,7 +68,7 @@ func verifyGroupKind(ref *appsv1alpha1.TargetReference, expectedKind string, exp
 
 func (h *WorkloadSpreadCreateUpdateHandler) validatingWorkloadSpreadFn(obj *appsv1alpha1.WorkloadSpread) field.ErrorList {
 	// validate ws.spec.
-	allErrs := validateWorkloadSpreadSpec(obj, field.NewPath("spec"))
+	allErrs := validateWorkloadSpreadSpec(obj, controllerfinder.NewControllerFinder(h.Client), field.NewPath("spec"))
 
 	// validate whether ws.spec.targetRef is in conflict with others.
 	wsList := &appsv1alpha1.WorkloadSpreadList{}
@@ -77,7 +81,7 @@ func (h *WorkloadSpreadCreateUpdateHandler) validatingWorkloadSpreadFn(obj *apps
 	return allErrs
 }
 
-func validateWorkloadSpreadSpec(obj *appsv1alpha1.WorkloadSpread, fldPath *field.Path) field.ErrorList {
+func validateWorkloadSpreadSpec(obj *appsv1alpha1.WorkloadSpread, finder *controllerfinder.ControllerFinder, fldPath *field.Path) field.ErrorList {
 	spec := &obj.Spec
 	allErrs := field.ErrorList{}
 
@@ -109,8 +113,25 @@ func validateWorkloadSpreadSpec(obj *appsv1alpha1.WorkloadSpread, fldPath *field
 				if !ok || err != nil {
 					allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference is not valid for Job."))
 				}
+			case controllerKindSts.Kind:
+				ok, err := verifyGroupKind(spec.TargetReference, controllerKindSts.Kind, []string{controllerKindSts.Group, controllerKruiseKindSts.Group})
+				if !ok || err != nil {
+					allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference is not valid for StatefulSet."))
+				}
+				// Pods of StatefulSet are placed into subsets by their ordinals, a percent maxReplicas would move
+				// the ordinal boundaries of subsets and reshuffle the Pods whenever the replicas changes.
+				for i, subset := range spec.Subsets {
+					if subset.MaxReplicas != nil && subset.MaxReplicas.Type == intstr.String {
+						allErrs = append(allErrs, field.Invalid(fldPath.Child("subsets").Index(i).Child("maxReplicas"), subset.MaxReplicas, "percent maxReplicas is not supported for StatefulSet"))
+					}
+				}
 			default:
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference's GroupKind is not permitted."))
+				// other workloads are permitted only if they have scale subresource
+				if ok, err := finder.HasScaleSubresource(spec.TargetReference.APIVersion, spec.TargetReference.Kind); err != nil {
+					allErrs = append(allErrs, field.InternalError(fldPath.Child("targetRef"), err))
+				} else if !ok {
+					allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference's GroupKind is not permitted."))
+				}
 			}
 		}
 	}
@@ -158,6 +179,7 @@ func validateWorkloadSpreadSubsets(subsets []appsv1alpha1.WorkloadSpreadSubset,
 	subSetNames := sets.String{}
 	maxReplicasSum := 0
 	var firstMaxReplicasType *intstr.Type
+	var weightSum int64
 
 	for i, subset := range subsets {
 		subsetName := subset.Name
@@ -214,6 +236,23 @@ func validateWorkloadSpreadSubsets(subsets []appsv1alpha1.WorkloadSpreadSubset,
 
 		//TODO validate patch
 
+		// weight must be specified for all subsets or none of them, and cannot be used together with maxReplicas.
+		if (subset.Weight != nil) != (subsets[0].Weight != nil) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("weight"), subset.Weight, "weight must be specified for all subsets or none of them"))
+			return allErrs
+		}
+		if subset.Weight != nil {
+			if subset.MaxReplicas != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("maxReplicas"), subset.MaxReplicas, "maxReplicas and weight cannot be specified together"))
+				return allErrs
+			}
+			if *subset.Weight < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("weight"), *subset.Weight, "weight must not be negative"))
+				return allErrs
+			}
+			weightSum += int64(*subset.Weight)
+		}
+
 		//1. All subset maxReplicas must be the same type: int or percent.
 		//2. Adaptive: the last subset must be not specified.
 		//3. If all maxReplicas is specified as percent, the total maxReplicas must equal 1, except the last subset is not specified.
@@ -241,7 +280,11 @@ func validateWorkloadSpreadSubsets(subsets []appsv1alpha1.WorkloadSpreadSubset,
 		}
 	}
 
-	if *firstMaxReplicasType == intstr.String && maxReplicasSum < 100 && subsets[len(subsets)-1].MaxReplicas != nil {
+	if subsets[0].Weight != nil && weightSum == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Index(0).Child("weight"), *subsets[0].Weight, "the sum of all subset's weight must be positive"))
+	}
+
+	if firstMaxReplicasType != nil && *firstMaxReplicasType == intstr.String && maxReplicasSum < 100 && subsets[len(subsets)-1].MaxReplicas != nil {
 		allErrs = append(allErrs, field.Invalid(fldPath.Index(0).Child("maxReplicas"), subsets[0].MaxReplicas, "maxReplicas sum of all subsets must equal 100% when type is specified as percent"))
 	}
 	return allErrs
@@ -375,6 +375,43 @@ func TestValidateWorkloadSpreadCreate(t *testing.T) {
 				},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ws-5", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.WorkloadSpreadSpec{
+				TargetReference: &appsv1alpha1.TargetReference{
+					APIVersion: controllerKruiseKindSts.GroupVersion().String(),
+					Kind:       controllerKruiseKindSts.Kind,
+					Name:       "test",
+				},
+				Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+					{
+						Name:        "subset-a",
+						MaxReplicas: &replicas1,
+						RequiredNodeSelectorTerm: &corev1.NodeSelectorTerm{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      "topology.kubernetes.io/zone",
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{"zone-a"},
+								},
+							},
+						},
+					},
+					{
+						Name: "subset-b",
+						RequiredNodeSelectorTerm: &corev1.NodeSelectorTerm{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      "topology.kubernetes.io/zone",
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{"zone-b"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for i, successCase := range successCases {
 		t.Run("success case "+strconv.Itoa(i), func(t *testing.T) {
@@ -427,6 +464,16 @@ func TestValidateWorkloadSpreadCreate(t *testing.T) {
 			},
 			errorSuffix: "spec.targetRef",
 		},
+		{
+			name: "targetRef's group of StatefulSet is not valid",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.TargetReference.APIVersion = "batch/v1"
+				workloadSpread.Spec.TargetReference.Kind = controllerKindSts.Kind
+				return workloadSpread
+			},
+			errorSuffix: "spec.targetRef",
+		},
 		{
 			name: "subsets is nil",
 			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
@@ -624,6 +671,52 @@ func TestValidateWorkloadSpreadCreate(t *testing.T) {
 			},
 			errorSuffix: "spec.subsets[0].maxReplicas",
 		},
+		{
+			name: "subset-a's maxReplicas is percent for StatefulSet",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.TargetReference.APIVersion = controllerKindSts.GroupVersion().String()
+				workloadSpread.Spec.TargetReference.Kind = controllerKindSts.Kind
+				maxReplicas := intstr.FromString("50%")
+				workloadSpread.Spec.Subsets[0].MaxReplicas = &maxReplicas
+				workloadSpread.Spec.Subsets[1].MaxReplicas = nil
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[0].maxReplicas",
+		},
+		{
+			name: "subset-b's weight is not specified",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.Subsets[0].MaxReplicas = nil
+				workloadSpread.Spec.Subsets[0].Weight = pointer.Int32Ptr(30)
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[1].weight",
+		},
+		{
+			name: "subset-a's maxReplicas and weight are both specified",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				for i := range workloadSpread.Spec.Subsets {
+					workloadSpread.Spec.Subsets[i].Weight = pointer.Int32Ptr(30)
+				}
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[0].maxReplicas",
+		},
+		{
+			name: "the sum of weights is 0",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				for i := range workloadSpread.Spec.Subsets {
+					workloadSpread.Spec.Subsets[i].MaxReplicas = nil
+					workloadSpread.Spec.Subsets[i].Weight = pointer.Int32Ptr(0)
+				}
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[0].weight",
+		},
 		{
 			name: "scheduleStrategy's type is not valid",
 			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
@@ -720,6 +813,17 @@ func TestValidateWorkloadSpreadCreate(t *testing.T) {
 	}
 }
 
+func TestValidateWeightedWorkloadSpreadSubsets(t *testing.T) {
+	workloadSpread := workloadSpreadDemo.DeepCopy()
+	for i, weight := range []int32{20, 30, 50} {
+		workloadSpread.Spec.Subsets[i].MaxReplicas = nil
+		workloadSpread.Spec.Subsets[i].Weight = pointer.Int32Ptr(weight)
+	}
+	if errs := validateWorkloadSpreadSubsets(workloadSpread.Spec.Subsets, field.NewPath("spec").Child("subsets")); len(errs) != 0 {
+		t.Fatalf("expected success: %v", errs)
+	}
+}
+
 func TestValidateWorkloadSpreadTargetRefUpdate(t *testing.T) {
 	oldWorkloadSpread := workloadSpreadDemo.DeepCopy()
 	errorSuffix := "spec.targetRef"